@@ -0,0 +1,1200 @@
+package ssql
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"iter"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ============================================================================
+// RECORD BATCHES - COLUMNAR BLOCKS OF RECORDS
+// ============================================================================
+
+// DefaultBatchSize is the number of rows per RecordBatch used when a
+// batch size of zero or less is given.
+const DefaultBatchSize = 1024
+
+// RecordBatch is a block of records stored column by column.
+// It is an opt-in representation for high-throughput pipelines: a batch
+// costs a handful of allocations per column instead of a map per record,
+// and int64, float64, bool and string values are stored unboxed.
+//
+// Batches are built with Batched or the batch readers, processed with
+// WhereBatch, SelectBatch and GroupByFieldsBatch, and turned back into
+// records with Unbatched. A batch should not be modified once it has been
+// passed to the next stage of a pipeline.
+//
+// Example:
+//
+//	batches, _ := ssql.ReadCSVBatches("sales.csv", 4096)
+//	large := ssql.WhereBatch(func(b *ssql.RecordBatch, row int) bool {
+//	    return ssql.BatchGetOr(b, "amount", row, float64(0)) > 1000
+//	})(batches)
+//	for record := range ssql.Unbatched()(large) {
+//	    fmt.Println(record)
+//	}
+type RecordBatch struct {
+	columns  []*Column
+	index    map[string]int
+	length   int
+	capacity int // expected number of rows, used to size new columns
+}
+
+// columnKind is the storage used by a Column
+type columnKind int
+
+const (
+	kindEmpty  columnKind = iota // no values yet
+	kindInt                      // ints
+	kindFloat                    // floats
+	kindBool                     // bools
+	kindString                   // strs
+	kindBoxed                    // boxed (mixed or other types)
+)
+
+// Column holds the values of one field across the rows of a RecordBatch.
+// While all values of a column share one of the types int64, float64, bool
+// or string they are kept unboxed (see Ints, Floats, Bools and Strings);
+// any other type, or a mix of types, is stored boxed.
+type Column struct {
+	name     string
+	kind     columnKind
+	n        int
+	capacity int
+	ints     []int64
+	floats   []float64
+	bools    []bool
+	strs     []string
+	boxed    []any
+	missing  []bool // missing[i] is true when row i has no value; nil while every row has one
+}
+
+// NewRecordBatch creates an empty RecordBatch with room for capacity rows
+func NewRecordBatch(capacity int) *RecordBatch {
+	if capacity <= 0 {
+		capacity = DefaultBatchSize
+	}
+	return &RecordBatch{index: make(map[string]int), capacity: capacity}
+}
+
+// Len returns the number of rows in the batch
+func (b *RecordBatch) Len() int {
+	return b.length
+}
+
+// Columns returns the columns of the batch in field order
+func (b *RecordBatch) Columns() []*Column {
+	return slices.Clone(b.columns)
+}
+
+// Column returns the column for a field
+func (b *RecordBatch) Column(name string) (*Column, bool) {
+	i, exists := b.index[name]
+	if !exists {
+		return nil, false
+	}
+	return b.columns[i], true
+}
+
+// Append adds a record as a new row
+func (b *RecordBatch) Append(r Record) {
+	for field, value := range r.All() {
+		b.column(field).appendValue(value)
+	}
+	b.length++
+	b.padColumns()
+}
+
+// Record returns row i as a Record, with fields in column order (the order
+// in which fields first appeared in the batch). Fields missing from the row
+// are absent from the record.
+func (b *RecordBatch) Record(i int) Record {
+	result := MakeMutableRecordWithCapacity(len(b.columns))
+	for _, c := range b.columns {
+		if value, ok := c.Value(i); ok {
+			result.set(c.name, value)
+		}
+	}
+	return result.Freeze()
+}
+
+// Records returns an iterator over the rows of the batch as Records
+func (b *RecordBatch) Records() iter.Seq[Record] {
+	return func(yield func(Record) bool) {
+		for i := range b.length {
+			if !yield(b.Record(i)) {
+				return
+			}
+		}
+	}
+}
+
+// Take returns a new batch containing the given rows, in the given order
+func (b *RecordBatch) Take(rows []int) *RecordBatch {
+	result := &RecordBatch{index: maps.Clone(b.index), length: len(rows)}
+	result.columns = make([]*Column, len(b.columns))
+	for i, c := range b.columns {
+		result.columns[i] = c.take(rows)
+	}
+	return result
+}
+
+// Project returns a batch with only the given fields, in the given order.
+// Fields that do not exist are skipped. Column storage is shared with b.
+func (b *RecordBatch) Project(fields ...string) *RecordBatch {
+	result := &RecordBatch{index: make(map[string]int, len(fields)), length: b.length}
+	for _, field := range fields {
+		if c, exists := b.Column(field); exists {
+			if _, dup := result.index[field]; !dup {
+				result.index[field] = len(result.columns)
+				result.columns = append(result.columns, c.clip())
+			}
+		}
+	}
+	return result
+}
+
+// WithColumn returns a batch with a column added, or replaced in place if
+// the field already exists. values must be a []int64, []float64, []bool,
+// []string or []any with one element per row; nil elements of a []any are
+// missing values.
+//
+// Example:
+//
+//	withTotal := ssql.SelectBatch(func(b *ssql.RecordBatch) *ssql.RecordBatch {
+//	    price, _ := b.Column("price")
+//	    qty, _ := b.Column("qty")
+//	    totals := make([]float64, b.Len())
+//	    for i, p := range price.Floats() {
+//	        totals[i] = p * float64(qty.Ints()[i])
+//	    }
+//	    result, _ := b.WithColumn("total", totals)
+//	    return result
+//	})(batches)
+func (b *RecordBatch) WithColumn(name string, values any) (*RecordBatch, error) {
+	c := &Column{name: name}
+	switch v := values.(type) {
+	case []int64:
+		c.kind, c.n, c.ints = kindInt, len(v), v
+	case []float64:
+		c.kind, c.n, c.floats = kindFloat, len(v), v
+	case []bool:
+		c.kind, c.n, c.bools = kindBool, len(v), v
+	case []string:
+		c.kind, c.n, c.strs = kindString, len(v), v
+	case []any:
+		for _, value := range v {
+			if value == nil {
+				c.appendMissing()
+			} else {
+				c.appendValue(value)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported column values %T", values)
+	}
+	if c.n != b.length {
+		return nil, fmt.Errorf("column %s has %d values, batch has %d rows", name, c.n, b.length)
+	}
+
+	result := &RecordBatch{index: maps.Clone(b.index), length: b.length}
+	result.columns = make([]*Column, len(b.columns), len(b.columns)+1)
+	for i, existing := range b.columns {
+		result.columns[i] = existing.clip()
+	}
+	if i, exists := result.index[name]; exists {
+		result.columns[i] = c
+	} else {
+		result.index[name] = len(result.columns)
+		result.columns = append(result.columns, c)
+	}
+	return result, nil
+}
+
+// column returns the column for a field, creating it (with missing values
+// for the rows already in the batch) if needed
+func (b *RecordBatch) column(name string) *Column {
+	if i, exists := b.index[name]; exists {
+		return b.columns[i]
+	}
+	c := &Column{name: name, capacity: b.capacity}
+	for range b.length {
+		c.appendMissing()
+	}
+	b.index[name] = len(b.columns)
+	b.columns = append(b.columns, c)
+	return c
+}
+
+// padColumns marks the current row missing in columns it did not set
+func (b *RecordBatch) padColumns() {
+	for _, c := range b.columns {
+		for c.n < b.length {
+			c.appendMissing()
+		}
+	}
+}
+
+// BatchGet retrieves a typed value from row i of a batch with the same
+// conversions as Get. field must be a top-level field name.
+// Typed columns are read without boxing when T matches their type.
+func BatchGet[T any](b *RecordBatch, field string, row int) (T, bool) {
+	var zero T
+	c, exists := b.Column(field)
+	if !exists || row < 0 || row >= c.n || (c.missing != nil && c.missing[row]) {
+		return zero, false
+	}
+
+	switch p := any(&zero).(type) {
+	case *int64:
+		if c.kind == kindInt {
+			*p = c.ints[row]
+			return zero, true
+		}
+	case *float64:
+		switch c.kind {
+		case kindFloat:
+			*p = c.floats[row]
+			return zero, true
+		case kindInt:
+			*p = float64(c.ints[row])
+			return zero, true
+		}
+	case *string:
+		if c.kind == kindString {
+			*p = c.strs[row]
+			return zero, true
+		}
+	case *bool:
+		if c.kind == kindBool {
+			*p = c.bools[row]
+			return zero, true
+		}
+	}
+
+	val, _ := c.Value(row)
+	if typed, ok := val.(T); ok {
+		return typed, true
+	}
+	return convertTo[T](val)
+}
+
+// BatchGetOr retrieves a typed value from row i of a batch with a default fallback
+func BatchGetOr[T any](b *RecordBatch, field string, row int, defaultVal T) T {
+	if val, ok := BatchGet[T](b, field, row); ok {
+		return val
+	}
+	return defaultVal
+}
+
+// ============================================================================
+// COLUMN ACCESS
+// ============================================================================
+
+// Name returns the field name of the column
+func (c *Column) Name() string {
+	return c.name
+}
+
+// Len returns the number of rows in the column
+func (c *Column) Len() int {
+	return c.n
+}
+
+// Type returns the type shared by all values of the column:
+// TypeInt, TypeFloat, TypeBool or TypeString for unboxed columns, and
+// TypeUnknown for boxed columns and columns without values.
+func (c *Column) Type() FieldType {
+	switch c.kind {
+	case kindInt:
+		return TypeInt
+	case kindFloat:
+		return TypeFloat
+	case kindBool:
+		return TypeBool
+	case kindString:
+		return TypeString
+	default:
+		return TypeUnknown
+	}
+}
+
+// Ints returns the values of a TypeInt column, or nil for other columns.
+// Missing rows hold 0; check them with Has.
+func (c *Column) Ints() []int64 {
+	if c.kind != kindInt {
+		return nil
+	}
+	return c.ints
+}
+
+// Floats returns the values of a TypeFloat column, or nil for other columns.
+// Missing rows hold 0; check them with Has.
+func (c *Column) Floats() []float64 {
+	if c.kind != kindFloat {
+		return nil
+	}
+	return c.floats
+}
+
+// Bools returns the values of a TypeBool column, or nil for other columns.
+// Missing rows hold false; check them with Has.
+func (c *Column) Bools() []bool {
+	if c.kind != kindBool {
+		return nil
+	}
+	return c.bools
+}
+
+// Strings returns the values of a TypeString column, or nil for other columns.
+// Missing rows hold ""; check them with Has.
+func (c *Column) Strings() []string {
+	if c.kind != kindString {
+		return nil
+	}
+	return c.strs
+}
+
+// Has reports whether row i has a value in this column
+func (c *Column) Has(i int) bool {
+	return i >= 0 && i < c.n && (c.missing == nil || !c.missing[i])
+}
+
+// Value returns the value of row i, boxed, and whether the row has one
+func (c *Column) Value(i int) (any, bool) {
+	if !c.Has(i) {
+		return nil, false
+	}
+	switch c.kind {
+	case kindInt:
+		return c.ints[i], true
+	case kindFloat:
+		return c.floats[i], true
+	case kindBool:
+		return c.bools[i], true
+	case kindString:
+		return c.strs[i], true
+	default:
+		return c.boxed[i], true
+	}
+}
+
+// appendValue appends a value, boxing the column if its type differs
+func (c *Column) appendValue(value any) {
+	switch v := value.(type) {
+	case int64:
+		c.appendInt(v)
+	case float64:
+		c.appendFloat(v)
+	case bool:
+		c.appendBool(v)
+	case string:
+		c.appendString(v)
+	default:
+		c.box()
+		c.boxed = append(c.boxed, value)
+		c.appendPresent()
+	}
+}
+
+func (c *Column) appendInt(v int64) {
+	if c.adopt(kindInt) {
+		c.ints = append(c.ints, v)
+	} else {
+		c.boxed = append(c.boxed, v)
+	}
+	c.appendPresent()
+}
+
+func (c *Column) appendFloat(v float64) {
+	if c.adopt(kindFloat) {
+		c.floats = append(c.floats, v)
+	} else {
+		c.boxed = append(c.boxed, v)
+	}
+	c.appendPresent()
+}
+
+func (c *Column) appendBool(v bool) {
+	if c.adopt(kindBool) {
+		c.bools = append(c.bools, v)
+	} else {
+		c.boxed = append(c.boxed, v)
+	}
+	c.appendPresent()
+}
+
+func (c *Column) appendString(v string) {
+	if c.adopt(kindString) {
+		c.strs = append(c.strs, v)
+	} else {
+		c.boxed = append(c.boxed, v)
+	}
+	c.appendPresent()
+}
+
+// appendMissing appends a row without a value
+func (c *Column) appendMissing() {
+	if c.missing == nil {
+		c.missing = make([]bool, c.n, max(c.n+1, c.capacity))
+	}
+	c.missing = append(c.missing, true)
+	switch c.kind {
+	case kindInt:
+		c.ints = append(c.ints, 0)
+	case kindFloat:
+		c.floats = append(c.floats, 0)
+	case kindBool:
+		c.bools = append(c.bools, false)
+	case kindString:
+		c.strs = append(c.strs, "")
+	case kindBoxed:
+		c.boxed = append(c.boxed, nil)
+	}
+	c.n++
+}
+
+// appendPresent records that the row just appended has a value
+func (c *Column) appendPresent() {
+	if c.missing != nil {
+		c.missing = append(c.missing, false)
+	}
+	c.n++
+}
+
+// dropLast removes the last row
+func (c *Column) dropLast() {
+	c.n--
+	switch c.kind {
+	case kindInt:
+		c.ints = c.ints[:c.n]
+	case kindFloat:
+		c.floats = c.floats[:c.n]
+	case kindBool:
+		c.bools = c.bools[:c.n]
+	case kindString:
+		c.strs = c.strs[:c.n]
+	case kindBoxed:
+		c.boxed = c.boxed[:c.n]
+	}
+	if c.missing != nil {
+		c.missing = c.missing[:c.n]
+	}
+}
+
+// adopt prepares the column for a value of kind k and reports whether it
+// can be stored unboxed. An empty column takes on kind k; a column of
+// another kind is converted to boxed storage.
+func (c *Column) adopt(k columnKind) bool {
+	switch c.kind {
+	case k:
+		return true
+	case kindEmpty:
+		// Earlier rows (if any) are all missing: fill them with zero values
+		c.kind = k
+		switch k {
+		case kindInt:
+			c.ints = make([]int64, c.n, max(c.n, c.capacity))
+		case kindFloat:
+			c.floats = make([]float64, c.n, max(c.n, c.capacity))
+		case kindBool:
+			c.bools = make([]bool, c.n, max(c.n, c.capacity))
+		case kindString:
+			c.strs = make([]string, c.n, max(c.n, c.capacity))
+		}
+		return true
+	default:
+		c.box()
+		return false
+	}
+}
+
+// box converts the column to boxed storage
+func (c *Column) box() {
+	if c.kind == kindBoxed {
+		return
+	}
+	boxed := make([]any, c.n, max(c.n+1, c.capacity))
+	for i := range c.n {
+		boxed[i], _ = c.Value(i)
+	}
+	c.kind, c.boxed = kindBoxed, boxed
+	c.ints, c.floats, c.bools, c.strs = nil, nil, nil, nil
+}
+
+// take returns a copy of the column with only the given rows
+func (c *Column) take(rows []int) *Column {
+	result := &Column{name: c.name, kind: c.kind, n: len(rows)}
+	switch c.kind {
+	case kindInt:
+		result.ints = gather(c.ints, rows)
+	case kindFloat:
+		result.floats = gather(c.floats, rows)
+	case kindBool:
+		result.bools = gather(c.bools, rows)
+	case kindString:
+		result.strs = gather(c.strs, rows)
+	case kindBoxed:
+		result.boxed = gather(c.boxed, rows)
+	}
+	if c.missing != nil {
+		result.missing = gather(c.missing, rows)
+	}
+	return result
+}
+
+// clip returns a copy of the column header whose slices cannot be appended
+// to in place, so batches sharing storage stay independent
+func (c *Column) clip() *Column {
+	clipped := *c
+	clipped.ints = slices.Clip(c.ints)
+	clipped.floats = slices.Clip(c.floats)
+	clipped.bools = slices.Clip(c.bools)
+	clipped.strs = slices.Clip(c.strs)
+	clipped.boxed = slices.Clip(c.boxed)
+	clipped.missing = slices.Clip(c.missing)
+	return &clipped
+}
+
+// gather returns values[rows[0]], values[rows[1]], ...
+func gather[E any](values []E, rows []int) []E {
+	result := make([]E, len(rows))
+	for i, row := range rows {
+		result[i] = values[row]
+	}
+	return result
+}
+
+// ============================================================================
+// BATCH ADAPTERS AND FILTERS
+// ============================================================================
+
+// Batched groups records into RecordBatches of up to size rows.
+// A size of zero or less uses DefaultBatchSize.
+//
+// Example:
+//
+//	batches := ssql.Batched(4096)(records)
+func Batched(size int) Filter[Record, *RecordBatch] {
+	if size <= 0 {
+		size = DefaultBatchSize
+	}
+
+	return func(input iter.Seq[Record]) iter.Seq[*RecordBatch] {
+		return func(yield func(*RecordBatch) bool) {
+			batch := NewRecordBatch(size)
+			for record := range input {
+				batch.Append(record)
+				if batch.Len() == size {
+					if !yield(batch) {
+						return
+					}
+					batch = NewRecordBatch(size)
+				}
+			}
+			if batch.Len() > 0 {
+				yield(batch)
+			}
+		}
+	}
+}
+
+// Unbatched expands RecordBatches back into a stream of records
+func Unbatched() Filter[*RecordBatch, Record] {
+	return func(input iter.Seq[*RecordBatch]) iter.Seq[Record] {
+		return func(yield func(Record) bool) {
+			for batch := range input {
+				for i := range batch.Len() {
+					if !yield(batch.Record(i)) {
+						return
+					}
+				}
+			}
+		}
+	}
+}
+
+// WhereBatch keeps the rows of each batch for which predicate returns true
+// (the batch version of Where). Batches left without rows are dropped.
+//
+// Example:
+//
+//	active := ssql.WhereBatch(func(b *ssql.RecordBatch, row int) bool {
+//	    return ssql.BatchGetOr(b, "status", row, "") == "active"
+//	})(batches)
+func WhereBatch(predicate func(b *RecordBatch, row int) bool) Filter[*RecordBatch, *RecordBatch] {
+	return func(input iter.Seq[*RecordBatch]) iter.Seq[*RecordBatch] {
+		return func(yield func(*RecordBatch) bool) {
+			var rows []int
+			for batch := range input {
+				rows = rows[:0]
+				for i := range batch.Len() {
+					if predicate(batch, i) {
+						rows = append(rows, i)
+					}
+				}
+				switch len(rows) {
+				case 0:
+					continue
+				case batch.Len():
+					if !yield(batch) {
+						return
+					}
+				default:
+					if !yield(batch.Take(rows)) {
+						return
+					}
+				}
+			}
+		}
+	}
+}
+
+// SelectBatch transforms each batch (the batch version of Select).
+// Returning nil drops the batch. Use RecordBatch.Project and
+// RecordBatch.WithColumn to remove and compute columns.
+func SelectBatch(fn func(*RecordBatch) *RecordBatch) Filter[*RecordBatch, *RecordBatch] {
+	return func(input iter.Seq[*RecordBatch]) iter.Seq[*RecordBatch] {
+		return func(yield func(*RecordBatch) bool) {
+			for batch := range input {
+				result := fn(batch)
+				if result == nil {
+					continue
+				}
+				if !yield(result) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// ============================================================================
+// BATCH AGGREGATION
+// ============================================================================
+
+// batchAggOp is the operation of a BatchAggregate
+type batchAggOp int
+
+const (
+	batchCount batchAggOp = iota
+	batchSum
+	batchAvg
+	batchMin
+	batchMax
+)
+
+// BatchAggregate is an aggregation computed incrementally from batch
+// columns by GroupByFieldsBatch. Create one with BatchCount, BatchSum,
+// BatchAvg, BatchMin or BatchMax.
+type BatchAggregate struct {
+	op    batchAggOp
+	field string
+}
+
+// BatchCount counts rows (SQL COUNT(*)), or with a field, the rows where
+// the field is present and not null (SQL COUNT(field)). Result: int64.
+func BatchCount(field ...string) BatchAggregate {
+	if len(field) == 0 {
+		return BatchAggregate{op: batchCount}
+	}
+	return BatchAggregate{op: batchCount, field: field[0]}
+}
+
+// BatchSum sums numeric values like Sum. Result: float64, or Decimal when
+// the group contains Decimal values.
+func BatchSum(field string) BatchAggregate {
+	return BatchAggregate{op: batchSum, field: field}
+}
+
+// BatchAvg averages numeric values like Avg. Result: float64, or Decimal
+// when the group contains Decimal values.
+func BatchAvg(field string) BatchAggregate {
+	return BatchAggregate{op: batchAvg, field: field}
+}
+
+// BatchMin finds the smallest numeric value like Min[float64].
+// Result: float64, or Decimal when the group contains Decimal values.
+func BatchMin(field string) BatchAggregate {
+	return BatchAggregate{op: batchMin, field: field}
+}
+
+// BatchMax finds the largest numeric value like Max[float64].
+// Result: float64, or Decimal when the group contains Decimal values.
+func BatchMax(field string) BatchAggregate {
+	return BatchAggregate{op: batchMax, field: field}
+}
+
+// batchAccumulator holds the running state of one BatchAggregate for one group
+type batchAccumulator struct {
+	count    int64
+	sum      float64
+	min, max float64
+	found    bool    // a non-decimal number was seen
+	ints     int64   // sum of the int64 values, kept exact for decimal results
+	floats   float64 // sum of the other non-decimal values
+
+	hasDecimal       bool
+	decSum           Decimal
+	decMin, decMax   Decimal
+	decimalScale     int32
+	decimalMinMaxSet bool
+}
+
+// addInt adds an int64
+func (a *batchAccumulator) addInt(v int64) {
+	a.ints += v
+	a.addNumber(float64(v))
+}
+
+// addFloat adds a non-integer number
+func (a *batchAccumulator) addFloat(v float64) {
+	a.floats += v
+	a.addNumber(v)
+}
+
+// addNumber updates the float64 statistics
+func (a *batchAccumulator) addNumber(v float64) {
+	a.count++
+	a.sum += v
+	if !a.found || v < a.min {
+		a.min = v
+	}
+	if !a.found || v > a.max {
+		a.max = v
+	}
+	a.found = true
+}
+
+// addDecimal adds a Decimal
+func (a *batchAccumulator) addDecimal(d Decimal) {
+	a.count++
+	a.decSum = a.decSum.Add(d)
+	if !a.decimalMinMaxSet || d.Cmp(a.decMin) < 0 {
+		a.decMin = d
+	}
+	if !a.decimalMinMaxSet || d.Cmp(a.decMax) > 0 {
+		a.decMax = d
+	}
+	a.decimalMinMaxSet = true
+	a.decimalScale = max(a.decimalScale, d.Scale())
+	a.hasDecimal = true
+}
+
+// add adds row i of column c (if it is numeric)
+func (a *batchAccumulator) add(c *Column, i int) {
+	switch c.kind {
+	case kindInt:
+		a.addInt(c.ints[i])
+	case kindFloat:
+		a.addFloat(c.floats[i])
+	default:
+		val, _ := c.Value(i)
+		if d, ok := val.(Decimal); ok {
+			a.addDecimal(d)
+		} else if n, ok := val.(int64); ok {
+			a.addInt(n)
+		} else if f, ok := convertTo[float64](val); ok {
+			a.addFloat(f)
+		}
+	}
+}
+
+// result returns the aggregate value for op
+func (a *batchAccumulator) result(op batchAggOp) any {
+	if a.hasDecimal {
+		// Integers in a decimal group are added exactly, other numbers at float precision
+		floats, _ := DecimalFromFloat(a.floats)
+		sum := a.decSum.Add(DecimalFromInt(a.ints)).Add(floats)
+		switch op {
+		case batchSum:
+			return sum
+		case batchAvg:
+			return sum.Div(DecimalFromInt(a.count), max(6, a.decimalScale))
+		case batchMin:
+			if low, _ := DecimalFromFloat(a.min); a.found && low.Cmp(a.decMin) < 0 {
+				return low
+			}
+			return a.decMin
+		case batchMax:
+			if high, _ := DecimalFromFloat(a.max); a.found && high.Cmp(a.decMax) > 0 {
+				return high
+			}
+			return a.decMax
+		}
+	}
+
+	switch op {
+	case batchSum:
+		return a.sum
+	case batchAvg:
+		if a.count == 0 {
+			return 0.0
+		}
+		return a.sum / float64(a.count)
+	case batchMin:
+		return a.min
+	default:
+		return a.max
+	}
+}
+
+// batchGroup is the state of one group in GroupByFieldsBatch
+type batchGroup struct {
+	fields Record
+	rows   int64
+	counts []int64
+	accs   []batchAccumulator
+}
+
+// GroupByFieldsBatch groups the rows of a batch stream by the given fields
+// and computes aggregations column by column, without building a record
+// per row (the batch version of GroupByFields followed by Aggregate).
+// Grouping follows GroupByFields: rows missing a field form their own group
+// and the field stays absent, nulls group together, and rows whose grouping
+// value is a Record or sequence are skipped. Fields must be top-level names.
+//
+// Yields one record per group, in order of first appearance, holding the
+// grouping fields followed by the aggregation results in name order.
+//
+// Example:
+//
+//	summary := ssql.GroupByFieldsBatch([]string{"region"}, map[string]ssql.BatchAggregate{
+//	    "orders":  ssql.BatchCount(),
+//	    "revenue": ssql.BatchSum("amount"),
+//	})(batches)
+func GroupByFieldsBatch(fields []string, aggregations map[string]BatchAggregate) Filter[*RecordBatch, Record] {
+	names := slices.Sorted(maps.Keys(aggregations))
+	aggs := make([]BatchAggregate, len(names))
+	for i, name := range names {
+		aggs[i] = aggregations[name]
+	}
+
+	return func(input iter.Seq[*RecordBatch]) iter.Seq[Record] {
+		return func(yield func(Record) bool) {
+			groups := make(map[string]*batchGroup)
+			var order []*batchGroup
+			var key []byte
+
+			for batch := range input {
+				groupCols := make([]*Column, len(fields))
+				for i, field := range fields {
+					groupCols[i], _ = batch.Column(field)
+				}
+				aggCols := make([]*Column, len(aggs))
+				for i, agg := range aggs {
+					if agg.field != "" {
+						aggCols[i], _ = batch.Column(agg.field)
+					}
+				}
+
+			rows:
+				for row := range batch.Len() {
+					key = key[:0]
+					for i, c := range groupCols {
+						if i > 0 {
+							key = append(key, 0)
+						}
+						var ok bool
+						if key, ok = appendGroupKey(key, c, row); !ok {
+							continue rows
+						}
+					}
+
+					group, exists := groups[string(key)]
+					if !exists {
+						group = &batchGroup{
+							fields: batchGroupFields(fields, groupCols, row),
+							counts: make([]int64, len(aggs)),
+							accs:   make([]batchAccumulator, len(aggs)),
+						}
+						groups[string(key)] = group
+						order = append(order, group)
+					}
+					group.rows++
+
+					for i, agg := range aggs {
+						c := aggCols[i]
+						switch {
+						case agg.op == batchCount && agg.field == "":
+							group.counts[i]++
+						case c == nil || !c.Has(row):
+						case agg.op == batchCount:
+							if c.kind == kindBoxed && IsNull(c.boxed[row]) {
+								continue
+							}
+							group.counts[i]++
+						default:
+							group.accs[i].add(c, row)
+						}
+					}
+				}
+			}
+
+			for _, group := range order {
+				result := group.fields.ToMutable()
+				for i, name := range names {
+					if aggs[i].op == batchCount {
+						result.set(name, group.counts[i])
+					} else {
+						result.set(name, group.accs[i].result(aggs[i].op))
+					}
+				}
+				if !yield(result.Freeze()) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// appendGroupKey appends the grouping key part for row of c, formatted as
+// GroupByFields does. Returns false if the value cannot be grouped.
+func appendGroupKey(key []byte, c *Column, row int) ([]byte, bool) {
+	if c == nil || !c.Has(row) {
+		return append(key, groupKeyMissing...), true
+	}
+	switch c.kind {
+	case kindInt:
+		return strconv.AppendInt(key, c.ints[row], 10), true
+	case kindString:
+		return append(key, c.strs[row]...), true
+	case kindBool:
+		return strconv.AppendBool(key, c.bools[row]), true
+	}
+	val, _ := c.Value(row)
+	switch {
+	case IsNull(val):
+		return append(key, groupKeyNull...), true
+	case !isSimpleValue(val):
+		return key, false
+	default:
+		return fmt.Append(key, val), true
+	}
+}
+
+// batchGroupFields returns the grouping field values of a group's first
+// row; fields the batch has no column for stay absent
+func batchGroupFields(fields []string, cols []*Column, row int) Record {
+	result := MakeMutableRecordWithCapacity(len(fields))
+	for i, field := range fields {
+		if cols[i] == nil {
+			continue
+		}
+		if val, ok := cols[i].Value(row); ok {
+			result.set(field, val)
+		}
+	}
+	return result.Freeze()
+}
+
+// ============================================================================
+// BATCH READERS
+// ============================================================================
+
+// ReadCSVBatchesFromReader reads CSV data into RecordBatches of up to size
+// rows. Cells are parsed straight into typed columns, with the same type
+// inference, schema and Decimals handling as ReadCSVFromReader, and each
+// row gets a _row_number. Reading stops at the first malformed row.
+func ReadCSVBatchesFromReader(reader io.Reader, size int, config ...CSVConfig) iter.Seq[*RecordBatch] {
+	cfg := DefaultCSVConfig()
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if size <= 0 {
+		size = DefaultBatchSize
+	}
+
+	return func(yield func(*RecordBatch) bool) {
+		csvReader := csv.NewReader(bufio.NewReader(reader))
+		csvReader.Comma = cfg.Delimiter
+		csvReader.Comment = cfg.Comment
+		csvReader.ReuseRecord = true
+
+		var headers []string
+		if cfg.HasHeaders {
+			headerRow, err := csvReader.Read()
+			if err != nil {
+				return
+			}
+			headers = slices.Clone(headerRow)
+		}
+
+		batch := NewRecordBatch(size)
+		rowIndex := int64(0)
+		for {
+			row, err := csvReader.Read()
+			if err != nil {
+				break // EOF or error
+			}
+
+			for i, value := range row {
+				var name string
+				if cfg.HasHeaders && len(headers) > 0 {
+					if i >= len(headers) {
+						continue
+					}
+					name = headers[i]
+				} else {
+					name = fmt.Sprintf("col_%d", i)
+				}
+				c := batch.column(name)
+				if c.n > batch.length {
+					// Repeated header: the last cell wins, as in ReadCSVFromReader
+					c.dropLast()
+				}
+				appendCSVCell(c, value, name, cfg)
+			}
+			batch.column("_row_number").appendInt(rowIndex)
+			rowIndex++
+			batch.length++
+			batch.padColumns()
+
+			if batch.Len() == size {
+				if !yield(batch) {
+					return
+				}
+				batch = NewRecordBatch(size)
+			}
+		}
+		if batch.Len() > 0 {
+			yield(batch)
+		}
+	}
+}
+
+// appendCSVCell parses one CSV cell into a column, like csvRowToRecord
+func appendCSVCell(c *Column, value, name string, cfg CSVConfig) {
+	if field, typed := cfg.Schema.Field(name); typed {
+		// For the batch API, keep unparseable cells as parsed (strings)
+		parsed, _ := parseTypedValue(value, field)
+		c.appendValue(parsed)
+		return
+	}
+	if cfg.Decimals {
+		c.appendValue(parseValueDecimal(value))
+		return
+	}
+
+	// Inline parseValue so the parsed value is never boxed
+	s := strings.TrimSpace(value)
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		c.appendInt(i)
+	} else if f, err := strconv.ParseFloat(s, 64); err == nil {
+		c.appendFloat(f)
+	} else if s == "true" || s == "false" {
+		c.appendBool(s == "true")
+	} else {
+		c.appendString(s)
+	}
+}
+
+// ReadCSVBatches reads a CSV file into RecordBatches of up to size rows.
+// See ReadCSVBatchesFromReader.
+//
+// Example:
+//
+//	batches, err := ssql.ReadCSVBatches("large.csv", 4096)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func ReadCSVBatches(filename string, size int, config ...CSVConfig) (iter.Seq[*RecordBatch], error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %s: %w", filename, err)
+	}
+
+	seq := func(yield func(*RecordBatch) bool) {
+		defer file.Close()
+
+		for batch := range ReadCSVBatchesFromReader(file, size, config...) {
+			if !yield(batch) {
+				return
+			}
+		}
+	}
+
+	return seq, nil
+}
+
+// ReadJSONBatchesFromReader reads JSON lines into RecordBatches of up to
+// size rows, decoding like ReadJSONFromReader (including schema and
+// Decimals handling and _line_number). Invalid lines are skipped.
+func ReadJSONBatchesFromReader(reader io.Reader, size int, config ...JSONConfig) iter.Seq[*RecordBatch] {
+	var cfg JSONConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if size <= 0 {
+		size = DefaultBatchSize
+	}
+
+	return func(yield func(*RecordBatch) bool) {
+		scanner := bufio.NewScanner(reader)
+		batch := NewRecordBatch(size)
+		lineNumber := int64(0)
+
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				lineNumber++
+				continue
+			}
+
+			record, err := decodeJSONRecord(line, cfg)
+			if err != nil {
+				lineNumber++
+				continue
+			}
+			if cfg.Schema.Len() > 0 {
+				record, _ = cfg.Schema.Coerce(record)
+			}
+
+			for field, value := range record.All() {
+				batch.column(field).appendValue(value)
+			}
+			batch.column("_line_number").appendInt(lineNumber)
+			lineNumber++
+			batch.length++
+			batch.padColumns()
+
+			if batch.Len() == size {
+				if !yield(batch) {
+					return
+				}
+				batch = NewRecordBatch(size)
+			}
+		}
+		if batch.Len() > 0 {
+			yield(batch)
+		}
+	}
+}
+
+// ReadJSONBatches reads a JSON lines file into RecordBatches of up to size
+// rows. See ReadJSONBatchesFromReader.
+func ReadJSONBatches(filename string, size int, config ...JSONConfig) (iter.Seq[*RecordBatch], error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %s: %w", filename, err)
+	}
+
+	seq := func(yield func(*RecordBatch) bool) {
+		defer file.Close()
+
+		for batch := range ReadJSONBatchesFromReader(file, size, config...) {
+			if !yield(batch) {
+				return
+			}
+		}
+	}
+
+	return seq, nil
+}
@@ -0,0 +1,77 @@
+package ssql
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// generateSalesCSV creates CSV data with n rows of region, product, amount and qty
+func generateSalesCSV(n int) string {
+	regions := []string{"north", "south", "east", "west"}
+	var sb strings.Builder
+	sb.WriteString("region,product,amount,qty\n")
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&sb, "%s,product_%d,%d.%02d,%d\n", regions[i%len(regions)], i%50, i%1000, i%100, i%7)
+	}
+	return sb.String()
+}
+
+// ============================================================================
+// CSV -> WHERE -> GROUP BY BENCHMARKS
+// ============================================================================
+
+func benchmarkRecordPipeline(b *testing.B, n int) {
+	data := generateSalesCSV(n)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		records := ReadCSVFromReader(strings.NewReader(data))
+		large := Where(func(r Record) bool { return GetOr(r, "amount", 0.0) > 100 })(records)
+		grouped := GroupByFields("sales", "region")(large)
+		summary := Aggregate("sales", map[string]AggregateFunc{
+			"count": Count(),
+			"total": Sum("amount"),
+			"avg":   Avg("qty"),
+		})(grouped)
+		for range summary {
+		}
+	}
+}
+
+func benchmarkBatchPipeline(b *testing.B, n int) {
+	data := generateSalesCSV(n)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		batches := ReadCSVBatchesFromReader(strings.NewReader(data), DefaultBatchSize)
+		large := WhereBatch(func(b *RecordBatch, row int) bool { return BatchGetOr(b, "amount", row, 0.0) > 100 })(batches)
+		summary := GroupByFieldsBatch([]string{"region"}, map[string]BatchAggregate{
+			"count": BatchCount(),
+			"total": BatchSum("amount"),
+			"avg":   BatchAvg("qty"),
+		})(large)
+		for range summary {
+		}
+	}
+}
+
+func BenchmarkPipeline_Records_10K(b *testing.B)  { benchmarkRecordPipeline(b, 10000) }
+func BenchmarkPipeline_Batches_10K(b *testing.B)  { benchmarkBatchPipeline(b, 10000) }
+func BenchmarkPipeline_Records_100K(b *testing.B) { benchmarkRecordPipeline(b, 100000) }
+func BenchmarkPipeline_Batches_100K(b *testing.B) { benchmarkBatchPipeline(b, 100000) }
+
+// ============================================================================
+// BATCHED / UNBATCHED ADAPTER BENCHMARKS
+// ============================================================================
+
+func BenchmarkBatchedRoundTrip_10K(b *testing.B) {
+	records := generateRecords(10000, "id")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for range Unbatched()(Batched(DefaultBatchSize)(slices.Values(records))) {
+		}
+	}
+}
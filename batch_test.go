@@ -0,0 +1,332 @@
+package ssql
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// ============================================================================
+// RECORD BATCH TESTS
+// ============================================================================
+
+func TestBatchedRoundTrip(t *testing.T) {
+	var records []Record
+	for i := range 10 {
+		records = append(records, MakeMutableRecord().
+			String("name", fmt.Sprintf("user%d", i)).
+			Int("age", int64(20+i)).
+			Float("score", float64(i)/2).
+			Bool("active", i%2 == 0).
+			Freeze())
+	}
+
+	batches := slices.Collect(Batched(4)(slices.Values(records)))
+	if len(batches) != 3 {
+		t.Fatalf("Expected 3 batches, got %d", len(batches))
+	}
+	if batches[0].Len() != 4 || batches[2].Len() != 2 {
+		t.Errorf("Expected batch sizes 4,4,2, got %d,%d,%d", batches[0].Len(), batches[1].Len(), batches[2].Len())
+	}
+
+	age, ok := batches[0].Column("age")
+	if !ok || age.Type() != TypeInt {
+		t.Fatalf("Expected typed int column for age, got %v", age.Type())
+	}
+	if !slices.Equal(age.Ints(), []int64{20, 21, 22, 23}) {
+		t.Errorf("Unexpected age column %v", age.Ints())
+	}
+	names := make([]string, 0, 4)
+	for _, c := range batches[0].Columns() {
+		names = append(names, c.Name())
+	}
+	if !slices.Equal(names, []string{"name", "age", "score", "active"}) {
+		t.Errorf("Expected columns in field order, got %v", names)
+	}
+
+	result := slices.Collect(Unbatched()(slices.Values(batches)))
+	if len(result) != len(records) {
+		t.Fatalf("Expected %d records, got %d", len(records), len(result))
+	}
+	for i := range records {
+		if !result[i].Equal(records[i]) || !slices.Equal(result[i].Keys(), records[i].Keys()) {
+			t.Errorf("Record %d: expected %v, got %v", i, records[i], result[i])
+		}
+	}
+}
+
+func TestRecordBatchMixedAndMissing(t *testing.T) {
+	batch := NewRecordBatch(4)
+	batch.Append(MakeMutableRecord().Int("id", 1).Int("value", 10).Freeze())
+	batch.Append(MakeMutableRecord().Int("id", 2).String("value", "ten").Freeze())
+	batch.Append(MakeMutableRecord().Int("id", 3).String("extra", "x").Freeze())
+
+	value, _ := batch.Column("value")
+	if value.Type() != TypeUnknown || value.Ints() != nil {
+		t.Errorf("Expected mixed column to be boxed, got %v", value.Type())
+	}
+	if v, ok := value.Value(1); !ok || v != "ten" {
+		t.Errorf("Expected 'ten', got %v", v)
+	}
+	if value.Has(2) {
+		t.Error("Row 2 should have no value")
+	}
+
+	extra, _ := batch.Column("extra")
+	if extra.Type() != TypeString || extra.Has(0) || !extra.Has(2) {
+		t.Errorf("Expected string column missing in rows 0 and 1, got %v", extra.Strings())
+	}
+
+	if _, exists := Get[any](batch.Record(0), "extra"); exists {
+		t.Error("Missing field should be absent from the record")
+	}
+	if got := BatchGetOr(batch, "value", 0, int64(0)); got != 10 {
+		t.Errorf("Expected 10, got %d", got)
+	}
+	if got := BatchGetOr(batch, "id", 2, float64(0)); got != 3 {
+		t.Errorf("Expected int converted to 3.0, got %v", got)
+	}
+	if got := BatchGetOr(batch, "extra", 0, "none"); got != "none" {
+		t.Errorf("Expected default for missing value, got %q", got)
+	}
+}
+
+func TestWhereBatch(t *testing.T) {
+	var records []Record
+	for i := range 10 {
+		records = append(records, MakeMutableRecord().Int("n", int64(i)).Freeze())
+	}
+	even := func(b *RecordBatch, row int) bool { return BatchGetOr(b, "n", row, int64(-1))%2 == 0 }
+
+	batched := WhereBatch(even)(Batched(3)(slices.Values(records)))
+	result := slices.Collect(Unbatched()(batched))
+
+	expected := slices.Collect(Where(func(r Record) bool { return GetOr(r, "n", int64(-1))%2 == 0 })(slices.Values(records)))
+	if len(result) != len(expected) {
+		t.Fatalf("Expected %d records, got %d", len(expected), len(result))
+	}
+	for i := range expected {
+		if !result[i].Equal(expected[i]) {
+			t.Errorf("Record %d: expected %v, got %v", i, expected[i], result[i])
+		}
+	}
+
+	none := slices.Collect(WhereBatch(func(*RecordBatch, int) bool { return false })(Batched(3)(slices.Values(records))))
+	if len(none) != 0 {
+		t.Errorf("Expected empty batches to be dropped, got %d", len(none))
+	}
+}
+
+func TestSelectBatchWithColumn(t *testing.T) {
+	records := []Record{
+		MakeMutableRecord().String("item", "a").Float("price", 2.5).Int("qty", 2).Freeze(),
+		MakeMutableRecord().String("item", "b").Float("price", 1.0).Int("qty", 3).Freeze(),
+	}
+
+	selected := SelectBatch(func(b *RecordBatch) *RecordBatch {
+		price, _ := b.Column("price")
+		qty, _ := b.Column("qty")
+		totals := make([]float64, b.Len())
+		for i, p := range price.Floats() {
+			totals[i] = p * float64(qty.Ints()[i])
+		}
+		result, err := b.Project("item").WithColumn("total", totals)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	})(Batched(0)(slices.Values(records)))
+
+	result := slices.Collect(Unbatched()(selected))
+	if len(result) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(result))
+	}
+	if !slices.Equal(result[0].Keys(), []string{"item", "total"}) {
+		t.Errorf("Expected fields [item total], got %v", result[0].Keys())
+	}
+	if GetOr(result[0], "total", 0.0) != 5.0 || GetOr(result[1], "total", 0.0) != 3.0 {
+		t.Errorf("Unexpected totals %v, %v", result[0], result[1])
+	}
+
+	batch := slices.Collect(Batched(0)(slices.Values(records)))[0]
+	if _, err := batch.WithColumn("bad", []int64{1}); err == nil {
+		t.Error("Expected error for column with wrong length")
+	}
+	if _, err := batch.WithColumn("bad", []int{1, 2}); err == nil {
+		t.Error("Expected error for unsupported column type")
+	}
+}
+
+func TestGroupByFieldsBatch(t *testing.T) {
+	var records []Record
+	regions := []string{"north", "south", "east"}
+	for i := range 50 {
+		r := MakeMutableRecord().String("region", regions[i%3]).Float("amount", float64(i)*1.5)
+		if i%7 == 0 {
+			r = r.Null("amount")
+		}
+		if i%11 != 0 {
+			r = r.Int("units", int64(i%5))
+		}
+		records = append(records, r.Freeze())
+	}
+
+	expected := slices.Collect(Aggregate("rows", map[string]AggregateFunc{
+		"count":   Count(),
+		"amounts": Count("amount"),
+		"total":   Sum("amount"),
+		"average": Avg("amount"),
+		"low":     Min[float64]("amount"),
+		"high":    Max[float64]("units"),
+	})(GroupByFields("rows", "region")(slices.Values(records))))
+
+	result := slices.Collect(GroupByFieldsBatch([]string{"region"}, map[string]BatchAggregate{
+		"count":   BatchCount(),
+		"amounts": BatchCount("amount"),
+		"total":   BatchSum("amount"),
+		"average": BatchAvg("amount"),
+		"low":     BatchMin("amount"),
+		"high":    BatchMax("units"),
+	})(Batched(8)(slices.Values(records))))
+
+	if len(result) != len(expected) {
+		t.Fatalf("Expected %d groups, got %d", len(expected), len(result))
+	}
+	for i := range expected {
+		if !slices.Equal(result[i].Keys(), expected[i].Keys()) {
+			t.Errorf("Group %d: expected fields %v, got %v", i, expected[i].Keys(), result[i].Keys())
+		}
+		for field, want := range expected[i].All() {
+			got, _ := Get[any](result[i], field)
+			if fmt.Sprint(got) != fmt.Sprint(want) {
+				t.Errorf("Group %d field %s: expected %v (%T), got %v (%T)", i, field, want, want, got, got)
+			}
+		}
+	}
+}
+
+func TestGroupByFieldsBatchMissingKeyColumn(t *testing.T) {
+	// The second batch has no "region" column at all
+	records := []Record{
+		MakeMutableRecord().String("region", "north").Int("units", 1).Freeze(),
+		MakeMutableRecord().String("region", "south").Int("units", 2).Freeze(),
+		MakeMutableRecord().Int("units", 3).Freeze(),
+		MakeMutableRecord().Int("units", 4).Freeze(),
+	}
+	aggregations := map[string]BatchAggregate{"total": BatchSum("units")}
+
+	result := slices.Collect(GroupByFieldsBatch([]string{"region"}, aggregations)(Batched(2)(slices.Values(records))))
+	expected := slices.Collect(Aggregate("rows", map[string]AggregateFunc{
+		"total": Sum("units"),
+	})(GroupByFields("rows", "region")(slices.Values(records))))
+
+	if len(result) != len(expected) || len(result) != 3 {
+		t.Fatalf("Expected %d groups, got %v", len(expected), result)
+	}
+	for i := range expected {
+		if !slices.Equal(result[i].Keys(), expected[i].Keys()) {
+			t.Errorf("Group %d: expected fields %v, got %v", i, expected[i].Keys(), result[i].Keys())
+		}
+	}
+	if _, exists := Get[any](result[2], "region"); exists {
+		t.Errorf("Group without the key column should not have the key field, got %v", result[2])
+	}
+	if total := GetOr(result[2], "total", 0.0); total != 7 {
+		t.Errorf("Expected total 7 for the keyless group, got %v", total)
+	}
+}
+
+func TestGroupByFieldsBatchDecimals(t *testing.T) {
+	records := []Record{
+		MakeMutableRecord().String("k", "a").Decimal("price", MustParseDecimal("0.10")).Freeze(),
+		MakeMutableRecord().String("k", "a").Decimal("price", MustParseDecimal("0.20")).Freeze(),
+		MakeMutableRecord().String("k", "a").Int("price", 1).Freeze(),
+		MakeMutableRecord().Int("price", 5).Freeze(),
+	}
+
+	result := slices.Collect(GroupByFieldsBatch([]string{"k"}, map[string]BatchAggregate{
+		"total": BatchSum("price"),
+		"avg":   BatchAvg("price"),
+		"max":   BatchMax("price"),
+	})(Batched(0)(slices.Values(records))))
+
+	if len(result) != 2 {
+		t.Fatalf("Expected 2 groups, got %d", len(result))
+	}
+	if total := GetOr(result[0], "total", Decimal{}); total.String() != "1.3" {
+		t.Errorf("Expected exact total 1.3, got %v", total)
+	}
+	if avg := GetOr(result[0], "avg", Decimal{}); avg.String() != "0.433333" {
+		t.Errorf("Expected average 0.433333, got %v", avg)
+	}
+	if maximum := GetOr(result[0], "max", Decimal{}); maximum.String() != "1" {
+		t.Errorf("Expected max 1, got %v", maximum)
+	}
+	if _, exists := Get[any](result[1], "k"); exists {
+		t.Error("Group of records missing the key should not have the key field")
+	}
+	if total := GetOr(result[1], "total", 0.0); total != 5 {
+		t.Errorf("Expected float total 5, got %v", result[1])
+	}
+}
+
+func TestReadCSVBatchesFromReader(t *testing.T) {
+	csvData := `name,age,score,active
+Alice,30,1.5,true
+Bob,25,2,false
+Charlie,x,3.25,true`
+
+	expected := slices.Collect(ReadCSVFromReader(strings.NewReader(csvData)))
+	batches := slices.Collect(ReadCSVBatchesFromReader(strings.NewReader(csvData), 2))
+	if len(batches) != 2 {
+		t.Fatalf("Expected 2 batches, got %d", len(batches))
+	}
+
+	score, _ := batches[0].Column("score")
+	if score.Type() != TypeUnknown {
+		// 1.5 and 2 parse as float64 and int64, as in ReadCSVFromReader
+		t.Errorf("Expected mixed score column, got %v", score.Type())
+	}
+	rowNumber, _ := batches[1].Column("_row_number")
+	if !slices.Equal(rowNumber.Ints(), []int64{2}) {
+		t.Errorf("Expected _row_number 2, got %v", rowNumber.Ints())
+	}
+
+	result := slices.Collect(Unbatched()(slices.Values(batches)))
+	if len(result) != len(expected) {
+		t.Fatalf("Expected %d records, got %d", len(expected), len(result))
+	}
+	for i := range expected {
+		if !result[i].Equal(expected[i]) || !slices.Equal(result[i].Keys(), expected[i].Keys()) {
+			t.Errorf("Record %d: expected %v, got %v", i, expected[i], result[i])
+		}
+	}
+}
+
+func TestReadJSONBatchesFromReader(t *testing.T) {
+	jsonData := `{"name": "Alice", "age": 30, "tags": ["a"]}
+
+{"name": "Bob", "city": "LA", "age": null}
+not json
+{"age": 41, "name": "Carol"}`
+
+	expected := slices.Collect(ReadJSONFromReader(strings.NewReader(jsonData)))
+	result := slices.Collect(Unbatched()(ReadJSONBatchesFromReader(strings.NewReader(jsonData), 0)))
+	if len(result) != len(expected) {
+		t.Fatalf("Expected %d records, got %d", len(expected), len(result))
+	}
+	for i := range expected {
+		// Batch records list fields in column order, so compare as maps
+		want, _ := json.Marshal(maps.Collect(expected[i].All()))
+		got, _ := json.Marshal(maps.Collect(result[i].All()))
+		if string(got) != string(want) {
+			t.Errorf("Record %d: expected %s, got %s", i, want, got)
+		}
+	}
+	if got := GetOr(result[2], "_line_number", int64(-1)); got != 4 {
+		t.Errorf("Expected _line_number 4, got %d", got)
+	}
+}
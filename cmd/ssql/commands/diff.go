@@ -0,0 +1,133 @@
+package commands
+
+import (
+	"fmt"
+	"iter"
+	"os"
+	"strings"
+
+	cf "github.com/rosscartlidge/autocli/v3"
+	"github.com/rosscartlidge/ssql/v2"
+	"github.com/rosscartlidge/ssql/v2/cmd/ssql/lib"
+)
+
+// RegisterDiff registers the diff subcommand
+func RegisterDiff(cmd *cf.CommandBuilder) *cf.CommandBuilder {
+	cmd.Subcommand("diff").
+		Description("Compare records with a baseline file by key (added, removed, changed)").
+		Example("ssql read-json today.jsonl | ssql diff -key id -right yesterday.jsonl", "Show records added, removed or changed since yesterday").
+		Example("ssql read-csv new.csv | ssql diff -key region -key sku -right old.csv -ignore _row_number", "Diff on a two-field key, ignoring CSV row numbers").
+		Flag("-right", "-r").
+			String().
+			Completer(&cf.FileCompleter{Pattern: "*.{csv,jsonl}"}).
+			Global().
+			Help("Baseline file to compare against (CSV or JSONL)").
+		Done().
+		Flag("-key", "-k").
+			String().
+			Completer(cf.NoCompleter{Hint: "<field-name>"}).
+			Accumulate().
+			Local().
+			Help("Key field or nested path matching records in both inputs").
+		Done().
+		Flag("-ignore").
+			String().
+			Completer(cf.NoCompleter{Hint: "<field-name>"}).
+			Accumulate().
+			Local().
+			Help("Field to leave out of the comparison").
+		Done().
+		Flag("FILE").
+			String().
+			Completer(&cf.FileCompleter{Pattern: "*.jsonl"}).
+			Global().
+			Default("").
+			Help("New input JSONL file (or stdin if not specified)").
+		Done().
+		Handler(func(ctx *cf.Context) error {
+			var inputFile, rightFile string
+
+			if fileVal, ok := ctx.GlobalFlags["FILE"]; ok {
+				inputFile = fileVal.(string)
+			}
+			if rightVal, ok := ctx.GlobalFlags["-right"]; ok {
+				rightFile = rightVal.(string)
+			}
+			if rightFile == "" {
+				return fmt.Errorf("baseline file required (use -right)")
+			}
+
+			var keyFields, ignoreFields []string
+			if len(ctx.Clauses) > 0 {
+				clause := ctx.Clauses[0]
+				keyFields = clauseStrings(clause.Flags["-key"])
+				ignoreFields = clauseStrings(clause.Flags["-ignore"])
+			}
+			if len(keyFields) == 0 {
+				return fmt.Errorf("at least one key field required (use -key)")
+			}
+
+			// Read new records (stdin or file)
+			input, err := lib.OpenInput(inputFile)
+			if err != nil {
+				return fmt.Errorf("opening input: %w", err)
+			}
+			defer input.Close()
+
+			left := lib.ReadJSONL(input)
+
+			// Read baseline file
+			var right iter.Seq[ssql.Record]
+			if strings.HasSuffix(rightFile, ".csv") {
+				right, err = ssql.ReadCSV(rightFile)
+				if err != nil {
+					return fmt.Errorf("reading baseline CSV: %w", err)
+				}
+			} else {
+				rightInput, err := os.Open(rightFile)
+				if err != nil {
+					return fmt.Errorf("opening baseline file: %w", err)
+				}
+				defer rightInput.Close()
+				right = lib.ReadJSONL(rightInput)
+			}
+
+			if len(ignoreFields) > 0 {
+				left = withoutFields(left, ignoreFields)
+				right = withoutFields(right, ignoreFields)
+			}
+
+			// Write output as JSONL
+			if err := lib.WriteJSONL(os.Stdout, ssql.DiffStreams(left, right, keyFields...)); err != nil {
+				return fmt.Errorf("writing output: %w", err)
+			}
+
+			return nil
+		}).
+		Done()
+	return cmd
+}
+
+// clauseStrings returns the non-empty strings of an accumulated clause flag
+func clauseStrings(raw any) []string {
+	var values []string
+	if slice, ok := raw.([]any); ok {
+		for _, v := range slice {
+			if s, ok := v.(string); ok && s != "" {
+				values = append(values, s)
+			}
+		}
+	}
+	return values
+}
+
+// withoutFields removes fields from every record
+func withoutFields(records iter.Seq[ssql.Record], fields []string) iter.Seq[ssql.Record] {
+	return ssql.Select(func(r ssql.Record) ssql.Record {
+		mut := r.ToMutable()
+		for _, field := range fields {
+			mut = mut.Delete(field)
+		}
+		return mut.Freeze()
+	})(records)
+}
@@ -1,8 +1,13 @@
 package commands
 
 import (
+	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	cf "github.com/rosscartlidge/autocli/v3"
 	"github.com/rosscartlidge/ssql/v2"
@@ -15,11 +20,55 @@ func RegisterDistinct(cmd *cf.CommandBuilder) *cf.CommandBuilder {
 		Description("Remove duplicate records").
 		Example("ssql read-csv data.csv | ssql distinct", "Remove duplicate records").
 		Example("ssql read-csv users.csv | ssql include email | ssql distinct", "Get unique email addresses").
+		Example("ssql read-json events.jsonl | ssql distinct -by user_id", "First record for each user").
+		Example("ssql read-json alerts.jsonl | ssql distinct -by host -by check -within 10m -time ts", "At most one alert per host and check every 10 minutes").
+		Example("ssql read-json messages.jsonl | ssql distinct -by message_id -lru 100000", "Drop redeliveries, remembering the last 100,000 IDs").
+		Example("ssql read-json events.jsonl | ssql distinct -by event_id -bloom 10000000 -fp 0.001", "Approximate dedup of ~10M IDs in fixed memory").
 		Flag("-generate", "-g").
 			Bool().
 			Global().
 			Help("Generate Go code instead of executing").
 		Done().
+		Flag("-by").
+			String().
+			Completer(cf.NoCompleter{Hint: "<field-name>"}).
+			Accumulate().
+			Local().
+			Help("Key field; records with the same key values are duplicates (default: the whole record)").
+		Done().
+		Flag("-within").
+			String().
+			Completer(cf.NoCompleter{Hint: "<duration e.g. 10m>"}).
+			Global().
+			Default("").
+			Help("Only drop duplicates within this event-time horizon of the kept record (needs -time)").
+		Done().
+		Flag("-time", "-t").
+			String().
+			Completer(cf.NoCompleter{Hint: "<field-name>"}).
+			Global().
+			Default("").
+			Help("Event time field for -within (RFC3339 timestamp or Unix seconds)").
+		Done().
+		Flag("-lru").
+			Int().
+			Global().
+			Default(0).
+			Help("Remember only the N most recently seen keys").
+		Done().
+		Flag("-bloom").
+			Int().
+			Global().
+			Default(0).
+			Help("Approximate dedup in fixed memory with a Bloom filter sized for N distinct keys").
+		Done().
+		Flag("-fp").
+			String().
+			Completer(cf.NoCompleter{Hint: "<rate e.g. 0.001>"}).
+			Global().
+			Default("").
+			Help("False-positive rate for -bloom: the fraction of new keys wrongly dropped (default 0.01)").
+		Done().
 		Flag("FILE").
 			String().
 			Completer(&cf.FileCompleter{Pattern: "*.jsonl"}).
@@ -39,9 +88,19 @@ func RegisterDistinct(cmd *cf.CommandBuilder) *cf.CommandBuilder {
 				generate = genVal.(bool)
 			}
 
+			var keyFields []string
+			if len(ctx.Clauses) > 0 {
+				keyFields = clauseStrings(ctx.Clauses[0].Flags["-by"])
+			}
+
+			def, err := parseDistinctDef(ctx.GlobalFlags, keyFields)
+			if err != nil {
+				return err
+			}
+
 			// Check if generation is enabled (flag or env var)
 			if shouldGenerate(generate) {
-				return generateDistinctCode()
+				return generateDistinctCode(def)
 			}
 
 			// Read JSONL from stdin or file
@@ -53,16 +112,8 @@ func RegisterDistinct(cmd *cf.CommandBuilder) *cf.CommandBuilder {
 
 			records := lib.ReadJSONL(input)
 
-			// Apply distinct using DistinctBy with JSON serialization for comparison
-			distinct := ssql.DistinctBy(func(r ssql.Record) string {
-				// Use JSON representation as unique key
-				// This is simpler than making Record comparable
-				json := fmt.Sprintf("%v", r)
-				return json
-			})(records)
-
 			// Write output as JSONL
-			if err := lib.WriteJSONL(os.Stdout, distinct); err != nil {
+			if err := lib.WriteJSONL(os.Stdout, def.filter()(records)); err != nil {
 				return fmt.Errorf("writing output: %w", err)
 			}
 
@@ -72,8 +123,120 @@ func RegisterDistinct(cmd *cf.CommandBuilder) *cf.CommandBuilder {
 	return cmd
 }
 
+// distinctDef is the deduplication chosen by the distinct command's flags
+type distinctDef struct {
+	keyFields []string
+	within    time.Duration
+	timeField string
+	lru       int
+	bloom     int
+	fpRate    float64
+}
+
+// parseDistinctDef reads at most one of -within (with -time), -lru or
+// -bloom (with optional -fp)
+func parseDistinctDef(flags map[string]any, keyFields []string) (distinctDef, error) {
+	def := distinctDef{keyFields: keyFields, fpRate: 0.01}
+	withinStr, _ := flags["-within"].(string)
+	def.timeField, _ = flags["-time"].(string)
+	def.lru, _ = flags["-lru"].(int)
+	def.bloom, _ = flags["-bloom"].(int)
+	fpStr, _ := flags["-fp"].(string)
+
+	modes := 0
+	for _, set := range []bool{withinStr != "", def.lru != 0, def.bloom != 0} {
+		if set {
+			modes++
+		}
+	}
+	switch {
+	case modes > 1:
+		return def, fmt.Errorf("use only one of -within, -lru and -bloom")
+	case def.lru < 0:
+		return def, fmt.Errorf("-lru must be positive, got %d", def.lru)
+	case def.bloom < 0:
+		return def, fmt.Errorf("-bloom must be positive, got %d", def.bloom)
+	case def.timeField != "" && withinStr == "":
+		return def, fmt.Errorf("-time only applies to -within")
+	case fpStr != "" && def.bloom == 0:
+		return def, fmt.Errorf("-fp only applies to -bloom")
+	}
+
+	if withinStr != "" {
+		within, err := time.ParseDuration(withinStr)
+		if err != nil || within <= 0 {
+			return def, fmt.Errorf("invalid -within %q: must be a positive duration like 30s, 10m or 1h", withinStr)
+		}
+		if def.timeField == "" {
+			return def, fmt.Errorf("event time field required with -within (use -time)")
+		}
+		def.within = within
+	}
+	if fpStr != "" {
+		fpRate, err := strconv.ParseFloat(fpStr, 64)
+		if err != nil || fpRate <= 0 || fpRate >= 1 {
+			return def, fmt.Errorf("invalid -fp %q: must be a number between 0 and 1", fpStr)
+		}
+		def.fpRate = fpRate
+	}
+	return def, nil
+}
+
+// key returns the deduplication key of r: its key fields, or the whole
+// record if there are none, as JSON with sorted field names so field
+// order does not matter
+func (d distinctDef) key(r ssql.Record) string {
+	if len(d.keyFields) > 0 {
+		r = r.Project(d.keyFields...)
+	}
+	key, _ := json.Marshal(maps.Collect(r.All()))
+	return string(key)
+}
+
+// filter returns the deduplication filter
+func (d distinctDef) filter() ssql.Filter[ssql.Record, ssql.Record] {
+	switch {
+	case d.within > 0:
+		return ssql.DistinctWithin(d.key, d.within, d.timeField)
+	case d.lru > 0:
+		return ssql.DistinctLRU(d.key, d.lru)
+	case d.bloom > 0:
+		return ssql.DistinctBloom(d.key, d.bloom, d.fpRate)
+	default:
+		return ssql.DistinctBy(d.key)
+	}
+}
+
+// code returns the Go expression for the deduplication filter and its imports
+func (d distinctDef) code() (string, []string) {
+	keyArg := "r"
+	if len(d.keyFields) > 0 {
+		quoted := make([]string, len(d.keyFields))
+		for i, f := range d.keyFields {
+			quoted[i] = fmt.Sprintf("%q", f)
+		}
+		keyArg = fmt.Sprintf("r.Project(%s)", strings.Join(quoted, ", "))
+	}
+	keyFn := fmt.Sprintf(`func(r ssql.Record) string {
+		key, _ := json.Marshal(maps.Collect(%s.All()))
+		return string(key)
+	}`, keyArg)
+	imports := []string{"encoding/json", "maps"}
+
+	switch {
+	case d.within > 0:
+		return fmt.Sprintf("ssql.DistinctWithin(%s, %s, %q)", keyFn, durationCode(d.within), d.timeField), append(imports, "time")
+	case d.lru > 0:
+		return fmt.Sprintf("ssql.DistinctLRU(%s, %d)", keyFn, d.lru), imports
+	case d.bloom > 0:
+		return fmt.Sprintf("ssql.DistinctBloom(%s, %d, %v)", keyFn, d.bloom, d.fpRate), imports
+	default:
+		return fmt.Sprintf("ssql.DistinctBy(%s)", keyFn), imports
+	}
+}
+
 // generateDistinctCode generates Go code for the distinct command
-func generateDistinctCode() error {
+func generateDistinctCode(def distinctDef) error {
 	fragments, err := lib.ReadAllCodeFragments()
 	if err != nil {
 		return fmt.Errorf("reading code fragments: %w", err)
@@ -90,9 +253,8 @@ func generateDistinctCode() error {
 		inputVar = "records"
 	}
 	outputVar := "distinct"
-	code := fmt.Sprintf(`%s := ssql.DistinctBy(func(r ssql.Record) string {
-		return fmt.Sprintf("%%v", r)
-	})(%s)`, outputVar, inputVar)
-	frag := lib.NewStmtFragment(outputVar, inputVar, code, []string{"fmt"}, getCommandString())
+	filterCode, imports := def.code()
+	code := fmt.Sprintf("%s := %s(%s)", outputVar, filterCode, inputVar)
+	frag := lib.NewStmtFragment(outputVar, inputVar, code, imports, getCommandString())
 	return lib.WriteCodeFragment(frag)
 }
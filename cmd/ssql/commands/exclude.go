@@ -16,6 +16,7 @@ func RegisterExclude(cmd *cf.CommandBuilder) *cf.CommandBuilder {
 		Description("Exclude specified fields").
 		Example("ssql read-csv data.csv | ssql exclude id created_at updated_at", "Remove metadata fields").
 		Example("ssql read-json api.json | ssql exclude password token secret_key", "Remove sensitive fields").
+		Example("ssql read-json users.jsonl | ssql exclude user.credentials.password", "Remove a nested field by path").
 		Flag("-generate", "-g").
 			Bool().
 			Global().
@@ -26,7 +27,7 @@ func RegisterExclude(cmd *cf.CommandBuilder) *cf.CommandBuilder {
 			Variadic().
 			Completer(cf.NoCompleter{Hint: "<field-name>"}).
 			Global().
-			Help("Fields to exclude").
+			Help("Fields to exclude (nested paths like user.password or items[0] allowed)").
 		Done().
 		Handler(func(ctx *cf.Context) error {
 			var generate bool
@@ -67,7 +68,7 @@ func RegisterExclude(cmd *cf.CommandBuilder) *cf.CommandBuilder {
 			excluder := func(r ssql.Record) ssql.Record {
 				mut := r.ToMutable()
 				for _, field := range fields {
-					mut = mut.Delete(field)
+					mut.DeletePath(field)
 				}
 				return mut.Freeze()
 			}
@@ -112,7 +113,7 @@ func generateExcludeCode(fields []string) error {
 	// Generate delete statements
 	var deleteStmts strings.Builder
 	for _, field := range fields {
-		deleteStmts.WriteString(fmt.Sprintf("\n\t\tmut = mut.Delete(%q)", field))
+		deleteStmts.WriteString(fmt.Sprintf("\n\t\tmut.DeletePath(%q)", field))
 	}
 
 	// Generate code
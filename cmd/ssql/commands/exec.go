@@ -15,6 +15,13 @@ func RegisterExec(cmd *cf.CommandBuilder) *cf.CommandBuilder {
 		Description("Execute command and parse output as records").
 		Example("ssql exec -- ps aux | ssql where -match USER eq root", "Parse ps output and filter for root processes").
 		Example("ssql exec -- ls -la | ssql include FILE SIZE", "Parse ls output and select specific fields").
+		Example("ssql exec -on-error skip -- ps aux | ssql table", "Skip output lines that do not fit the header").
+		Flag("-on-error").
+			String().
+			Global().
+			Default("").
+			Help("What to do with lines that cannot be parsed: skip, fail or dlq=FILE (write them to FILE as JSONL)").
+		Done().
 		Handler(func(ctx *cf.Context) error {
 			// Everything after -- is in ctx.RemainingArgs
 			if len(ctx.RemainingArgs) == 0 {
@@ -24,6 +31,26 @@ func RegisterExec(cmd *cf.CommandBuilder) *cf.CommandBuilder {
 			command := ctx.RemainingArgs[0]
 			args := ctx.RemainingArgs[1:]
 
+			policy, closePolicy, err := onErrorPolicy(ctx.GlobalFlags)
+			if err != nil {
+				return err
+			}
+			if policy != nil {
+				var execErr error
+				records := applyOnError(policy, ssql.ExecCommandSafe(command, args), &execErr)
+
+				// Write as JSONL to stdout
+				writeErr := lib.WriteJSONL(os.Stdout, records)
+				closeErr := closePolicy()
+				if writeErr != nil {
+					return fmt.Errorf("writing JSONL: %w", writeErr)
+				}
+				if execErr != nil {
+					return fmt.Errorf("executing command: %w", execErr)
+				}
+				return closeErr
+			}
+
 			// Execute command and parse output
 			records, err := ssql.ExecCommand(command, args)
 			if err != nil {
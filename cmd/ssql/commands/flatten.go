@@ -0,0 +1,129 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	cf "github.com/rosscartlidge/autocli/v3"
+	"github.com/rosscartlidge/ssql/v2"
+	"github.com/rosscartlidge/ssql/v2/cmd/ssql/lib"
+)
+
+// RegisterFlatten registers the flatten subcommand
+func RegisterFlatten(cmd *cf.CommandBuilder) *cf.CommandBuilder {
+	cmd.Subcommand("flatten").
+		Description("Flatten nested records into dotted field names").
+		Example("ssql read-json users.jsonl | ssql flatten | ssql write-csv users.csv", "Write nested JSON as flat CSV columns").
+		Example("ssql read-json users.jsonl | ssql flatten address", "Flatten only the address field").
+		Example("ssql read-json users.jsonl | ssql flatten -separator _", "Join nested names with underscores").
+		Flag("-generate", "-g").
+			Bool().
+			Global().
+			Help("Generate Go code instead of executing").
+		Done().
+		Flag("-separator", "-s").
+			String().
+			Completer(cf.NoCompleter{Hint: "<separator>"}).
+			Global().
+			Default(".").
+			Help("Separator between nested field names (default: .)").
+		Done().
+		Flag("FIELDS").
+			String().
+			Variadic().
+			Completer(cf.NoCompleter{Hint: "<field-name>"}).
+			Global().
+			Help("Top-level fields to flatten (all nested fields if not specified)").
+		Done().
+		Handler(func(ctx *cf.Context) error {
+			var generate bool
+			var separator string
+			var fields []string
+
+			if genVal, ok := ctx.GlobalFlags["-generate"]; ok {
+				generate = genVal.(bool)
+			}
+
+			if sepVal, ok := ctx.GlobalFlags["-separator"]; ok {
+				separator = sepVal.(string)
+			}
+
+			if fieldsVal, ok := ctx.GlobalFlags["FIELDS"]; ok {
+				switch v := fieldsVal.(type) {
+				case []string:
+					fields = v
+				case []any:
+					for _, item := range v {
+						if s, ok := item.(string); ok {
+							fields = append(fields, s)
+						}
+					}
+				case string:
+					fields = []string{v}
+				}
+			}
+
+			// Check if generation is enabled (flag or env var)
+			if shouldGenerate(generate) {
+				return generateFlattenCode(separator, fields)
+			}
+
+			// Read JSONL from stdin
+			records := lib.ReadJSONL(os.Stdin)
+
+			// Apply flattening
+			flattened := ssql.DotFlatten(separator, fields...)(records)
+
+			// Write output as JSONL
+			if err := lib.WriteJSONL(os.Stdout, flattened); err != nil {
+				return fmt.Errorf("writing output: %w", err)
+			}
+
+			return nil
+		}).
+		Done()
+	return cmd
+}
+
+// generateFlattenCode generates Go code for the flatten command
+func generateFlattenCode(separator string, fields []string) error {
+	// Read all previous code fragments from stdin
+	fragments, err := lib.ReadAllCodeFragments()
+	if err != nil {
+		return fmt.Errorf("reading code fragments: %w", err)
+	}
+
+	// Pass through all previous fragments
+	for _, frag := range fragments {
+		if err := lib.WriteCodeFragment(frag); err != nil {
+			return fmt.Errorf("writing previous fragment: %w", err)
+		}
+	}
+
+	// Get input variable from last fragment
+	var inputVar string
+	if len(fragments) > 0 {
+		inputVar = fragments[len(fragments)-1].Var
+	} else {
+		inputVar = "records"
+	}
+
+	// Generate code
+	outputVar := "flattened"
+	code := fmt.Sprintf(`%s := ssql.DotFlatten(%s)(%s)`, outputVar, flattenArgs(separator, fields), inputVar)
+
+	// Create stmt fragment
+	frag := lib.NewStmtFragment(outputVar, inputVar, code, nil, getCommandString())
+	return lib.WriteCodeFragment(frag)
+}
+
+// flattenArgs formats the separator and field arguments shared by
+// DotFlatten and DotUnflatten
+func flattenArgs(separator string, fields []string) string {
+	args := []string{fmt.Sprintf("%q", separator)}
+	for _, field := range fields {
+		args = append(args, fmt.Sprintf("%q", field))
+	}
+	return strings.Join(args, ", ")
+}
@@ -16,6 +16,7 @@ func RegisterGroupBy(cmd *cf.CommandBuilder) *cf.CommandBuilder {
 		Example("ssql read-csv sales.csv | ssql group-by region -count total", "Count records by region").
 		Example("ssql read-csv sales.csv | ssql group-by region -sum amount total_sales", "Sum sales amount by region").
 		Example("ssql read-csv data.csv | ssql group-by dept -count num_employees -avg salary avg_salary -sum hours total_hours", "Multiple aggregations in one command").
+		Example("ssql read-json orders.jsonl | ssql group-by customer.address.country -sum total revenue", "Group by a nested field path").
 		Flag("-generate", "-g").
 			Bool().
 			Global().
@@ -26,7 +27,7 @@ func RegisterGroupBy(cmd *cf.CommandBuilder) *cf.CommandBuilder {
 			Variadic().
 			Completer(cf.NoCompleter{Hint: "<field-name>"}).
 			Global().
-			Help("Fields to group by").
+			Help("Fields to group by (nested paths like user.address.city allowed)").
 		Done().
 		Flag("-count").
 			Arg("result-name").Completer(cf.NoCompleter{Hint: "<name>"}).Done().
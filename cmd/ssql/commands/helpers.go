@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
 	"iter"
 	"os"
@@ -16,23 +17,12 @@ import (
 
 // Helper functions for command handlers
 
-func extractNumeric(val any) float64 {
-	switch v := val.(type) {
-	case int64:
-		return float64(v)
-	case float64:
-		return v
-	case string:
-		// For strings, use 0 (they'll maintain relative order)
-		return 0
-	default:
-		return 0
-	}
-}
-
-
 // applyOperator applies a comparison operator for where command
+// Null compares as unknown, so every operator (including ne) is false for it.
 func applyOperator(fieldValue any, op string, compareValue string) bool {
+	if ssql.IsNull(fieldValue) {
+		return false
+	}
 	switch op {
 	case "eq":
 		return compareEqual(fieldValue, compareValue)
@@ -71,6 +61,10 @@ func compareEqual(fieldValue any, compareValue string) bool {
 		if num, err := strconv.ParseFloat(compareValue, 64); err == nil {
 			return v == num
 		}
+	case ssql.Decimal:
+		if d, err := ssql.ParseDecimal(compareValue); err == nil {
+			return v.Equal(d)
+		}
 	case bool:
 		if b, err := strconv.ParseBool(compareValue); err == nil {
 			return v == b
@@ -89,6 +83,10 @@ func compareGreater(fieldValue any, compareValue string) bool {
 		if num, err := strconv.ParseFloat(compareValue, 64); err == nil {
 			return v > num
 		}
+	case ssql.Decimal:
+		if d, err := ssql.ParseDecimal(compareValue); err == nil {
+			return v.Cmp(d) > 0
+		}
 	case string:
 		return v > compareValue
 	}
@@ -105,6 +103,10 @@ func compareLess(fieldValue any, compareValue string) bool {
 		if num, err := strconv.ParseFloat(compareValue, 64); err == nil {
 			return v < num
 		}
+	case ssql.Decimal:
+		if d, err := ssql.ParseDecimal(compareValue); err == nil {
+			return v.Cmp(d) < 0
+		}
 	case string:
 		return v < compareValue
 	}
@@ -170,6 +172,88 @@ func buildAggregator(function, field string) (ssql.AggregateFunc, error) {
 	}
 }
 
+// aggSpec is one aggregation flag: -count NAME, or -sum/-avg/-min/-max FIELD NAME
+type aggSpec struct {
+	function string
+	field    string
+	result   string
+}
+
+// parseAggSpecs reads the aggregation flags shared by session and window
+func parseAggSpecs(flags map[string]any) []aggSpec {
+	var specs []aggSpec
+	counts, _ := flags["-count"].([]any)
+	for _, countVal := range counts {
+		// When there's only 1 Arg(), autocli doesn't wrap in a slice
+		if resultName, ok := countVal.(string); ok && resultName != "" {
+			specs = append(specs, aggSpec{function: "count", result: resultName})
+		}
+	}
+	for _, function := range []string{"sum", "avg", "min", "max"} {
+		vals, _ := flags["-"+function].([]any)
+		for _, val := range vals {
+			// When there are 2+ Args(), autocli returns a map with arg names as keys
+			if argsMap, ok := val.(map[string]any); ok {
+				field, _ := argsMap["field"].(string)
+				result, _ := argsMap["result-name"].(string)
+				if field != "" && result != "" {
+					specs = append(specs, aggSpec{function: function, field: field, result: result})
+				}
+			}
+		}
+	}
+	return specs
+}
+
+// buildAggregations builds the Aggregate map for specs
+func buildAggregations(specs []aggSpec) (map[string]ssql.AggregateFunc, error) {
+	aggregations := make(map[string]ssql.AggregateFunc)
+	for _, spec := range specs {
+		agg, err := buildAggregator(spec.function, spec.field)
+		if err != nil {
+			return nil, err
+		}
+		aggregations[spec.result] = agg
+	}
+	return aggregations, nil
+}
+
+// aggregateCode generates the ssql.Aggregate call for specs over inputVar
+func aggregateCode(outputVar, sequenceField, inputVar string, specs []aggSpec) string {
+	return fmt.Sprintf("%s := ssql.Aggregate(%s, %s)(%s)", outputVar, sequenceField, aggregationsCode(specs), inputVar)
+}
+
+// aggregationsCode generates the map[string]ssql.AggregateFunc literal for specs
+func aggregationsCode(specs []aggSpec) string {
+	code := "map[string]ssql.AggregateFunc{\n"
+	for i, spec := range specs {
+		if i > 0 {
+			code += ",\n"
+		}
+		code += fmt.Sprintf("\t\t%q: %s", spec.result, generateAggregatorCode(spec))
+	}
+	return code + ",\n\t}"
+}
+
+// durationCode returns a Go expression for d, e.g. "30 * time.Minute"
+func durationCode(d time.Duration) string {
+	units := []struct {
+		unit time.Duration
+		name string
+	}{
+		{time.Hour, "time.Hour"},
+		{time.Minute, "time.Minute"},
+		{time.Second, "time.Second"},
+		{time.Millisecond, "time.Millisecond"},
+	}
+	for _, u := range units {
+		if d%u.unit == 0 {
+			return fmt.Sprintf("%d * %s", d/u.unit, u.name)
+		}
+	}
+	return fmt.Sprintf("time.Duration(%d)", int64(d))
+}
+
 // unionRecordToKey converts a record to a string key for deduplication (for union command)
 func unionRecordToKey(r ssql.Record) string {
 	// Use JSON representation as unique key
@@ -188,28 +272,7 @@ func chainRecords(firstRecords iter.Seq[ssql.Record], additionalFiles []string)
 
 		// Yield from each additional file
 		for _, file := range additionalFiles {
-			var records iter.Seq[ssql.Record]
-
-			if strings.HasSuffix(file, ".csv") {
-				// Read CSV
-				csvRecords, err := ssql.ReadCSV(file)
-				if err != nil {
-					// Skip file on error
-					continue
-				}
-				records = csvRecords
-			} else {
-				// Read JSONL
-				f, err := os.Open(file)
-				if err != nil {
-					continue
-				}
-				records = lib.ReadJSONL(f)
-				defer f.Close()
-			}
-
-			// Yield from this file
-			for record := range records {
+			for record := range fileRecords(file) {
 				if !yield(record) {
 					return
 				}
@@ -218,6 +281,48 @@ func chainRecords(firstRecords iter.Seq[ssql.Record], additionalFiles []string)
 	}
 }
 
+// mergeRecords merges data sources that are each sorted by field into a
+// single sorted stream (for union -merge-by)
+func mergeRecords(firstRecords iter.Seq[ssql.Record], additionalFiles []string, field string) iter.Seq[ssql.Record] {
+	sources := []iter.Seq[ssql.Record]{firstRecords}
+	for _, file := range additionalFiles {
+		sources = append(sources, fileRecords(file))
+	}
+	return ssql.MergeSortedFunc(ssql.CompareByKeys(ssql.Asc(field)), sources...)
+}
+
+// fileRecords reads a CSV or JSONL file when iterated, yielding nothing if
+// it cannot be opened
+func fileRecords(file string) iter.Seq[ssql.Record] {
+	return func(yield func(ssql.Record) bool) {
+		var records iter.Seq[ssql.Record]
+
+		if strings.HasSuffix(file, ".csv") {
+			// Read CSV
+			csvRecords, err := ssql.ReadCSV(file)
+			if err != nil {
+				// Skip file on error
+				return
+			}
+			records = csvRecords
+		} else {
+			// Read JSONL
+			f, err := os.Open(file)
+			if err != nil {
+				return
+			}
+			defer f.Close()
+			records = lib.ReadJSONL(f)
+		}
+
+		for record := range records {
+			if !yield(record) {
+				return
+			}
+		}
+	}
+}
+
 // shouldGenerate checks if code generation is enabled via flag or environment variable
 // Returns true if:
 //   - The generate flag is explicitly set to true, OR
@@ -230,6 +335,121 @@ func shouldGenerate(flagValue bool) bool {
 	return envValue == "1" || envValue == "true"
 }
 
+// parallelFlag reads the -parallel flag: the number of worker goroutines
+// for per-record evaluation. 0 and 1 mean sequential processing.
+func parallelFlag(flags map[string]any) (int, error) {
+	workers, _ := flags["-parallel"].(int)
+	if workers < 0 {
+		return 0, fmt.Errorf("-parallel must not be negative, got %d", workers)
+	}
+	return workers, nil
+}
+
+// onErrorPolicy reads the -on-error flag of the reader commands: "skip",
+// "fail" or "dlq=FILE". An empty flag returns a nil policy, meaning the
+// command keeps its default behaviour. The returned close function closes
+// the dead-letter file and reports how many records went to it.
+func onErrorPolicy(flags map[string]any) (ssql.ErrorPolicy, func() error, error) {
+	noClose := func() error { return nil }
+	value, _ := flags["-on-error"].(string)
+	switch {
+	case value == "":
+		return nil, noClose, nil
+	case value == "skip":
+		return ssql.SkipErrors(), noClose, nil
+	case value == "fail":
+		return ssql.FailFast(), noClose, nil
+	case strings.HasPrefix(value, "dlq="):
+		filename := strings.TrimPrefix(value, "dlq=")
+		if filename == "" {
+			return nil, nil, fmt.Errorf("-on-error dlq= requires a file name")
+		}
+		deadLetter, err := ssql.CreateDeadLetter(filename)
+		if err != nil {
+			return nil, nil, err
+		}
+		closeDeadLetter := func() error {
+			if n := deadLetter.Count(); n > 0 {
+				fmt.Fprintf(os.Stderr, "%d failed records written to %s\n", n, filename)
+			}
+			return deadLetter.Close()
+		}
+		return deadLetter, closeDeadLetter, nil
+	default:
+		return nil, nil, fmt.Errorf("invalid -on-error %q (use skip, fail or dlq=FILE)", value)
+	}
+}
+
+// applyOnError runs records through the -on-error policy. The policy only
+// sees malformed records (*ssql.RecordError); other errors, such as a
+// command that fails to start, always stop the stream. Iteration stops at
+// the first error that is not skipped, which is stored in *errp.
+func applyOnError(policy ssql.ErrorPolicy, records iter.Seq2[ssql.Record, error], errp *error) iter.Seq[ssql.Record] {
+	return func(yield func(ssql.Record) bool) {
+		for record, err := range records {
+			if err != nil {
+				var recErr *ssql.RecordError
+				if !errors.As(err, &recErr) {
+					*errp = err
+					return
+				}
+				if err := policy.HandleError(err); err != nil {
+					*errp = err
+					return
+				}
+				continue
+			}
+			if !yield(record) {
+				return
+			}
+		}
+	}
+}
+
+// onErrorPolicyCode returns generated Go code declaring the -on-error
+// policy for a reader, and the expression naming it
+func onErrorPolicyCode(value string) (code, policy string) {
+	switch {
+	case value == "fail":
+		return "", "ssql.FailFast()"
+	case strings.HasPrefix(value, "dlq="):
+		code = fmt.Sprintf(`deadLetter, err := ssql.CreateDeadLetter(%q)
+	if err != nil {
+		return fmt.Errorf("creating dead-letter file: %%w", err)
+	}
+	defer deadLetter.Close()
+	`, strings.TrimPrefix(value, "dlq="))
+		return code, "deadLetter"
+	default:
+		return "", "ssql.SkipErrors()"
+	}
+}
+
+// parseByteSize parses a size such as "512MB", "2G" or "1048576".
+// Units are binary: KB = 1024 bytes, MB = 1024 KB, GB = 1024 MB.
+func parseByteSize(s string) (int64, error) {
+	units := []struct {
+		suffix string
+		scale  int64
+	}{
+		{"KB", 1 << 10}, {"MB", 1 << 20}, {"GB", 1 << 30}, {"TB", 1 << 40},
+		{"K", 1 << 10}, {"M", 1 << 20}, {"G", 1 << 30}, {"T", 1 << 40},
+		{"B", 1},
+	}
+	number, scale := strings.ToUpper(strings.TrimSpace(s)), int64(1)
+	for _, u := range units {
+		if strings.HasSuffix(number, u.suffix) {
+			number, scale = strings.TrimSpace(strings.TrimSuffix(number, u.suffix)), u.scale
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(number, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid size %q (use e.g. 512MB or 2GB)", s)
+	}
+	return int64(n * float64(scale)), nil
+}
+
 // getCommandString returns the command line that invoked this command
 // Filters out the -generate flag since it's implied by the code generation context
 // Returns something like "ssql read-csv data.csv" or "ssql where -match age gt 18"
@@ -367,6 +587,8 @@ func applyValueToRecord(mut ssql.MutableRecord, field string, value any) ssql.Mu
 		return mut.Int(field, v)
 	case float64:
 		return mut.Float(field, v)
+	case ssql.Decimal:
+		return mut.Decimal(field, v)
 	case bool:
 		return mut.Bool(field, v)
 	case time.Time:
@@ -1,9 +1,19 @@
 package commands
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
+	cf "github.com/rosscartlidge/autocli/v3"
 	"github.com/rosscartlidge/ssql/v2"
+	"github.com/rosscartlidge/ssql/v2/cmd/ssql/lib"
 )
 
 func TestApplyValueToRecord(t *testing.T) {
@@ -329,6 +339,79 @@ func TestEvaluateExpression_Conditionals(t *testing.T) {
 	}
 }
 
+func TestEvaluateExpression_Decimal(t *testing.T) {
+	price := ssql.MustParseDecimal("19.99")
+	tests := []struct {
+		name       string
+		expression string
+		record     ssql.Record
+		want       any
+		wantErr    bool
+	}{
+		{
+			name:       "decimal times int field",
+			expression: "price * qty",
+			record: ssql.MakeMutableRecord().
+				Decimal("price", price).
+				Int("qty", int64(3)).
+				Freeze(),
+			want:    ssql.MustParseDecimal("59.97"),
+			wantErr: false,
+		},
+		{
+			name:       "decimal from float field",
+			expression: "decimal(a) + decimal(b)",
+			record: ssql.MakeMutableRecord().
+				Float("a", 0.1).
+				Float("b", 0.2).
+				Freeze(),
+			want:    ssql.MustParseDecimal("0.3"),
+			wantErr: false,
+		},
+		{
+			name:       "decimal comparison",
+			expression: "price > 19.5 ? \"high\" : \"low\"",
+			record: ssql.MakeMutableRecord().
+				Decimal("price", price).
+				Freeze(),
+			want:    "high",
+			wantErr: false,
+		},
+		{
+			name:       "decimal division",
+			expression: "price / 2",
+			record: ssql.MakeMutableRecord().
+				Decimal("price", price).
+				Freeze(),
+			want:    ssql.MustParseDecimal("9.995"),
+			wantErr: false,
+		},
+		{
+			name:       "non-decimal arithmetic unchanged",
+			expression: "a + b",
+			record: ssql.MakeMutableRecord().
+				Int("a", int64(2)).
+				Int("b", int64(3)).
+				Freeze(),
+			want:    5,
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := evaluateExpression(tt.expression, tt.record)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("evaluateExpression() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("evaluateExpression() = %v (%T), want %v (%T)", got, got, tt.want, tt.want)
+			}
+		})
+	}
+}
+
 func TestEvaluateExpression_Strings(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -465,3 +548,506 @@ func TestEvaluateExpression_ComplexExpressions(t *testing.T) {
 		})
 	}
 }
+
+func TestOnErrorPolicy(t *testing.T) {
+	records := func(yield func(ssql.Record, error) bool) {
+		good := ssql.MakeMutableRecord().Int("id", 1).Freeze()
+		bad := &ssql.RecordError{Stage: "read-json", Position: 1, Input: "{bad", Err: errors.New("invalid JSON")}
+		_ = yield(good, nil) && yield(ssql.Record{}, bad) && yield(good, nil) && yield(ssql.Record{}, errors.New("read failed"))
+	}
+
+	policy, closePolicy, err := onErrorPolicy(map[string]any{"-on-error": "skip"})
+	if err != nil {
+		t.Fatalf("skip: %v", err)
+	}
+	var readErr error
+	count := 0
+	for range applyOnError(policy, records, &readErr) {
+		count++
+	}
+	if count != 2 || readErr == nil || readErr.Error() != "read failed" {
+		t.Errorf("skip: expected 2 records then the read error, got %d, %v", count, readErr)
+	}
+	if err := closePolicy(); err != nil {
+		t.Error(err)
+	}
+
+	policy, _, _ = onErrorPolicy(map[string]any{"-on-error": "fail"})
+	readErr, count = nil, 0
+	for range applyOnError(policy, records, &readErr) {
+		count++
+	}
+	if count != 1 || readErr == nil || readErr.Error() != "invalid JSON" {
+		t.Errorf("fail: expected 1 record then the parse error, got %d, %v", count, readErr)
+	}
+
+	if policy, _, err := onErrorPolicy(map[string]any{}); policy != nil || err != nil {
+		t.Errorf("Expected no policy without -on-error, got %v, %v", policy, err)
+	}
+	for _, bad := range []string{"ignore", "dlq="} {
+		if _, _, err := onErrorPolicy(map[string]any{"-on-error": bad}); err == nil {
+			t.Errorf("Expected error for -on-error %q", bad)
+		}
+	}
+}
+
+func TestReadCSVConfig(t *testing.T) {
+	const input = "# exported\nitem,price\nwidget,19.99\ngadget,5\n"
+	path := filepath.Join(t.TempDir(), "prices.csv")
+	if err := os.WriteFile(path, []byte(input), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, decimals := range []bool{false, true} {
+		config := readCSVConfig(map[string]any{"-decimals": decimals})
+		records, err := ssql.ReadCSV(path, config)
+		if err != nil {
+			t.Fatalf("ReadCSV: %v", err)
+		}
+		got := slices.Collect(records)
+		if len(got) != 2 || ssql.GetOr(got[0], "item", "") != "widget" {
+			t.Fatalf("decimals=%v: got %v", decimals, got)
+		}
+		price, _ := ssql.Get[any](got[0], "price")
+		if _, isDecimal := price.(ssql.Decimal); isDecimal != decimals {
+			t.Errorf("decimals=%v: price is %T", decimals, price)
+		}
+
+		// The -on-error path reads the same records and ends
+		policy, _, _ := onErrorPolicy(map[string]any{"-on-error": "skip"})
+		var readErr error
+		count := 0
+		for range applyOnError(policy, ssql.ReadCSVSafeFromReader(strings.NewReader(input), config), &readErr) {
+			if count++; count > 2 {
+				break
+			}
+		}
+		if count != 2 || readErr != nil {
+			t.Errorf("decimals=%v: -on-error skip read %d records, %v", decimals, count, readErr)
+		}
+	}
+}
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int64
+	}{
+		{"1048576", 1 << 20},
+		{"512MB", 512 << 20},
+		{"2G", 2 << 30},
+		{"64kb", 64 << 10},
+		{"1.5GB", 3 << 29},
+		{"100B", 100},
+	}
+	for _, tt := range tests {
+		got, err := parseByteSize(tt.input)
+		if err != nil || got != tt.want {
+			t.Errorf("parseByteSize(%q) = %d, %v; want %d", tt.input, got, err, tt.want)
+		}
+	}
+	for _, bad := range []string{"", "MB", "-5MB", "lots"} {
+		if _, err := parseByteSize(bad); err == nil {
+			t.Errorf("parseByteSize(%q) should fail", bad)
+		}
+	}
+}
+
+func TestSortKeysFromContext(t *testing.T) {
+	// ssql sort region -asc + revenue -desc -nulls-first + -by name -natural -nocase -memory 512MB
+	ctx := &cf.Context{
+		GlobalFlags: map[string]any{"FIELDS": []any{"region", "revenue"}, "-memory": "512MB"},
+		Clauses: []cf.Clause{
+			{Flags: map[string]any{"-asc": true}},
+			{Flags: map[string]any{"-desc": true, "-nulls-first": true}},
+			{Flags: map[string]any{"-by": "name", "-natural": true, "-nocase": true}},
+		},
+	}
+	keys, err := sortKeysFromContext(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ssql.SortKey{
+		ssql.Asc("region"),
+		ssql.Desc("revenue").WithNulls(ssql.NullsFirst),
+		ssql.Asc("name").WithMode(ssql.SortNaturalCaseInsensitive),
+	}
+	if len(keys) != len(want) {
+		t.Fatalf("Expected %d keys, got %+v", len(want), keys)
+	}
+	for i := range want {
+		if keys[i].Field != want[i].Field || keys[i].Direction != want[i].Direction || keys[i].Nulls != want[i].Nulls || keys[i].Mode != want[i].Mode {
+			t.Errorf("Key %d = %+v, want %+v", i, keys[i], want[i])
+		}
+	}
+
+	// ssql sort -desc amount id: the clause takes the first field, the rest sort ascending
+	ctx = &cf.Context{
+		GlobalFlags: map[string]any{"FIELDS": []any{"amount", "id"}},
+		Clauses:     []cf.Clause{{Flags: map[string]any{"-desc": true}}},
+	}
+	keys, err = sortKeysFromContext(ctx)
+	if err != nil || len(keys) != 2 || keys[0].Field != "amount" || keys[0].Direction != ssql.Descending ||
+		keys[1].Field != "id" || keys[1].Direction != ssql.Ascending {
+		t.Errorf("Expected amount descending then id, got %+v, %v", keys, err)
+	}
+
+	bad := []*cf.Context{
+		{Clauses: []cf.Clause{{Flags: map[string]any{"-desc": true}}}},
+		{Clauses: []cf.Clause{{Flags: map[string]any{"-by": "a", "-asc": true, "-desc": true}}}},
+		{Clauses: []cf.Clause{{Flags: map[string]any{"-by": "a", "-numeric": true, "-natural": true}}}},
+	}
+	for i, ctx := range bad {
+		if _, err := sortKeysFromContext(ctx); err == nil {
+			t.Errorf("Case %d: expected an error", i)
+		}
+	}
+}
+
+func TestTopRecords(t *testing.T) {
+	records := []ssql.Record{
+		ssql.MakeMutableRecord().String("region", "west").Int("revenue", 10).Freeze(),
+		ssql.MakeMutableRecord().String("region", "west").String("revenue", "n/a").Freeze(),
+		ssql.MakeMutableRecord().String("region", "east").Float("revenue", 5.5).Freeze(),
+		ssql.MakeMutableRecord().String("region", "west").Int("revenue", 30).Freeze(),
+		ssql.MakeMutableRecord().String("region", "east").Int("revenue", 8).Freeze(),
+	}
+	var got []string
+	for r := range topRecords(slices.Values(records), 1, "revenue", []string{"region"}, false) {
+		got = append(got, fmt.Sprintf("%s=%v", ssql.GetOr(r, "region", ""), ssql.GetOr(r, "revenue", 0.0)))
+	}
+	if !slices.Equal(got, []string{"west=30", "east=8"}) {
+		t.Errorf("top per region = %v", got)
+	}
+
+	got = nil
+	for r := range topRecords(slices.Values(records), 2, "revenue", nil, true) {
+		got = append(got, fmt.Sprint(ssql.GetOr(r, "revenue", 0.0)))
+	}
+	if !slices.Equal(got, []string{"5.5", "8"}) {
+		t.Errorf("bottom 2 = %v", got)
+	}
+}
+
+func TestParseAggSpecs(t *testing.T) {
+	flags := map[string]any{
+		"-count": []any{"pages"},
+		"-sum":   []any{map[string]any{"field": "bytes", "result-name": "total"}},
+		"-max":   []any{map[string]any{"field": "ms", "result-name": ""}}, // incomplete: ignored
+	}
+	specs := parseAggSpecs(flags)
+	want := []aggSpec{{function: "count", result: "pages"}, {function: "sum", field: "bytes", result: "total"}}
+	if !slices.Equal(specs, want) {
+		t.Errorf("parseAggSpecs = %+v, want %+v", specs, want)
+	}
+	aggregations, err := buildAggregations(specs)
+	if err != nil || len(aggregations) != 2 {
+		t.Errorf("buildAggregations = %v, %v", aggregations, err)
+	}
+
+	code := aggregateCode("aggregated", "ssql.SessionRecordsField", "sessions", specs)
+	for _, part := range []string{`"pages": ssql.Count()`, `"total": ssql.Sum("bytes")`, "})(sessions)"} {
+		if !strings.Contains(code, part) {
+			t.Errorf("aggregateCode missing %q:\n%s", part, code)
+		}
+	}
+}
+
+func TestDurationCode(t *testing.T) {
+	tests := map[time.Duration]string{
+		30 * time.Minute:        "30 * time.Minute",
+		2 * time.Hour:           "2 * time.Hour",
+		90 * time.Second:        "90 * time.Second",
+		1500 * time.Millisecond: "1500 * time.Millisecond",
+		time.Microsecond:        "time.Duration(1000)",
+	}
+	for d, want := range tests {
+		if got := durationCode(d); got != want {
+			t.Errorf("durationCode(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestParseWindowDef(t *testing.T) {
+	def, err := parseWindowDef(map[string]any{"-tumbling": "5m", "-time": "ts", "-delay": "30s"}, []string{"host"})
+	if err != nil {
+		t.Fatalf("parseWindowDef: %v", err)
+	}
+	want := windowDef{kind: "tumbling", size: 5 * time.Minute, delay: 30 * time.Second, timeField: "ts", keyFields: []string{"host"}}
+	if def.kind != want.kind || def.size != want.size || def.delay != want.delay || def.timeField != want.timeField || !slices.Equal(def.keyFields, want.keyFields) {
+		t.Errorf("parseWindowDef = %+v, want %+v", def, want)
+	}
+	config, ok := def.spec().(ssql.EventTimeWindowConfig)
+	if !ok || config.Size != 5*time.Minute || config.Watermark == nil {
+		t.Errorf("spec = %+v", def.spec())
+	}
+	wantCode := "spec := ssql.Tumbling(5 * time.Minute, \"ts\", \"host\")\n\tspec.Watermark = ssql.BoundedOutOfOrderness(30 * time.Second)"
+	if code := def.specCode(); code != wantCode {
+		t.Errorf("specCode = %q, want %q", code, wantCode)
+	}
+
+	def, err = parseWindowDef(map[string]any{"-sliding": "10m", "-slide": "1m", "-time": "ts"}, nil)
+	if err != nil || def.spec().(ssql.EventTimeWindowConfig).Slide != time.Minute {
+		t.Errorf("sliding window = %+v, %v", def, err)
+	}
+	def, err = parseWindowDef(map[string]any{"-session": "30m", "-time": "ts"}, []string{"user"})
+	if _, ok := def.spec().(ssql.SessionWindowConfig); err != nil || !ok {
+		t.Errorf("session window = %+v, %v", def, err)
+	}
+
+	for name, flags := range map[string]map[string]any{
+		"no window":         {"-time": "ts"},
+		"two windows":       {"-tumbling": "5m", "-session": "5m", "-time": "ts"},
+		"no time":           {"-tumbling": "5m"},
+		"bad size":          {"-tumbling": "soon", "-time": "ts"},
+		"sliding no slide":  {"-sliding": "10m", "-time": "ts"},
+		"slide on tumbling": {"-tumbling": "5m", "-slide": "1m", "-time": "ts"},
+		"delay on session":  {"-session": "5m", "-delay": "1m", "-time": "ts"},
+	} {
+		if _, err := parseWindowDef(flags, nil); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestMergeRecords(t *testing.T) {
+	dir := t.TempDir()
+	tuesday := filepath.Join(dir, "tue.jsonl")
+	wednesday := filepath.Join(dir, "wed.csv")
+	if err := os.WriteFile(tuesday, []byte(`{"ts":"2024-01-02T00:05:00Z","n":2}`+"\n"+`{"ts":"2024-01-02T09:00:00Z","n":4}`+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(wednesday, []byte("ts,n\n2024-01-02T08:00:00Z,3\n2024-01-03T00:00:00Z,5\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	monday := slices.Values([]ssql.Record{
+		ssql.MakeMutableRecord().String("ts", "2024-01-01T23:00:00Z").Int("n", 1).Freeze(),
+		ssql.MakeMutableRecord().String("ts", "2024-01-02T23:59:00Z").Int("n", 5).Freeze(),
+	})
+
+	var got []string
+	for r := range mergeRecords(monday, []string{tuesday, wednesday, filepath.Join(dir, "missing.jsonl")}, "ts") {
+		got = append(got, ssql.GetOr(r, "ts", ""))
+	}
+	want := []string{"2024-01-01T23:00:00Z", "2024-01-02T00:05:00Z", "2024-01-02T08:00:00Z", "2024-01-02T09:00:00Z", "2024-01-02T23:59:00Z", "2024-01-03T00:00:00Z"}
+	if !slices.Equal(got, want) {
+		t.Errorf("mergeRecords = %v, want %v", got, want)
+	}
+
+	chained := slices.Collect(chainRecords(slices.Values([]ssql.Record{}), []string{tuesday, wednesday}))
+	if len(chained) != 4 || ssql.GetOr(chained[2], "ts", "") != "2024-01-02T08:00:00Z" {
+		t.Errorf("chainRecords = %v", chained)
+	}
+}
+
+func TestParseSampleDef(t *testing.T) {
+	tests := []struct {
+		flags    map[string]any
+		keys     []string
+		wantCode string
+	}{
+		{map[string]any{"-n": 100, "-seed": "7"}, nil, "ssql.ReservoirSample[ssql.Record](100, 7)"},
+		{map[string]any{"-n": 5, "-seed": "7"}, []string{"region"}, `ssql.StratifiedSampleSeeded(5, 7, "region")`},
+		{map[string]any{"-n": 5}, []string{"region"}, `ssql.StratifiedSample(5, "region")`},
+		{map[string]any{"-fraction": "0.01", "-seed": "3"}, nil, "ssql.BernoulliSample[ssql.Record](0.01, 3)"},
+		{map[string]any{"-fraction": "0.01", "-seed": "0"}, nil, "ssql.BernoulliSample[ssql.Record](0.01, 0)"},
+		{map[string]any{"-fraction": "0.05"}, []string{"user_id"}, `ssql.HashSample(0.05, "user_id")`},
+		{map[string]any{"-n": 10}, nil, "ssql.ReservoirSample[ssql.Record](10, time.Now().UnixNano())"},
+	}
+	for _, tt := range tests {
+		def, err := parseSampleDef(tt.flags, tt.keys)
+		if err != nil {
+			t.Errorf("parseSampleDef(%v): %v", tt.flags, err)
+			continue
+		}
+		if code, _ := def.code(); code != tt.wantCode {
+			t.Errorf("code = %q, want %q", code, tt.wantCode)
+		}
+	}
+
+	records := make([]ssql.Record, 50)
+	for i := range records {
+		records[i] = ssql.MakeMutableRecord().Int("id", int64(i)).Freeze()
+	}
+	def, _ := parseSampleDef(map[string]any{"-n": 10, "-seed": "1"}, nil)
+	if got := slices.Collect(def.filter()(slices.Values(records))); len(got) != 10 {
+		t.Errorf("Reservoir sample kept %d records", len(got))
+	}
+
+	for name, flags := range map[string]map[string]any{
+		"no size":        {},
+		"both":           {"-n": 5, "-fraction": "0.1"},
+		"bad fraction":   {"-fraction": "1.5"},
+		"not a number":   {"-fraction": "half"},
+		"negative":       {"-n": -1},
+		"seed with hash": {"-fraction": "0.1", "-seed": "4"},
+		"bad seed":       {"-n": 5, "-seed": "x"},
+	} {
+		keys := []string(nil)
+		if name == "seed with hash" {
+			keys = []string{"user"}
+		}
+		if _, err := parseSampleDef(flags, keys); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestParseDistinctDef(t *testing.T) {
+	const byEmail = `func(r ssql.Record) string {
+		key, _ := json.Marshal(maps.Collect(r.Project("email").All()))
+		return string(key)
+	}`
+	tests := []struct {
+		flags    map[string]any
+		keys     []string
+		wantCode string
+	}{
+		{map[string]any{}, []string{"email"}, "ssql.DistinctBy(" + byEmail + ")"},
+		{map[string]any{"-within": "10m", "-time": "ts"}, []string{"email"}, "ssql.DistinctWithin(" + byEmail + `, 10 * time.Minute, "ts")`},
+		{map[string]any{"-lru": 1000}, []string{"email"}, "ssql.DistinctLRU(" + byEmail + ", 1000)"},
+		{map[string]any{"-bloom": 5000, "-fp": "0.001"}, []string{"email"}, "ssql.DistinctBloom(" + byEmail + ", 5000, 0.001)"},
+		{map[string]any{"-bloom": 5000}, []string{"email"}, "ssql.DistinctBloom(" + byEmail + ", 5000, 0.01)"},
+	}
+	for _, tt := range tests {
+		def, err := parseDistinctDef(tt.flags, tt.keys)
+		if err != nil {
+			t.Errorf("parseDistinctDef(%v): %v", tt.flags, err)
+			continue
+		}
+		if code, _ := def.code(); code != tt.wantCode {
+			t.Errorf("code = %q, want %q", code, tt.wantCode)
+		}
+	}
+
+	records := []ssql.Record{
+		ssql.MakeMutableRecord().String("email", "a@x").Int("id", 1).Freeze(),
+		ssql.MakeMutableRecord().String("email", "b@x").Int("id", 2).Freeze(),
+		ssql.MakeMutableRecord().String("email", "a@x").Int("id", 3).Freeze(),
+		ssql.MakeMutableRecord().String("email", "a@x").Int("id", 1).Freeze(),
+	}
+	byKey, _ := parseDistinctDef(map[string]any{}, []string{"email"})
+	if got := slices.Collect(byKey.filter()(slices.Values(records))); len(got) != 2 {
+		t.Errorf("Distinct by email kept %d records, want 2", len(got))
+	}
+	whole, _ := parseDistinctDef(map[string]any{"-lru": 10}, nil)
+	if got := slices.Collect(whole.filter()(slices.Values(records))); len(got) != 3 {
+		t.Errorf("Distinct whole records kept %d records, want 3", len(got))
+	}
+
+	for name, flags := range map[string]map[string]any{
+		"two modes":        {"-lru": 10, "-bloom": 100},
+		"within no time":   {"-within": "10m"},
+		"bad within":       {"-within": "soon", "-time": "ts"},
+		"time alone":       {"-time": "ts"},
+		"fp without bloom": {"-fp": "0.01"},
+		"bad fp":           {"-bloom": 100, "-fp": "1"},
+		"negative lru":     {"-lru": -1},
+	} {
+		if _, err := parseDistinctDef(flags, nil); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestFieldPairMatch(t *testing.T) {
+	left := ssql.MakeMutableRecord().Int("id", 7).Null("ref").String("name", "null").Freeze()
+	tests := []struct {
+		right     ssql.Record
+		leftField string
+		want      bool
+	}{
+		{ssql.MakeMutableRecord().Int("user_id", 7).Freeze(), "id", true},
+		{ssql.MakeMutableRecord().Int("user_id", 8).Freeze(), "id", false},
+		{ssql.MakeMutableRecord().String("user_id", "7").Freeze(), "id", false},
+		{ssql.MakeMutableRecord().Null("user_id").Freeze(), "ref", false},
+		{ssql.MakeMutableRecord().Null("user_id").Freeze(), "name", false},
+		{ssql.MakeMutableRecord().String("user_id", "null").Freeze(), "ref", false},
+		{ssql.MakeMutableRecord().Freeze(), "id", false},
+	}
+	for _, tt := range tests {
+		if got := fieldPairMatch(left, tt.right, tt.leftField, "user_id"); got != tt.want {
+			t.Errorf("fieldPairMatch(%s, %v) = %v, want %v", tt.leftField, tt.right, got, tt.want)
+		}
+	}
+
+	// Nested objects and arrays from JSONL can't be compared with ==
+	nested := slices.Collect(lib.ReadJSONL(strings.NewReader(
+		`{"user": {"id": 1}, "tags": ["a", "b"]}` + "\n" +
+			`{"owner": {"id": 1}, "labels": ["a", "b"]}` + "\n" +
+			`{"owner": {"id": 2}, "labels": ["b"]}` + "\n")))
+	if !fieldPairMatch(nested[0], nested[1], "user", "owner") || !fieldPairMatch(nested[0], nested[1], "tags", "labels") {
+		t.Error("Expected equal nested values to match")
+	}
+	if fieldPairMatch(nested[0], nested[2], "user", "owner") || fieldPairMatch(nested[0], nested[2], "tags", "labels") {
+		t.Error("Expected different nested values not to match")
+	}
+}
+
+func TestApplyOperatorDecimal(t *testing.T) {
+	price := ssql.MustParseDecimal("19.99")
+	tests := []struct {
+		op, value string
+		want      bool
+	}{
+		{"eq", "19.99", true},
+		{"eq", "19.990", true},
+		{"ne", "19.99", false},
+		{"gt", "19.98", true},
+		{"gt", "19.99", false},
+		{"ge", "19.99", true},
+		{"lt", "20", true},
+		{"le", "19.98", false},
+	}
+	for _, tt := range tests {
+		if got := applyOperator(price, tt.op, tt.value); got != tt.want {
+			t.Errorf("19.99 %s %s = %v, want %v", tt.op, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestDecimalJSONLRoundTrip(t *testing.T) {
+	input := []ssql.Record{
+		ssql.MakeMutableRecord().
+			Decimal("price", ssql.MustParseDecimal("19.99")).
+			Decimal("whole", ssql.DecimalFromInt(20)).
+			Decimal("huge", ssql.MustParseDecimal("1.5e200")).
+			Float("ratio", 0.5).
+			Int("count", 3).
+			Freeze(),
+	}
+	var buf bytes.Buffer
+	if err := lib.WriteJSONL(&buf, slices.Values(input)); err != nil {
+		t.Fatal(err)
+	}
+	got := slices.Collect(lib.ReadJSONL(&buf))
+	if len(got) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(got))
+	}
+	r := got[0]
+	if price, ok := ssql.Get[ssql.Decimal](r, "price"); !ok || !price.Equal(ssql.MustParseDecimal("19.99")) {
+		t.Errorf("price = %v", price)
+	}
+	if huge, _ := ssql.Get[ssql.Decimal](r, "huge"); !huge.Equal(ssql.MustParseDecimal("1.5e200")) {
+		t.Errorf("huge = %v", huge)
+	}
+	for field, want := range map[string]string{"price": "ssql.Decimal", "whole": "ssql.Decimal", "huge": "ssql.Decimal", "ratio": "float64", "count": "int64"} {
+		val, _ := ssql.Get[any](r, field)
+		if typ := fmt.Sprintf("%T", val); typ != want {
+			t.Errorf("%s: got %s, want %s", field, typ, want)
+		}
+	}
+
+	// Reading with decimals turns every non-integer number into a Decimal
+	records := slices.Collect(lib.ReadJSON(strings.NewReader(`[{"amount": 0.1, "n": 2}]`), true))
+	if amount, _ := ssql.Get[any](records[0], "amount"); amount != ssql.MustParseDecimal("0.1") {
+		t.Errorf("amount = %T %v, want Decimal 0.1", amount, amount)
+	}
+	if n, _ := ssql.Get[any](records[0], "n"); n != int64(2) {
+		t.Errorf("n = %T %v, want int64 2", n, n)
+	}
+	plain := slices.Collect(lib.ReadJSON(strings.NewReader(`{"amount": 0.1}`), false))
+	if amount, _ := ssql.Get[any](plain[0], "amount"); amount != 0.1 {
+		t.Errorf("amount = %T %v, want float64 0.1", amount, amount)
+	}
+}
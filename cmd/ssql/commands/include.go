@@ -16,6 +16,7 @@ func RegisterInclude(cmd *cf.CommandBuilder) *cf.CommandBuilder {
 		Description("Include only specified fields").
 		Example("ssql read-csv data.csv | ssql include name age", "Select only name and age columns").
 		Example("ssql read-json users.json | ssql include email status | ssql write-csv out.csv", "Extract email and status to CSV").
+		Example("ssql read-json orders.jsonl | ssql include id customer.address.city items[0].sku", "Keep nested fields by path").
 		Flag("-generate", "-g").
 			Bool().
 			Global().
@@ -26,7 +27,7 @@ func RegisterInclude(cmd *cf.CommandBuilder) *cf.CommandBuilder {
 			Variadic().
 			Completer(cf.NoCompleter{Hint: "<field-name>"}).
 			Global().
-			Help("Fields to include").
+			Help("Fields to include (nested paths like user.address.city or items[0].sku allowed)").
 		Done().
 		Handler(func(ctx *cf.Context) error {
 			var generate bool
@@ -63,21 +64,9 @@ func RegisterInclude(cmd *cf.CommandBuilder) *cf.CommandBuilder {
 			// Read JSONL from stdin
 			records := lib.ReadJSONL(os.Stdin)
 
-			// Build included fields map
-			includedMap := make(map[string]bool)
-			for _, field := range fields {
-				includedMap[field] = true
-			}
-
-			// Build inclusion function - delete fields not in the included list
+			// Build inclusion function - keep only the listed fields (nested paths keep their nesting)
 			includer := func(r ssql.Record) ssql.Record {
-				mut := r.ToMutable()
-				for k := range r.All() {
-					if !includedMap[k] {
-						mut = mut.Delete(k)
-					}
-				}
-				return mut.Freeze()
+				return r.Project(fields...)
 			}
 
 			// Apply inclusion
@@ -118,29 +107,16 @@ func generateIncludeCode(fields []string) error {
 	}
 
 	// Generate field list
-	// Build included fields map
-	var includedMap strings.Builder
-	includedMap.WriteString("map[string]bool{")
+	quotedFields := make([]string, len(fields))
 	for i, field := range fields {
-		if i > 0 {
-			includedMap.WriteString(", ")
-		}
-		includedMap.WriteString(fmt.Sprintf("%q: true", field))
+		quotedFields[i] = fmt.Sprintf("%q", field)
 	}
-	includedMap.WriteString("}")
 
 	// Generate code
 	outputVar := "included"
 	code := fmt.Sprintf(`%s := ssql.Select(func(r ssql.Record) ssql.Record {
-		includedMap := %s
-		mut := r.ToMutable()
-		for k := range r.All() {
-			if !includedMap[k] {
-				mut = mut.Delete(k)
-			}
-		}
-		return mut.Freeze()
-	})(%s)`, outputVar, includedMap.String(), inputVar)
+		return r.Project(%s)
+	})(%s)`, outputVar, strings.Join(quotedFields, ", "), inputVar)
 
 	// Create stmt fragment
 	frag := lib.NewStmtFragment(outputVar, inputVar, code, nil, getCommandString())
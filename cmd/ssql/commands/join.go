@@ -17,6 +17,7 @@ func RegisterJoin(cmd *cf.CommandBuilder) *cf.CommandBuilder {
 		Description("Join records from two data sources (SQL JOIN)").
 		Example("ssql read-csv users.csv | ssql join -right orders.csv -on user_id", "Inner join users and orders on user_id").
 		Example("ssql read-csv employees.csv | ssql join -type left -right departments.csv -on dept_id", "Left join employees with departments").
+		Example("ssql read-json orders.jsonl | ssql join -right customers.jsonl -left-field customer.id -right-field id", "Join on a nested field path").
 		Flag("-generate", "-g").
 			Bool().
 			Global().
@@ -40,7 +41,7 @@ func RegisterJoin(cmd *cf.CommandBuilder) *cf.CommandBuilder {
 			Completer(cf.NoCompleter{Hint: "<field-name>"}).
 			Accumulate().
 			Local().
-			Help("Field name for equality join (same name in both sides)").
+			Help("Field name or nested path for equality join (same name in both sides)").
 		Done().
 		Flag("-left-field").
 			String().
@@ -158,12 +159,7 @@ func RegisterJoin(cmd *cf.CommandBuilder) *cf.CommandBuilder {
 			} else {
 				// Use different field names
 				predicate = ssql.OnCondition(func(left, right ssql.Record) bool {
-					leftVal, leftOk := ssql.Get[any](left, leftField)
-					rightVal, rightOk := ssql.Get[any](right, rightField)
-					if !leftOk || !rightOk {
-						return false
-					}
-					return fmt.Sprintf("%v", leftVal) == fmt.Sprintf("%v", rightVal)
+					return fieldPairMatch(left, right, leftField, rightField)
 				})
 			}
 
@@ -195,6 +191,14 @@ func RegisterJoin(cmd *cf.CommandBuilder) *cf.CommandBuilder {
 	return cmd
 }
 
+// fieldPairMatch reports whether left's leftField equals right's
+// rightField, compared like ssql.OnFields (see ssql.JoinValuesEqual)
+func fieldPairMatch(left, right ssql.Record, leftField, rightField string) bool {
+	leftVal, leftOk := ssql.Get[any](left, leftField)
+	rightVal, rightOk := ssql.Get[any](right, rightField)
+	return leftOk && rightOk && ssql.JoinValuesEqual(leftVal, rightVal)
+}
+
 // generateJoinCode generates Go code for the join command
 // Generates TWO fragments: one init fragment for reading the right file,
 // and one stmt fragment for the join operation
@@ -277,12 +281,8 @@ func generateJoinCode(rightFile, joinType string, onFields []string, leftField,
 		predicateCode = fmt.Sprintf(`ssql.OnCondition(func(left, right ssql.Record) bool {
 		leftVal, leftOk := ssql.Get[any](left, %q)
 		rightVal, rightOk := ssql.Get[any](right, %q)
-		if !leftOk || !rightOk {
-			return false
-		}
-		return fmt.Sprintf("%%v", leftVal) == fmt.Sprintf("%%v", rightVal)
+		return leftOk && rightOk && ssql.JoinValuesEqual(leftVal, rightVal)
 	})`, leftField, rightField)
-		stmtImports = append(stmtImports, "fmt")
 	}
 
 	// Generate join function call
@@ -2,6 +2,7 @@ package commands
 
 import (
 	"fmt"
+	"io"
 	"iter"
 	"os"
 
@@ -16,11 +17,24 @@ func RegisterReadCSV(cmd *cf.CommandBuilder) *cf.CommandBuilder {
 		Description("Read CSV file and output JSONL stream").
 		Example("ssql read-csv data.csv | ssql table", "Read CSV and display as table").
 		Example("cat data.csv | ssql read-csv | ssql limit 10", "Read from stdin and show first 10 records").
+		Example("ssql read-csv -on-error dlq=bad.jsonl data.csv | ssql table", "Skip malformed rows, writing them to bad.jsonl").
+		Example("ssql read-csv -decimals ledger.csv | ssql group-by account -sum amount balance", "Exact money totals").
 		Flag("-generate", "-g").
 			Bool().
 			Global().
 			Help("Generate Go code instead of executing").
 		Done().
+		Flag("-on-error").
+			String().
+			Global().
+			Default("").
+			Help("What to do with malformed rows: skip, fail or dlq=FILE (write them to FILE as JSONL)").
+		Done().
+		Flag("-decimals").
+			Bool().
+			Global().
+			Help("Read non-integer numbers as exact decimals instead of float64 (kept through the pipeline)").
+		Done().
 		Flag("FILE").
 			String().
 			Completer(&cf.FileCompleter{Pattern: "*.csv"}).
@@ -40,18 +54,29 @@ func RegisterReadCSV(cmd *cf.CommandBuilder) *cf.CommandBuilder {
 				generate = genVal.(bool)
 			}
 
+			onError, _ := ctx.GlobalFlags["-on-error"].(string)
+			config := readCSVConfig(ctx.GlobalFlags)
+
 			// Check if generation is enabled (flag or env var)
 			if shouldGenerate(generate) {
-				return generateReadCSVCode(inputFile)
+				return generateReadCSVCode(inputFile, onError, config.Decimals)
+			}
+
+			policy, closePolicy, err := onErrorPolicy(ctx.GlobalFlags)
+			if err != nil {
+				return err
+			}
+			if policy != nil {
+				return readCSVWithPolicy(inputFile, config, policy, closePolicy)
 			}
 
 			// Read CSV from file or stdin
 			var records iter.Seq[ssql.Record]
 			if inputFile == "" {
-				records = ssql.ReadCSVFromReader(os.Stdin)
+				records = ssql.ReadCSVFromReader(os.Stdin, config)
 			} else {
 				var err error
-				records, err = ssql.ReadCSV(inputFile)
+				records, err = ssql.ReadCSV(inputFile, config)
 				if err != nil {
 					return fmt.Errorf("reading CSV: %w", err)
 				}
@@ -68,27 +93,82 @@ func RegisterReadCSV(cmd *cf.CommandBuilder) *cf.CommandBuilder {
 	return cmd
 }
 
+// readCSVConfig returns the CSV reader config for the read-csv flags
+func readCSVConfig(flags map[string]any) ssql.CSVConfig {
+	config := ssql.DefaultCSVConfig()
+	config.Decimals, _ = flags["-decimals"].(bool)
+	return config
+}
+
+// readCSVWithPolicy reads CSV from a file or stdin with the Safe reader,
+// handling malformed rows with the -on-error policy
+func readCSVWithPolicy(inputFile string, config ssql.CSVConfig, policy ssql.ErrorPolicy, closePolicy func() error) error {
+	input := io.Reader(os.Stdin)
+	if inputFile != "" {
+		file, err := os.Open(inputFile)
+		if err != nil {
+			closePolicy()
+			return fmt.Errorf("reading CSV: %w", err)
+		}
+		defer file.Close()
+		input = file
+	}
+
+	var readErr error
+	records := applyOnError(policy, ssql.ReadCSVSafeFromReader(input, config), &readErr)
+
+	// Write as JSONL to stdout
+	writeErr := lib.WriteJSONL(os.Stdout, records)
+	closeErr := closePolicy()
+	if writeErr != nil {
+		return fmt.Errorf("writing JSONL: %w", writeErr)
+	}
+	if readErr != nil {
+		return fmt.Errorf("reading CSV: %w", readErr)
+	}
+	return closeErr
+}
+
 // generateReadCSVCode generates Go code for the read-csv command
-func generateReadCSVCode(filename string) error {
+func generateReadCSVCode(filename, onError string, decimals bool) error {
 	// Generate ReadCSV call with error handling
 	var code string
 	var imports []string
+	config, setup := "", ""
+	if decimals {
+		config = ", csvConfig"
+		setup = "csvConfig := ssql.DefaultCSVConfig()\n\tcsvConfig.Decimals = true\n\t"
+	}
 
-	if filename == "" {
+	if onError != "" {
+		// Safe reader with the -on-error policy; errors it does not skip panic
+		policyCode, policy := onErrorPolicyCode(onError)
+		source := fmt.Sprintf("ssql.ReadCSVSafeFromReader(os.Stdin%s)", config)
+		if filename != "" {
+			source = fmt.Sprintf("ssql.ReadCSVSafe(%q%s)", filename, config)
+		}
+		code = policyCode + fmt.Sprintf(`records := ssql.Unsafe(ssql.ApplyErrorPolicy[ssql.Record]("read-csv", %s)(%s))`, policy, source)
+		if policyCode != "" {
+			imports = append(imports, "fmt")
+		}
+		if filename == "" {
+			imports = append(imports, "os")
+		}
+	} else if filename == "" {
 		// Reading from stdin - use ReadCSVFromReader
-		code = `records := ssql.ReadCSVFromReader(os.Stdin)`
+		code = fmt.Sprintf("records := ssql.ReadCSVFromReader(os.Stdin%s)", config)
 		imports = []string{"os"}
 	} else {
 		// Reading from file - use ReadCSV with error handling
-		code = fmt.Sprintf(`records, err := ssql.ReadCSV(%q)
+		code = fmt.Sprintf(`records, err := ssql.ReadCSV(%q%s)
 	if err != nil {
 		return fmt.Errorf("reading CSV: %%w", err)
-	}`, filename)
+	}`, filename, config)
 		imports = []string{"fmt"}
 	}
 
 	// Create init fragment (first in pipeline)
-	frag := lib.NewInitFragment("records", code, imports, getCommandString())
+	frag := lib.NewInitFragment("records", setup+code, imports, getCommandString())
 
 	// Write to stdout
 	return lib.WriteCodeFragment(frag)
@@ -14,11 +14,24 @@ func RegisterReadJSON(cmd *cf.CommandBuilder) *cf.CommandBuilder {
 		Description("Read JSON array or JSONL file (auto-detects format)").
 		Example("ssql read-json data.jsonl | ssql table", "Read JSONL file and display as table").
 		Example("ssql read-json array.json | ssql where -match status eq active", "Read JSON array and filter records").
+		Example("ssql read-json -on-error fail data.jsonl | ssql table", "Stop with an error at the first malformed line").
+		Example("ssql read-json -decimals payments.jsonl | ssql group-by currency -sum amount total", "Exact money totals").
 		Flag("-generate", "-g").
 			Bool().
 			Global().
 			Help("Generate Go code instead of executing").
 		Done().
+		Flag("-on-error").
+			String().
+			Global().
+			Default("").
+			Help("What to do with malformed lines: skip, fail or dlq=FILE (write them to FILE as JSONL)").
+		Done().
+		Flag("-decimals").
+			Bool().
+			Global().
+			Help("Read non-integer numbers as exact decimals instead of float64 (kept through the pipeline)").
+		Done().
 		Flag("FILE").
 			String().
 			Completer(&cf.FileCompleter{Pattern: "*.{json,jsonl}"}).
@@ -40,42 +53,86 @@ func RegisterReadJSON(cmd *cf.CommandBuilder) *cf.CommandBuilder {
 				generate = genVal.(bool)
 			}
 
+			onError, _ := ctx.GlobalFlags["-on-error"].(string)
+			decimals, _ := ctx.GlobalFlags["-decimals"].(bool)
+
 			// Check if generation is enabled (flag or env var)
 			if shouldGenerate(generate) {
-				return generateReadJSONCode(inputFile)
+				return generateReadJSONCode(inputFile, onError, decimals)
+			}
+
+			policy, closePolicy, err := onErrorPolicy(ctx.GlobalFlags)
+			if err != nil {
+				return err
 			}
 
 			// Open and read JSON file
 			input, err := lib.OpenInput(inputFile)
 			if err != nil {
+				closePolicy()
 				return err
 			}
 			defer input.Close()
 
-			records := lib.ReadJSON(input)
+			if policy == nil {
+				records := lib.ReadJSON(input, decimals)
 
-			// Write as JSONL to stdout
-			if err := lib.WriteJSONL(os.Stdout, records); err != nil {
-				return fmt.Errorf("writing JSONL: %w", err)
+				// Write as JSONL to stdout
+				if err := lib.WriteJSONL(os.Stdout, records); err != nil {
+					return fmt.Errorf("writing JSONL: %w", err)
+				}
+				return nil
 			}
 
-			return nil
+			var readErr error
+			records := applyOnError(policy, lib.ReadJSONSafe(input, decimals), &readErr)
+
+			// Write as JSONL to stdout
+			writeErr := lib.WriteJSONL(os.Stdout, records)
+			closeErr := closePolicy()
+			if writeErr != nil {
+				return fmt.Errorf("writing JSONL: %w", writeErr)
+			}
+			if readErr != nil {
+				return fmt.Errorf("reading JSON: %w", readErr)
+			}
+			return closeErr
 		}).
 		Done()
 	return cmd
 }
 
 // generateReadJSONCode generates Go code for the read-json command
-func generateReadJSONCode(filename string) error {
+func generateReadJSONCode(filename, onError string, decimals bool) error {
 	// No previous fragments for init command
 	outputVar := "records"
 	imports := []string{"fmt", "os"}
+	config := ""
+	if decimals {
+		config = ", ssql.JSONConfig{Decimals: true}"
+	}
+
+	if onError != "" {
+		// The Safe JSON reader expects JSONL; errors the policy does not skip panic
+		policyCode, policy := onErrorPolicyCode(onError)
+		code := policyCode + fmt.Sprintf(`records := ssql.Unsafe(ssql.ApplyErrorPolicy[ssql.Record]("read-json", %s)(ssql.ReadJSONSafe(%q%s)))`, policy, filename, config)
+		if policyCode == "" {
+			imports = nil
+		}
+		frag := lib.NewInitFragment(outputVar, code, imports, getCommandString())
+		return lib.WriteCodeFragment(frag)
+	}
 
-	code := fmt.Sprintf(`records, err := ssql.ReadJSONAuto(%q)
+	// ReadJSONAuto takes no config; the decimal reader expects JSONL
+	reader := fmt.Sprintf("ssql.ReadJSONAuto(%q)", filename)
+	if decimals {
+		reader = fmt.Sprintf("ssql.ReadJSON(%q%s)", filename, config)
+	}
+	code := fmt.Sprintf(`records, err := %s
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %%v\n", fmt.Errorf("reading JSON: %%w", err))
 		os.Exit(1)
-	}`, filename)
+	}`, reader)
 
 	frag := lib.NewInitFragment(outputVar, code, imports, getCommandString())
 	return lib.WriteCodeFragment(frag)
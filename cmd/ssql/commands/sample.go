@@ -0,0 +1,205 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	cf "github.com/rosscartlidge/autocli/v3"
+	"github.com/rosscartlidge/ssql/v2"
+	"github.com/rosscartlidge/ssql/v2/cmd/ssql/lib"
+)
+
+// RegisterSample registers the sample subcommand
+func RegisterSample(cmd *cf.CommandBuilder) *cf.CommandBuilder {
+	cmd.Subcommand("sample").
+		Description("Keep a random sample of records in one pass").
+		Example("ssql read-csv huge.csv | ssql sample -n 1000", "1000 records chosen uniformly at random (reservoir sample)").
+		Example("ssql read-csv huge.csv | ssql sample -n 100 -by region", "100 records from every region (stratified sample)").
+		Example("ssql read-json events.jsonl | ssql sample -fraction 0.01 -seed 42", "About 1% of records, the same ones on every run (Bernoulli sample)").
+		Example("ssql read-json events.jsonl | ssql sample -fraction 0.05 -by user_id", "Every record of about 5% of users, consistent across runs and files").
+		Flag("-generate", "-g").
+			Bool().
+			Global().
+			Help("Generate Go code instead of executing").
+		Done().
+		Flag("-n").
+			Int().
+			Global().
+			Default(0).
+			Help("Sample size (per group with -by)").
+		Done().
+		Flag("-fraction").
+			String().
+			Completer(cf.NoCompleter{Hint: "<0-1 e.g. 0.01>"}).
+			Global().
+			Default("").
+			Help("Fraction of records (of keys with -by) to keep").
+		Done().
+		Flag("-by").
+			String().
+			Completer(cf.NoCompleter{Hint: "<field-name>"}).
+			Accumulate().
+			Local().
+			Help("Key field: sample -n records per key, or a -fraction of keys with all their records").
+		Done().
+		Flag("-seed").
+			String().
+			Completer(cf.NoCompleter{Hint: "<integer>"}).
+			Global().
+			Default("").
+			Help("Random seed for a repeatable sample (omit for a different sample each run)").
+		Done().
+		Handler(func(ctx *cf.Context) error {
+			var generate bool
+
+			if genVal, ok := ctx.GlobalFlags["-generate"]; ok {
+				generate = genVal.(bool)
+			}
+
+			var keyFields []string
+			if len(ctx.Clauses) > 0 {
+				keyFields = clauseStrings(ctx.Clauses[0].Flags["-by"])
+			}
+
+			def, err := parseSampleDef(ctx.GlobalFlags, keyFields)
+			if err != nil {
+				return err
+			}
+
+			// Check if generation is enabled (flag or env var)
+			if shouldGenerate(generate) {
+				return generateSampleCode(def)
+			}
+
+			// Read JSONL from stdin
+			records := lib.ReadJSONL(os.Stdin)
+
+			// Write output as JSONL
+			if err := lib.WriteJSONL(os.Stdout, def.filter()(records)); err != nil {
+				return fmt.Errorf("writing output: %w", err)
+			}
+
+			return nil
+		}).
+		Done()
+	return cmd
+}
+
+// sampleDef is a sample chosen by the sample command's flags
+type sampleDef struct {
+	n         int
+	fraction  float64
+	keyFields []string
+	seed      int64
+	seeded    bool // false = a different sample each run
+}
+
+// parseSampleDef reads exactly one of -n or -fraction, with optional -by
+// and -seed
+func parseSampleDef(flags map[string]any, keyFields []string) (sampleDef, error) {
+	def := sampleDef{keyFields: keyFields}
+	if nVal, ok := flags["-n"].(int); ok {
+		def.n = nVal
+	}
+	if seedStr, _ := flags["-seed"].(string); seedStr != "" {
+		seed, err := strconv.ParseInt(seedStr, 10, 64)
+		if err != nil {
+			return def, fmt.Errorf("invalid -seed %q: must be an integer", seedStr)
+		}
+		def.seed, def.seeded = seed, true
+	}
+	fractionStr, _ := flags["-fraction"].(string)
+
+	switch {
+	case def.n < 0:
+		return def, fmt.Errorf("-n must be positive, got %d", def.n)
+	case def.n > 0 && fractionStr != "":
+		return def, fmt.Errorf("use either -n or -fraction, not both")
+	case def.n == 0 && fractionStr == "":
+		return def, fmt.Errorf("sample size required (use -n or -fraction)")
+	}
+	if fractionStr != "" {
+		fraction, err := strconv.ParseFloat(fractionStr, 64)
+		if err != nil || fraction <= 0 || fraction > 1 {
+			return def, fmt.Errorf("invalid -fraction %q: must be a number between 0 and 1", fractionStr)
+		}
+		def.fraction = fraction
+		if len(keyFields) > 0 && def.seeded {
+			return def, fmt.Errorf("-seed does not apply to -fraction with -by: keys are chosen by hash")
+		}
+	}
+	return def, nil
+}
+
+// filter returns the sampling filter
+func (d sampleDef) filter() ssql.Filter[ssql.Record, ssql.Record] {
+	seed := d.seed
+	if !d.seeded {
+		seed = time.Now().UnixNano()
+	}
+	switch {
+	case d.n > 0 && len(d.keyFields) > 0:
+		if !d.seeded {
+			return ssql.StratifiedSample(d.n, d.keyFields...)
+		}
+		return ssql.StratifiedSampleSeeded(d.n, seed, d.keyFields...)
+	case d.n > 0:
+		return ssql.ReservoirSample[ssql.Record](d.n, seed)
+	case len(d.keyFields) > 0:
+		return ssql.HashSample(d.fraction, d.keyFields...)
+	default:
+		return ssql.BernoulliSample[ssql.Record](d.fraction, seed)
+	}
+}
+
+// code returns the Go expression for the sampling filter and its imports
+func (d sampleDef) code() (string, []string) {
+	seed, imports := strconv.FormatInt(d.seed, 10), []string(nil)
+	if !d.seeded {
+		seed, imports = "time.Now().UnixNano()", []string{"time"}
+	}
+	keys := make([]string, len(d.keyFields))
+	for i, f := range d.keyFields {
+		keys[i] = fmt.Sprintf(", %q", f)
+	}
+	switch {
+	case d.n > 0 && len(d.keyFields) > 0:
+		if !d.seeded {
+			return fmt.Sprintf("ssql.StratifiedSample(%d%s)", d.n, strings.Join(keys, "")), nil
+		}
+		return fmt.Sprintf("ssql.StratifiedSampleSeeded(%d, %s%s)", d.n, seed, strings.Join(keys, "")), imports
+	case d.n > 0:
+		return fmt.Sprintf("ssql.ReservoirSample[ssql.Record](%d, %s)", d.n, seed), imports
+	case len(d.keyFields) > 0:
+		return fmt.Sprintf("ssql.HashSample(%v%s)", d.fraction, strings.Join(keys, "")), nil
+	default:
+		return fmt.Sprintf("ssql.BernoulliSample[ssql.Record](%v, %s)", d.fraction, seed), imports
+	}
+}
+
+// generateSampleCode generates Go code for the sample command
+func generateSampleCode(def sampleDef) error {
+	fragments, err := lib.ReadAllCodeFragments()
+	if err != nil {
+		return fmt.Errorf("reading code fragments: %w", err)
+	}
+	for _, frag := range fragments {
+		if err := lib.WriteCodeFragment(frag); err != nil {
+			return fmt.Errorf("writing previous fragment: %w", err)
+		}
+	}
+	var inputVar string
+	if len(fragments) > 0 {
+		inputVar = fragments[len(fragments)-1].Var
+	} else {
+		inputVar = "records"
+	}
+
+	filterCode, imports := def.code()
+	code := fmt.Sprintf("sampled := %s(%s)", filterCode, inputVar)
+	frag := lib.NewStmtFragment("sampled", inputVar, code, imports, getCommandString())
+	return lib.WriteCodeFragment(frag)
+}
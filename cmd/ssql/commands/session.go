@@ -0,0 +1,178 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	cf "github.com/rosscartlidge/autocli/v3"
+	"github.com/rosscartlidge/ssql/v2"
+	"github.com/rosscartlidge/ssql/v2/cmd/ssql/lib"
+)
+
+// RegisterSession registers the session subcommand
+func RegisterSession(cmd *cf.CommandBuilder) *cf.CommandBuilder {
+	cmd.Subcommand("session").
+		Description("Group events into sessions split by inactivity gaps, optionally aggregating each session").
+		Example("ssql read-json clicks.jsonl | ssql session -gap 30m -time ts -by user_id", "One record per user session with start, end, duration, count and its events").
+		Example("ssql read-json clicks.jsonl | ssql session -gap 30m -time ts -by user_id -count pages -sum bytes total_bytes", "Summarise each session").
+		Example("ssql read-csv logins.csv | ssql session -gap 1h -time login_at -by account -by ip -count logins", "Sessions per account and address").
+		Flag("-generate", "-g").
+			Bool().
+			Global().
+			Help("Generate Go code instead of executing").
+		Done().
+		Flag("-gap").
+			String().
+			Completer(cf.NoCompleter{Hint: "<duration e.g. 30m>"}).
+			Global().
+			Default("").
+			Help("Inactivity gap that ends a session (e.g. 30s, 30m, 1h)").
+		Done().
+		Flag("-time", "-t").
+			String().
+			Completer(cf.NoCompleter{Hint: "<field-name>"}).
+			Global().
+			Default("").
+			Help("Event time field (RFC3339 timestamp or Unix seconds)").
+		Done().
+		Flag("-by").
+			String().
+			Completer(cf.NoCompleter{Hint: "<field-name>"}).
+			Accumulate().
+			Local().
+			Help("Key field; sessions are tracked separately for each key").
+		Done().
+		Flag("-count").
+			Arg("result-name").Completer(cf.NoCompleter{Hint: "<name>"}).Done().
+			Accumulate().
+			Global().
+			Help("Count records (result field name)").
+		Done().
+		Flag("-sum").
+			Arg("field").Completer(cf.NoCompleter{Hint: "<field>"}).Done().
+			Arg("result-name").Completer(cf.NoCompleter{Hint: "<name>"}).Done().
+			Accumulate().
+			Global().
+			Help("Sum field values (field name, result name)").
+		Done().
+		Flag("-avg").
+			Arg("field").Completer(cf.NoCompleter{Hint: "<field>"}).Done().
+			Arg("result-name").Completer(cf.NoCompleter{Hint: "<name>"}).Done().
+			Accumulate().
+			Global().
+			Help("Average field values (field name, result name)").
+		Done().
+		Flag("-min").
+			Arg("field").Completer(cf.NoCompleter{Hint: "<field>"}).Done().
+			Arg("result-name").Completer(cf.NoCompleter{Hint: "<name>"}).Done().
+			Accumulate().
+			Global().
+			Help("Minimum field value (field name, result name)").
+		Done().
+		Flag("-max").
+			Arg("field").Completer(cf.NoCompleter{Hint: "<field>"}).Done().
+			Arg("result-name").Completer(cf.NoCompleter{Hint: "<name>"}).Done().
+			Accumulate().
+			Global().
+			Help("Maximum field value (field name, result name)").
+		Done().
+		Handler(func(ctx *cf.Context) error {
+			var generate bool
+			var gapStr, timeField string
+
+			if genVal, ok := ctx.GlobalFlags["-generate"]; ok {
+				generate = genVal.(bool)
+			}
+			if gapVal, ok := ctx.GlobalFlags["-gap"]; ok {
+				gapStr = gapVal.(string)
+			}
+			if timeVal, ok := ctx.GlobalFlags["-time"]; ok {
+				timeField = timeVal.(string)
+			}
+
+			var keyFields []string
+			if len(ctx.Clauses) > 0 {
+				keyFields = clauseStrings(ctx.Clauses[0].Flags["-by"])
+			}
+
+			if gapStr == "" {
+				return fmt.Errorf("inactivity gap required (use -gap, e.g. -gap 30m)")
+			}
+			gap, err := time.ParseDuration(gapStr)
+			if err != nil || gap <= 0 {
+				return fmt.Errorf("invalid -gap %q: must be a positive duration like 30s, 30m or 1h", gapStr)
+			}
+			if timeField == "" {
+				return fmt.Errorf("event time field required (use -time)")
+			}
+
+			specs := parseAggSpecs(ctx.GlobalFlags)
+
+			// Check if generation is enabled (flag or env var)
+			if shouldGenerate(generate) {
+				return generateSessionCode(gap, timeField, keyFields, specs)
+			}
+
+			// Read JSONL from stdin
+			records := lib.ReadJSONL(os.Stdin)
+
+			sessions := ssql.SessionWindow(gap, timeField, keyFields...)(records)
+			if len(specs) > 0 {
+				aggregations, err := buildAggregations(specs)
+				if err != nil {
+					return err
+				}
+				sessions = ssql.Aggregate(ssql.SessionRecordsField, aggregations)(sessions)
+			}
+
+			// Write output as JSONL
+			if err := lib.WriteJSONL(os.Stdout, sessions); err != nil {
+				return fmt.Errorf("writing output: %w", err)
+			}
+
+			return nil
+		}).
+		Done()
+	return cmd
+}
+
+// generateSessionCode generates Go code for the session command
+func generateSessionCode(gap time.Duration, timeField string, keyFields []string, specs []aggSpec) error {
+	fragments, err := lib.ReadAllCodeFragments()
+	if err != nil {
+		return fmt.Errorf("reading code fragments: %w", err)
+	}
+	for _, frag := range fragments {
+		if err := lib.WriteCodeFragment(frag); err != nil {
+			return fmt.Errorf("writing previous fragment: %w", err)
+		}
+	}
+	var inputVar string
+	if len(fragments) > 0 {
+		inputVar = fragments[len(fragments)-1].Var
+	} else {
+		inputVar = "records"
+	}
+
+	// Fragment 1: SessionWindow
+	args := []string{durationCode(gap), fmt.Sprintf("%q", timeField)}
+	for _, f := range keyFields {
+		args = append(args, fmt.Sprintf("%q", f))
+	}
+	sessionCode := fmt.Sprintf("sessions := ssql.SessionWindow(%s)(%s)", strings.Join(args, ", "), inputVar)
+	frag1 := lib.NewStmtFragment("sessions", inputVar, sessionCode, []string{"time"}, getCommandString())
+	if len(specs) == 0 {
+		return lib.WriteCodeFragment(frag1)
+	}
+	if err := lib.WriteCodeFragment(frag1); err != nil {
+		return fmt.Errorf("writing SessionWindow fragment: %w", err)
+	}
+
+	// Fragment 2: Aggregate
+	// Note: Empty command string since this is part of the same CLI command as Fragment 1
+	aggCode := aggregateCode("aggregated", "ssql.SessionRecordsField", "sessions", specs)
+	frag2 := lib.NewStmtFragment("aggregated", "sessions", aggCode, nil, "")
+	return lib.WriteCodeFragment(frag2)
+}
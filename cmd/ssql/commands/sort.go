@@ -2,8 +2,8 @@ package commands
 
 import (
 	"fmt"
-	"iter"
 	"os"
+	"strings"
 
 	cf "github.com/rosscartlidge/autocli/v3"
 	"github.com/rosscartlidge/ssql/v2"
@@ -13,75 +13,137 @@ import (
 // RegisterSort registers the sort subcommand
 func RegisterSort(cmd *cf.CommandBuilder) *cf.CommandBuilder {
 	cmd.Subcommand("sort").
-		Description("Sort records by field").
+		Description("Sort records by one or more fields").
 		Example("ssql read-csv data.csv | ssql sort age", "Sort by age ascending").
 		Example("ssql read-csv sales.csv | ssql sort amount -desc", "Sort by amount descending").
-		Flag("FIELD").
+		Example("ssql read-csv sales.csv | ssql sort region -asc + revenue -desc", "Sort by region, then highest revenue first within each region").
+		Example("ssql read-csv sales.csv | ssql sort -by region + -by revenue -desc -nulls-first", "The same keys named with -by; each + starts the next key").
+		Example("ssql read-json files.jsonl | ssql sort name -natural -nocase", "Natural order (file9 before file10), ignoring case").
+		Example("ssql read-json orders.jsonl | ssql sort items[0].price", "Sort by a nested field path").
+		Example("ssql read-csv huge.csv | ssql sort ts -memory 512MB -tmpdir /var/tmp", "Sort more data than fits in memory, spilling sorted runs to disk").
+		Flag("FIELDS").
 			String().
-			Required().
+			Variadic().
 			Completer(cf.NoCompleter{Hint: "<field-name>"}).
 			Global().
-			Help("Field to sort by").
+			Help("Fields to sort by, most significant first (nested paths like user.age allowed); a clause without -by applies its options to the next of these").
+		Done().
+		Flag("-by").
+			String().
+			Completer(cf.NoCompleter{Hint: "<field-name>"}).
+			Local().
+			Default("").
+			Help("Field this clause sorts by").
 		Done().
 		Flag("-generate", "-g").
 			Bool().
 			Global().
 			Help("Generate Go code instead of executing").
 		Done().
+		Flag("-asc", "-a").
+			Bool().
+			Local().
+			Help("Sort this clause's field ascending (the default)").
+		Done().
 		Flag("-desc", "-d").
 			Bool().
+			Local().
+			Help("Sort this clause's field descending").
+		Done().
+		Flag("-numeric").
+			Bool().
+			Local().
+			Help("Compare this clause's field as numbers, parsing numeric strings").
+		Done().
+		Flag("-lexical").
+			Bool().
+			Local().
+			Help("Compare this clause's field as text").
+		Done().
+		Flag("-natural").
+			Bool().
+			Local().
+			Help("Compare this clause's field as text with embedded numbers in numeric order (file9 before file10)").
+		Done().
+		Flag("-nocase").
+			Bool().
+			Local().
+			Help("Compare this clause's field as text, ignoring case (with -natural, natural order ignoring case)").
+		Done().
+		Flag("-nulls-first").
+			Bool().
+			Local().
+			Help("Put null and missing values of this clause's field first (default: last)").
+		Done().
+		Flag("-nulls-last").
+			Bool().
+			Local().
+			Help("Put null and missing values of this clause's field last").
+		Done().
+		Flag("-memory").
+			String().
+			Completer(cf.NoCompleter{Hint: "<size e.g. 512MB>"}).
+			Global().
+			Default("").
+			Help("Memory budget (e.g. 512MB, 2GB); larger inputs are sorted in runs spilled to disk").
+		Done().
+		Flag("-tmpdir").
+			String().
+			Completer(&cf.FileCompleter{Pattern: "*/"}).
 			Global().
-			Help("Sort descending").
+			Default("").
+			Help("Directory for spilled runs (default: system temp dir); implies an external sort").
 		Done().
 		Handler(func(ctx *cf.Context) error {
-			var field string
-			var desc bool
 			var generate bool
 
-			if fieldVal, ok := ctx.GlobalFlags["FIELD"]; ok {
-				field = fieldVal.(string)
-			}
-
-			if descVal, ok := ctx.GlobalFlags["-desc"]; ok {
-				desc = descVal.(bool)
-			}
-
 			if genVal, ok := ctx.GlobalFlags["-generate"]; ok {
 				generate = genVal.(bool)
 			}
 
-			if field == "" {
+			keys, err := sortKeysFromContext(ctx)
+			if err != nil {
+				return err
+			}
+			if len(keys) == 0 {
 				return fmt.Errorf("no sort field specified")
 			}
 
+			external, err := externalSortFlags(ctx.GlobalFlags)
+			if err != nil {
+				return err
+			}
+
 			// Check if generation is enabled (flag or env var)
 			if shouldGenerate(generate) {
-				return generateSortCode(field, desc)
+				return generateSortCode(keys, external)
 			}
 
 			// Read JSONL from stdin
 			records := lib.ReadJSONL(os.Stdin)
 
-			// Build sort key extractor and apply sort
-			var result iter.Seq[ssql.Record]
-			if desc {
-				// Descending: negate numeric values
-				sorter := ssql.SortBy(func(r ssql.Record) float64 {
-					val, _ := ssql.Get[any](r, field)
-					return -extractNumeric(val)
-				})
-				result = sorter(records)
-			} else {
-				// Ascending
-				sorter := ssql.SortBy(func(r ssql.Record) float64 {
-					val, _ := ssql.Get[any](r, field)
-					return extractNumeric(val)
-				})
-				result = sorter(records)
+			if external != nil {
+				// External sort: report spill errors instead of panicking
+				var sortErr error
+				sorted := func(yield func(ssql.Record) bool) {
+					for r, err := range ssql.ExternalSortByKeysSafe(*external, keys...)(ssql.Safe(records)) {
+						if err != nil {
+							sortErr = err
+							return
+						}
+						if !yield(r) {
+							return
+						}
+					}
+				}
+				if err := lib.WriteJSONL(os.Stdout, sorted); err != nil {
+					return fmt.Errorf("writing output: %w", err)
+				}
+				return sortErr
 			}
 
 			// Write output as JSONL
-			if err := lib.WriteJSONL(os.Stdout, result); err != nil {
+			if err := lib.WriteJSONL(os.Stdout, ssql.SortByKeys(keys...)(records)); err != nil {
 				return fmt.Errorf("writing output: %w", err)
 			}
 
@@ -91,8 +153,105 @@ func RegisterSort(cmd *cf.CommandBuilder) *cf.CommandBuilder {
 	return cmd
 }
 
+// sortKeysFromContext builds sort keys from sort's clauses. Each clause
+// (separated by +) is one key: its field is -by, or failing that the next
+// positional field, and its options (-asc, -desc, -numeric, -lexical,
+// -natural, -nocase, -nulls-first, -nulls-last) apply to that field only.
+// Positional fields left over after the clauses sort ascending.
+func sortKeysFromContext(ctx *cf.Context) ([]ssql.SortKey, error) {
+	var fields []string
+	if fieldsVal, ok := ctx.GlobalFlags["FIELDS"]; ok {
+		switch v := fieldsVal.(type) {
+		case []string:
+			fields = v
+		case []any:
+			for _, item := range v {
+				if s, ok := item.(string); ok {
+					fields = append(fields, s)
+				}
+			}
+		}
+	}
+
+	var keys []ssql.SortKey
+	for i, clause := range ctx.Clauses {
+		set := func(name string) bool {
+			v, _ := clause.Flags[name].(bool)
+			return v
+		}
+		field, _ := clause.Flags["-by"].(string)
+		hasOptions := set("-asc") || set("-desc") || set("-numeric") || set("-lexical") ||
+			set("-natural") || set("-nocase") || set("-nulls-first") || set("-nulls-last")
+		if field == "" {
+			if len(fields) == 0 {
+				if hasOptions {
+					return nil, fmt.Errorf("sort clause %d has options but no field", i+1)
+				}
+				continue
+			}
+			field, fields = fields[0], fields[1:]
+		}
+
+		if set("-asc") && set("-desc") {
+			return nil, fmt.Errorf("sort field %q: -asc and -desc conflict", field)
+		}
+		if set("-nulls-first") && set("-nulls-last") {
+			return nil, fmt.Errorf("sort field %q: -nulls-first and -nulls-last conflict", field)
+		}
+		if set("-numeric") && (set("-lexical") || set("-natural") || set("-nocase")) ||
+			set("-lexical") && (set("-natural") || set("-nocase")) {
+			return nil, fmt.Errorf("sort field %q: conflicting comparison modes", field)
+		}
+
+		key := ssql.Asc(field)
+		if set("-desc") {
+			key = ssql.Desc(field)
+		}
+		switch {
+		case set("-numeric"):
+			key = key.WithMode(ssql.SortNumeric)
+		case set("-lexical"):
+			key = key.WithMode(ssql.SortLexical)
+		case set("-natural") && set("-nocase"):
+			key = key.WithMode(ssql.SortNaturalCaseInsensitive)
+		case set("-natural"):
+			key = key.WithMode(ssql.SortNatural)
+		case set("-nocase"):
+			key = key.WithMode(ssql.SortCaseInsensitive)
+		}
+		if set("-nulls-first") {
+			key = key.WithNulls(ssql.NullsFirst)
+		}
+		keys = append(keys, key)
+	}
+
+	for _, field := range fields {
+		keys = append(keys, ssql.Asc(field))
+	}
+	return keys, nil
+}
+
+// externalSortFlags reads -memory and -tmpdir. It returns nil when
+// neither is set, meaning an in-memory sort.
+func externalSortFlags(flags map[string]any) (*ssql.ExternalSortConfig, error) {
+	memory, _ := flags["-memory"].(string)
+	tmpDir, _ := flags["-tmpdir"].(string)
+	if memory == "" && tmpDir == "" {
+		return nil, nil
+	}
+	cfg := &ssql.ExternalSortConfig{TempDir: tmpDir}
+	if memory != "" {
+		limit, err := parseByteSize(memory)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -memory: %w", err)
+		}
+		cfg.MemoryLimit = limit
+	}
+	return cfg, nil
+}
+
 // generateSortCode generates Go code for the sort command
-func generateSortCode(field string, desc bool) error {
+func generateSortCode(keys []ssql.SortKey, external *ssql.ExternalSortConfig) error {
 	fragments, err := lib.ReadAllCodeFragments()
 	if err != nil {
 		return fmt.Errorf("reading code fragments: %w", err)
@@ -109,17 +268,39 @@ func generateSortCode(field string, desc bool) error {
 		inputVar = "records"
 	}
 	outputVar := "sorted"
-	var sortFunc string
-	if desc {
-		sortFunc = fmt.Sprintf(`ssql.SortBy(func(r ssql.Record) float64 {
-		return -ssql.GetOr(r, %q, 0.0)
-	})`, field)
-	} else {
-		sortFunc = fmt.Sprintf(`ssql.SortBy(func(r ssql.Record) float64 {
-		return ssql.GetOr(r, %q, 0.0)
-	})`, field)
+	keyArgs := make([]string, len(keys))
+	for i, k := range keys {
+		keyArgs[i] = sortKeyCode(k)
+	}
+	sortFunc := fmt.Sprintf("ssql.SortByKeys(%s)", strings.Join(keyArgs, ", "))
+	if external != nil {
+		sortFunc = fmt.Sprintf("ssql.ExternalSortByKeys(ssql.ExternalSortConfig{MemoryLimit: %d, TempDir: %q}, %s)", external.MemoryLimit, external.TempDir, strings.Join(keyArgs, ", "))
 	}
 	code := fmt.Sprintf("%s := %s(%s)", outputVar, sortFunc, inputVar)
 	frag := lib.NewStmtFragment(outputVar, inputVar, code, nil, getCommandString())
 	return lib.WriteCodeFragment(frag)
 }
+
+// sortKeyCode returns the Go expression for a field sort key
+func sortKeyCode(k ssql.SortKey) string {
+	code := fmt.Sprintf("ssql.Asc(%q)", k.Field)
+	if k.Direction == ssql.Descending {
+		code = fmt.Sprintf("ssql.Desc(%q)", k.Field)
+	}
+	switch k.Mode {
+	case ssql.SortNumeric:
+		code += ".WithMode(ssql.SortNumeric)"
+	case ssql.SortLexical:
+		code += ".WithMode(ssql.SortLexical)"
+	case ssql.SortNatural:
+		code += ".WithMode(ssql.SortNatural)"
+	case ssql.SortCaseInsensitive:
+		code += ".WithMode(ssql.SortCaseInsensitive)"
+	case ssql.SortNaturalCaseInsensitive:
+		code += ".WithMode(ssql.SortNaturalCaseInsensitive)"
+	}
+	if k.Nulls == ssql.NullsFirst {
+		code += ".WithNulls(ssql.NullsFirst)"
+	}
+	return code
+}
@@ -0,0 +1,158 @@
+package commands
+
+import (
+	"fmt"
+	"iter"
+	"os"
+	"strings"
+
+	cf "github.com/rosscartlidge/autocli/v3"
+	"github.com/rosscartlidge/ssql/v2"
+	"github.com/rosscartlidge/ssql/v2/cmd/ssql/lib"
+)
+
+// RegisterTop registers the top subcommand
+func RegisterTop(cmd *cf.CommandBuilder) *cf.CommandBuilder {
+	cmd.Subcommand("top").
+		Description("Keep the N records with the largest (or smallest) value of a field, optionally per group").
+		Example("ssql read-csv sales.csv | ssql top -n 10 -by revenue", "Ten highest-revenue records").
+		Example("ssql read-csv sales.csv | ssql top -n 3 -by revenue -per region", "Top three records in each region").
+		Example("ssql read-json latency.jsonl | ssql top -n 5 -by ms -bottom", "Five fastest requests").
+		Flag("-generate", "-g").
+			Bool().
+			Global().
+			Help("Generate Go code instead of executing").
+		Done().
+		Flag("-n").
+			Int().
+			Global().
+			Default(10).
+			Help("Number of records to keep (per group with -per)").
+		Done().
+		Flag("-by").
+			String().
+			Completer(cf.NoCompleter{Hint: "<field-name>"}).
+			Global().
+			Default("").
+			Help("Numeric field to rank by (nested paths allowed); records without a numeric value are skipped").
+		Done().
+		Flag("-per").
+			String().
+			Completer(cf.NoCompleter{Hint: "<field-name>"}).
+			Accumulate().
+			Local().
+			Help("Group field; keep N records for each distinct value").
+		Done().
+		Flag("-bottom").
+			Bool().
+			Global().
+			Help("Keep the smallest values instead of the largest").
+		Done().
+		Handler(func(ctx *cf.Context) error {
+			var generate, bottom bool
+			var by string
+			n := 10
+
+			if genVal, ok := ctx.GlobalFlags["-generate"]; ok {
+				generate = genVal.(bool)
+			}
+			if nVal, ok := ctx.GlobalFlags["-n"]; ok {
+				n = nVal.(int)
+			}
+			if byVal, ok := ctx.GlobalFlags["-by"]; ok {
+				by = byVal.(string)
+			}
+			if bottomVal, ok := ctx.GlobalFlags["-bottom"]; ok {
+				bottom = bottomVal.(bool)
+			}
+
+			var perFields []string
+			if len(ctx.Clauses) > 0 {
+				perFields = clauseStrings(ctx.Clauses[0].Flags["-per"])
+			}
+
+			if by == "" {
+				return fmt.Errorf("ranking field required (use -by)")
+			}
+			if n <= 0 {
+				return fmt.Errorf("-n must be positive, got %d", n)
+			}
+
+			// Check if generation is enabled (flag or env var)
+			if shouldGenerate(generate) {
+				return generateTopCode(n, by, perFields, bottom)
+			}
+
+			// Read JSONL from stdin
+			records := lib.ReadJSONL(os.Stdin)
+
+			// Write output as JSONL
+			if err := lib.WriteJSONL(os.Stdout, topRecords(records, n, by, perFields, bottom)); err != nil {
+				return fmt.Errorf("writing output: %w", err)
+			}
+
+			return nil
+		}).
+		Done()
+	return cmd
+}
+
+// topRecords keeps the n records with the largest (smallest if bottom)
+// numeric value of field by within each group of perFields
+func topRecords(records iter.Seq[ssql.Record], n int, by string, perFields []string, bottom bool) iter.Seq[ssql.Record] {
+	numeric := ssql.Where(func(r ssql.Record) bool {
+		_, ok := ssql.Get[float64](r, by)
+		return ok
+	})(records)
+	key := func(r ssql.Record) float64 {
+		return ssql.GetOr(r, by, 0.0)
+	}
+	if bottom {
+		return ssql.BottomKBy(n, perFields, key)(numeric)
+	}
+	return ssql.TopKBy(n, perFields, key)(numeric)
+}
+
+// generateTopCode generates Go code for the top command
+func generateTopCode(n int, by string, perFields []string, bottom bool) error {
+	fragments, err := lib.ReadAllCodeFragments()
+	if err != nil {
+		return fmt.Errorf("reading code fragments: %w", err)
+	}
+	for _, frag := range fragments {
+		if err := lib.WriteCodeFragment(frag); err != nil {
+			return fmt.Errorf("writing previous fragment: %w", err)
+		}
+	}
+	var inputVar string
+	if len(fragments) > 0 {
+		inputVar = fragments[len(fragments)-1].Var
+	} else {
+		inputVar = "records"
+	}
+
+	// Fragment 1: skip records without a numeric ranking value
+	numericCode := fmt.Sprintf(`numeric := ssql.Where(func(r ssql.Record) bool {
+		_, ok := ssql.Get[float64](r, %q)
+		return ok
+	})(%s)`, by, inputVar)
+	frag1 := lib.NewStmtFragment("numeric", inputVar, numericCode, nil, getCommandString())
+	if err := lib.WriteCodeFragment(frag1); err != nil {
+		return fmt.Errorf("writing Where fragment: %w", err)
+	}
+
+	// Fragment 2: TopKBy / BottomKBy
+	quotedFields := make([]string, len(perFields))
+	for i, f := range perFields {
+		quotedFields[i] = fmt.Sprintf("%q", f)
+	}
+	topFunc := "ssql.TopKBy"
+	if bottom {
+		topFunc = "ssql.BottomKBy"
+	}
+	topCode := fmt.Sprintf(`top := %s(%d, []string{%s}, func(r ssql.Record) float64 {
+		return ssql.GetOr(r, %q, 0.0)
+	})(numeric)`, topFunc, n, strings.Join(quotedFields, ", "), by)
+	frag2 := lib.NewStmtFragment("top", "numeric", topCode, nil, "")
+	return lib.WriteCodeFragment(frag2)
+}
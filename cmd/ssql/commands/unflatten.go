@@ -0,0 +1,128 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	cf "github.com/rosscartlidge/autocli/v3"
+	"github.com/rosscartlidge/ssql/v2"
+	"github.com/rosscartlidge/ssql/v2/cmd/ssql/lib"
+)
+
+// RegisterUnflatten registers the unflatten subcommand
+func RegisterUnflatten(cmd *cf.CommandBuilder) *cf.CommandBuilder {
+	cmd.Subcommand("unflatten").
+		Description("Rebuild nested records from dotted field names").
+		Example("ssql read-csv users.csv | ssql unflatten | ssql write-json users.jsonl", "Turn address.city, address.zip columns into an address object").
+		Example("ssql read-csv tags.csv | ssql unflatten tags", "Turn tags.0, tags.1 columns into a tags array").
+		Example("ssql read-csv users.csv | ssql unflatten -separator _", "Split nested names on underscores").
+		Flag("-generate", "-g").
+			Bool().
+			Global().
+			Help("Generate Go code instead of executing").
+		Done().
+		Flag("-separator", "-s").
+			String().
+			Completer(cf.NoCompleter{Hint: "<separator>"}).
+			Global().
+			Default(".").
+			Help("Separator between nested field names (default: .)").
+		Done().
+		Flag("FIELDS").
+			String().
+			Variadic().
+			Completer(cf.NoCompleter{Hint: "<field-name>"}).
+			Global().
+			Help("Top-level fields to unflatten (all dotted fields if not specified)").
+		Done().
+		Handler(func(ctx *cf.Context) error {
+			var generate bool
+			var separator string
+			var fields []string
+
+			if genVal, ok := ctx.GlobalFlags["-generate"]; ok {
+				generate = genVal.(bool)
+			}
+
+			if sepVal, ok := ctx.GlobalFlags["-separator"]; ok {
+				separator = sepVal.(string)
+			}
+
+			if fieldsVal, ok := ctx.GlobalFlags["FIELDS"]; ok {
+				switch v := fieldsVal.(type) {
+				case []string:
+					fields = v
+				case []any:
+					for _, item := range v {
+						if s, ok := item.(string); ok {
+							fields = append(fields, s)
+						}
+					}
+				case string:
+					fields = []string{v}
+				}
+			}
+
+			// Check if generation is enabled (flag or env var)
+			if shouldGenerate(generate) {
+				return generateUnflattenCode(separator, fields)
+			}
+
+			// Read JSONL from stdin
+			records := lib.ReadJSONL(os.Stdin)
+
+			// Apply unflattening, reporting keys that cannot be nested
+			// (e.g. "a" alongside "a.b"); those are passed through unchanged
+			unflattened := func(yield func(ssql.Record) bool) {
+				for record, err := range ssql.DotUnflattenSafe(separator, fields...)(ssql.Safe(records)) {
+					if err != nil {
+						fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+					}
+					if !yield(record) {
+						return
+					}
+				}
+			}
+
+			// Write output as JSONL
+			if err := lib.WriteJSONL(os.Stdout, unflattened); err != nil {
+				return fmt.Errorf("writing output: %w", err)
+			}
+
+			return nil
+		}).
+		Done()
+	return cmd
+}
+
+// generateUnflattenCode generates Go code for the unflatten command
+func generateUnflattenCode(separator string, fields []string) error {
+	// Read all previous code fragments from stdin
+	fragments, err := lib.ReadAllCodeFragments()
+	if err != nil {
+		return fmt.Errorf("reading code fragments: %w", err)
+	}
+
+	// Pass through all previous fragments
+	for _, frag := range fragments {
+		if err := lib.WriteCodeFragment(frag); err != nil {
+			return fmt.Errorf("writing previous fragment: %w", err)
+		}
+	}
+
+	// Get input variable from last fragment
+	var inputVar string
+	if len(fragments) > 0 {
+		inputVar = fragments[len(fragments)-1].Var
+	} else {
+		inputVar = "records"
+	}
+
+	// Generate code
+	outputVar := "unflattened"
+	code := fmt.Sprintf(`%s := ssql.DotUnflatten(%s)(%s)`, outputVar, flattenArgs(separator, fields), inputVar)
+
+	// Create stmt fragment
+	frag := lib.NewStmtFragment(outputVar, inputVar, code, nil, getCommandString())
+	return lib.WriteCodeFragment(frag)
+}
@@ -16,6 +16,7 @@ func RegisterUnion(cmd *cf.CommandBuilder) *cf.CommandBuilder {
 		Description("Combine records from multiple sources (SQL UNION)").
 		Example("ssql read-csv 2023.csv | ssql union -file 2024.csv", "Combine records from two CSV files (removes duplicates)").
 		Example("ssql read-csv east.csv | ssql union -all -file west.csv -file south.csv", "Combine three files keeping all records (UNION ALL)").
+		Example("ssql read-json mon.jsonl | ssql union -all -merge-by ts -file tue.jsonl -file wed.jsonl", "Merge per-day files, each sorted by ts, into one stream sorted by ts").
 		Flag("-file", "-f").
 			String().
 			Completer(&cf.FileCompleter{Pattern: "*.{csv,jsonl}"}).
@@ -28,6 +29,13 @@ func RegisterUnion(cmd *cf.CommandBuilder) *cf.CommandBuilder {
 			Global().
 			Help("Keep duplicates (UNION ALL instead of UNION)").
 		Done().
+		Flag("-merge-by").
+			String().
+			Completer(cf.NoCompleter{Hint: "<field-name>"}).
+			Global().
+			Default("").
+			Help("Merge inputs that are each sorted by this field, keeping the output sorted (instead of concatenating)").
+		Done().
 		Flag("-input", "-i").
 			String().
 			Completer(&cf.FileCompleter{Pattern: "*.jsonl"}).
@@ -36,7 +44,7 @@ func RegisterUnion(cmd *cf.CommandBuilder) *cf.CommandBuilder {
 			Help("First input JSONL file (or stdin if not specified)").
 		Done().
 		Handler(func(ctx *cf.Context) error {
-			var inputFile string
+			var inputFile, mergeBy string
 			var unionAll bool
 
 			if fileVal, ok := ctx.GlobalFlags["-input"]; ok {
@@ -45,6 +53,9 @@ func RegisterUnion(cmd *cf.CommandBuilder) *cf.CommandBuilder {
 			if allVal, ok := ctx.GlobalFlags["-all"]; ok {
 				unionAll = allVal.(bool)
 			}
+			if mergeVal, ok := ctx.GlobalFlags["-merge-by"]; ok {
+				mergeBy = mergeVal.(string)
+			}
 
 			// Get additional files from -file flags
 			var additionalFiles []string
@@ -74,8 +85,13 @@ func RegisterUnion(cmd *cf.CommandBuilder) *cf.CommandBuilder {
 
 			firstRecords := lib.ReadJSONL(firstInput)
 
-			// Chain all iterators together
-			combined := chainRecords(firstRecords, additionalFiles)
+			// Chain all iterators together, or merge them in order
+			var combined iter.Seq[ssql.Record]
+			if mergeBy != "" {
+				combined = mergeRecords(firstRecords, additionalFiles, mergeBy)
+			} else {
+				combined = chainRecords(firstRecords, additionalFiles)
+			}
 
 			// Apply distinct if not UNION ALL
 			var result iter.Seq[ssql.Record]
@@ -2,6 +2,7 @@ package commands
 
 import (
 	"fmt"
+	"iter"
 	"os"
 	"strings"
 	"time"
@@ -22,6 +23,7 @@ func RegisterUpdate(cmd *cf.CommandBuilder) *cf.CommandBuilder {
 		Example("ssql read-csv sales.csv | ssql update -set-expr discount 'total > 1000 ? total * 0.1 : 0'", "Apply conditional discount (ternary operator)").
 		Example("ssql read-csv users.csv | ssql update -set-expr email 'lower(trim(email))'", "Normalize email addresses").
 		Example("ssql read-csv data.csv | ssql update -set-expr tier 'revenue > 10000 ? \"gold\" : (revenue > 5000 ? \"silver\" : \"bronze\")'", "Multi-tier categorization").
+		Example("ssql read-json events.jsonl | ssql update -parallel 8 -set-expr host 'lower(trim(host))'", "Evaluate expressions on 8 goroutines").
 		ClauseDescription("Clauses are evaluated in order using if-then-else logic.\nSeparators: +, -\nThe FIRST matching clause applies its updates, then processing stops (first-match-wins).\nThis is different from 'where' which uses OR logic - all clauses are evaluated.").
 		Flag("-generate", "-g").
 			Bool().
@@ -50,6 +52,12 @@ func RegisterUpdate(cmd *cf.CommandBuilder) *cf.CommandBuilder {
 			Local().
 			Help("Set field to expression result: -set-expr <field> <expression>").
 		Done().
+		Flag("-parallel").
+			Int().
+			Global().
+			Default(0).
+			Help("Evaluate updates on N goroutines, keeping record order (0 = sequential)").
+		Done().
 		Flag("FILE").
 			String().
 			Completer(&cf.FileCompleter{Pattern: "*.jsonl"}).
@@ -69,9 +77,14 @@ func RegisterUpdate(cmd *cf.CommandBuilder) *cf.CommandBuilder {
 				generate = genVal.(bool)
 			}
 
+			parallel, err := parallelFlag(ctx.GlobalFlags)
+			if err != nil {
+				return err
+			}
+
 			// Check if generation is enabled (flag or env var)
 			if shouldGenerate(generate) {
-				return generateUpdateCode(ctx, inputFile)
+				return generateUpdateCode(ctx, inputFile, parallel)
 			}
 
 			// Parse clauses - each clause has optional -match conditions and required -set/-set-expr operations
@@ -192,8 +205,8 @@ func RegisterUpdate(cmd *cf.CommandBuilder) *cf.CommandBuilder {
 
 			records := lib.ReadJSONL(input)
 
-			// Build update filter with first-match-wins clause evaluation (using pre-compiled expressions)
-			updateFilter := ssql.Update(func(mut ssql.MutableRecord) ssql.MutableRecord {
+			// Build update function with first-match-wins clause evaluation (using pre-compiled expressions)
+			updateRecord := func(mut ssql.MutableRecord) ssql.MutableRecord {
 				frozen := mut.Freeze()
 
 				// Evaluate clauses in order - first match wins
@@ -237,10 +250,17 @@ func RegisterUpdate(cmd *cf.CommandBuilder) *cf.CommandBuilder {
 				}
 
 				return mut
-			})
+			}
 
-			// Apply update
-			updated := updateFilter(records)
+			// Apply update (pre-compiled expressions are safe to share between goroutines)
+			var updated iter.Seq[ssql.Record]
+			if parallel > 1 {
+				updated = ssql.ParallelSelect(parallel, ssql.ParallelOrdered, func(r ssql.Record) ssql.Record {
+					return updateRecord(r.ToMutable()).Freeze()
+				})(records)
+			} else {
+				updated = ssql.Update(updateRecord)(records)
+			}
 
 			// Write output as JSONL
 			if err := lib.WriteJSONL(os.Stdout, updated); err != nil {
@@ -254,7 +274,7 @@ func RegisterUpdate(cmd *cf.CommandBuilder) *cf.CommandBuilder {
 }
 
 // generateUpdateCode generates Go code for the update command with conditional clauses
-func generateUpdateCode(ctx *cf.Context, inputFile string) error {
+func generateUpdateCode(ctx *cf.Context, inputFile string, parallel int) error {
 	// Read all previous code fragments from stdin
 	fragments, err := lib.ReadAllCodeFragments()
 	if err != nil {
@@ -523,10 +543,19 @@ func generateUpdateCode(ctx *cf.Context, inputFile string) error {
 	}
 
 	outputVar := "updated"
-	updateCode := fmt.Sprintf(`%s := ssql.Update(func(mut ssql.MutableRecord) ssql.MutableRecord {
+	var updateCode string
+	if parallel > 1 {
+		updateCode = fmt.Sprintf(`%s := ssql.ParallelSelect(%d, ssql.ParallelOrdered, func(r ssql.Record) ssql.Record {
+		mut := r.ToMutable()
+%s
+		return mut.Freeze()
+	})(%s)`, outputVar, parallel, codeBody.String(), inputVar)
+	} else {
+		updateCode = fmt.Sprintf(`%s := ssql.Update(func(mut ssql.MutableRecord) ssql.MutableRecord {
 %s
 		return mut
 	})(%s)`, outputVar, codeBody.String(), inputVar)
+	}
 	codeLines = append(codeLines, updateCode)
 	code := strings.Join(codeLines, "\n")
 
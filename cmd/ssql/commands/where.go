@@ -2,6 +2,7 @@ package commands
 
 import (
 	"fmt"
+	"iter"
 	"os"
 	"strconv"
 	"strings"
@@ -22,6 +23,8 @@ func RegisterWhere(cmd *cf.CommandBuilder) *cf.CommandBuilder {
 		Example("ssql read-csv users.csv | ssql where -expr 'age >= 18 and status == \"active\"'", "Multiple conditions with AND logic").
 		Example("ssql read-csv data.csv | ssql where -expr 'has(\"email\") and contains(email, \"@\")'", "Validate email field exists and format").
 		Example("ssql read-csv sales.csv | ssql where -expr '(age >= 18 and verified) or role == \"admin\"'", "Complex boolean logic").
+		Example("ssql read-json orders.jsonl | ssql where -match customer.address.city eq Paris", "Match a nested field by path").
+		Example("ssql read-json events.jsonl | ssql where -parallel 8 -expr 'message matches \"timeout|refused\"'", "Evaluate expressions on 8 goroutines").
 		Flag("-generate", "-g").
 			Bool().
 			Global().
@@ -41,6 +44,12 @@ func RegisterWhere(cmd *cf.CommandBuilder) *cf.CommandBuilder {
 			Local().
 			Help("Filter using boolean expression: -expr <expression>").
 		Done().
+		Flag("-parallel").
+			Int().
+			Global().
+			Default(0).
+			Help("Evaluate conditions on N goroutines, keeping record order (0 = sequential)").
+		Done().
 		Flag("FILE").
 			String().
 			Completer(&cf.FileCompleter{Pattern: "*.jsonl"}).
@@ -60,9 +69,14 @@ func RegisterWhere(cmd *cf.CommandBuilder) *cf.CommandBuilder {
 				generate = genVal.(bool)
 			}
 
+			parallel, err := parallelFlag(ctx.GlobalFlags)
+			if err != nil {
+				return err
+			}
+
 			// Check if generation is enabled (flag or env var)
 			if shouldGenerate(generate) {
-				return generateWhereCode(ctx, inputFile)
+				return generateWhereCode(ctx, inputFile, parallel)
 			}
 
 			// Pre-compile all expressions ONCE before processing records
@@ -193,8 +207,13 @@ func RegisterWhere(cmd *cf.CommandBuilder) *cf.CommandBuilder {
 
 			records := lib.ReadJSONL(input)
 
-			// Apply filter
-			filtered := ssql.Where(filter)(records)
+			// Apply filter (pre-compiled expressions are safe to share between goroutines)
+			var filtered iter.Seq[ssql.Record]
+			if parallel > 1 {
+				filtered = ssql.ParallelWhere(parallel, ssql.ParallelOrdered, filter)(records)
+			} else {
+				filtered = ssql.Where(filter)(records)
+			}
 
 			// Write output as JSONL
 			if err := lib.WriteJSONL(os.Stdout, filtered); err != nil {
@@ -208,7 +227,7 @@ func RegisterWhere(cmd *cf.CommandBuilder) *cf.CommandBuilder {
 }
 
 // generateWhereCode generates Go code for the where command
-func generateWhereCode(ctx *cf.Context, inputFile string) error {
+func generateWhereCode(ctx *cf.Context, inputFile string, parallel int) error {
 	// Read all previous code fragments from stdin (if any)
 	fragments, err := lib.ReadAllCodeFragments()
 	if err != nil {
@@ -240,7 +259,11 @@ func generateWhereCode(ctx *cf.Context, inputFile string) error {
 	}
 
 	outputVar := "filtered"
-	codeLines = append(codeLines, fmt.Sprintf("%s := ssql.Where(%s)(%s)", outputVar, filterCode, inputVar))
+	if parallel > 1 {
+		codeLines = append(codeLines, fmt.Sprintf("%s := ssql.ParallelWhere(%d, ssql.ParallelOrdered, %s)(%s)", outputVar, parallel, filterCode, inputVar))
+	} else {
+		codeLines = append(codeLines, fmt.Sprintf("%s := ssql.Where(%s)(%s)", outputVar, filterCode, inputVar))
+	}
 	code := strings.Join(codeLines, "\n")
 
 	// Create code fragment
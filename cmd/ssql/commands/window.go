@@ -0,0 +1,282 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	cf "github.com/rosscartlidge/autocli/v3"
+	"github.com/rosscartlidge/ssql/v2"
+	"github.com/rosscartlidge/ssql/v2/cmd/ssql/lib"
+)
+
+// RegisterWindow registers the window subcommand
+func RegisterWindow(cmd *cf.CommandBuilder) *cf.CommandBuilder {
+	cmd.Subcommand("window").
+		Description("Aggregate records over tumbling, sliding or session windows of event time").
+		Example("ssql read-json access.jsonl | ssql window -tumbling 5m -time ts -sum bytes total", "Total bytes in each five-minute window").
+		Example("ssql read-json access.jsonl | ssql window -tumbling 1m -time ts -by host -count requests -max ms slowest", "Requests and slowest request per host per minute").
+		Example("ssql read-json access.jsonl | ssql window -sliding 10m -slide 1m -time ts -delay 30s -avg ms avg_ms", "Ten-minute moving average every minute, tolerating 30s of disorder").
+		Example("ssql read-json clicks.jsonl | ssql window -session 30m -time ts -by user_id -count pages", "Pages per user session").
+		Flag("-generate", "-g").
+			Bool().
+			Global().
+			Help("Generate Go code instead of executing").
+		Done().
+		Flag("-tumbling").
+			String().
+			Completer(cf.NoCompleter{Hint: "<duration e.g. 5m>"}).
+			Global().
+			Default("").
+			Help("Back-to-back windows of this length").
+		Done().
+		Flag("-sliding").
+			String().
+			Completer(cf.NoCompleter{Hint: "<duration e.g. 10m>"}).
+			Global().
+			Default("").
+			Help("Overlapping windows of this length, starting every -slide").
+		Done().
+		Flag("-slide").
+			String().
+			Completer(cf.NoCompleter{Hint: "<duration e.g. 1m>"}).
+			Global().
+			Default("").
+			Help("Distance between sliding window starts").
+		Done().
+		Flag("-session").
+			String().
+			Completer(cf.NoCompleter{Hint: "<duration e.g. 30m>"}).
+			Global().
+			Default("").
+			Help("Session windows split by inactivity gaps of this length").
+		Done().
+		Flag("-time", "-t").
+			String().
+			Completer(cf.NoCompleter{Hint: "<field-name>"}).
+			Global().
+			Default("").
+			Help("Event time field (RFC3339 timestamp or Unix seconds)").
+		Done().
+		Flag("-by").
+			String().
+			Completer(cf.NoCompleter{Hint: "<field-name>"}).
+			Accumulate().
+			Local().
+			Help("Key field; windows are computed separately for each key").
+		Done().
+		Flag("-delay").
+			String().
+			Completer(cf.NoCompleter{Hint: "<duration e.g. 30s>"}).
+			Global().
+			Default("").
+			Help("How far out of order records may arrive (tumbling and sliding windows)").
+		Done().
+		Flag("-count").
+			Arg("result-name").Completer(cf.NoCompleter{Hint: "<name>"}).Done().
+			Accumulate().
+			Global().
+			Help("Count records (result field name)").
+		Done().
+		Flag("-sum").
+			Arg("field").Completer(cf.NoCompleter{Hint: "<field>"}).Done().
+			Arg("result-name").Completer(cf.NoCompleter{Hint: "<name>"}).Done().
+			Accumulate().
+			Global().
+			Help("Sum field values (field name, result name)").
+		Done().
+		Flag("-avg").
+			Arg("field").Completer(cf.NoCompleter{Hint: "<field>"}).Done().
+			Arg("result-name").Completer(cf.NoCompleter{Hint: "<name>"}).Done().
+			Accumulate().
+			Global().
+			Help("Average field values (field name, result name)").
+		Done().
+		Flag("-min").
+			Arg("field").Completer(cf.NoCompleter{Hint: "<field>"}).Done().
+			Arg("result-name").Completer(cf.NoCompleter{Hint: "<name>"}).Done().
+			Accumulate().
+			Global().
+			Help("Minimum field value (field name, result name)").
+		Done().
+		Flag("-max").
+			Arg("field").Completer(cf.NoCompleter{Hint: "<field>"}).Done().
+			Arg("result-name").Completer(cf.NoCompleter{Hint: "<name>"}).Done().
+			Accumulate().
+			Global().
+			Help("Maximum field value (field name, result name)").
+		Done().
+		Handler(func(ctx *cf.Context) error {
+			var generate bool
+
+			if genVal, ok := ctx.GlobalFlags["-generate"]; ok {
+				generate = genVal.(bool)
+			}
+
+			var keyFields []string
+			if len(ctx.Clauses) > 0 {
+				keyFields = clauseStrings(ctx.Clauses[0].Flags["-by"])
+			}
+
+			def, err := parseWindowDef(ctx.GlobalFlags, keyFields)
+			if err != nil {
+				return err
+			}
+
+			specs := parseAggSpecs(ctx.GlobalFlags)
+			if len(specs) == 0 {
+				return fmt.Errorf("no aggregations specified (use -count, -sum, -avg, -min, -max)")
+			}
+
+			// Check if generation is enabled (flag or env var)
+			if shouldGenerate(generate) {
+				return generateWindowCode(def, specs)
+			}
+
+			aggregations, err := buildAggregations(specs)
+			if err != nil {
+				return err
+			}
+
+			// Read JSONL from stdin
+			records := lib.ReadJSONL(os.Stdin)
+
+			summaries := ssql.WindowAggregate(def.spec(), aggregations)(records)
+
+			// Write output as JSONL
+			if err := lib.WriteJSONL(os.Stdout, summaries); err != nil {
+				return fmt.Errorf("writing output: %w", err)
+			}
+
+			return nil
+		}).
+		Done()
+	return cmd
+}
+
+// windowDef is a window chosen by the window command's flags
+type windowDef struct {
+	kind      string // "tumbling", "sliding" or "session"
+	size      time.Duration
+	slide     time.Duration
+	delay     time.Duration
+	timeField string
+	keyFields []string
+}
+
+// parseWindowDef reads the window selected by exactly one of -tumbling,
+// -sliding (with -slide) or -session, with -time and optional -delay
+func parseWindowDef(flags map[string]any, keyFields []string) (windowDef, error) {
+	flag := func(name string) string {
+		val, _ := flags[name].(string)
+		return val
+	}
+	duration := func(name string) (time.Duration, error) {
+		d, err := time.ParseDuration(flag(name))
+		if err != nil || d <= 0 {
+			return 0, fmt.Errorf("invalid %s %q: must be a positive duration like 30s, 5m or 1h", name, flag(name))
+		}
+		return d, nil
+	}
+
+	def := windowDef{timeField: flag("-time"), keyFields: keyFields}
+	var kinds []string
+	for _, kind := range []string{"tumbling", "sliding", "session"} {
+		if flag("-"+kind) != "" {
+			kinds = append(kinds, kind)
+		}
+	}
+	if len(kinds) != 1 {
+		return def, fmt.Errorf("exactly one of -tumbling, -sliding or -session required")
+	}
+	def.kind = kinds[0]
+	if def.timeField == "" {
+		return def, fmt.Errorf("event time field required (use -time)")
+	}
+
+	var err error
+	if def.size, err = duration("-" + def.kind); err != nil {
+		return def, err
+	}
+	switch {
+	case def.kind == "sliding" && flag("-slide") == "":
+		return def, fmt.Errorf("sliding windows need -slide")
+	case def.kind != "sliding" && flag("-slide") != "":
+		return def, fmt.Errorf("-slide only applies to -sliding windows")
+	case def.kind == "session" && flag("-delay") != "":
+		return def, fmt.Errorf("-delay only applies to -tumbling and -sliding windows")
+	}
+	if flag("-slide") != "" {
+		if def.slide, err = duration("-slide"); err != nil {
+			return def, err
+		}
+	}
+	if flag("-delay") != "" {
+		if def.delay, err = duration("-delay"); err != nil {
+			return def, err
+		}
+	}
+	return def, nil
+}
+
+// spec returns the WindowSpec for the window
+func (d windowDef) spec() ssql.WindowSpec {
+	switch d.kind {
+	case "session":
+		return ssql.Sessions(d.size, d.timeField, d.keyFields...)
+	case "sliding":
+		config := ssql.Sliding(d.size, d.slide, d.timeField, d.keyFields...)
+		if d.delay > 0 {
+			config.Watermark = ssql.BoundedOutOfOrderness(d.delay)
+		}
+		return config
+	default:
+		config := ssql.Tumbling(d.size, d.timeField, d.keyFields...)
+		if d.delay > 0 {
+			config.Watermark = ssql.BoundedOutOfOrderness(d.delay)
+		}
+		return config
+	}
+}
+
+// specCode returns Go statements declaring the window's spec as variable spec
+func (d windowDef) specCode() string {
+	args := []string{durationCode(d.size)}
+	if d.kind == "sliding" {
+		args = append(args, durationCode(d.slide))
+	}
+	args = append(args, fmt.Sprintf("%q", d.timeField))
+	for _, f := range d.keyFields {
+		args = append(args, fmt.Sprintf("%q", f))
+	}
+	constructor := map[string]string{"tumbling": "ssql.Tumbling", "sliding": "ssql.Sliding", "session": "ssql.Sessions"}[d.kind]
+	code := fmt.Sprintf("spec := %s(%s)", constructor, strings.Join(args, ", "))
+	if d.delay > 0 {
+		code += fmt.Sprintf("\n\tspec.Watermark = ssql.BoundedOutOfOrderness(%s)", durationCode(d.delay))
+	}
+	return code
+}
+
+// generateWindowCode generates Go code for the window command
+func generateWindowCode(def windowDef, specs []aggSpec) error {
+	fragments, err := lib.ReadAllCodeFragments()
+	if err != nil {
+		return fmt.Errorf("reading code fragments: %w", err)
+	}
+	for _, frag := range fragments {
+		if err := lib.WriteCodeFragment(frag); err != nil {
+			return fmt.Errorf("writing previous fragment: %w", err)
+		}
+	}
+	var inputVar string
+	if len(fragments) > 0 {
+		inputVar = fragments[len(fragments)-1].Var
+	} else {
+		inputVar = "records"
+	}
+
+	code := fmt.Sprintf("%s\n\twindowed := ssql.WindowAggregate(spec, %s)(%s)", def.specCode(), aggregationsCode(specs), inputVar)
+	frag := lib.NewStmtFragment("windowed", inputVar, code, []string{"time"}, getCommandString())
+	return lib.WriteCodeFragment(frag)
+}
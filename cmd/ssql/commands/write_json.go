@@ -99,16 +99,8 @@ func generateWriteJSONCode(filename string, pretty bool) error {
 	if filename == "" {
 		// Write to stdout
 		if pretty {
-			code = fmt.Sprintf(`	// Collect and pretty-print records to stdout
-	var recordMaps []map[string]interface{}
-	for record := range %s {
-		data := make(map[string]interface{})
-		for k, v := range record.All() {
-			data[k] = v
-		}
-		recordMaps = append(recordMaps, data)
-	}
-	jsonBytes, err := json.MarshalIndent(recordMaps, "", "  ")
+			code = fmt.Sprintf(`	// Collect and pretty-print records to stdout (Records keep their field order)
+	jsonBytes, err := json.MarshalIndent(slices.Collect(%s), "", "  ")
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error encoding JSON: %%v\n", err)
 		os.Exit(1)
@@ -142,7 +134,7 @@ func generateWriteJSONCode(filename string, pretty bool) error {
 	imports := []string{"fmt", "os"}
 	// Add encoding/json import if pretty printing to stdout
 	if filename == "" && pretty {
-		imports = append(imports, "encoding/json")
+		imports = append(imports, "encoding/json", "slices")
 	}
 	frag := lib.NewFinalFragment(inputVar, code, imports, getCommandString())
 	return lib.WriteCodeFragment(frag)
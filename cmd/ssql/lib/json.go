@@ -11,13 +11,24 @@ import (
 )
 
 // ReadJSON reads JSON from a reader and returns an iterator of Records.
-// Auto-detects JSON array format ([{...}, {...}]) vs JSONL ({...}\n{...}\n)
-func ReadJSON(r io.Reader) iter.Seq[ssql.Record] {
-	return func(yield func(ssql.Record) bool) {
+// Auto-detects JSON array format ([{...}, {...}]) vs JSONL ({...}\n{...}\n).
+// Malformed input is skipped; use ReadJSONSafe to see it. With decimals,
+// non-integer numbers are read as ssql.Decimal instead of float64.
+func ReadJSON(r io.Reader, decimals bool) iter.Seq[ssql.Record] {
+	return ssql.IgnoreErrors(ReadJSONSafe(r, decimals))
+}
+
+// ReadJSONSafe reads JSON like ReadJSON, yielding an *ssql.RecordError
+// (stage "read-json", 0-based line number and the raw line) for each
+// malformed JSONL line, and a plain error if the input cannot be read or
+// a JSON array cannot be parsed.
+func ReadJSONSafe(r io.Reader, decimals bool) iter.Seq2[ssql.Record, error] {
+	return func(yield func(ssql.Record, error) bool) {
 		// Read all input to detect format
 		data, err := io.ReadAll(r)
 		if err != nil {
-			return // Fail silently in streaming context
+			yield(ssql.Record{}, fmt.Errorf("reading JSON: %w", err))
+			return
 		}
 
 		// Trim whitespace
@@ -29,43 +40,48 @@ func ReadJSON(r io.Reader) iter.Seq[ssql.Record] {
 		// Check if it starts with '[' (JSON array)
 		if data[0] == '[' {
 			// Parse as JSON array
-			var records []map[string]interface{}
-			if err := json.Unmarshal(data, &records); err != nil {
-				return // Fail silently
+			var elements []json.RawMessage
+			if err := json.Unmarshal(data, &elements); err != nil {
+				yield(ssql.Record{}, fmt.Errorf("invalid JSON array: %w", err))
+				return
 			}
 
-			for _, rec := range records {
-				record := ssql.MakeMutableRecord()
-				for k, v := range rec {
-					record = setValueFromJSON(record, k, v)
+			for i, element := range elements {
+				record, err := decodeJSONLine(element, decimals)
+				if err != nil {
+					yield(ssql.Record{}, fmt.Errorf("invalid JSON array element %d: %w", i, err))
+					return
 				}
-
-				if !yield(record.Freeze()) {
+				if !yield(record, nil) {
 					return
 				}
 			}
-		} else {
-			// Parse as JSONL (line by line)
-			lines := bytes.Split(data, []byte("\n"))
-			for _, line := range lines {
-				line = bytes.TrimSpace(line)
-				if len(line) == 0 {
-					continue
-				}
+			return
+		}
 
-				var rec map[string]interface{}
-				if err := json.Unmarshal(line, &rec); err != nil {
-					continue // Skip malformed lines
-				}
+		// Parse as JSONL (line by line)
+		for i, line := range bytes.Split(data, []byte("\n")) {
+			line = bytes.TrimSpace(line)
+			if len(line) == 0 {
+				continue
+			}
 
-				record := ssql.MakeMutableRecord()
-				for k, v := range rec {
-					record = setValueFromJSON(record, k, v)
+			record, err := decodeJSONLine(line, decimals)
+			if err != nil {
+				recErr := &ssql.RecordError{
+					Stage:    "read-json",
+					Position: int64(i),
+					Input:    string(line),
+					Err:      fmt.Errorf("invalid JSON on line %d: %w", i, err),
 				}
-
-				if !yield(record.Freeze()) {
+				if !yield(ssql.Record{}, recErr) {
 					return
 				}
+				continue
+			}
+
+			if !yield(record, nil) {
+				return
 			}
 		}
 	}
@@ -81,17 +97,13 @@ func WriteJSON(w io.Writer, records iter.Seq[ssql.Record], pretty bool) error {
 	}
 
 	// Collect all records into a slice
-	var recordMaps []map[string]interface{}
+	var objects []interface{}
 	for record := range records {
-		data := make(map[string]interface{})
-		for k, v := range record.All() {
-			data[k] = convertRecordValue(v)
-		}
-		recordMaps = append(recordMaps, data)
+		objects = append(objects, convertRecordValue(record))
 	}
 
 	// Marshal as pretty JSON array
-	jsonBytes, err := json.MarshalIndent(recordMaps, "", "  ")
+	jsonBytes, err := json.MarshalIndent(objects, "", "  ")
 	if err != nil {
 		return fmt.Errorf("encoding records as JSON: %w", err)
 	}
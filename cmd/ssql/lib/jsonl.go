@@ -2,11 +2,17 @@ package lib
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"iter"
+	"maps"
 	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/rosscartlidge/ssql/v2"
 )
@@ -14,39 +20,97 @@ import (
 // Stdout is a convenience variable for writing to stdout
 var Stdout io.WriteCloser = os.Stdout
 
-// ReadJSONL reads JSONL (JSON Lines) from a reader and returns an iterator of Records
-func ReadJSONL(r io.Reader) iter.Seq[ssql.Record] {
-	return func(yield func(ssql.Record) bool) {
+// ReadJSONL reads JSONL (JSON Lines) from a reader and returns an iterator of Records.
+// If a schema is given, its fields are converted to the declared types; values
+// that cannot be converted are kept as decoded. Malformed lines are skipped;
+// use ReadJSONLSafe to see them.
+//
+// Decimals written by WriteJSONL are read back as ssql.Decimal (see
+// decimalJSONL), so exact values survive each step of a pipeline.
+func ReadJSONL(r io.Reader, schema ...ssql.Schema) iter.Seq[ssql.Record] {
+	return ssql.IgnoreErrors(ReadJSONLSafe(r, schema...))
+}
+
+// ReadJSONLSafe reads JSONL like ReadJSONL, yielding an *ssql.RecordError
+// (stage "read-jsonl", 0-based line number and the raw line) for each
+// malformed line and a plain error if reading fails.
+func ReadJSONLSafe(r io.Reader, schema ...ssql.Schema) iter.Seq2[ssql.Record, error] {
+	return func(yield func(ssql.Record, error) bool) {
 		scanner := bufio.NewScanner(r)
 
 		// Increase buffer size for large lines
 		buf := make([]byte, 0, 64*1024)
 		scanner.Buffer(buf, 1024*1024) // 1MB max token size
 
+		lineNumber := int64(-1)
 		for scanner.Scan() {
+			lineNumber++
 			line := scanner.Bytes()
 			if len(line) == 0 {
 				continue // Skip empty lines
 			}
 
-			// Parse JSON object
-			var data map[string]interface{}
-			if err := json.Unmarshal(line, &data); err != nil {
-				// Skip malformed lines silently in streaming context
+			record, err := decodeJSONLine(line, false)
+			if err != nil {
+				recErr := &ssql.RecordError{
+					Stage:    "read-jsonl",
+					Position: lineNumber,
+					Input:    string(line),
+					Err:      fmt.Errorf("invalid JSON on line %d: %w", lineNumber, err),
+				}
+				if !yield(ssql.Record{}, recErr) {
+					return
+				}
 				continue
 			}
 
-			// Convert to Record directly (not using TypedRecord builder)
-			record := ssql.MakeMutableRecord()
-			for k, v := range data {
-				record = setValueFromJSON(record, k, v)
+			if len(schema) > 0 {
+				record, _ = schema[0].Coerce(record)
 			}
 
-			if !yield(record.Freeze()) {
+			if !yield(record, nil) {
 				return
 			}
 		}
+
+		if err := scanner.Err(); err != nil {
+			yield(ssql.Record{}, fmt.Errorf("reading JSONL: %w", err))
+		}
+	}
+}
+
+// decodeJSONLine parses one JSON object, keeping the key order of the line.
+// Numbers are decoded from their text (see jsonNumberValue); with decimals,
+// every non-integer number becomes an ssql.Decimal.
+func decodeJSONLine(line []byte, decimals bool) (ssql.Record, error) {
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	if tok, err := dec.Token(); err != nil {
+		return ssql.Record{}, err
+	} else if tok != json.Delim('{') {
+		return ssql.Record{}, fmt.Errorf("expected JSON object, got %v", tok)
+	}
+
+	// Convert to Record directly (not using TypedRecord builder)
+	record := ssql.MakeMutableRecord()
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return ssql.Record{}, err
+		}
+		var value any
+		if err := dec.Decode(&value); err != nil {
+			return ssql.Record{}, err
+		}
+		record = setValueFromJSON(record, tok.(string), value, decimals)
+	}
+	if _, err := dec.Token(); err != nil { // closing '}'
+		return ssql.Record{}, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return ssql.Record{}, fmt.Errorf("invalid character after top-level value")
 	}
+	return record.Freeze(), nil
 }
 
 // WriteJSONL writes Records to a writer as JSONL (JSON Lines)
@@ -55,13 +119,8 @@ func WriteJSONL(w io.Writer, records iter.Seq[ssql.Record]) error {
 	defer writer.Flush()
 
 	for record := range records {
-		// Convert Record to map for JSON encoding
-		data := make(map[string]interface{})
-
-		// Extract all fields from record
-		for k, v := range record.All() {
-			data[k] = convertRecordValue(v)
-		}
+		// Convert Record to an ordered JSON object (keys in field order)
+		data := convertRecordValue(record)
 
 		// Encode as JSON
 		jsonBytes, err := json.Marshal(data)
@@ -117,11 +176,11 @@ func OpenOutput(filename string) (io.WriteCloser, error) {
 
 // setValueFromJSON sets a field on a MutableRecord from a JSON value
 // Handles JSON-specific type conversions (nil, arrays, nested objects, numbers, bools, strings)
-func setValueFromJSON(record ssql.MutableRecord, key string, v interface{}) ssql.MutableRecord {
+func setValueFromJSON(record ssql.MutableRecord, key string, v interface{}, decimals bool) ssql.MutableRecord {
 	switch val := v.(type) {
-	case nil:
-		// Skip nil values - don't set the field
-		return record
+	case nil, ssql.Null:
+		// JSON null is kept as an explicit null, distinct from an absent field
+		return record.Null(key)
 	case []interface{}:
 		// Convert array to JSON string for storage
 		jsonBytes, err := json.Marshal(val)
@@ -135,11 +194,23 @@ func setValueFromJSON(record ssql.MutableRecord, key string, v interface{}) ssql
 		return record.JSONString(key, jsonStr)
 	case map[string]interface{}:
 		// Nested object - convert to Record recursively
+		// Nested objects decode without key order; sort for stable output
 		nested := ssql.MakeMutableRecord()
-		for k, subv := range val {
-			nested = setValueFromJSON(nested, k, subv)
+		for _, k := range slices.Sorted(maps.Keys(val)) {
+			nested = setValueFromJSON(nested, k, val[k], decimals)
 		}
 		return ssql.Set(record, key, nested.Freeze())
+	case json.Number:
+		switch n := jsonNumberValue(val, decimals).(type) {
+		case int64:
+			return record.Int(key, n)
+		case ssql.Decimal:
+			return record.Decimal(key, n)
+		case float64:
+			return record.Float(key, n)
+		default:
+			return record.String(key, string(val))
+		}
 	case float64:
 		// JSON numbers are always float64 - check if it's actually an integer
 		if val == float64(int64(val)) {
@@ -160,17 +231,110 @@ func setValueFromJSON(record ssql.MutableRecord, key string, v interface{}) ssql
 func convertRecordValue(v interface{}) interface{} {
 	switch val := v.(type) {
 	case ssql.Record:
-		// Convert nested Record to map
-		result := make(map[string]interface{})
+		// Convert nested Record to an object that keeps the field order
+		result := make(jsonObject, 0, val.Len())
 		for k, subv := range val.All() {
-			result[k] = convertRecordValue(subv)
+			result = append(result, jsonMember{key: k, value: convertRecordValue(subv)})
 		}
 		return result
 	case int64, float64, bool, string, nil:
 		// Canonical types pass through
 		return val
+	case ssql.Null:
+		return nil
+	case ssql.Decimal:
+		// Keep every digit; json.Number is written as a bare number
+		return json.Number(decimalJSONL(val))
+	case ssql.JSONString:
+		// Arrays read from JSONL are written back as JSON, not as quoted strings
+		if val.IsValid() {
+			return json.RawMessage(val)
+		}
+		return string(val)
 	default:
-		// For sequences and other types, try to convert to simple representation
+		// Sequences (e.g. from unflatten) become JSON arrays
+		if reflect.ValueOf(v).Kind() == reflect.Func {
+			if js, err := ssql.NewJSONString(v); err == nil {
+				return json.RawMessage(js)
+			}
+		}
+		// For other types, try to convert to simple representation
 		return fmt.Sprintf("%v", v)
 	}
 }
+
+// decimalJSONL formats a Decimal for JSONL with at least one trailing
+// fractional zero in its mantissa (19.99 as 19.990, 20 as 20.0, 1.5e200 as
+// 1.50e200). encoding/json never writes a float64 that way, so
+// jsonNumberValue can tell the Decimal apart when the next command in the
+// pipeline reads it; to any other JSON reader it is the same number.
+func decimalJSONL(d ssql.Decimal) string {
+	mantissa, exponent, scientific := strings.Cut(d.String(), "e")
+	if !strings.Contains(mantissa, ".") {
+		mantissa += "."
+	}
+	mantissa += "0"
+	if scientific {
+		return mantissa + "e" + exponent
+	}
+	return mantissa
+}
+
+// jsonNumberValue converts a JSON number to a Record value: int64 for
+// integers, ssql.Decimal for numbers written by decimalJSONL (or every
+// non-integer number with decimals), float64 otherwise
+func jsonNumberValue(n json.Number, decimals bool) any {
+	s := string(n)
+	if !strings.ContainsAny(s, ".eE") {
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return i
+		}
+	}
+	mantissa, _, _ := strings.Cut(strings.ToLower(s), "e")
+	if decimals || (strings.Contains(mantissa, ".") && strings.HasSuffix(mantissa, "0")) {
+		if d, err := ssql.ParseDecimal(s); err == nil {
+			return d
+		}
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return s
+	}
+	if f == float64(int64(f)) {
+		return int64(f)
+	}
+	return f
+}
+
+// jsonMember is one key/value pair of a jsonObject
+type jsonMember struct {
+	key   string
+	value interface{}
+}
+
+// jsonObject is a JSON object that is encoded with its keys in order
+type jsonObject []jsonMember
+
+// MarshalJSON implements json.Marshaler
+func (o jsonObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, m := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(m.key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(m.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
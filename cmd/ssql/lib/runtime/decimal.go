@@ -0,0 +1,173 @@
+package runtime
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/ast"
+	exprruntime "github.com/expr-lang/expr/vm/runtime"
+	"github.com/rosscartlidge/ssql/v2"
+)
+
+// decimalType is the reflect type of ssql.Decimal
+var decimalType = reflect.TypeOf(ssql.Decimal{})
+
+// decimalOperators are the binary operators that understand ssql.Decimal operands
+var decimalOperators = map[string]bool{
+	"+": true, "-": true, "*": true, "/": true,
+	"<": true, ">": true, "<=": true, ">=": true, "==": true, "!=": true,
+}
+
+// decimalPatcher rewrites arithmetic and comparisons whose operand types are
+// not known at compile time into calls to decimalBinary, so that record
+// fields holding ssql.Decimal values work with the usual operators.
+// Operations on known non-decimal types (e.g. literals) are left untouched.
+type decimalPatcher struct{}
+
+func (decimalPatcher) Visit(node *ast.Node) {
+	switch n := (*node).(type) {
+	case *ast.BinaryNode:
+		if !decimalOperators[n.Operator] || (!maybeDecimal(n.Left.Type()) && !maybeDecimal(n.Right.Type())) {
+			return
+		}
+		ast.Patch(node, &ast.CallNode{
+			Callee:    &ast.IdentifierNode{Value: "decimalBinary"},
+			Arguments: []ast.Node{&ast.StringNode{Value: n.Operator}, n.Left, n.Right},
+		})
+	case *ast.UnaryNode:
+		if n.Operator != "-" || !maybeDecimal(n.Node.Type()) {
+			return
+		}
+		ast.Patch(node, &ast.CallNode{
+			Callee:    &ast.IdentifierNode{Value: "decimalNegate"},
+			Arguments: []ast.Node{n.Node},
+		})
+	}
+}
+
+// maybeDecimal reports whether a node of type t may hold a Decimal at runtime
+func maybeDecimal(t reflect.Type) bool {
+	return t == nil || t == decimalType || t.Kind() == reflect.Interface
+}
+
+// decimalOptions returns the expr options that add Decimal support:
+// the operator patch, its helper functions and decimal(x) for building
+// Decimals from numbers and numeric strings.
+func decimalOptions() []expr.Option {
+	return []expr.Option{
+		expr.Patch(decimalPatcher{}),
+		expr.Function("decimal", func(params ...any) (any, error) {
+			d, ok := toDecimal(params[0])
+			if !ok {
+				return nil, fmt.Errorf("decimal: cannot convert %v (%T)", params[0], params[0])
+			}
+			return d, nil
+		}, new(func(any) ssql.Decimal)),
+		expr.Function("decimalBinary", func(params ...any) (any, error) {
+			return decimalBinary(params[0].(string), params[1], params[2])
+		}),
+		expr.Function("decimalNegate", func(params ...any) (any, error) {
+			if d, ok := params[0].(ssql.Decimal); ok {
+				return d.Neg(), nil
+			}
+			return exprruntime.Negate(params[0]), nil
+		}),
+	}
+}
+
+// decimalBinary applies op exactly when either operand is a Decimal and
+// falls back to expr's own semantics otherwise.
+// Division keeps at least 6 decimal places, like ssql.Avg.
+func decimalBinary(op string, a, b any) (any, error) {
+	_, aDecimal := a.(ssql.Decimal)
+	_, bDecimal := b.(ssql.Decimal)
+	if !aDecimal && !bDecimal {
+		return exprBinary(op, a, b), nil
+	}
+
+	x, xok := toDecimal(a)
+	y, yok := toDecimal(b)
+	if !xok || !yok {
+		switch op {
+		case "==":
+			return false, nil
+		case "!=":
+			return true, nil
+		}
+		return nil, fmt.Errorf("invalid operation: %v (%T) %s %v (%T)", a, a, op, b, b)
+	}
+
+	switch op {
+	case "+":
+		return x.Add(y), nil
+	case "-":
+		return x.Sub(y), nil
+	case "*":
+		return x.Mul(y), nil
+	case "/":
+		if y.IsZero() {
+			return nil, fmt.Errorf("decimal division by zero")
+		}
+		return x.Div(y, max(6, x.Scale(), y.Scale())), nil
+	case "<":
+		return x.Cmp(y) < 0, nil
+	case ">":
+		return x.Cmp(y) > 0, nil
+	case "<=":
+		return x.Cmp(y) <= 0, nil
+	case ">=":
+		return x.Cmp(y) >= 0, nil
+	case "==":
+		return x.Equal(y), nil
+	default: // "!="
+		return !x.Equal(y), nil
+	}
+}
+
+// exprBinary evaluates op with expr's runtime helpers (the unpatched behavior)
+func exprBinary(op string, a, b any) any {
+	switch op {
+	case "+":
+		return exprruntime.Add(a, b)
+	case "-":
+		return exprruntime.Subtract(a, b)
+	case "*":
+		return exprruntime.Multiply(a, b)
+	case "/":
+		return exprruntime.Divide(a, b)
+	case "<":
+		return exprruntime.Less(a, b)
+	case ">":
+		return exprruntime.More(a, b)
+	case "<=":
+		return exprruntime.LessOrEqual(a, b)
+	case ">=":
+		return exprruntime.MoreOrEqual(a, b)
+	case "==":
+		return exprruntime.Equal(a, b)
+	default: // "!="
+		return !exprruntime.Equal(a, b)
+	}
+}
+
+// toDecimal converts expression values (ints from literals, float64, numeric
+// strings and Decimals) to ssql.Decimal
+func toDecimal(v any) (ssql.Decimal, bool) {
+	switch val := v.(type) {
+	case ssql.Decimal:
+		return val, true
+	case int:
+		return ssql.DecimalFromInt(int64(val)), true
+	case int64:
+		return ssql.DecimalFromInt(val), true
+	case float64:
+		d, err := ssql.DecimalFromFloat(val)
+		return d, err == nil
+	case string:
+		d, err := ssql.ParseDecimal(val)
+		return d, err == nil
+	default:
+		return ssql.Decimal{}, false
+	}
+}
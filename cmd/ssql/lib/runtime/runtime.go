@@ -45,10 +45,11 @@ func CompileExpr(expression string) (func(ssql.Record) (any, error), error) {
 	sampleEnv["has"] = func(field string) bool { return false }
 	sampleEnv["getOr"] = func(field string, defaultValue any) any { return defaultValue }
 
-	program, err := expr.Compile(expression,
+	options := append([]expr.Option{
 		expr.Env(sampleEnv),
 		expr.AllowUndefinedVariables(),
-	)
+	}, decimalOptions()...)
+	program, err := expr.Compile(expression, options...)
 	if err != nil {
 		return nil, fmt.Errorf("compile expression: %w", err)
 	}
@@ -58,6 +59,10 @@ func CompileExpr(expression string) (func(ssql.Record) (any, error), error) {
 		// Build environment with all record fields
 		env := make(map[string]interface{})
 		for k, v := range record.All() {
+			if ssql.IsNull(v) {
+				// Expose explicit nulls as nil so expressions can test "field == nil"
+				v = nil
+			}
 			env[k] = v
 		}
 
@@ -27,18 +27,25 @@ func buildRootCommand() *cf.Command {
 	cmd = commands.RegisterLimit(cmd)
 	cmd = commands.RegisterOffset(cmd)
 	cmd = commands.RegisterSort(cmd)
+	cmd = commands.RegisterTop(cmd)
+	cmd = commands.RegisterSample(cmd)
 	cmd = commands.RegisterDistinct(cmd)
 	cmd = commands.RegisterWhere(cmd)
 	cmd = commands.RegisterUpdate(cmd)
 	cmd = commands.RegisterInclude(cmd)
 	cmd = commands.RegisterExclude(cmd)
+	cmd = commands.RegisterFlatten(cmd)
+	cmd = commands.RegisterUnflatten(cmd)
 	cmd = commands.RegisterRename(cmd)
 	cmd = commands.RegisterReadCSV(cmd)
 	cmd = commands.RegisterWriteCSV(cmd)
 	cmd = commands.RegisterReadJSON(cmd)
 	cmd = commands.RegisterWriteJSON(cmd)
 	cmd = commands.RegisterGroupBy(cmd)
+	cmd = commands.RegisterSession(cmd)
+	cmd = commands.RegisterWindow(cmd)
 	cmd = commands.RegisterJoin(cmd)
+	cmd = commands.RegisterDiff(cmd)
 	cmd = commands.RegisterUnion(cmd)
 	cmd = commands.RegisterExec(cmd)
 	cmd = commands.RegisterTable(cmd)
@@ -1,13 +1,16 @@
 package ssql
 
 import (
+	"bytes"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"iter"
 	"maps"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -148,6 +151,7 @@ func ChainWithErrors[T any](filters ...FilterWithErrors[T, T]) FilterWithErrors[
 //   - Supports sequences (iter.Seq[T])
 //   - Immutable updates via Record methods (creates copies)
 //   - maps-style API (All, Keys, Values) for iteration
+//   - Fields keep the order they were added in (CSV header order, JSON key order)
 //
 // Example:
 //
@@ -171,6 +175,7 @@ func ChainWithErrors[T any](filters ...FilterWithErrors[T, T]) FilterWithErrors[
 //	updated := record.Int("age", int64(31))
 type Record struct {
 	fields map[string]any
+	order  *[]string // field names in insertion order (see orderedKeys)
 }
 
 // JSONString represents a string containing valid JSON data.
@@ -228,18 +233,66 @@ func NewJSONString(value any) (JSONString, error) {
 	return JSONString(bytes), nil
 }
 
+// Null represents an explicit SQL-style NULL field value.
+// It lets a pipeline distinguish three states for a field:
+//   - absent: the field is not in the record (r.Has(field) == false)
+//   - null: the field is present with no value (r.IsNull(field) == true)
+//   - empty: the field holds a zero value such as ""
+//
+// Null follows SQL three-valued logic throughout ssql: it never equals
+// anything (including another Null) in join keys, aggregations skip it,
+// and Get reports it as not convertible to any concrete type.
+// It is written as an empty CSV cell and as JSON null.
+//
+// Example:
+//
+//	record := ssql.MakeMutableRecord().
+//	    String("name", "Alice").
+//	    Null("manager").
+//	    Freeze()
+//
+//	record.Has("manager")    // true
+//	record.IsNull("manager") // true
+//	_, ok := ssql.Get[string](record, "manager") // "", false
+type Null struct{}
+
+// String returns "null" so that Null displays clearly in tables and logs
+func (Null) String() string {
+	return "null"
+}
+
+// MarshalJSON implements json.Marshaler, encoding Null as JSON null
+func (Null) MarshalJSON() ([]byte, error) {
+	return []byte("null"), nil
+}
+
+// IsNull reports whether a field value is null (ssql.Null or a Go nil)
+func IsNull(value any) bool {
+	if value == nil {
+		return true
+	}
+	_, ok := value.(Null)
+	return ok
+}
+
 // Value constraint for type-safe record values
 // Hybrid approach: Canonical scalars (int64/float64), flexible sequences (any numeric type)
 type Value interface {
 	// Canonical scalar types only
 	~int64 | ~float64 |
 
+		// Exact base-10 numbers
+		Decimal |
+
 		// Other basic types
 		~bool | string | time.Time |
 
 		// JSON and Record types for structured data
 		JSONString | Record |
 
+		// Explicit null
+		Null |
+
 		// Iterator types - common types for ergonomics with slices.Values()
 		iter.Seq[int] | iter.Seq[int64] | iter.Seq[float64] |
 		iter.Seq[bool] | iter.Seq[string] | iter.Seq[time.Time] |
@@ -272,6 +325,7 @@ type Value interface {
 //	updated := record.Int("age", int64(31))  // Creates new Record
 type MutableRecord struct {
 	fields map[string]any
+	order  *[]string // shared by copies so chained calls see one order
 }
 
 // MakeMutableRecord creates an empty MutableRecord for efficient building.
@@ -284,15 +338,17 @@ type MutableRecord struct {
 //	    Int("population", int64(873965)).
 //	    Freeze()
 func MakeMutableRecord() MutableRecord {
-	return MutableRecord{fields: make(map[string]any)}
+	return MutableRecord{fields: make(map[string]any), order: new([]string)}
 }
 
 // MakeMutableRecordWithCapacity creates a MutableRecord with pre-allocated capacity
 func MakeMutableRecordWithCapacity(capacity int) MutableRecord {
-	return MutableRecord{fields: make(map[string]any, capacity)}
+	order := make([]string, 0, capacity)
+	return MutableRecord{fields: make(map[string]any, capacity), order: &order}
 }
 
 // NewRecord creates a Record from a map (for compatibility)
+// A map has no field order, so the fields are ordered by name.
 func NewRecord(fields map[string]any) Record {
 	// Copy the map to maintain encapsulation
 	m := make(map[string]any, len(fields))
@@ -305,7 +361,7 @@ func NewRecord(fields map[string]any) Record {
 func (m MutableRecord) Freeze() Record {
 	frozen := make(map[string]any, len(m.fields))
 	maps.Copy(frozen, m.fields)
-	return Record{fields: frozen}
+	return Record{fields: frozen, order: shareOrder(m.fields, m.order)}
 }
 
 // ToMutable creates a mutable copy of a Record for modification
@@ -313,7 +369,100 @@ func (m MutableRecord) Freeze() Record {
 func (r Record) ToMutable() MutableRecord {
 	m := make(map[string]any, len(r.fields))
 	maps.Copy(m, r.fields)
-	return MutableRecord{fields: m}
+	return MutableRecord{fields: m, order: shareOrder(r.fields, r.order)}
+}
+
+// set stores a field, appending it to the field order if it is new
+func (m MutableRecord) set(field string, value any) {
+	setField(m.fields, m.order, field, value)
+}
+
+// set stores a field in place; only for records still being built
+// inside the package, before anyone else can see them
+func (r Record) set(field string, value any) {
+	setField(r.fields, r.order, field, value)
+}
+
+// copyFields sets every field of src on dst, in src's field order
+func copyFields(dst MutableRecord, src Record) {
+	for k, v := range src.All() {
+		dst.set(k, v)
+	}
+}
+
+// setField stores a field, appending it to order if it is new
+func setField(fields map[string]any, order *[]string, field string, value any) {
+	if _, exists := fields[field]; !exists && order != nil {
+		*order = append(*order, field)
+	}
+	fields[field] = value
+}
+
+// remove deletes a field and its place in the field order
+func (m MutableRecord) remove(field string) {
+	if _, exists := m.fields[field]; !exists {
+		return
+	}
+	delete(m.fields, field)
+	if m.order != nil {
+		// Copy first: the order may be shared with frozen records (see shareOrder)
+		*m.order = slices.DeleteFunc(slices.Clone(*m.order), func(k string) bool { return k == field })
+	}
+}
+
+// shareOrder returns the field order for a copy of fields. A complete order
+// is shared rather than copied, with its capacity capped so that appending
+// to either copy reallocates; in-place edits (remove, Rename) copy first.
+func shareOrder(fields map[string]any, order *[]string) *[]string {
+	if order != nil && len(*order) == len(fields) {
+		shared := (*order)[:len(fields):len(fields)]
+		return &shared
+	}
+	keys := orderedKeys(fields, order)
+	return &keys
+}
+
+// keyOrder returns the field names in order without copying a complete
+// order; callers must not modify the result
+func (r Record) keyOrder() []string {
+	if r.order != nil && len(*r.order) == len(r.fields) {
+		return *r.order
+	}
+	return orderedKeys(r.fields, r.order)
+}
+
+// orderedKeys returns the field names in insertion order.
+// Fields stored without order information (e.g. records built from a map
+// with NewRecord) follow in sorted order, so output is still deterministic.
+func orderedKeys(fields map[string]any, order *[]string) []string {
+	keys := make([]string, 0, len(fields))
+	if order != nil {
+		for _, k := range *order {
+			if _, exists := fields[k]; exists {
+				keys = append(keys, k)
+			}
+		}
+	}
+	if len(keys) == len(fields) {
+		return keys
+	}
+
+	seen := make(map[string]bool, len(fields))
+	unique := keys[:0]
+	for _, k := range keys {
+		if !seen[k] {
+			seen[k] = true
+			unique = append(unique, k)
+		}
+	}
+	var rest []string
+	for k := range fields {
+		if !seen[k] {
+			rest = append(rest, k)
+		}
+	}
+	slices.Sort(rest)
+	return append(unique, rest...)
 }
 
 // ============================================================================
@@ -329,6 +478,11 @@ func (r Record) ToMutable() MutableRecord {
 //   - bool ↔ int64 (0/1)
 //   - string → time.Time (RFC3339, SQL datetime)
 //   - int64 → time.Time (Unix timestamp)
+//   - int64/float64/string → Decimal (exact for numeric strings)
+//   - Decimal → float64 (nearest), int64 (truncation), string
+//
+// field may be a nested path such as "user.address.city" or "items[0].sku"
+// (see SetPath); an exact top-level field name always takes precedence.
 //
 // Example:
 //
@@ -345,14 +499,18 @@ func (r Record) ToMutable() MutableRecord {
 //
 //	// Field doesn't exist
 //	missing, ok := ssql.Get[string](record, "missing")  // "", false
+//
+//	// Nested path
+//	city, ok := ssql.Get[string](order, "customer.address.city")
 func Get[T any](r Record, field string) (T, bool) {
-	val, exists := r.fields[field]
+	val, exists := r.lookup(field)
 	if !exists {
 		var zero T
 		return zero, false
 	}
 
 	// Direct type assertion first (fast path)
+	// Null only matches T = any or T = Null; it never converts to a concrete type
 	if typed, ok := val.(T); ok {
 		return typed, true
 	}
@@ -399,18 +557,20 @@ func (r Record) Has(field string) bool {
 	return exists
 }
 
+// IsNull checks if a field exists and holds a null value
+func (r Record) IsNull(field string) bool {
+	val, exists := r.fields[field]
+	return exists && IsNull(val)
+}
+
 // Len returns the number of fields in the record
 func (r Record) Len() int {
 	return len(r.fields)
 }
 
-// Keys returns all field names as a slice
+// Keys returns all field names as a slice, in the order they were added
 func (r Record) Keys() []string {
-	keys := make([]string, 0, len(r.fields))
-	for k := range r.fields {
-		keys = append(keys, k)
-	}
-	return keys
+	return slices.Clone(r.keyOrder())
 }
 
 // ============================================================================
@@ -418,10 +578,11 @@ func (r Record) Keys() []string {
 // ============================================================================
 
 // All returns an iterator over key-value pairs (matches maps.All)
+// Fields are visited in the order they were added.
 func (r Record) All() iter.Seq2[string, any] {
 	return func(yield func(string, any) bool) {
-		for k, v := range r.fields {
-			if !yield(k, v) {
+		for _, k := range r.keyOrder() {
+			if !yield(k, r.fields[k]) {
 				return
 			}
 		}
@@ -431,7 +592,7 @@ func (r Record) All() iter.Seq2[string, any] {
 // KeysIter returns an iterator over field names (matches maps.Keys)
 func (r Record) KeysIter() iter.Seq[string] {
 	return func(yield func(string) bool) {
-		for k := range r.fields {
+		for _, k := range r.keyOrder() {
 			if !yield(k) {
 				return
 			}
@@ -442,8 +603,8 @@ func (r Record) KeysIter() iter.Seq[string] {
 // Values returns an iterator over field values (matches maps.Values)
 func (r Record) Values() iter.Seq[any] {
 	return func(yield func(any) bool) {
-		for _, v := range r.fields {
-			if !yield(v) {
+		for _, k := range r.keyOrder() {
+			if !yield(r.fields[k]) {
 				return
 			}
 		}
@@ -454,10 +615,11 @@ func (r Record) Values() iter.Seq[any] {
 func (r Record) Clone() Record {
 	cloned := make(map[string]any, len(r.fields))
 	maps.Copy(cloned, r.fields)
-	return Record{fields: cloned}
+	return Record{fields: cloned, order: shareOrder(r.fields, r.order)}
 }
 
 // Equal checks if two records have the same fields and values (matches maps.Equal)
+// Field order is not compared.
 func (r Record) Equal(other Record) bool {
 	return maps.Equal(r.fields, other.fields)
 }
@@ -467,59 +629,148 @@ func (r Record) Equal(other Record) bool {
 // ============================================================================
 
 // MarshalJSON implements json.Marshaler
-// Records marshal as {"name": "Alice", "age": 30} not {"fields": {...}}
+// Records marshal as {"name": "Alice", "age": 30} not {"fields": {...}},
+// with keys in field order.
 func (r Record) MarshalJSON() ([]byte, error) {
-	return json.Marshal(r.fields)
+	return marshalFields(r.fields, r.keyOrder())
 }
 
 // UnmarshalJSON implements json.Unmarshaler
+// JSON null values become Null fields; fields keep the document's key order.
 func (r *Record) UnmarshalJSON(data []byte) error {
-	fields := make(map[string]any)
-	if err := json.Unmarshal(data, &fields); err != nil {
+	fields, order, err := decodeJSONObject(data, false)
+	if err != nil {
 		return err
 	}
-	r.fields = fields
+	nullifyJSONFields(fields)
+	r.fields, r.order = fields, &order
 	return nil
 }
 
 // MarshalJSON implements json.Marshaler for MutableRecord
 func (m MutableRecord) MarshalJSON() ([]byte, error) {
-	return json.Marshal(m.fields)
+	return marshalFields(m.fields, orderedKeys(m.fields, m.order))
 }
 
 // UnmarshalJSON implements json.Unmarshaler for MutableRecord
 func (m *MutableRecord) UnmarshalJSON(data []byte) error {
-	fields := make(map[string]any)
-	if err := json.Unmarshal(data, &fields); err != nil {
+	fields, order, err := decodeJSONObject(data, false)
+	if err != nil {
 		return err
 	}
-	m.fields = fields
+	nullifyJSONFields(fields)
+	m.fields, m.order = fields, &order
 	return nil
 }
 
+// marshalFields encodes fields as a JSON object with keys in the given order
+func marshalFields(fields map[string]any, keys []string) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, k := range keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(fields[k])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// decodeJSONObject decodes a JSON object, returning its fields and its keys
+// in document order. With useNumber, numbers decode as json.Number.
+func decodeJSONObject(data []byte, useNumber bool) (map[string]any, []string, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if useNumber {
+		dec.UseNumber()
+	}
+	if tok, err := dec.Token(); err != nil {
+		return nil, nil, err
+	} else if tok != json.Delim('{') {
+		return nil, nil, fmt.Errorf("expected JSON object, got %v", tok)
+	}
+
+	fields := make(map[string]any)
+	var order []string
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+		key := tok.(string) // object keys are always strings
+		var value any
+		if err := dec.Decode(&value); err != nil {
+			return nil, nil, err
+		}
+		if _, exists := fields[key]; !exists {
+			order = append(order, key)
+		}
+		fields[key] = value
+	}
+	if _, err := dec.Token(); err != nil { // closing '}'
+		return nil, nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, nil, fmt.Errorf("invalid character after top-level value")
+	}
+	return fields, order, nil
+}
+
+// nullifyJSONFields replaces decoded JSON nulls with Null
+func nullifyJSONFields(fields map[string]any) {
+	for k, v := range fields {
+		if v == nil {
+			fields[k] = Null{}
+		}
+	}
+}
+
 // ============================================================================
 // MUTABLERECORD FIELD METHODS - IN-PLACE MUTATION (EFFICIENT)
 // ============================================================================
 
 // Set adds a field with compile-time type safety (mutates in place)
 func Set[V Value](m MutableRecord, field string, value V) MutableRecord {
-	m.fields[field] = value
+	m.set(field, value)
 	return m
 }
 
 // Delete removes a field (mutates in place)
 func (m MutableRecord) Delete(field string) MutableRecord {
-	delete(m.fields, field)
+	m.remove(field)
 	return m
 }
 
 // Rename renames a field (mutates in place)
+// The renamed field keeps its position in the field order.
 // If the old field doesn't exist, this is a no-op.
 // If the new field name already exists, it will be overwritten.
 func (m MutableRecord) Rename(oldField, newField string) MutableRecord {
-	if val, exists := m.fields[oldField]; exists {
-		m.fields[newField] = val
-		delete(m.fields, oldField)
+	val, exists := m.fields[oldField]
+	if !exists || oldField == newField {
+		return m
+	}
+	m.remove(newField)
+	delete(m.fields, oldField)
+	m.fields[newField] = val
+	if m.order != nil {
+		if i := slices.Index(*m.order, oldField); i >= 0 {
+			order := slices.Clone(*m.order) // may be shared (see shareOrder)
+			order[i] = newField
+			*m.order = order
+		} else {
+			*m.order = append(*m.order, newField)
+		}
 	}
 	return m
 }
@@ -539,6 +790,11 @@ func (m MutableRecord) JSONString(field string, value JSONString) MutableRecord
 	return Set(m, field, value)
 }
 
+// Decimal adds an exact decimal field (mutates in place)
+func (m MutableRecord) Decimal(field string, value Decimal) MutableRecord {
+	return Set(m, field, value)
+}
+
 // Int adds an integer field (mutates in place)
 func (m MutableRecord) Int(field string, value int64) MutableRecord {
 	return Set(m, field, value)
@@ -564,16 +820,20 @@ func (m MutableRecord) Nested(field string, value Record) MutableRecord {
 	return Set(m, field, value)
 }
 
+// Null adds an explicit null field (mutates in place)
+func (m MutableRecord) Null(field string) MutableRecord {
+	return Set(m, field, Null{})
+}
+
 // ============================================================================
 // RECORD FIELD METHODS - IMMUTABLE UPDATES (CREATES COPIES)
 // ============================================================================
 
 // SetImmutable adds a field with compile-time type safety - creates new Record (immutable)
 func SetImmutable[V Value](r Record, field string, value V) Record {
-	result := make(map[string]any, len(r.fields)+1)
-	maps.Copy(result, r.fields)
-	result[field] = value
-	return Record{fields: result}
+	result := r.ToMutable()
+	result.set(field, value)
+	return Record{fields: result.fields, order: result.order}
 }
 
 // String adds a string field (creates new Record)
@@ -586,6 +846,11 @@ func (r Record) JSONString(field string, value JSONString) Record {
 	return SetImmutable(r, field, value)
 }
 
+// Decimal adds an exact decimal field (creates new Record)
+func (r Record) Decimal(field string, value Decimal) Record {
+	return SetImmutable(r, field, value)
+}
+
 // Int adds an integer field (creates new Record)
 func (r Record) Int(field string, value int64) Record {
 	return SetImmutable(r, field, value)
@@ -611,6 +876,11 @@ func (r Record) Nested(field string, value Record) Record {
 	return SetImmutable(r, field, value)
 }
 
+// Null adds an explicit null field (creates new Record)
+func (r Record) Null(field string) Record {
+	return SetImmutable(r, field, Null{})
+}
+
 // ============================================================================
 // MUTABLERECORD ITER.SEQ FIELD METHODS - IN-PLACE MUTATION
 // ============================================================================
@@ -697,8 +967,8 @@ func convertTo[T any](val any) (T, bool) {
 	var zero T
 	targetType := reflect.TypeOf(zero)
 
-	// Handle nil
-	if val == nil {
+	// Handle nil and Null
+	if IsNull(val) {
 		return zero, false
 	}
 
@@ -737,6 +1007,11 @@ func convertTo[T any](val any) (T, bool) {
 			return any(converted).(T), true
 		}
 		return zero, false
+	case Decimal:
+		if converted, ok := convertToDecimal(val); ok {
+			return any(converted).(T), true
+		}
+		return zero, false
 	default:
 		_ = target
 		return zero, false
@@ -752,6 +1027,8 @@ func convertToInt64(val any) (int64, bool) {
 		return v, true
 	case float64:
 		return int64(v), true // Allow float64 -> int64 truncation
+	case Decimal:
+		return v.Int64() // Truncates like float64
 	case string:
 		if parsed, err := strconv.ParseInt(v, 10, 64); err == nil {
 			return parsed, true
@@ -776,6 +1053,8 @@ func convertToFloat64(val any) (float64, bool) {
 		return v, true
 	case int64:
 		return float64(v), true // Allow int64 -> float64 widening
+	case Decimal:
+		return v.Float64(), true // Nearest float64, may lose precision
 	case string:
 		if parsed, err := strconv.ParseFloat(v, 64); err == nil {
 			return parsed, true
@@ -867,6 +1146,10 @@ func isValueType(value any) bool {
 		return true
 	case JSONString:
 		return true
+	case Decimal:
+		return true
+	case Null:
+		return true
 	// Record type
 	case Record:
 		return true
@@ -888,7 +1171,7 @@ func isValueType(value any) bool {
 
 // Field creates a single-field Record with compile-time type safety
 func Field[V Value](key string, value V) Record {
-	return Record{fields: map[string]any{key: value}}
+	return Record{fields: map[string]any{key: value}, order: &[]string{key}}
 }
 
 // ============================================================================
@@ -1039,10 +1322,10 @@ func Materialize(sourceField, targetField, separator string) Filter[Record, Reco
 					for _, val := range values {
 						stringValues = append(stringValues, fmt.Sprintf("%v", val))
 					}
-					result.fields[targetField] = strings.Join(stringValues, separator)
+					result.set(targetField, strings.Join(stringValues, separator))
 				} else if exists {
 					// Source field exists but isn't a sequence - convert to string
-					result.fields[targetField] = fmt.Sprintf("%v", sourceValue)
+					result.set(targetField, fmt.Sprintf("%v", sourceValue))
 				}
 				// If source field doesn't exist, don't add target field
 
@@ -1068,10 +1351,10 @@ func MaterializeJSON(sourceField, targetField string) Filter[Record, Record] {
 					// Convert any complex field to JSON representation
 					jsonValue := convertToJSONValue(sourceValue)
 					if jsonBytes, err := json.Marshal(jsonValue); err == nil {
-						result.fields[targetField] = JSONString(jsonBytes)
+						result.set(targetField, JSONString(jsonBytes))
 					} else {
 						// Fallback to string representation if JSON fails
-						result.fields[targetField] = fmt.Sprintf("%v", sourceValue)
+						result.set(targetField, fmt.Sprintf("%v", sourceValue))
 					}
 				}
 				// If source field doesn't exist, don't add target field
@@ -1116,7 +1399,7 @@ func Hash(sourceField, targetField string) Filter[Record, Record] {
 					// Compute SHA256 hash
 					hash := sha256.Sum256([]byte(strValue))
 					// Encode as hex string (64 characters, human-readable)
-					result.fields[targetField] = hex.EncodeToString(hash[:])
+					result.set(targetField, hex.EncodeToString(hash[:]))
 				}
 				// If source field doesn't exist, don't add target field
 
@@ -1190,6 +1473,65 @@ func CrossFlatten(separator string, fields ...string) Filter[Record, Record] {
 	}
 }
 
+// DotUnflatten rebuilds nested records from separator-joined keys, undoing DotFlatten's nesting.
+// Prefixed fields become nested Records: {"user.name": "Alice"} → {"user": {"name": "Alice"}}
+// Keys ending in the indexes 0..n-1 become sequence fields:
+// {"tags.0": "a", "tags.1": "b"} → {"tags": iter.Seq["a", "b"]}
+// Sequences of mixed element types become a JSONString array.
+// If fields are specified, only keys starting with those top-level names are unflattened.
+//
+// A key that is also the prefix of another key (e.g. "a" alongside "a.b")
+// cannot be nested, nor can a key with an empty segment ("a.", ".b",
+// "c..d"); DotUnflatten leaves all keys involved in such a conflict
+// unchanged. Use DotUnflattenSafe to receive an error for those records.
+//
+// Example:
+//
+//	flat, _ := ssql.ReadCSV("users.csv") // columns: id, address.city, address.zip
+//	nested := ssql.DotUnflatten(".")(flat)
+//	ssql.WriteJSON(nested, "users.jsonl") // {"id": 1, "address": {"city": ..., "zip": ...}}
+func DotUnflatten(separator string, fields ...string) Filter[Record, Record] {
+	if separator == "" {
+		separator = "."
+	}
+
+	return func(input iter.Seq[Record]) iter.Seq[Record] {
+		return func(yield func(Record) bool) {
+			for record := range input {
+				unflattened, _ := dotUnflattenRecord(record, separator, fields...)
+				if !yield(unflattened) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// DotUnflattenSafe is DotUnflatten with error handling.
+// Records with conflicting keys are yielded with the conflicting keys left
+// flat and an error listing the conflicts.
+func DotUnflattenSafe(separator string, fields ...string) FilterWithErrors[Record, Record] {
+	if separator == "" {
+		separator = "."
+	}
+
+	return func(input iter.Seq2[Record, error]) iter.Seq2[Record, error] {
+		return func(yield func(Record, error) bool) {
+			for record, err := range input {
+				if err != nil {
+					if !yield(record, err) {
+						return
+					}
+					continue
+				}
+				if !yield(dotUnflattenRecord(record, separator, fields...)) {
+					return
+				}
+			}
+		}
+	}
+}
+
 // dotFlattenRecord recursively flattens a record using dot notation
 // If fields are specified, only flattens those top-level fields
 func dotFlattenRecord(record Record, prefix, separator string, fields ...string) Record {
@@ -1215,10 +1557,10 @@ func dotFlattenRecord(record Record, prefix, separator string, fields ...string)
 		// If the value is a nested record, flatten it recursively
 		if nestedRecord, ok := value.(Record); ok && shouldFlatten {
 			flattened := dotFlattenRecord(nestedRecord, newKey, separator)
-			maps.Copy(result.fields, flattened.fields)
+			copyFields(result, flattened)
 		} else {
 			// For non-record values (including sequences), or fields not to be flattened, keep as-is
-			result.fields[newKey] = value
+			result.set(newKey, value)
 		}
 	}
 
@@ -1254,17 +1596,18 @@ func dotFlattenRecordWithSeqs(record Record, prefix, separator string, fields ..
 		// If the value is a nested record, flatten it recursively
 		if nestedRecord, ok := value.(Record); ok && shouldFlatten {
 			flattened := dotFlattenRecord(nestedRecord, newKey, separator)
-			maps.Copy(nonSeqRecord.fields, flattened.fields)
+			copyFields(nonSeqRecord, flattened)
 		} else if shouldFlatten && isIterSeq(value) {
 			// This is an iter.Seq field - collect its values for dot product expansion
 			values := materializeSequence(value)
 			if len(values) > 0 {
 				seqFields = append(seqFields, newKey)
 				seqValues = append(seqValues, values)
+				nonSeqRecord.set(newKey, nil) // placeholder keeps the field's position
 			}
 		} else {
 			// For non-record, non-sequence values, or fields not to be flattened, keep as-is
-			nonSeqRecord.fields[newKey] = value
+			nonSeqRecord.set(newKey, value)
 		}
 	}
 
@@ -1287,11 +1630,11 @@ func dotFlattenRecordWithSeqs(record Record, prefix, separator string, fields ..
 		result := MakeMutableRecord()
 
 		// Copy non-sequence fields
-		maps.Copy(result.fields, nonSeqRecord.fields)
+		copyFields(result, nonSeqRecord.Freeze())
 
 		// Add corresponding element from each sequence
 		for j, fieldName := range seqFields {
-			result.fields[fieldName] = seqValues[j][i]
+			result.set(fieldName, seqValues[j][i])
 		}
 
 		results = append(results, result.Freeze())
@@ -1324,7 +1667,7 @@ func crossFlattenRecord(r Record, _ string, fields ...string) []Record {
 				var rs []Record
 				for _, val := range values {
 					// Create a record with this sequence value
-					newRecord := Record{fields: map[string]any{f: val}}
+					newRecord := Record{fields: map[string]any{f: val}, order: &[]string{f}}
 					rs = append(rs, newRecord)
 				}
 				if len(rs) > 0 {
@@ -1348,14 +1691,25 @@ func crossFlattenRecord(r Record, _ string, fields ...string) []Record {
 	// Create cartesian product of expanded fields
 	crs := cartesianProduct(columns)
 
-	// Add non-sequence fields to each result record
-	for _, cr := range crs {
-		for _, f := range nonSeqFields {
-			cr.fields[f] = r.fields[f]
+	// Add non-sequence fields to each result record, keeping the field order of r
+	keep := make(map[string]bool, len(nonSeqFields))
+	for _, f := range nonSeqFields {
+		keep[f] = true
+	}
+	results := make([]Record, len(crs))
+	for i, cr := range crs {
+		result := MakeMutableRecordWithCapacity(r.Len())
+		for f, value := range r.All() {
+			if expanded, ok := cr.fields[f]; ok {
+				result.set(f, expanded)
+			} else if keep[f] {
+				result.set(f, value)
+			}
 		}
+		results[i] = result.Freeze()
 	}
 
-	return crs
+	return results
 }
 
 // cartesianProduct performs cartesian product of record slices
@@ -1370,14 +1724,180 @@ func cartesianProduct(columns [][]Record) []Record {
 	for _, lr := range cartesianProduct(columns[1:]) {
 		for _, rr := range columns[0] {
 			r := MakeMutableRecord()
-			maps.Copy(r.fields, rr.fields)
-			maps.Copy(r.fields, lr.fields)
+			copyFields(r, rr)
+			copyFields(r, lr)
 			rs = append(rs, r.Freeze())
 		}
 	}
 	return rs
 }
 
+// unflattenNode is a node of the tree built by dotUnflattenRecord
+type unflattenNode struct {
+	value    any
+	children map[string]*unflattenNode
+	order    []string // child keys in the order they were first seen
+}
+
+// dotUnflattenRecord nests the keys of one record. Keys involved in a
+// conflict are copied unchanged and reported in the returned error.
+func dotUnflattenRecord(record Record, separator string, fields ...string) (Record, error) {
+	shouldUnflatten := func(key string) bool {
+		if !strings.Contains(key, separator) {
+			return false
+		}
+		if len(fields) == 0 {
+			return true
+		}
+		top, _, _ := strings.Cut(key, separator)
+		return slices.Contains(fields, top)
+	}
+
+	// A key conflicts with every other key it is a prefix of
+	conflicting := make(map[string]bool)
+	var conflicts []string
+	for key := range record.fields {
+		if !shouldUnflatten(key) {
+			continue
+		}
+		if slices.Contains(strings.Split(key, separator), "") {
+			conflicting[key] = true
+			conflicts = append(conflicts, fmt.Sprintf("'%s' has an empty segment", key))
+			continue
+		}
+		for i := range len(key) {
+			if !strings.HasPrefix(key[i:], separator) {
+				continue
+			}
+			if prefix := key[:i]; record.Has(prefix) {
+				conflicting[prefix], conflicting[key] = true, true
+				conflicts = append(conflicts, fmt.Sprintf("'%s' conflicts with '%s'", prefix, key))
+			}
+		}
+	}
+
+	result := MakeMutableRecordWithCapacity(record.Len())
+	root := &unflattenNode{}
+	for key, value := range record.All() {
+		if conflicting[key] || !shouldUnflatten(key) {
+			result.set(key, value)
+			continue
+		}
+		if top, _, _ := strings.Cut(key, separator); root.children[top] == nil {
+			result.set(top, nil) // placeholder keeps the field's position
+		}
+		node := root
+		for part := range strings.SplitSeq(key, separator) {
+			node = node.child(part)
+		}
+		node.value = value
+	}
+	for _, key := range root.order {
+		result.set(key, root.children[key].build())
+	}
+
+	if len(conflicts) > 0 {
+		slices.Sort(conflicts)
+		return result.Freeze(), fmt.Errorf("cannot unflatten: %s", strings.Join(conflicts, ", "))
+	}
+	return result.Freeze(), nil
+}
+
+// build converts a node to a record value: leaves keep their value,
+// children indexed 0..n-1 become a sequence, other children a Record
+func (n *unflattenNode) build() any {
+	if len(n.children) == 0 {
+		return n.value
+	}
+
+	if elements, ok := n.sequenceElements(); ok {
+		return sequenceOf(elements)
+	}
+
+	nested := MakeMutableRecordWithCapacity(len(n.children))
+	for _, key := range n.order {
+		nested.set(key, n.children[key].build())
+	}
+	return nested.Freeze()
+}
+
+// child returns the child node for key, creating it if needed
+func (n *unflattenNode) child(key string) *unflattenNode {
+	if c, exists := n.children[key]; exists {
+		return c
+	}
+	if n.children == nil {
+		n.children = make(map[string]*unflattenNode)
+	}
+	c := &unflattenNode{}
+	n.children[key] = c
+	n.order = append(n.order, key)
+	return c
+}
+
+// sequenceElements returns the built children in index order if the child
+// keys are exactly "0", "1", ... "n-1"
+func (n *unflattenNode) sequenceElements() ([]any, bool) {
+	elements := make([]any, len(n.children))
+	for key, child := range n.children {
+		index, err := strconv.Atoi(key)
+		if err != nil || index < 0 || index >= len(elements) || strconv.Itoa(index) != key {
+			return nil, false
+		}
+		elements[index] = child.build()
+	}
+	return elements, true
+}
+
+// sequenceOf returns the iter.Seq variant matching the element type,
+// or a JSONString array when the elements have mixed types
+func sequenceOf(elements []any) any {
+	switch elements[0].(type) {
+	case int64:
+		if values, ok := typedElements[int64](elements); ok {
+			return seqOfSlice(values)
+		}
+	case float64:
+		if values, ok := typedElements[float64](elements); ok {
+			return seqOfSlice(values)
+		}
+	case string:
+		if values, ok := typedElements[string](elements); ok {
+			return seqOfSlice(values)
+		}
+	case bool:
+		if values, ok := typedElements[bool](elements); ok {
+			return seqOfSlice(values)
+		}
+	case time.Time:
+		if values, ok := typedElements[time.Time](elements); ok {
+			return seqOfSlice(values)
+		}
+	case Record:
+		if values, ok := typedElements[Record](elements); ok {
+			return seqOfSlice(values)
+		}
+	}
+	js, err := NewJSONString(elements)
+	if err != nil {
+		return fmt.Sprintf("%v", elements)
+	}
+	return js
+}
+
+// typedElements converts elements to []E if every element has type E
+func typedElements[E any](elements []any) ([]E, bool) {
+	values := make([]E, len(elements))
+	for i, e := range elements {
+		v, ok := e.(E)
+		if !ok {
+			return nil, false
+		}
+		values[i] = v
+	}
+	return values, true
+}
+
 // isIterSeq checks if a value is an iter.Seq type using reflection
 func isIterSeq(value any) bool {
 	if value == nil {
@@ -1413,7 +1933,7 @@ func isSimpleValue(value any) bool {
 	// Other basic types
 	case bool, string, time.Time:
 		return true
-	case JSONString:
+	case JSONString, Decimal, Null:
 		return true
 	// Complex types not allowed for grouping
 	case Record:
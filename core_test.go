@@ -1,8 +1,10 @@
 package ssql
 
 import (
+	"encoding/json"
 	"iter"
 	"slices"
+	"strings"
 	"testing"
 	"time"
 )
@@ -1014,6 +1016,168 @@ func TestDotFlattenWithSequences(t *testing.T) {
 	}
 }
 
+func TestDotUnflatten(t *testing.T) {
+	r := MakeMutableRecord()
+	r.fields["id"] = int64(1)
+	r.fields["user.name"] = "Alice"
+	r.fields["user.address.city"] = "NYC"
+	r.fields["tags.0"] = "a"
+	r.fields["tags.1"] = "b"
+	r.fields["mixed.0"] = int64(1)
+	r.fields["mixed.1"] = "two"
+	r.fields["gap.0"] = "x"
+	r.fields["gap.2"] = "z"
+	input := slices.Values([]Record{r.Freeze()})
+
+	result := slices.Collect(DotUnflatten(".")(input))
+	if len(result) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(result))
+	}
+	got := result[0]
+
+	if got.fields["id"] != int64(1) {
+		t.Errorf("Expected id=1, got %v", got.fields["id"])
+	}
+	if name := GetOr(got, "user.name", ""); name != "Alice" {
+		t.Errorf("Expected user.name=Alice, got %q", name)
+	}
+	if city := GetOr(got, "user.address.city", ""); city != "NYC" {
+		t.Errorf("Expected user.address.city=NYC, got %q", city)
+	}
+
+	tags, ok := got.fields["tags"].(iter.Seq[string])
+	if !ok {
+		t.Fatalf("Expected tags to be iter.Seq[string], got %T", got.fields["tags"])
+	}
+	if collected := slices.Collect(tags); !slices.Equal(collected, []string{"a", "b"}) {
+		t.Errorf("Expected tags [a b], got %v", collected)
+	}
+
+	if mixed, ok := got.fields["mixed"].(JSONString); !ok || mixed != `[1,"two"]` {
+		t.Errorf("Expected mixed to be JSONString [1,\"two\"], got %T %v", got.fields["mixed"], got.fields["mixed"])
+	}
+
+	// Indexes with a gap are not a sequence
+	gap, ok := got.fields["gap"].(Record)
+	if !ok {
+		t.Fatalf("Expected gap to be a Record, got %T", got.fields["gap"])
+	}
+	if gap.fields["0"] != "x" || gap.fields["2"] != "z" {
+		t.Errorf("Expected gap {0: x, 2: z}, got %v", gap.fields)
+	}
+}
+
+func TestDotUnflattenRoundTrip(t *testing.T) {
+	address := MakeMutableRecord().String("city", "NYC").String("zip", "10001").Freeze()
+	original := MakeMutableRecord().Int("id", 1).Nested("address", address).Freeze()
+
+	flat := slices.Collect(DotFlatten("_")(slices.Values([]Record{original})))
+	result := slices.Collect(DotUnflatten("_")(slices.Values(flat)))
+	if len(result) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(result))
+	}
+
+	nested, ok := result[0].fields["address"].(Record)
+	if !ok {
+		t.Fatalf("Expected address to be a Record, got %T", result[0].fields["address"])
+	}
+	if !nested.Equal(address) {
+		t.Errorf("Expected address %v, got %v", address.fields, nested.fields)
+	}
+}
+
+func TestDotUnflattenFields(t *testing.T) {
+	r := MakeMutableRecord().String("a.x", "1").String("b.x", "2").Freeze()
+
+	result := slices.Collect(DotUnflatten(".", "a")(slices.Values([]Record{r})))
+	if _, ok := result[0].fields["a"].(Record); !ok {
+		t.Errorf("Expected a to be unflattened, got %T", result[0].fields["a"])
+	}
+	if result[0].fields["b.x"] != "2" {
+		t.Errorf("Expected b.x to stay flat, got %v", result[0].fields)
+	}
+}
+
+func TestDotUnflattenConflicts(t *testing.T) {
+	r := MakeMutableRecord().
+		Int("a", 1).
+		Int("a.b", 2).
+		Int("c.d", 3).
+		Freeze()
+	input := Safe(slices.Values([]Record{r}))
+
+	var results []Record
+	var errs []error
+	for record, err := range DotUnflattenSafe(".")(input) {
+		results = append(results, record)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(results) != 1 || len(errs) != 1 {
+		t.Fatalf("Expected 1 record and 1 error, got %d records and %d errors", len(results), len(errs))
+	}
+	if !strings.Contains(errs[0].Error(), "'a' conflicts with 'a.b'") {
+		t.Errorf("Expected conflict between a and a.b, got %v", errs[0])
+	}
+
+	got := results[0]
+	if got.fields["a"] != int64(1) || got.fields["a.b"] != int64(2) {
+		t.Errorf("Expected conflicting keys to stay flat, got %v", got.fields)
+	}
+	if _, ok := got.fields["c"].(Record); !ok {
+		t.Errorf("Expected c to be unflattened, got %v", got.fields)
+	}
+
+	// The unsafe variant drops the error but keeps the record
+	unsafe := slices.Collect(DotUnflatten(".")(slices.Values([]Record{r})))
+	if len(unsafe) != 1 || unsafe[0].fields["a.b"] != int64(2) {
+		t.Errorf("Expected DotUnflatten to pass conflicting keys through, got %v", unsafe)
+	}
+}
+
+func TestDotUnflattenEmptySegments(t *testing.T) {
+	r := MakeMutableRecord().
+		Int("a.", 1).
+		Int(".b", 2).
+		Int("c..d", 3).
+		Int("c.e", 4).
+		Freeze()
+
+	var errs []error
+	var got Record
+	for record, err := range DotUnflattenSafe(".")(Safe(slices.Values([]Record{r}))) {
+		got = record
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) != 1 {
+		t.Fatalf("Expected 1 error, got %v", errs)
+	}
+	for _, key := range []string{"a.", ".b", "c..d"} {
+		if !strings.Contains(errs[0].Error(), "'"+key+"' has an empty segment") {
+			t.Errorf("Expected %q to be reported, got %v", key, errs[0])
+		}
+		if _, ok := got.fields[key]; !ok {
+			t.Errorf("Expected %q to stay flat, got %v", key, got.fields)
+		}
+	}
+	if _, ok := got.fields[""]; ok {
+		t.Errorf("Expected no empty field name, got %v", got.fields)
+	}
+	c, ok := got.fields["c"].(Record)
+	if !ok || c.Len() != 1 || c.fields["e"] != int64(4) {
+		t.Errorf("Expected c.e alone to be unflattened, got %v", got.fields["c"])
+	}
+
+	unsafe := slices.Collect(DotUnflatten(".")(slices.Values([]Record{r})))
+	if _, ok := unsafe[0].fields[""]; ok || unsafe[0].fields[".b"] != int64(2) {
+		t.Errorf("Expected DotUnflatten to leave empty-segment keys flat, got %v", unsafe[0].fields)
+	}
+}
+
 func TestCrossFlatten(t *testing.T) {
 	tagSeq := func(yield func(string) bool) {
 		for _, tag := range []string{"a", "b"} {
@@ -1091,3 +1255,165 @@ type testError struct {
 func (e *testError) Error() string {
 	return e.msg
 }
+
+// ============================================================================
+// NULL TESTS
+// ============================================================================
+
+func TestNullField(t *testing.T) {
+	r := MakeMutableRecord().String("name", "Alice").Null("manager").Freeze()
+
+	if !r.Has("manager") || !r.IsNull("manager") {
+		t.Error("Expected manager to be present and null")
+	}
+	if r.IsNull("name") || r.IsNull("missing") {
+		t.Error("Only present null fields should report IsNull")
+	}
+	if _, ok := Get[string](r, "manager"); ok {
+		t.Error("Null should not convert to string")
+	}
+	if v, ok := Get[Null](r, "manager"); !ok || v != (Null{}) {
+		t.Error("Expected Get[Null] to return the null value")
+	}
+	if got := GetOr(r, "manager", "none"); got != "none" {
+		t.Errorf("Expected GetOr default for null, got %q", got)
+	}
+	if err := ValidateRecord(r); err != nil {
+		t.Errorf("Null should be a valid field value: %v", err)
+	}
+}
+
+func TestNullJSONRoundTrip(t *testing.T) {
+	var r Record
+	if err := r.UnmarshalJSON([]byte(`{"a": null, "b": 1}`)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !r.IsNull("a") {
+		t.Errorf("Expected JSON null to decode as Null, got %T", r.fields["a"])
+	}
+	data, err := r.MarshalJSON()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(string(data), `"a":null`) {
+		t.Errorf("Expected null in JSON output, got %s", data)
+	}
+}
+
+func TestRecordFieldOrder(t *testing.T) {
+	r := MakeMutableRecord().
+		String("zeta", "z").
+		Int("alpha", 1).
+		Float("mid", 2.5).
+		Freeze()
+
+	if keys := r.Keys(); !slices.Equal(keys, []string{"zeta", "alpha", "mid"}) {
+		t.Errorf("Keys() = %v, want insertion order", keys)
+	}
+
+	var allKeys []string
+	for k := range r.All() {
+		allKeys = append(allKeys, k)
+	}
+	if !slices.Equal(allKeys, []string{"zeta", "alpha", "mid"}) {
+		t.Errorf("All() visited %v, want insertion order", allKeys)
+	}
+	if values := slices.Collect(r.Values()); values[0] != "z" || values[2] != 2.5 {
+		t.Errorf("Values() = %v, want insertion order", values)
+	}
+
+	// Overwriting keeps the position; Record methods append new fields
+	updated := r.Int("zeta", 9).String("omega", "o")
+	if keys := updated.Keys(); !slices.Equal(keys, []string{"zeta", "alpha", "mid", "omega"}) {
+		t.Errorf("Keys() after update = %v", keys)
+	}
+
+	// Rename keeps the field's position; delete and re-add moves it to the end
+	mut := r.ToMutable().Rename("alpha", "first").Delete("zeta").String("zeta", "again")
+	if keys := mut.Freeze().Keys(); !slices.Equal(keys, []string{"first", "mid", "zeta"}) {
+		t.Errorf("Keys() after rename/delete = %v", keys)
+	}
+
+	// The original is unaffected
+	if keys := r.Clone().Keys(); !slices.Equal(keys, []string{"zeta", "alpha", "mid"}) {
+		t.Errorf("Clone().Keys() = %v", keys)
+	}
+}
+
+func TestRecordFieldOrderSharing(t *testing.T) {
+	// Freeze, ToMutable and Clone share the order slice; later edits to
+	// any copy must not show through in the others
+	m := MakeMutableRecordWithCapacity(8).String("a", "1").String("b", "2").String("c", "3")
+	frozen := m.Freeze()
+	m.String("d", "4")
+	before := frozen.Clone()
+	m.Rename("a", "z").Delete("b")
+
+	if keys := frozen.Keys(); !slices.Equal(keys, []string{"a", "b", "c"}) {
+		t.Errorf("Frozen keys changed to %v", keys)
+	}
+	if keys := before.Keys(); !slices.Equal(keys, []string{"a", "b", "c"}) {
+		t.Errorf("Clone keys changed to %v", keys)
+	}
+	if keys := m.Freeze().Keys(); !slices.Equal(keys, []string{"z", "c", "d"}) {
+		t.Errorf("Mutable keys = %v", keys)
+	}
+
+	// Two mutable copies of one record append independently
+	x, y := frozen.ToMutable(), frozen.ToMutable()
+	x.String("x", "x")
+	y.String("y", "y")
+	if keys := x.Freeze().Keys(); !slices.Equal(keys, []string{"a", "b", "c", "x"}) {
+		t.Errorf("x keys = %v", keys)
+	}
+	if keys := y.Freeze().Keys(); !slices.Equal(keys, []string{"a", "b", "c", "y"}) {
+		t.Errorf("y keys = %v", keys)
+	}
+
+	// Keys returns a copy
+	keys := frozen.Keys()
+	keys[0] = "changed"
+	if frozen.Keys()[0] != "a" {
+		t.Error("Modifying Keys() changed the record")
+	}
+}
+
+func TestRecordFieldOrderWithoutOrder(t *testing.T) {
+	// Records built from a map have no order; keys come back sorted
+	r := NewRecord(map[string]any{"b": int64(2), "a": int64(1), "c": int64(3)})
+	if keys := r.Keys(); !slices.Equal(keys, []string{"a", "b", "c"}) {
+		t.Errorf("Keys() = %v, want sorted", keys)
+	}
+}
+
+func TestRecordJSONFieldOrder(t *testing.T) {
+	r := MakeMutableRecord().
+		String("name", "Alice").
+		Int("age", 30).
+		Nested("address", MakeMutableRecord().String("zip", "10001").String("city", "NYC").Freeze()).
+		Freeze()
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	want := `{"name":"Alice","age":30,"address":{"zip":"10001","city":"NYC"}}`
+	if string(data) != want {
+		t.Errorf("Marshal = %s, want %s", data, want)
+	}
+
+	var decoded Record
+	if err := json.Unmarshal([]byte(`{"z":1,"a":null,"m":"x"}`), &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if keys := decoded.Keys(); !slices.Equal(keys, []string{"z", "a", "m"}) {
+		t.Errorf("Unmarshal keys = %v, want document order", keys)
+	}
+	if !decoded.IsNull("a") {
+		t.Errorf("Expected a to be null")
+	}
+
+	if err := json.Unmarshal([]byte(`[1, 2]`), &decoded); err == nil {
+		t.Error("Expected error unmarshaling a JSON array into a Record")
+	}
+}
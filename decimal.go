@@ -0,0 +1,493 @@
+package ssql
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// ============================================================================
+// DECIMAL - EXACT BASE-10 NUMBERS
+// ============================================================================
+
+// Decimal is an exact base-10 number for monetary and other values where
+// float64 rounding drift is not acceptable. The value is coef × 10^exp.
+//
+// Decimals are normalized (trailing zeros removed from the coefficient), so
+// two Decimals holding the same number are == and can be used as join,
+// group and distinct keys. Use StringFixed to format with a fixed number of
+// decimal places, e.g. for currency output.
+//
+// The coefficient holds about 18 significant digits. Results that need more
+// are rounded half away from zero, which only happens for extremely large or
+// extremely precise values.
+//
+// Example:
+//
+//	price, _ := ssql.ParseDecimal("19.99")
+//	total := price.Mul(ssql.DecimalFromInt(3)) // 59.97 exactly
+//
+//	record := ssql.MakeMutableRecord().
+//	    Decimal("amount", total).
+//	    Freeze()
+type Decimal struct {
+	coef int64
+	exp  int32
+}
+
+// NewDecimal returns coef × 10^exp, e.g. NewDecimal(1999, -2) is 19.99
+func NewDecimal(coef int64, exp int32) Decimal {
+	return Decimal{coef: coef, exp: exp}.normalize()
+}
+
+// DecimalFromInt returns the Decimal holding an integer value
+func DecimalFromInt(i int64) Decimal {
+	return NewDecimal(i, 0)
+}
+
+// DecimalFromFloat returns the Decimal with the shortest representation
+// that round-trips to f, so DecimalFromFloat(19.99) is exactly 19.99.
+// NaN and infinities cannot be represented and return an error.
+func DecimalFromFloat(f float64) (Decimal, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return Decimal{}, fmt.Errorf("cannot convert %v to Decimal", f)
+	}
+	return ParseDecimal(strconv.FormatFloat(f, 'g', -1, 64))
+}
+
+// MaxDecimalExponent bounds the exponent ParseDecimal accepts: numbers
+// beyond ±1e1000 (far outside the float64 range) are rejected rather than
+// expanded digit by digit
+const MaxDecimalExponent = 1000
+
+// ParseDecimal parses a decimal string such as "19.99", "-0.5", "1e3" or
+// "+2.50E-1". Exponents beyond ±MaxDecimalExponent are an error.
+func ParseDecimal(s string) (Decimal, error) {
+	str := strings.TrimSpace(s)
+	mantissa, exponent := str, int64(0)
+	if i := strings.IndexAny(str, "eE"); i >= 0 {
+		e, err := strconv.ParseInt(str[i+1:], 10, 32)
+		if err != nil {
+			return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+		}
+		mantissa, exponent = str[:i], e
+	}
+
+	negative := false
+	if mantissa != "" && (mantissa[0] == '+' || mantissa[0] == '-') {
+		negative = mantissa[0] == '-'
+		mantissa = mantissa[1:]
+	}
+	intPart, fracPart, _ := strings.Cut(mantissa, ".")
+	digits := intPart + fracPart
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+	}
+
+	exponent -= int64(len(fracPart))
+	if exponent < math.MinInt32 || exponent > math.MaxInt32 {
+		return Decimal{}, fmt.Errorf("decimal exponent out of range in %q", s)
+	}
+	var d Decimal
+	if coef, err := strconv.ParseInt(digits, 10, 64); err == nil {
+		if negative {
+			coef = -coef
+		}
+		d = NewDecimal(coef, int32(exponent))
+	} else {
+		// Too many digits for the fast path
+		coef, _ := new(big.Int).SetString(digits, 10)
+		if negative {
+			coef.Neg(coef)
+		}
+		d = decimalFromBig(coef, int32(exponent))
+	}
+	if d.exp > MaxDecimalExponent || d.exp < -MaxDecimalExponent {
+		return Decimal{}, fmt.Errorf("decimal exponent out of range in %q", s)
+	}
+	return d, nil
+}
+
+// MustParseDecimal is like ParseDecimal but panics on invalid input.
+// Use it for constants in code.
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// String formats the Decimal in plain notation without trailing zeros.
+// Values whose plain form would need more than 64 padding zeros (possible
+// after arithmetic on extreme values) use scientific notation instead,
+// e.g. "1.5e200", which ParseDecimal reads back.
+func (d Decimal) String() string {
+	if d.coef != 0 && (d.exp > maxPlainZeros || -d.exp > maxPlainZeros+int32(len(strconv.FormatUint(absUint64(d.coef), 10)))) {
+		return d.scientific()
+	}
+	if d.exp >= 0 {
+		if d.coef == 0 {
+			return "0"
+		}
+		return strconv.FormatInt(d.coef, 10) + strings.Repeat("0", int(d.exp))
+	}
+
+	digits := strconv.FormatInt(d.coef, 10)
+	sign := ""
+	if d.coef < 0 {
+		sign, digits = "-", digits[1:]
+	}
+	places := int(-d.exp)
+	if len(digits) <= places {
+		digits = strings.Repeat("0", places-len(digits)+1) + digits
+	}
+	point := len(digits) - places
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+// maxPlainZeros is the most zeros String pads a value with
+const maxPlainZeros = 64
+
+// scientific formats the Decimal as digits with one before the point and
+// a base-10 exponent
+func (d Decimal) scientific() string {
+	digits := strconv.FormatInt(d.coef, 10)
+	sign := ""
+	if d.coef < 0 {
+		sign, digits = "-", digits[1:]
+	}
+	exp := int64(d.exp) + int64(len(digits)) - 1
+	mantissa := digits[:1]
+	if len(digits) > 1 {
+		mantissa += "." + digits[1:]
+	}
+	return sign + mantissa + "e" + strconv.FormatInt(exp, 10)
+}
+
+// StringFixed formats the Decimal rounded to exactly places decimal places,
+// e.g. StringFixed(2) of 20 is "20.00". Values String writes in scientific
+// notation are returned as String writes them.
+func (d Decimal) StringFixed(places int32) string {
+	s := d.Round(places).String()
+	if places <= 0 || strings.Contains(s, "e") {
+		return s // scientific notation has no fixed places
+	}
+	whole, frac, _ := strings.Cut(s, ".")
+	return whole + "." + frac + strings.Repeat("0", int(places)-len(frac))
+}
+
+// Scale returns the number of digits after the decimal point
+func (d Decimal) Scale() int32 {
+	if d.exp >= 0 {
+		return 0
+	}
+	return -d.exp
+}
+
+// Sign returns -1, 0 or +1 depending on the sign of d
+func (d Decimal) Sign() int {
+	switch {
+	case d.coef < 0:
+		return -1
+	case d.coef > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// IsZero reports whether d is zero
+func (d Decimal) IsZero() bool {
+	return d.coef == 0
+}
+
+// Neg returns -d
+func (d Decimal) Neg() Decimal {
+	if d.coef == math.MinInt64 {
+		return decimalFromBig(new(big.Int).Neg(big.NewInt(d.coef)), d.exp)
+	}
+	return Decimal{coef: -d.coef, exp: d.exp}
+}
+
+// Abs returns |d|
+func (d Decimal) Abs() Decimal {
+	if d.coef < 0 {
+		return d.Neg()
+	}
+	return d
+}
+
+// Add returns d + o
+func (d Decimal) Add(o Decimal) Decimal {
+	if a, b, exp, ok := alignDecimals(d, o); ok {
+		if sum := a + b; (sum > a) == (b > 0) {
+			return NewDecimal(sum, exp)
+		}
+	}
+	a, b, exp := alignDecimalsBig(d, o)
+	return decimalFromBig(a.Add(a, b), exp)
+}
+
+// Sub returns d - o
+func (d Decimal) Sub(o Decimal) Decimal {
+	return d.Add(o.Neg())
+}
+
+// Mul returns d × o
+func (d Decimal) Mul(o Decimal) Decimal {
+	exp := int64(d.exp) + int64(o.exp)
+	if exp >= math.MinInt32 && exp <= math.MaxInt32 {
+		hi, lo := bits.Mul64(absUint64(d.coef), absUint64(o.coef))
+		if hi == 0 && lo <= math.MaxInt64 {
+			coef := int64(lo)
+			if (d.coef < 0) != (o.coef < 0) {
+				coef = -coef
+			}
+			return NewDecimal(coef, int32(exp))
+		}
+	}
+	product := new(big.Int).Mul(big.NewInt(d.coef), big.NewInt(o.coef))
+	return decimalFromBig(product, clampExp(exp))
+}
+
+// Div returns d / o rounded half away from zero to places decimal places.
+// Div panics if o is zero, like integer division.
+func (d Decimal) Div(o Decimal, places int32) Decimal {
+	if o.coef == 0 {
+		panic("ssql: Decimal division by zero")
+	}
+	// d/o = (a/b) × 10^(ea-eb); the result coefficient is a × 10^(ea-eb+places) / b
+	num, den := big.NewInt(d.coef), big.NewInt(o.coef)
+	shift := int64(d.exp) - int64(o.exp) + int64(places)
+	if shift >= 0 {
+		num.Mul(num, pow10Big(shift))
+	} else {
+		den.Mul(den, pow10Big(-shift))
+	}
+	return decimalFromBig(divRoundBig(num, den), -places)
+}
+
+// Round returns d rounded half away from zero to places decimal places.
+// Negative places round to tens, hundreds, etc.
+func (d Decimal) Round(places int32) Decimal {
+	if d.exp >= -places {
+		return d
+	}
+	shift := int64(-places) - int64(d.exp)
+	if shift > 19 {
+		// Every int64 coefficient rounds to zero
+		return Decimal{}
+	}
+	return decimalFromBig(divRoundBig(big.NewInt(d.coef), pow10Big(shift)), -places)
+}
+
+// Cmp compares d and o, returning -1 if d < o, 0 if d == o and +1 if d > o
+func (d Decimal) Cmp(o Decimal) int {
+	if a, b, _, ok := alignDecimals(d, o); ok {
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		default:
+			return 0
+		}
+	}
+	a, b, _ := alignDecimalsBig(d, o)
+	return a.Cmp(b)
+}
+
+// Equal reports whether d and o hold the same number
+func (d Decimal) Equal(o Decimal) bool {
+	return d == o
+}
+
+// Float64 returns the nearest float64 to d
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// Int64 returns the integer part of d, truncating towards zero.
+// The second result is false if the integer part does not fit in an int64.
+func (d Decimal) Int64() (int64, bool) {
+	if d.exp >= 0 {
+		return scaleUp(d.coef, int64(d.exp))
+	}
+	if d.exp < -18 {
+		return 0, true
+	}
+	return d.coef / int64(math.Pow10(int(-d.exp))), true
+}
+
+// MarshalJSON encodes the Decimal as a JSON number with all of its digits
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON decodes a JSON number or numeric string without going through float64
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+	parsed, err := ParseDecimal(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// normalize strips trailing zeros from the coefficient so that equal numbers compare ==
+func (d Decimal) normalize() Decimal {
+	if d.coef == 0 {
+		return Decimal{}
+	}
+	for d.coef%10 == 0 && d.exp < math.MaxInt32 {
+		d.coef /= 10
+		d.exp++
+	}
+	return d
+}
+
+// decimalFromBig converts an arbitrary precision coefficient to a Decimal,
+// rounding away the least significant digits if it does not fit in an int64
+func decimalFromBig(coef *big.Int, exp int32) Decimal {
+	if coef.IsInt64() {
+		return NewDecimal(coef.Int64(), exp)
+	}
+	digits := int64(len(new(big.Int).Abs(coef).String()))
+	drop := digits - 18
+	rounded := divRoundBig(coef, pow10Big(drop))
+	return NewDecimal(rounded.Int64(), clampExp(int64(exp)+drop))
+}
+
+// alignDecimals rescales both coefficients to the smaller exponent,
+// returning ok=false if that would overflow an int64
+func alignDecimals(d, o Decimal) (int64, int64, int32, bool) {
+	switch {
+	case d.exp == o.exp:
+		return d.coef, o.coef, d.exp, true
+	case d.exp > o.exp:
+		a, ok := scaleUp(d.coef, int64(d.exp)-int64(o.exp))
+		return a, o.coef, o.exp, ok
+	default:
+		b, ok := scaleUp(o.coef, int64(o.exp)-int64(d.exp))
+		return d.coef, b, d.exp, ok
+	}
+}
+
+// alignDecimalsBig is the arbitrary precision fallback for alignDecimals
+func alignDecimalsBig(d, o Decimal) (*big.Int, *big.Int, int32) {
+	a, b := big.NewInt(d.coef), big.NewInt(o.coef)
+	if d.exp > o.exp {
+		a.Mul(a, pow10Big(int64(d.exp)-int64(o.exp)))
+		return a, b, o.exp
+	}
+	b.Mul(b, pow10Big(int64(o.exp)-int64(d.exp)))
+	return a, b, d.exp
+}
+
+// scaleUp multiplies coef by 10^n, reporting false on overflow
+func scaleUp(coef int64, n int64) (int64, bool) {
+	for ; n > 0; n-- {
+		if coef > math.MaxInt64/10 || coef < math.MinInt64/10 {
+			return 0, false
+		}
+		coef *= 10
+	}
+	return coef, true
+}
+
+// divRoundBig returns num/den rounded half away from zero
+func divRoundBig(num, den *big.Int) *big.Int {
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	if r.Sign() == 0 {
+		return q
+	}
+	if new(big.Int).Abs(new(big.Int).Lsh(r, 1)).Cmp(new(big.Int).Abs(den)) >= 0 {
+		if (num.Sign() < 0) != (den.Sign() < 0) {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return q
+}
+
+func pow10Big(n int64) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(n), nil)
+}
+
+func absUint64(i int64) uint64 {
+	if i < 0 {
+		return uint64(-(i + 1)) + 1
+	}
+	return uint64(i)
+}
+
+func clampExp(exp int64) int32 {
+	return int32(max(math.MinInt32, min(math.MaxInt32, exp)))
+}
+
+// ============================================================================
+// DECIMAL CONVERSIONS
+// ============================================================================
+
+// convertToDecimal converts canonical numeric values and numeric strings to Decimal
+func convertToDecimal(val any) (Decimal, bool) {
+	switch v := val.(type) {
+	case Decimal:
+		return v, true
+	case int64:
+		return DecimalFromInt(v), true
+	case float64:
+		d, err := DecimalFromFloat(v)
+		return d, err == nil
+	case string:
+		d, err := ParseDecimal(v)
+		return d, err == nil
+	case json.Number:
+		d, err := ParseDecimal(string(v))
+		return d, err == nil
+	default:
+		return Decimal{}, false
+	}
+}
+
+// decimalValues collects the values of field as Decimals when at least one
+// record holds a Decimal. It reports false when no Decimal is present so
+// callers can keep their float64 behavior. Values that cannot be converted
+// (including nulls) are skipped.
+func decimalValues(records []Record, field string) ([]Decimal, bool) {
+	hasDecimal := false
+	for _, record := range records {
+		if val, _ := record.lookup(field); isDecimal(val) {
+			hasDecimal = true
+			break
+		}
+	}
+	if !hasDecimal {
+		return nil, false
+	}
+
+	values := make([]Decimal, 0, len(records))
+	for _, record := range records {
+		val, _ := record.lookup(field)
+		if d, ok := convertToDecimal(val); ok {
+			values = append(values, d)
+		}
+	}
+	return values, true
+}
+
+func isDecimal(val any) bool {
+	_, ok := val.(Decimal)
+	return ok
+}
@@ -0,0 +1,203 @@
+package ssql
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"19.99", "19.99"},
+		{"-0.50", "-0.5"},
+		{"+2.50E-1", "0.25"},
+		{"1e3", "1000"},
+		{".5", "0.5"},
+		{"000", "0"},
+		{"123456789012345678901234", "123456789012345679000000"},
+		{"1e64", "1" + strings.Repeat("0", 64)},
+		{"1.5e200", "1.5e200"},
+		{"-25e-100", "-2.5e-99"},
+		{"1e-1000", "1e-1000"},
+	}
+	for _, tt := range tests {
+		d, err := ParseDecimal(tt.in)
+		if err != nil {
+			t.Errorf("ParseDecimal(%q): unexpected error %v", tt.in, err)
+			continue
+		}
+		if d.String() != tt.want {
+			t.Errorf("ParseDecimal(%q) = %s, want %s", tt.in, d, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"", ".", "1.2.3", "abc", "1e", "NaN", "0x10", "1e2000000000", "1e1001", "1e-1001"} {
+		if _, err := ParseDecimal(bad); err == nil {
+			t.Errorf("ParseDecimal(%q): expected error", bad)
+		}
+	}
+}
+
+func TestDecimalArithmetic(t *testing.T) {
+	a := MustParseDecimal("0.1")
+	b := MustParseDecimal("0.2")
+	if got := a.Add(b); got != MustParseDecimal("0.3") {
+		t.Errorf("0.1 + 0.2 = %s, want 0.3", got)
+	}
+	if got := MustParseDecimal("19.99").Mul(DecimalFromInt(3)); got.String() != "59.97" {
+		t.Errorf("19.99 * 3 = %s", got)
+	}
+	if got := MustParseDecimal("1").Sub(MustParseDecimal("0.01")); got.String() != "0.99" {
+		t.Errorf("1 - 0.01 = %s", got)
+	}
+	if got := DecimalFromInt(10).Div(DecimalFromInt(3), 4); got.String() != "3.3333" {
+		t.Errorf("10 / 3 = %s", got)
+	}
+	if got := DecimalFromInt(-2).Div(DecimalFromInt(3), 2); got.String() != "-0.67" {
+		t.Errorf("-2 / 3 = %s", got)
+	}
+	if got := MustParseDecimal("2.345").Round(2); got.String() != "2.35" {
+		t.Errorf("Round(2.345, 2) = %s", got)
+	}
+	if got := DecimalFromInt(20).StringFixed(2); got != "20.00" {
+		t.Errorf("StringFixed(2) = %s", got)
+	}
+	if MustParseDecimal("1.50") != MustParseDecimal("1.5") {
+		t.Error("Equal decimals should be ==")
+	}
+	if MustParseDecimal("-1.5").Cmp(MustParseDecimal("-1.25")) != -1 {
+		t.Error("Expected -1.5 < -1.25")
+	}
+	if i, ok := MustParseDecimal("-7.9").Int64(); !ok || i != -7 {
+		t.Errorf("Int64(-7.9) = %d, %v", i, ok)
+	}
+
+	// Overflowing int64 falls back to rounding instead of wrapping
+	big := DecimalFromInt(9_000_000_000_000_000_000)
+	if got := big.Add(big); got.Sign() != 1 || got.String() != "18000000000000000000" {
+		t.Errorf("Large add = %s", got)
+	}
+}
+
+func TestDecimalGet(t *testing.T) {
+	r := MakeMutableRecord().
+		Decimal("price", MustParseDecimal("19.99")).
+		String("text", "0.10").
+		Float("f", 0.1).
+		Int("n", 5).
+		Freeze()
+
+	if f, ok := Get[float64](r, "price"); !ok || f != 19.99 {
+		t.Errorf("Get[float64] = %v, %v", f, ok)
+	}
+	if s := GetOr(r, "price", ""); s != "19.99" {
+		t.Errorf("GetOr string = %q", s)
+	}
+	for field, want := range map[string]string{"text": "0.1", "f": "0.1", "n": "5"} {
+		if d, ok := Get[Decimal](r, field); !ok || d.String() != want {
+			t.Errorf("Get[Decimal](%s) = %s, %v", field, d, ok)
+		}
+	}
+	if err := ValidateRecord(r); err != nil {
+		t.Errorf("Decimal should be a valid field value: %v", err)
+	}
+}
+
+func TestDecimalAggregates(t *testing.T) {
+	var records []Record
+	for range 10 {
+		records = append(records, MakeMutableRecord().Decimal("amount", MustParseDecimal("0.10")).Freeze())
+	}
+	records = append(records, MakeMutableRecord().Int("amount", 2).Freeze())
+	records = append(records, MakeMutableRecord().Null("amount").Freeze())
+
+	if got := Sum("amount")(records).getValue(); got != MustParseDecimal("3") {
+		t.Errorf("Sum = %v (%T), want exact 3", got, got)
+	}
+	if got := Avg("amount")(records).getValue(); got != MustParseDecimal("0.272727") {
+		t.Errorf("Avg = %v", got)
+	}
+	if got := Min[float64]("amount")(records).getValue(); got != MustParseDecimal("0.1") {
+		t.Errorf("Min = %v (%T)", got, got)
+	}
+	if got := Max[float64]("amount")(records).getValue(); got != DecimalFromInt(2) {
+		t.Errorf("Max = %v (%T)", got, got)
+	}
+
+	// Groups without decimals keep float64 results
+	floats := []Record{MakeMutableRecord().Float("amount", 1.5).Freeze()}
+	if got := Sum("amount")(floats).getValue(); got != 1.5 {
+		t.Errorf("Sum without decimals = %v (%T)", got, got)
+	}
+}
+
+func TestReadCSVDecimals(t *testing.T) {
+	data := "id,amount\n1,19.99\n2,0.01\n"
+	cfg := DefaultCSVConfig()
+	cfg.Decimals = true
+
+	records := slices.Collect(ReadCSVFromReader(strings.NewReader(data), cfg))
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(records))
+	}
+	if _, ok := records[0].fields["id"].(int64); !ok {
+		t.Errorf("Expected integer id to stay int64, got %T", records[0].fields["id"])
+	}
+	if got := Sum("amount")(records).getValue(); got != MustParseDecimal("20") {
+		t.Errorf("Sum = %v", got)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCSVToWriter(slices.Values(records), &buf, CSVConfig{HasHeaders: true, Delimiter: ',', Fields: []string{"amount"}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if buf.String() != "amount\n19.99\n0.01\n" {
+		t.Errorf("Unexpected CSV output: %q", buf.String())
+	}
+}
+
+func TestReadJSONDecimals(t *testing.T) {
+	data := `{"amount": 0.1, "qty": 3, "note": null}` + "\n"
+
+	records := slices.Collect(ReadJSONFromReader(strings.NewReader(data), JSONConfig{Decimals: true}))
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(records))
+	}
+	r := records[0]
+	if d, ok := r.fields["amount"].(Decimal); !ok || d.String() != "0.1" {
+		t.Errorf("Expected Decimal 0.1, got %v (%T)", r.fields["amount"], r.fields["amount"])
+	}
+	if _, ok := r.fields["qty"].(int64); !ok {
+		t.Errorf("Expected int64 qty, got %T", r.fields["qty"])
+	}
+	if !r.IsNull("note") {
+		t.Error("Expected null note")
+	}
+
+	var buf bytes.Buffer
+	if err := WriteJSONToWriter(slices.Values(records), &buf); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"amount":0.1`) {
+		t.Errorf("Expected decimal written as a JSON number, got %s", buf.String())
+	}
+}
+
+func TestInferSchemaDecimal(t *testing.T) {
+	records := []Record{
+		MakeMutableRecord().Decimal("amount", MustParseDecimal("1.25")).Freeze(),
+		MakeMutableRecord().Int("amount", 3).Freeze(),
+	}
+	schema, _ := InferSchema(slices.Values(records), 0)
+	if field, _ := schema.Field("amount"); field.Type != TypeDecimal {
+		t.Errorf("Expected decimal, got %s", field.Type)
+	}
+	coerced, err := schema.Coerce(records[1])
+	if err != nil || coerced.fields["amount"] != DecimalFromInt(3) {
+		t.Errorf("Expected coercion to Decimal, got %v, %v", coerced.fields["amount"], err)
+	}
+}
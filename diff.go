@@ -0,0 +1,206 @@
+package ssql
+
+import (
+	"iter"
+	"reflect"
+)
+
+// ============================================================================
+// RECORD AND STREAM DIFFS
+// ============================================================================
+
+// Diff kinds, stored in the DiffField of DiffStreams output
+const (
+	DiffAdded   = "added"
+	DiffRemoved = "removed"
+	DiffChanged = "changed"
+)
+
+// DiffField is the field of DiffStreams output records holding the diff
+// kind: DiffAdded, DiffRemoved or DiffChanged
+const DiffField = "_diff"
+
+// FieldDiff is one field that differs between two records.
+// Before is nil for an added field and After is nil for a removed one.
+type FieldDiff struct {
+	Field  string
+	Before any
+	After  any
+}
+
+// RecordDiff lists the fields that differ between two records
+type RecordDiff struct {
+	Changed []FieldDiff // Fields in both records with different values
+	Added   []FieldDiff // Fields only in the second record
+	Removed []FieldDiff // Fields only in the first record
+}
+
+// Empty reports whether the records had no differences
+func (d RecordDiff) Empty() bool {
+	return len(d.Changed) == 0 && len(d.Added) == 0 && len(d.Removed) == 0
+}
+
+// Before returns a Record of the changed and removed fields with their
+// values in the first record
+func (d RecordDiff) Before() Record {
+	result := MakeMutableRecordWithCapacity(len(d.Changed) + len(d.Removed))
+	for _, f := range d.Changed {
+		result.set(f.Field, f.Before)
+	}
+	for _, f := range d.Removed {
+		result.set(f.Field, f.Before)
+	}
+	return result.Freeze()
+}
+
+// After returns a Record of the changed and added fields with their
+// values in the second record
+func (d RecordDiff) After() Record {
+	result := MakeMutableRecordWithCapacity(len(d.Changed) + len(d.Added))
+	for _, f := range d.Changed {
+		result.set(f.Field, f.After)
+	}
+	for _, f := range d.Added {
+		result.set(f.Field, f.After)
+	}
+	return result.Freeze()
+}
+
+// DiffRecords compares two records field by field, describing the changes
+// from a to b. Nested Records are compared by value and sequence fields by
+// their elements (which consumes them). Changed and removed fields follow
+// a's field order; added fields follow b's.
+//
+// Example:
+//
+//	d := ssql.DiffRecords(yesterday, today)
+//	for _, f := range d.Changed {
+//	    fmt.Printf("%s: %v -> %v\n", f.Field, f.Before, f.After)
+//	}
+func DiffRecords(a, b Record) RecordDiff {
+	var d RecordDiff
+	for _, field := range a.keyOrder() {
+		before := a.fields[field]
+		after, exists := b.fields[field]
+		switch {
+		case !exists:
+			d.Removed = append(d.Removed, FieldDiff{Field: field, Before: before})
+		case !valuesEqual(before, after):
+			d.Changed = append(d.Changed, FieldDiff{Field: field, Before: before, After: after})
+		}
+	}
+	for _, field := range b.keyOrder() {
+		if _, exists := a.fields[field]; !exists {
+			d.Added = append(d.Added, FieldDiff{Field: field, After: b.fields[field]})
+		}
+	}
+	return d
+}
+
+// valuesEqual compares two field values without panicking on
+// non-comparable types
+func valuesEqual(a, b any) bool {
+	if ar, ok := a.(Record); ok {
+		br, ok := b.(Record)
+		return ok && DiffRecords(ar, br).Empty()
+	}
+	if isIterSeq(a) || isIterSeq(b) {
+		return isIterSeq(a) && isIterSeq(b) && reflect.DeepEqual(materializeSequence(a), materializeSequence(b))
+	}
+	ta := reflect.TypeOf(a)
+	if ta != nil && ta == reflect.TypeOf(b) && ta.Comparable() {
+		return a == b
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// DiffStreams compares two record streams matched on keyFields, treating
+// right as the baseline (the old data) and left as the new data. It emits
+// one record per difference, with DiffField set to:
+//
+//   - "added": a left record whose key is not in right; "after" holds it
+//   - "removed": a right record whose key is not in left; "before" holds it
+//   - "changed": matching records that differ; "before" and "after" hold
+//     the differing fields (see DiffRecords)
+//
+// Each output record also carries the key fields. Unchanged records are
+// not emitted. Records are matched with the OnFields hash-join key, so
+// null or missing keys never match; right is held in memory and left is
+// streamed. Removed records follow the added and changed ones, in right's
+// order. If a key repeats, its records are matched in order.
+//
+// Example:
+//
+//	today, _ := ssql.ReadCSV("extract-today.csv")
+//	yesterday, _ := ssql.ReadCSV("extract-yesterday.csv")
+//	for d := range ssql.DiffStreams(today, yesterday, "id") {
+//	    fmt.Println(ssql.GetOr(d, ssql.DiffField, ""), ssql.GetOr(d, "id", int64(0)))
+//	}
+//
+// Metadata fields such as _row_number differ whenever rows move; remove
+// them before diffing.
+func DiffStreams(left, right iter.Seq[Record], keyFields ...string) iter.Seq[Record] {
+	extractor := OnFields(keyFields...).(KeyExtractor)
+	return func(yield func(Record) bool) {
+		// BUILD PHASE: Hash right side, remembering its order
+		var rightRecords []Record
+		hashTable := make(map[string][]int)
+		for r := range right {
+			if key, ok := extractor.ExtractKey(r); ok {
+				hashTable[key] = append(hashTable[key], len(rightRecords))
+			}
+			rightRecords = append(rightRecords, r)
+		}
+		matched := make([]bool, len(rightRecords))
+
+		// PROBE PHASE: Stream left and look up its baseline
+		for l := range left {
+			key, ok := extractor.ExtractKey(l)
+			candidates := hashTable[key]
+			if !ok || len(candidates) == 0 {
+				if !yield(diffRecord(DiffAdded, l, keyFields, Record{}, l)) {
+					return
+				}
+				continue
+			}
+
+			index := candidates[0]
+			hashTable[key] = candidates[1:]
+			matched[index] = true
+
+			d := DiffRecords(rightRecords[index], l)
+			if d.Empty() {
+				continue
+			}
+			if !yield(diffRecord(DiffChanged, l, keyFields, d.Before(), d.After())) {
+				return
+			}
+		}
+
+		for i, r := range rightRecords {
+			if matched[i] {
+				continue
+			}
+			if !yield(diffRecord(DiffRemoved, r, keyFields, r, Record{})) {
+				return
+			}
+		}
+	}
+}
+
+// diffRecord builds a DiffStreams output record. Empty before or after
+// records are left out.
+func diffRecord(kind string, keySource Record, keyFields []string, before, after Record) Record {
+	result := MakeMutableRecord()
+	result.set(DiffField, kind)
+	for field, value := range keySource.Project(keyFields...).All() {
+		result.set(field, value)
+	}
+	if before.Len() > 0 {
+		result.set("before", before)
+	}
+	if after.Len() > 0 {
+		result.set("after", after)
+	}
+	return result.Freeze()
+}
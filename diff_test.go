@@ -0,0 +1,96 @@
+package ssql
+
+import (
+	"slices"
+	"testing"
+)
+
+// ============================================================================
+// DIFF TESTS
+// ============================================================================
+
+func TestDiffRecords(t *testing.T) {
+	address := MakeMutableRecord().String("city", "Paris").Freeze()
+	a := MakeMutableRecord().
+		Int("id", 1).
+		String("name", "Ann").
+		Float("score", 9.5).
+		Nested("address", address).
+		String("legacy", "x").
+		Freeze()
+	b := MakeMutableRecord().
+		Int("id", 1).
+		String("name", "Anne").
+		Float("score", 9.5).
+		Nested("address", MakeMutableRecord().String("city", "Lyon").Freeze()).
+		Bool("active", true).
+		Freeze()
+
+	d := DiffRecords(a, b)
+	var changed []string
+	for _, f := range d.Changed {
+		changed = append(changed, f.Field)
+	}
+	if !slices.Equal(changed, []string{"name", "address"}) {
+		t.Errorf("Expected name and address changed, got %v", changed)
+	}
+	if d.Changed[0].Before != "Ann" || d.Changed[0].After != "Anne" {
+		t.Errorf("Unexpected name change %+v", d.Changed[0])
+	}
+	if len(d.Added) != 1 || d.Added[0].Field != "active" || d.Added[0].After != true {
+		t.Errorf("Expected active added, got %+v", d.Added)
+	}
+	if len(d.Removed) != 1 || d.Removed[0].Field != "legacy" || d.Removed[0].Before != "x" {
+		t.Errorf("Expected legacy removed, got %+v", d.Removed)
+	}
+	if !slices.Equal(d.Before().Keys(), []string{"name", "address", "legacy"}) {
+		t.Errorf("Unexpected Before fields %v", d.Before().Keys())
+	}
+
+	same := MakeMutableRecord().Int("id", 1).Nested("address", address).StringSeq("tags", slices.Values([]string{"a"})).Freeze()
+	other := MakeMutableRecord().Int("id", 1).Nested("address", address).StringSeq("tags", slices.Values([]string{"a"})).Freeze()
+	if d := DiffRecords(same, other); !d.Empty() {
+		t.Errorf("Expected no differences, got %+v", d)
+	}
+}
+
+func TestDiffStreams(t *testing.T) {
+	row := func(id int64, name string, qty int64) Record {
+		return MakeMutableRecord().Int("id", id).String("name", name).Int("qty", qty).Freeze()
+	}
+	old := []Record{row(1, "apple", 5), row(2, "pear", 3), row(3, "plum", 7)}
+	current := []Record{row(1, "apple", 5), row(3, "plum", 9), row(4, "fig", 1)}
+
+	diffs := slices.Collect(DiffStreams(slices.Values(current), slices.Values(old), "id"))
+	if len(diffs) != 3 {
+		t.Fatalf("Expected 3 diffs, got %d: %v", len(diffs), diffs)
+	}
+
+	kinds := []string{GetOr(diffs[0], DiffField, ""), GetOr(diffs[1], DiffField, ""), GetOr(diffs[2], DiffField, "")}
+	if !slices.Equal(kinds, []string{DiffChanged, DiffAdded, DiffRemoved}) {
+		t.Errorf("Expected changed, added, removed; got %v", kinds)
+	}
+
+	changed := diffs[0]
+	if GetOr(changed, "id", int64(0)) != 3 || GetOr(changed, "before.qty", int64(0)) != 7 || GetOr(changed, "after.qty", int64(0)) != 9 {
+		t.Errorf("Unexpected changed record %v", changed)
+	}
+	if _, ok := Get[any](changed, "after.name"); ok {
+		t.Errorf("Unchanged fields should not appear in after: %v", changed)
+	}
+
+	if GetOr(diffs[1], "id", int64(0)) != 4 || GetOr(diffs[1], "after.name", "") != "fig" {
+		t.Errorf("Unexpected added record %v", diffs[1])
+	}
+	if _, ok := Get[any](diffs[1], "before"); ok {
+		t.Errorf("Added record should have no before: %v", diffs[1])
+	}
+	if GetOr(diffs[2], "id", int64(0)) != 2 || GetOr(diffs[2], "before.name", "") != "pear" {
+		t.Errorf("Unexpected removed record %v", diffs[2])
+	}
+
+	// Early stop
+	if first := slices.Collect(Limit[Record](1)(DiffStreams(slices.Values(current), slices.Values(old), "id"))); len(first) != 1 {
+		t.Errorf("Expected 1 diff after Limit, got %d", len(first))
+	}
+}
@@ -0,0 +1,197 @@
+package ssql
+
+import (
+	"container/heap"
+	"container/list"
+	"hash/maphash"
+	"iter"
+	"math"
+	"time"
+)
+
+// ============================================================================
+// BOUNDED DEDUPLICATION
+// ============================================================================
+
+// Distinct and DistinctBy remember every key they have seen, which grows
+// without limit on infinite streams. The filters below bound that memory
+// by event time, by key count or with a fixed-size Bloom filter.
+
+// DistinctWithin drops a record if another record with the same key was
+// kept less than ttl before or after it in event time (the time in
+// timeField), so each key is emitted at most once per ttl. Keys are
+// forgotten once the latest event time seen is ttl past the record kept
+// for them, so memory is bounded by the number of distinct keys within
+// one ttl. Records without a valid time are passed through.
+//
+// Example:
+//
+//	// Suppress repeated alerts for the same host and check for 10 minutes
+//	alerts := ssql.DistinctWithin(func(r ssql.Record) string {
+//	    return ssql.GetOr(r, "host", "") + "/" + ssql.GetOr(r, "check", "")
+//	}, 10*time.Minute, "ts")(events)
+func DistinctWithin[K comparable](keyFn func(Record) K, ttl time.Duration, timeField string) Filter[Record, Record] {
+	return func(input iter.Seq[Record]) iter.Seq[Record] {
+		return func(yield func(Record) bool) {
+			kept := make(map[K]time.Time)
+			expiry := &keyTimeHeap[K]{}
+			var maxTime time.Time
+
+			for r := range input {
+				val, _ := r.lookup(timeField)
+				t := parseTimeValue(val)
+				if t.IsZero() {
+					if !yield(r) {
+						return
+					}
+					continue
+				}
+
+				// Forget keys the stream has moved ttl past
+				if t.After(maxTime) {
+					maxTime = t
+					for expiry.Len() > 0 && maxTime.Sub(expiry.entries[0].time) >= ttl {
+						e := heap.Pop(expiry).(keyTime[K])
+						if kept[e.key].Equal(e.time) {
+							delete(kept, e.key)
+						}
+					}
+				}
+
+				key := keyFn(r)
+				if last, seen := kept[key]; seen {
+					if d := t.Sub(last); d < ttl && d > -ttl {
+						continue
+					}
+				}
+				kept[key] = t
+				heap.Push(expiry, keyTime[K]{key, t})
+				if !yield(r) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// keyTime is a key and the event time it was kept at
+type keyTime[K comparable] struct {
+	key  K
+	time time.Time
+}
+
+// keyTimeHeap orders keys by the time they were kept, oldest first
+type keyTimeHeap[K comparable] struct {
+	entries []keyTime[K]
+}
+
+func (h *keyTimeHeap[K]) Len() int { return len(h.entries) }
+
+func (h *keyTimeHeap[K]) Less(i, j int) bool { return h.entries[i].time.Before(h.entries[j].time) }
+
+func (h *keyTimeHeap[K]) Swap(i, j int) { h.entries[i], h.entries[j] = h.entries[j], h.entries[i] }
+
+func (h *keyTimeHeap[K]) Push(x any) { h.entries = append(h.entries, x.(keyTime[K])) }
+
+func (h *keyTimeHeap[K]) Pop() any {
+	last := h.entries[len(h.entries)-1]
+	h.entries = h.entries[:len(h.entries)-1]
+	return last
+}
+
+// DistinctLRU drops elements whose key is among the maxKeys most recently
+// seen keys (at least 1). Seeing a key again, kept or not, makes it the
+// most recent; when the limit is reached the least recently seen key is
+// forgotten and its next occurrence is emitted again. Use it when
+// duplicates arrive close together, such as retried messages.
+//
+// Example:
+//
+//	// Drop redelivered messages, remembering the last 100,000 IDs
+//	unique := ssql.DistinctLRU(func(m ssql.Record) string {
+//	    return ssql.GetOr(m, "message_id", "")
+//	}, 100_000)(messages)
+func DistinctLRU[T any, K comparable](keyFn func(T) K, maxKeys int) Filter[T, T] {
+	if maxKeys < 1 {
+		maxKeys = 1
+	}
+	return func(input iter.Seq[T]) iter.Seq[T] {
+		return func(yield func(T) bool) {
+			recent := make(map[K]*list.Element, maxKeys)
+			order := list.New() // most recently seen first
+
+			for v := range input {
+				key := keyFn(v)
+				if elem, seen := recent[key]; seen {
+					order.MoveToFront(elem)
+					continue
+				}
+				if order.Len() >= maxKeys {
+					oldest := order.Back()
+					order.Remove(oldest)
+					delete(recent, oldest.Value.(K))
+				}
+				recent[key] = order.PushFront(key)
+				if !yield(v) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// DistinctBloom drops elements whose key is probably a duplicate, using a
+// Bloom filter sized for expectedN distinct keys at false-positive rate
+// fpRate (for example 0.001), so memory is fixed at about
+// 1.44·log2(1/fpRate) bits per expected key however long the stream runs.
+// Duplicates are always dropped; about fpRate of the first occurrences are
+// wrongly dropped too, more once the stream has over expectedN distinct
+// keys. Key hashes are seeded per run, so which keys are affected varies
+// between runs.
+//
+// Example:
+//
+//	// Deduplicate ~10 million event IDs in about 18 MB
+//	unique := ssql.DistinctBloom(func(e ssql.Record) string {
+//	    return ssql.GetOr(e, "event_id", "")
+//	}, 10_000_000, 0.001)(events)
+func DistinctBloom[T any, K comparable](keyFn func(T) K, expectedN int, fpRate float64) Filter[T, T] {
+	if expectedN < 1 {
+		expectedN = 1
+	}
+	if fpRate <= 0 || fpRate >= 1 {
+		fpRate = 0.01
+	}
+	// Optimal size m = -n·ln(p)/ln(2)² bits and k = m/n·ln(2) hash functions
+	bits := uint64(math.Ceil(-float64(expectedN) * math.Log(fpRate) / (math.Ln2 * math.Ln2)))
+	hashes := max(1, int(math.Round(float64(bits)/float64(expectedN)*math.Ln2)))
+
+	return func(input iter.Seq[T]) iter.Seq[T] {
+		return func(yield func(T) bool) {
+			filter := make([]uint64, (bits+63)/64)
+			seed1, seed2 := maphash.MakeSeed(), maphash.MakeSeed()
+
+			for v := range input {
+				key := keyFn(v)
+				// Double hashing: the i-th hash is h1 + i·h2
+				h1 := maphash.Comparable(seed1, key)
+				h2 := maphash.Comparable(seed2, key) | 1
+				present := true
+				for i := range uint64(hashes) {
+					bit := (h1 + i*h2) % bits
+					word, mask := bit/64, uint64(1)<<(bit%64)
+					if filter[word]&mask == 0 {
+						present = false
+						filter[word] |= mask
+					}
+				}
+				if present {
+					continue
+				}
+				if !yield(v) {
+					return
+				}
+			}
+		}
+	}
+}
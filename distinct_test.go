@@ -0,0 +1,140 @@
+package ssql
+
+import (
+	"fmt"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// ============================================================================
+// BOUNDED DEDUPLICATION TESTS
+// ============================================================================
+
+func TestDistinctWithin(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	event := func(id int, host string, offset time.Duration) Record {
+		r := MakeMutableRecord().Int("id", int64(id)).String("host", host)
+		if offset >= 0 {
+			r = r.Time("ts", base.Add(offset))
+		}
+		return r.Freeze()
+	}
+	input := []Record{
+		event(1, "a", 0),
+		event(2, "a", 2*time.Minute),  // duplicate within 5m
+		event(3, "b", 3*time.Minute),  // new key
+		event(4, "a", 4*time.Minute),  // still within 5m of the kept record
+		event(5, "a", 6*time.Minute),  // 6m after the kept record: kept
+		event(6, "b", -1),             // no time: passed through
+		event(7, "a", 90*time.Second), // out of order, within 5m of #5
+		event(8, "b", 20*time.Minute), // expired long ago: kept
+		event(9, "b", 21*time.Minute), // duplicate of #8
+	}
+
+	host := func(r Record) string { return GetOr(r, "host", "") }
+	var ids []int64
+	for r := range DistinctWithin(host, 5*time.Minute, "ts")(slices.Values(input)) {
+		ids = append(ids, GetOr(r, "id", int64(0)))
+	}
+	if want := []int64{1, 3, 5, 6, 8}; !slices.Equal(ids, want) {
+		t.Errorf("Expected %v, got %v", want, ids)
+	}
+
+	// Stopping early
+	var first []int64
+	for r := range DistinctWithin(host, 5*time.Minute, "ts")(slices.Values(input)) {
+		first = append(first, GetOr(r, "id", int64(0)))
+		if len(first) == 2 {
+			break
+		}
+	}
+	if !slices.Equal(first, []int64{1, 3}) {
+		t.Errorf("Expected [1 3], got %v", first)
+	}
+}
+
+func TestDistinctWithinLongStream(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	input := func(yield func(Record) bool) {
+		for i := range 9000 {
+			r := MakeMutableRecord().Int("key", int64(i%30)).Time("ts", base.Add(time.Duration(i)*time.Second))
+			if !yield(r.Freeze()) {
+				return
+			}
+		}
+	}
+	// Each key repeats every 30s, so with a one-minute horizon every other
+	// occurrence is kept
+	key := func(r Record) int64 { return GetOr(r, "key", int64(0)) }
+	if got := slices.Collect(DistinctWithin(key, time.Minute, "ts")(input)); len(got) != 4500 {
+		t.Errorf("Expected 4500 records, got %d", len(got))
+	}
+}
+
+func TestDistinctLRU(t *testing.T) {
+	identity := func(s string) string { return s }
+	input := []string{"a", "b", "a", "c", "d", "a", "b", "e", "a"}
+
+	got := slices.Collect(DistinctLRU(identity, 3)(slices.Values(input)))
+	// "a" stays recent because it keeps being seen; "b" is forgotten when
+	// "d" arrives, and is emitted again
+	if want := []string{"a", "b", "c", "d", "b", "e"}; !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	// Unbounded enough behaves like Distinct
+	if got := slices.Collect(DistinctLRU(identity, 100)(slices.Values(input))); !slices.Equal(got, []string{"a", "b", "c", "d", "e"}) {
+		t.Errorf("Expected plain distinct, got %v", got)
+	}
+
+	// maxKeys < 1 only removes adjacent duplicates
+	if got := slices.Collect(DistinctLRU(identity, 0)(slices.Values([]string{"a", "a", "b", "a"}))); !slices.Equal(got, []string{"a", "b", "a"}) {
+		t.Errorf("Expected [a b a], got %v", got)
+	}
+}
+
+func TestDistinctBloom(t *testing.T) {
+	const n = 20000
+	input := func(yield func(int) bool) {
+		// Every value twice, the repeat well after the first
+		for i := range 2 * n {
+			if !yield(i % n) {
+				return
+			}
+		}
+	}
+	identity := func(v int) int { return v }
+
+	got := slices.Collect(DistinctBloom(identity, n, 0.01)(input))
+	seen := make(map[int]bool)
+	for _, v := range got {
+		if seen[v] {
+			t.Fatalf("Duplicate %d passed", v)
+		}
+		seen[v] = true
+	}
+	// About 1% of first occurrences may be wrongly dropped
+	if dropped := n - len(got); dropped > n/50 {
+		t.Errorf("Expected about %d false positives, got %d", n/100, dropped)
+	}
+
+	// Works with any comparable key
+	type pair struct{ a, b string }
+	pairs := []pair{{"x", "y"}, {"x", "z"}, {"x", "y"}}
+	if got := slices.Collect(DistinctBloom(func(p pair) pair { return p }, 100, 0.001)(slices.Values(pairs))); len(got) != 2 {
+		t.Errorf("Expected 2 distinct pairs, got %v", got)
+	}
+}
+
+func TestDistinctBloomStopsEarly(t *testing.T) {
+	var produced atomic.Int64
+	got := slices.Collect(Limit[int](5)(DistinctBloom(func(v int) string { return fmt.Sprint(v) }, 1000, 0.01)(countTo(1000, &produced))))
+	if len(got) != 5 {
+		t.Errorf("Expected 5 elements, got %v", got)
+	}
+	if produced.Load() > 10 {
+		t.Errorf("Expected input to stop early, produced %d", produced.Load())
+	}
+}
@@ -0,0 +1,220 @@
+package ssql
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"reflect"
+	"sync"
+)
+
+// ============================================================================
+// ERROR POLICIES AND DEAD-LETTER SINKS
+// ============================================================================
+
+// RecordError describes one input element that failed in a pipeline stage.
+// The Safe readers yield *RecordError for rows and lines they cannot parse;
+// ApplyErrorPolicy wraps any other error it sees.
+type RecordError struct {
+	Stage    string // Stage that failed, such as "read-csv"
+	Position int64  // Row, line or element number within the stage's input
+	Input    any    // The failed input (raw line, CSV fields or element), nil if unknown
+	Err      error  // The underlying error
+}
+
+// Error returns the underlying error's message
+func (e *RecordError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorPolicy decides what happens to an error in a Safe pipeline.
+// HandleError returns nil to skip the failed element and continue, or a
+// non-nil error to stop the stream with that error.
+type ErrorPolicy interface {
+	HandleError(err error) error
+}
+
+// ErrorPolicyFunc adapts an ordinary function to the ErrorPolicy interface
+type ErrorPolicyFunc func(err error) error
+
+// HandleError calls f(err)
+func (f ErrorPolicyFunc) HandleError(err error) error {
+	return f(err)
+}
+
+// FailFast returns a policy that stops the stream at the first error
+func FailFast() ErrorPolicy {
+	return ErrorPolicyFunc(func(err error) error { return err })
+}
+
+// SkipErrors returns a policy that drops every failed element.
+// Unlike IgnoreErrors it can be combined with other policies, for example
+// to log errors before skipping them.
+func SkipErrors() ErrorPolicy {
+	return ErrorPolicyFunc(func(error) error { return nil })
+}
+
+// SkipErrorsUpTo returns a policy that skips up to limit errors and stops
+// the stream at the next one. The returned policy counts errors across all
+// streams it is used with; create a new one per pipeline run.
+func SkipErrorsUpTo(limit int) ErrorPolicy {
+	var mu sync.Mutex
+	count := 0
+	return ErrorPolicyFunc(func(err error) error {
+		mu.Lock()
+		defer mu.Unlock()
+		count++
+		if count > limit {
+			return fmt.Errorf("too many errors (more than %d): %w", limit, err)
+		}
+		return nil
+	})
+}
+
+// ApplyErrorPolicy passes values through and hands each error to policy.
+// Errors the policy skips are dropped; the first error it returns is
+// yielded and ends the stream. Errors that are not already a *RecordError
+// are wrapped in one naming stage and the element's position in the input
+// (counting from 0), so a DeadLetter sink can record where they came from.
+//
+// Example:
+//
+//	dlq, err := ssql.CreateDeadLetter("rejected.jsonl")
+//	if err != nil {
+//	    return err
+//	}
+//	defer dlq.Close()
+//
+//	records := ssql.ApplyErrorPolicy[ssql.Record]("read-csv", dlq)(ssql.ReadCSVSafe("orders.csv"))
+//	for r, err := range records {
+//	    if err != nil {
+//	        return err // Only failures writing the dead-letter file get here
+//	    }
+//	    process(r)
+//	}
+func ApplyErrorPolicy[T any](stage string, policy ErrorPolicy) FilterWithErrors[T, T] {
+	return func(input iter.Seq2[T, error]) iter.Seq2[T, error] {
+		return func(yield func(T, error) bool) {
+			position := int64(0)
+			for v, err := range input {
+				if err == nil {
+					position++
+					if !yield(v, nil) {
+						return
+					}
+					continue
+				}
+
+				var recErr *RecordError
+				if !errors.As(err, &recErr) {
+					recErr = &RecordError{Stage: stage, Position: position, Err: err}
+					if rv := reflect.ValueOf(any(v)); rv.IsValid() && !rv.IsZero() {
+						recErr.Input = v
+					}
+					err = recErr
+				}
+				position++
+
+				if stopErr := policy.HandleError(err); stopErr != nil {
+					yield(v, stopErr)
+					return
+				}
+			}
+		}
+	}
+}
+
+// DeadLetter is an ErrorPolicy that writes each error to a JSONL sink and
+// skips the failed element. Each line holds the error text and, for a
+// *RecordError, the stage, position and failed input:
+//
+//	{"stage":"read-json","position":3,"error":"failed to parse JSON on line 3: ...","input":"{bad"}
+//
+// A DeadLetter is safe for concurrent use. Failing to write the sink stops
+// the stream with the write error.
+type DeadLetter struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+	closer  io.Closer
+	count   int64
+}
+
+// deadLetterEntry is one line of a DeadLetter sink
+type deadLetterEntry struct {
+	Stage    string `json:"stage,omitempty"`
+	Position *int64 `json:"position,omitempty"`
+	Error    string `json:"error"`
+	Input    any    `json:"input,omitempty"`
+}
+
+// NewDeadLetter returns a DeadLetter that writes to w
+func NewDeadLetter(w io.Writer) *DeadLetter {
+	return &DeadLetter{encoder: json.NewEncoder(w)}
+}
+
+// CreateDeadLetter creates (or truncates) filename and returns a DeadLetter
+// writing to it. Call Close when the pipeline is done.
+func CreateDeadLetter(filename string) (*DeadLetter, error) {
+	file, err := os.Create(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dead-letter file %s: %w", filename, err)
+	}
+	d := NewDeadLetter(file)
+	d.closer = file
+	return d, nil
+}
+
+// HandleError writes err to the sink and returns nil so the stream continues
+func (d *DeadLetter) HandleError(err error) error {
+	entry := deadLetterEntry{Error: err.Error()}
+	var recErr *RecordError
+	if errors.As(err, &recErr) {
+		entry.Stage = recErr.Stage
+		entry.Position = &recErr.Position
+		entry.Input = deadLetterInput(recErr.Input)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if writeErr := d.encoder.Encode(entry); writeErr != nil {
+		return fmt.Errorf("failed to write dead letter: %w (original error: %w)", writeErr, err)
+	}
+	d.count++
+	return nil
+}
+
+// Count returns the number of errors written so far
+func (d *DeadLetter) Count() int64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.count
+}
+
+// Close closes the file opened by CreateDeadLetter. It does nothing for a
+// DeadLetter created with NewDeadLetter.
+func (d *DeadLetter) Close() error {
+	if d.closer == nil {
+		return nil
+	}
+	return d.closer.Close()
+}
+
+// deadLetterInput returns input in a form encoding/json can write,
+// falling back to its fmt representation
+func deadLetterInput(input any) any {
+	if input == nil {
+		return nil
+	}
+	if _, err := json.Marshal(input); err != nil {
+		return fmt.Sprintf("%v", input)
+	}
+	return input
+}
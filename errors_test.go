@@ -0,0 +1,150 @@
+package ssql
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// ============================================================================
+// ERROR POLICY TESTS
+// ============================================================================
+
+// numbersWithErrors yields 0..9 with errors in place of 2, 5 and 7
+func numbersWithErrors(yield func(int, error) bool) {
+	for i := range 10 {
+		var err error
+		if i == 2 || i == 5 || i == 7 {
+			err = errors.New("bad number")
+		}
+		if !yield(i, err) {
+			return
+		}
+	}
+}
+
+// collectWithError collects values and the error that ended the stream
+func collectWithError[T any](seq func(func(T, error) bool)) ([]T, error) {
+	var values []T
+	for v, err := range seq {
+		if err != nil {
+			return values, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+func TestErrorPolicies(t *testing.T) {
+	values, err := collectWithError(ApplyErrorPolicy[int]("numbers", SkipErrors())(numbersWithErrors))
+	if err != nil || !slices.Equal(values, []int{0, 1, 3, 4, 6, 8, 9}) {
+		t.Errorf("SkipErrors: got %v, %v", values, err)
+	}
+
+	values, err = collectWithError(ApplyErrorPolicy[int]("numbers", FailFast())(numbersWithErrors))
+	if !slices.Equal(values, []int{0, 1}) {
+		t.Errorf("FailFast: expected [0 1] before the error, got %v", values)
+	}
+	var recErr *RecordError
+	if !errors.As(err, &recErr) || recErr.Stage != "numbers" || recErr.Position != 2 {
+		t.Errorf("FailFast: expected RecordError at position 2 of stage numbers, got %#v", err)
+	}
+	if err.Error() != "bad number" {
+		t.Errorf("RecordError should keep the original message, got %q", err.Error())
+	}
+
+	values, err = collectWithError(ApplyErrorPolicy[int]("numbers", SkipErrorsUpTo(2))(numbersWithErrors))
+	if !slices.Equal(values, []int{0, 1, 3, 4, 6}) {
+		t.Errorf("SkipErrorsUpTo(2): got %v", values)
+	}
+	if err == nil || !strings.Contains(err.Error(), "too many errors") || !errors.As(err, &recErr) || recErr.Position != 7 {
+		t.Errorf("SkipErrorsUpTo(2): expected limit error at position 7, got %v", err)
+	}
+}
+
+func TestApplyErrorPolicyKeepsRecordError(t *testing.T) {
+	data := "name,age\nalice,30\nbob,\"x\ncarol,40\n"
+	var seen []error
+	policy := ErrorPolicyFunc(func(err error) error {
+		seen = append(seen, err)
+		return nil
+	})
+
+	for range ApplyErrorPolicy[Record]("pipeline", policy)(ReadCSVSafeFromReader(strings.NewReader(data))) {
+	}
+	if len(seen) != 1 {
+		t.Fatalf("Expected 1 error, got %v", seen)
+	}
+	var recErr *RecordError
+	if !errors.As(seen[0], &recErr) || recErr.Stage != "read-csv" || recErr.Position != 1 {
+		t.Errorf("Expected the reader's RecordError (stage read-csv, row 1), got %#v", seen[0])
+	}
+}
+
+func TestErrorPolicyWrappedRecordError(t *testing.T) {
+	// A RecordError wrapped by a later stage keeps its stage and input
+	wrapped := func(yield func(Record, error) bool) {
+		recErr := &RecordError{Stage: "read-json", Position: 4, Input: "{bad", Err: errors.New("invalid JSON")}
+		yield(Record{}, fmt.Errorf("spilling: %w", recErr))
+	}
+
+	var seen []error
+	policy := ErrorPolicyFunc(func(err error) error {
+		seen = append(seen, err)
+		return nil
+	})
+	for range ApplyErrorPolicy[Record]("pipeline", policy)(wrapped) {
+	}
+	var recErr *RecordError
+	if len(seen) != 1 || !errors.As(seen[0], &recErr) || recErr.Stage != "read-json" || recErr.Position != 4 {
+		t.Errorf("Expected the wrapped RecordError to pass through, got %#v", seen)
+	}
+
+	var sink bytes.Buffer
+	for range ApplyErrorPolicy[Record]("pipeline", NewDeadLetter(&sink))(wrapped) {
+	}
+	if want := `{"stage":"read-json","position":4,"error":"spilling: invalid JSON","input":"{bad"}`; strings.TrimSpace(sink.String()) != want {
+		t.Errorf("Dead letter = %s, want %s", sink.String(), want)
+	}
+}
+
+func TestDeadLetter(t *testing.T) {
+	data := "{\"id\":1}\n{bad json\n{\"id\":3}\n"
+	var sink bytes.Buffer
+	dlq := NewDeadLetter(&sink)
+
+	records, err := collectWithError(ApplyErrorPolicy[Record]("ingest", dlq)(ReadJSONSafeFromReader(strings.NewReader(data))))
+	if err != nil {
+		t.Fatalf("DeadLetter should skip errors, got %v", err)
+	}
+	if len(records) != 2 {
+		t.Errorf("Expected 2 records, got %d", len(records))
+	}
+	if dlq.Count() != 1 {
+		t.Errorf("Expected 1 dead letter, got %d", dlq.Count())
+	}
+
+	var entry map[string]any
+	if err := json.Unmarshal(sink.Bytes(), &entry); err != nil {
+		t.Fatalf("Dead-letter sink is not JSONL: %v (%q)", err, sink.String())
+	}
+	if entry["stage"] != "read-json" || entry["position"] != float64(1) || entry["input"] != "{bad json" {
+		t.Errorf("Unexpected dead-letter entry %v", entry)
+	}
+	if !strings.Contains(entry["error"].(string), "line 1") {
+		t.Errorf("Expected error text in entry, got %v", entry["error"])
+	}
+
+	// Errors from other stages are wrapped with the element's position and value
+	sink.Reset()
+	for range ApplyErrorPolicy[int]("numbers", dlq)(numbersWithErrors) {
+	}
+	lines := strings.Split(strings.TrimSpace(sink.String()), "\n")
+	if len(lines) != 3 || lines[2] != `{"stage":"numbers","position":7,"error":"bad number","input":7}` {
+		t.Errorf("Unexpected dead letters %q", lines)
+	}
+}
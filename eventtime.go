@@ -0,0 +1,283 @@
+package ssql
+
+import (
+	"cmp"
+	"iter"
+	"slices"
+	"time"
+)
+
+// ============================================================================
+// EVENT-TIME WINDOWS WITH WATERMARKS
+// ============================================================================
+
+// Fields added to window output records
+const (
+	// WindowStartField holds the window's inclusive start time
+	WindowStartField = "window_start"
+	// WindowEndField holds the window's exclusive end time
+	WindowEndField = "window_end"
+	// WindowUpdateField numbers re-emissions of a window updated by late
+	// records (1, 2, ...); it is absent from a window's first emission
+	WindowUpdateField = "window_update"
+)
+
+// WatermarkStrategy computes the watermark from the latest event time seen
+// so far. The watermark is the event time up to which the stream is taken
+// to be complete: a window is emitted once the watermark reaches its end,
+// and records for windows the watermark has passed are late.
+type WatermarkStrategy func(maxEventTime time.Time) time.Time
+
+// BoundedOutOfOrderness returns a watermark that trails the latest event
+// time by maxDelay, so records may arrive up to maxDelay out of order
+// without being late.
+func BoundedOutOfOrderness(maxDelay time.Duration) WatermarkStrategy {
+	return func(maxEventTime time.Time) time.Time {
+		return maxEventTime.Add(-maxDelay)
+	}
+}
+
+// AscendingTimestamps returns a watermark equal to the latest event time,
+// for streams whose timestamps never go backwards
+func AscendingTimestamps() WatermarkStrategy {
+	return BoundedOutOfOrderness(0)
+}
+
+// LatePolicy decides what happens to a record whose window has already
+// been emitted
+type LatePolicy int
+
+const (
+	// LateDrop discards late records (the default)
+	LateDrop LatePolicy = iota
+	// LateSideOutput passes late records to EventTimeWindowConfig.LateOutput
+	LateSideOutput
+	// LateUpdate adds late records to their window and emits the window
+	// again, marked with WindowUpdateField, until the watermark passes the
+	// window end plus AllowedLateness. Records later than that are dropped,
+	// or passed to LateOutput if it is set.
+	LateUpdate
+)
+
+// EventTimeWindowConfig configures EventTimeWindow
+type EventTimeWindowConfig struct {
+	// TimeField holds each record's event time (time.Time, a timestamp
+	// string or Unix seconds); nested paths allowed. Records without a
+	// valid time are skipped.
+	TimeField string
+	// Size is the window length
+	Size time.Duration
+	// Slide is the distance between window starts. Zero (or Size) gives
+	// tumbling windows; less than Size gives overlapping sliding windows.
+	Slide time.Duration
+	// KeyFields, if set, window each key separately; key values are copied
+	// to the output like GroupByFields
+	KeyFields []string
+	// Watermark computes the watermark; nil uses AscendingTimestamps
+	Watermark WatermarkStrategy
+	// AllowedLateness keeps emitted windows open for updates under LateUpdate
+	AllowedLateness time.Duration
+	// Late is the policy for records whose window was already emitted
+	Late LatePolicy
+	// LateOutput receives late records under LateSideOutput, and records
+	// too late to update under LateUpdate
+	LateOutput func(Record)
+}
+
+// EventTimeWindow groups records into windows by the event time in each
+// record rather than by arrival order, so out-of-order input is assigned
+// to the right window. Windows are aligned to multiples of Slide (or
+// Size), and each is emitted once the watermark reaches its end; windows
+// still open when the input ends are emitted then. Records arriving for a
+// window that was already emitted are handled by the Late policy.
+//
+// Each output record holds the key fields, WindowStartField,
+// WindowEndField and, in sequenceField, the window's records as an
+// iter.Seq[Record] in arrival order, so it can be passed to Aggregate like
+// the output of GroupByFields. Windows are emitted in order of end time.
+//
+// Example:
+//
+//	// Five-minute request counts per host, tolerating 30s of disorder
+//	counts := ssql.Chain(
+//	    ssql.EventTimeWindow("requests", ssql.EventTimeWindowConfig{
+//	        TimeField: "ts",
+//	        Size:      5 * time.Minute,
+//	        KeyFields: []string{"host"},
+//	        Watermark: ssql.BoundedOutOfOrderness(30 * time.Second),
+//	    }),
+//	    ssql.Aggregate("requests", map[string]ssql.AggregateFunc{
+//	        "count": ssql.Count(),
+//	    }),
+//	)(logs)
+func EventTimeWindow(sequenceField string, config EventTimeWindowConfig) Filter[Record, Record] {
+	cfg := config
+	if cfg.Slide <= 0 {
+		cfg.Slide = cfg.Size
+	}
+	if cfg.Watermark == nil {
+		cfg.Watermark = AscendingTimestamps()
+	}
+
+	return func(input iter.Seq[Record]) iter.Seq[Record] {
+		return func(yield func(Record) bool) {
+			if cfg.Size <= 0 {
+				return
+			}
+			w := &eventTimeWindower{cfg: cfg, sequenceField: sequenceField, windows: make(map[windowID]*eventWindow)}
+
+			for r := range input {
+				if !w.add(r, yield) {
+					return
+				}
+			}
+
+			// End of input: emit every window still waiting for the watermark
+			w.fire(func(*eventWindow) bool { return true }, yield)
+		}
+	}
+}
+
+// windowID identifies one window of one key
+type windowID struct {
+	key   string
+	start int64
+}
+
+// eventWindow is the state of one open window
+type eventWindow struct {
+	id          windowID
+	groupFields Record
+	start, end  time.Time
+	members     []Record
+	emitted     int   // times emitted so far
+	created     int64 // creation order, breaking ties between equal windows
+}
+
+// eventTimeWindower holds the state of one EventTimeWindow run
+type eventTimeWindower struct {
+	cfg           EventTimeWindowConfig
+	sequenceField string
+	windows       map[windowID]*eventWindow
+	created       int64
+	maxEventTime  time.Time
+	watermark     time.Time
+	hasWatermark  bool
+}
+
+// add assigns r to its windows and advances the watermark, emitting any
+// windows it completes. It returns false if yield asked to stop.
+func (w *eventTimeWindower) add(r Record, yield func(Record) bool) bool {
+	val, _ := r.lookup(w.cfg.TimeField)
+	t := parseTimeValue(val)
+	if t.IsZero() {
+		return true // Skip records without valid timestamps
+	}
+	key, groupFields, ok := groupKey(r, w.cfg.KeyFields)
+	if !ok {
+		return true
+	}
+
+	missed := false
+	for _, start := range w.windowStarts(t) {
+		end := start.Add(w.cfg.Size)
+		late := w.hasWatermark && !w.watermark.Before(end)
+		if late && (w.cfg.Late != LateUpdate || !w.watermark.Before(end.Add(w.cfg.AllowedLateness))) {
+			missed = true
+			continue
+		}
+
+		id := windowID{key, start.UnixNano()}
+		win, exists := w.windows[id]
+		if !exists {
+			win = &eventWindow{id: id, groupFields: groupFields, start: start, end: end, created: w.created}
+			w.created++
+			w.windows[id] = win
+		}
+		win.members = append(win.members, r)
+		if late && !w.emit(win, yield) {
+			return false
+		}
+	}
+	if missed && w.cfg.LateOutput != nil && w.cfg.Late != LateDrop {
+		w.cfg.LateOutput(r)
+	}
+
+	if t.After(w.maxEventTime) {
+		w.maxEventTime = t
+		if wm := w.cfg.Watermark(t); !w.hasWatermark || wm.After(w.watermark) {
+			w.watermark, w.hasWatermark = wm, true
+			if !w.fire(func(win *eventWindow) bool { return !wm.Before(win.end) }, yield) {
+				return false
+			}
+			w.purge()
+		}
+	}
+	return true
+}
+
+// windowStarts returns the starts of the windows containing t
+func (w *eventTimeWindower) windowStarts(t time.Time) []time.Time {
+	var starts []time.Time
+	earliest := t.Add(-w.cfg.Size)
+	for start := t.Truncate(w.cfg.Slide); start.After(earliest); start = start.Add(-w.cfg.Slide) {
+		starts = append(starts, start)
+	}
+	slices.Reverse(starts)
+	return starts
+}
+
+// fire emits the not yet emitted windows selected by ready, in order of
+// end time. It returns false if yield asked to stop.
+func (w *eventTimeWindower) fire(ready func(*eventWindow) bool, yield func(Record) bool) bool {
+	var due []*eventWindow
+	for _, win := range w.windows {
+		if win.emitted == 0 && ready(win) {
+			due = append(due, win)
+		}
+	}
+	slices.SortFunc(due, func(a, b *eventWindow) int {
+		if c := a.end.Compare(b.end); c != 0 {
+			return c
+		}
+		if c := a.start.Compare(b.start); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.created, b.created)
+	})
+	for _, win := range due {
+		if !w.emit(win, yield) {
+			return false
+		}
+	}
+	return true
+}
+
+// emit yields the window's output record
+func (w *eventTimeWindower) emit(win *eventWindow, yield func(Record) bool) bool {
+	result := MakeMutableRecord()
+	for k, v := range win.groupFields.All() {
+		result.set(k, v)
+	}
+	result.set(WindowStartField, win.start)
+	result.set(WindowEndField, win.end)
+	if win.emitted > 0 {
+		result.set(WindowUpdateField, int64(win.emitted))
+	}
+	result.set(w.sequenceField, slices.Values(slices.Clone(win.members)))
+	win.emitted++
+	return yield(result.Freeze())
+}
+
+// purge drops emitted windows that can no longer be updated
+func (w *eventTimeWindower) purge() {
+	lateness := time.Duration(0)
+	if w.cfg.Late == LateUpdate {
+		lateness = w.cfg.AllowedLateness
+	}
+	for id, win := range w.windows {
+		if win.emitted > 0 && !w.watermark.Before(win.end.Add(lateness)) {
+			delete(w.windows, id)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package ssql
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+// ============================================================================
+// EVENT-TIME WINDOW TESTS
+// ============================================================================
+
+// eventAt builds a record with an event time base+seconds and an id
+func eventAt(base time.Time, seconds int, id string) Record {
+	return MakeMutableRecord().Time("ts", base.Add(time.Duration(seconds)*time.Second)).String("id", id).Freeze()
+}
+
+// describeWindows renders window records as "start-end:ids[/update]"
+func describeWindows(base time.Time, windows []Record) []string {
+	var out []string
+	for _, w := range windows {
+		start := int(GetOr(w, WindowStartField, time.Time{}).Sub(base) / time.Second)
+		end := int(GetOr(w, WindowEndField, time.Time{}).Sub(base) / time.Second)
+		var ids string
+		for r := range GetOr(w, "events", slices.Values([]Record{})) {
+			ids += GetOr(r, "id", "")
+		}
+		desc := fmt.Sprintf("%d-%d:%s", start, end, ids)
+		if update, ok := Get[int64](w, WindowUpdateField); ok {
+			desc += fmt.Sprintf("/%d", update)
+		}
+		if host, ok := Get[string](w, "host"); ok {
+			desc = host + "@" + desc
+		}
+		out = append(out, desc)
+	}
+	return out
+}
+
+func TestEventTimeWindowOutOfOrder(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	events := []Record{
+		eventAt(base, 1, "a"),
+		eventAt(base, 12, "b"),
+		eventAt(base, 8, "c"), // out of order, within the 5s bound
+		eventAt(base, 21, "d"),
+		eventAt(base, 3, "e"), // late: window 0-10 was emitted at watermark 16
+	}
+
+	var late []string
+	windows := slices.Collect(EventTimeWindow("events", EventTimeWindowConfig{
+		TimeField:  "ts",
+		Size:       10 * time.Second,
+		Watermark:  BoundedOutOfOrderness(5 * time.Second),
+		Late:       LateSideOutput,
+		LateOutput: func(r Record) { late = append(late, GetOr(r, "id", "")) },
+	})(slices.Values(events)))
+
+	got := describeWindows(base, windows)
+	if !slices.Equal(got, []string{"0-10:ac", "10-20:b", "20-30:d"}) {
+		t.Errorf("Unexpected windows %v", got)
+	}
+	if !slices.Equal(late, []string{"e"}) {
+		t.Errorf("Expected e on the side output, got %v", late)
+	}
+
+	// Without a bound, the out-of-order record is late and dropped
+	windows = slices.Collect(EventTimeWindow("events", EventTimeWindowConfig{
+		TimeField: "ts",
+		Size:      10 * time.Second,
+	})(slices.Values(events)))
+	if got := describeWindows(base, windows); !slices.Equal(got, []string{"0-10:a", "10-20:b", "20-30:d"}) {
+		t.Errorf("Unexpected windows with ascending watermark %v", got)
+	}
+}
+
+func TestEventTimeWindowLateUpdate(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	events := []Record{
+		eventAt(base, 1, "a"),
+		eventAt(base, 11, "b"), // watermark 11: emits 0-10
+		eventAt(base, 4, "c"),  // late, within 5s lateness: re-emit 0-10
+		eventAt(base, 16, "d"), // watermark 16: 0-10 closed for good
+		eventAt(base, 5, "e"),  // too late
+	}
+
+	var tooLate []string
+	windows := slices.Collect(EventTimeWindow("events", EventTimeWindowConfig{
+		TimeField:       "ts",
+		Size:            10 * time.Second,
+		AllowedLateness: 5 * time.Second,
+		Late:            LateUpdate,
+		LateOutput:      func(r Record) { tooLate = append(tooLate, GetOr(r, "id", "")) },
+	})(slices.Values(events)))
+
+	got := describeWindows(base, windows)
+	if !slices.Equal(got, []string{"0-10:a", "0-10:ac/1", "10-20:bd"}) {
+		t.Errorf("Unexpected windows %v", got)
+	}
+	if !slices.Equal(tooLate, []string{"e"}) {
+		t.Errorf("Expected e too late, got %v", tooLate)
+	}
+}
+
+func TestEventTimeWindowSlidingKeyed(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	host := func(seconds int, name, id string) Record {
+		return eventAt(base, seconds, id).ToMutable().String("host", name).Freeze()
+	}
+	events := []Record{host(1, "x", "a"), host(6, "y", "b"), host(7, "x", "c")}
+
+	windows := slices.Collect(EventTimeWindow("events", EventTimeWindowConfig{
+		TimeField: "ts",
+		Size:      10 * time.Second,
+		Slide:     5 * time.Second,
+		KeyFields: []string{"host"},
+	})(slices.Values(events)))
+
+	got := describeWindows(base, windows)
+	want := []string{"x@-5-5:a", "x@0-10:ac", "y@0-10:b", "y@5-15:b", "x@5-15:c"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Sliding windows = %v, want %v", got, want)
+	}
+
+	// Output feeds Aggregate like GroupByFields
+	counts := slices.Collect(Aggregate("events", map[string]AggregateFunc{"n": Count()})(slices.Values(windows)))
+	if GetOr(counts[1], "n", int64(0)) != 2 || GetOr(counts[1], "host", "") != "x" {
+		t.Errorf("Unexpected aggregate %v", counts[1])
+	}
+	if _, ok := Get[any](counts[1], "events"); ok {
+		t.Errorf("Aggregate should drop the sequence field: %v", counts[1])
+	}
+
+	// Early stop
+	if first := slices.Collect(Limit[Record](1)(EventTimeWindow("events", EventTimeWindowConfig{TimeField: "ts", Size: time.Second})(slices.Values(events)))); len(first) != 1 {
+		t.Errorf("Expected 1 window after Limit, got %d", len(first))
+	}
+}
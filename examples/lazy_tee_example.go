@@ -218,6 +218,6 @@ func testDifferentSpeeds() {
 	// Wait for processing to complete
 	time.Sleep(500 * time.Millisecond)
 
-	fmt.Println("\n💡 Note: LazyTee may drop data for slow consumers to prevent blocking")
-	fmt.Println("    This is a trade-off for handling infinite streams efficiently")
+	fmt.Println("\n💡 Note: by default LazyTee holds the producer back for slow consumers")
+	fmt.Println("    Use LazyTeeConfig.Backpressure to drop data for them instead")
 }
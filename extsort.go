@@ -0,0 +1,274 @@
+package ssql
+
+import (
+	"cmp"
+	"container/heap"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"slices"
+)
+
+// ============================================================================
+// EXTERNAL (DISK-SPILLING) SORT
+// ============================================================================
+
+// DefaultSortMemory is the memory budget ExternalSortBy uses when none is set
+const DefaultSortMemory = 64 << 20 // 64 MiB
+
+// ExternalSortConfig configures ExternalSortBy
+type ExternalSortConfig struct {
+	// MemoryLimit is the approximate number of bytes of records held in
+	// memory before a sorted run is written to disk. Zero or less uses
+	// DefaultSortMemory.
+	MemoryLimit int64
+	// TempDir is the directory for run files. Empty uses os.TempDir().
+	TempDir string
+}
+
+// ExternalSortBy sorts records by key like SortBy, but holds at most about
+// MemoryLimit bytes of records in memory. Input beyond the budget is cut
+// into sorted runs written to temporary files, which are then merged.
+// Input that fits in the budget is sorted in memory without touching disk.
+// The sort is stable and run files are removed when iteration ends.
+//
+// Fields may hold any record value, including the iter.Seq fields built by
+// FromStructs and the nested maps and slices ReadJSON decodes; sequence
+// fields are materialized when a run is written.
+// ExternalSortBy panics if a run cannot be written or read back; use
+// ExternalSortBySafe to handle those errors.
+//
+// Example:
+//
+//	logs, _ := ssql.ReadCSV("export.csv")
+//	sorted := ssql.ExternalSortBy(func(r ssql.Record) string {
+//	    return ssql.GetOr(r, "timestamp", "")
+//	}, ssql.ExternalSortConfig{MemoryLimit: 512 << 20})(logs)
+func ExternalSortBy[K cmp.Ordered](keyFn func(Record) K, config ...ExternalSortConfig) Filter[Record, Record] {
+	return func(input iter.Seq[Record]) iter.Seq[Record] {
+		return Unsafe(ExternalSortBySafe(keyFn, config...)(Safe(input)))
+	}
+}
+
+// ExternalSortBySafe sorts records like ExternalSortBy, yielding errors
+// from the input as they are read and a final error if a run file cannot
+// be written or read.
+func ExternalSortBySafe[K cmp.Ordered](keyFn func(Record) K, config ...ExternalSortConfig) FilterWithErrors[Record, Record] {
+	cfg := ExternalSortConfig{}
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	return externalSort(keyFn, cmp.Compare[K], cfg)
+}
+
+// ExternalSortByKeys sorts records like SortByKeys within the memory
+// budget of config, spilling sorted runs to disk as ExternalSortBy does.
+//
+// Example:
+//
+//	sorted := ssql.ExternalSortByKeys(ssql.ExternalSortConfig{MemoryLimit: 1 << 30},
+//	    ssql.Asc("region"), ssql.Desc("revenue"))(sales)
+func ExternalSortByKeys(config ExternalSortConfig, keys ...SortKey) Filter[Record, Record] {
+	return func(input iter.Seq[Record]) iter.Seq[Record] {
+		return Unsafe(ExternalSortByKeysSafe(config, keys...)(Safe(input)))
+	}
+}
+
+// ExternalSortByKeysSafe sorts records like ExternalSortByKeys, yielding
+// input errors and run file errors like ExternalSortBySafe.
+func ExternalSortByKeysSafe(config ExternalSortConfig, keys ...SortKey) FilterWithErrors[Record, Record] {
+	return externalSort(func(r Record) []any { return sortKeyValues(keys, r) }, compareKeyValues(keys), config)
+}
+
+// externalSort builds an external sort ordering records by compare on
+// the keys extracted by keyFn
+func externalSort[K any](keyFn func(Record) K, compare func(a, b K) int, cfg ExternalSortConfig) FilterWithErrors[Record, Record] {
+	if cfg.MemoryLimit <= 0 {
+		cfg.MemoryLimit = DefaultSortMemory
+	}
+
+	return func(input iter.Seq2[Record, error]) iter.Seq2[Record, error] {
+		return func(yield func(Record, error) bool) {
+			s := &externalSorter[K]{keyFn: keyFn, compare: compare, cfg: cfg}
+			defer s.cleanup()
+
+			for r, err := range input {
+				if err != nil {
+					if !yield(r, err) {
+						return
+					}
+					continue
+				}
+				s.buffer = append(s.buffer, r)
+				s.size += estimateRecordSize(r)
+				if s.size >= cfg.MemoryLimit {
+					if err := s.spill(); err != nil {
+						yield(Record{}, err)
+						return
+					}
+				}
+			}
+
+			if len(s.runs) == 0 {
+				// Everything fit in memory
+				for _, r := range s.sortBuffer() {
+					if !yield(r, nil) {
+						return
+					}
+				}
+				return
+			}
+			if len(s.buffer) > 0 {
+				if err := s.spill(); err != nil {
+					yield(Record{}, err)
+					return
+				}
+			}
+			if err := s.merge(yield); err != nil {
+				yield(Record{}, err)
+			}
+		}
+	}
+}
+
+// externalSorter holds the state of one ExternalSortBySafe run
+type externalSorter[K any] struct {
+	keyFn   func(Record) K
+	compare func(a, b K) int
+	cfg     ExternalSortConfig
+	buffer  []Record
+	size    int64
+	runs    []*os.File
+}
+
+// sortBuffer stably sorts the buffered records by key
+func (s *externalSorter[K]) sortBuffer() []Record {
+	type keyed struct {
+		key    K
+		record Record
+	}
+	items := make([]keyed, len(s.buffer))
+	for i, r := range s.buffer {
+		items[i] = keyed{s.keyFn(r), r}
+	}
+	slices.SortStableFunc(items, func(a, b keyed) int { return s.compare(a.key, b.key) })
+	for i, item := range items {
+		s.buffer[i] = item.record
+	}
+	return s.buffer
+}
+
+// spill writes the buffered records to a new sorted run file
+func (s *externalSorter[K]) spill() error {
+	file, err := os.CreateTemp(s.cfg.TempDir, "ssql-sort-*.run")
+	if err != nil {
+		return fmt.Errorf("external sort: creating run file: %w", err)
+	}
+	s.runs = append(s.runs, file)
+
+	w := newRecordWriter(file)
+	for _, r := range s.sortBuffer() {
+		if err := w.Write(r); err != nil {
+			return fmt.Errorf("external sort: writing run: %w", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("external sort: writing run: %w", err)
+	}
+
+	clear(s.buffer)
+	s.buffer = s.buffer[:0]
+	s.size = 0
+	return nil
+}
+
+// cleanup closes and removes the run files
+func (s *externalSorter[K]) cleanup() {
+	for _, file := range s.runs {
+		file.Close()
+		os.Remove(file.Name())
+	}
+}
+
+// merge k-way merges the run files into yield
+func (s *externalSorter[K]) merge(yield func(Record, error) bool) error {
+	h := &runHeap[K]{compare: s.compare}
+	advance := func(c *runCursor[K]) (bool, error) {
+		r, err := c.reader.Read()
+		if err == io.EOF {
+			return false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("external sort: reading run: %w", err)
+		}
+		c.record, c.key = r, s.keyFn(r)
+		return true, nil
+	}
+
+	for i, file := range s.runs {
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return fmt.Errorf("external sort: rewinding run: %w", err)
+		}
+		c := &runCursor[K]{run: i, reader: newRecordReader(file)}
+		ok, err := advance(c)
+		if err != nil {
+			return err
+		}
+		if ok {
+			h.cursors = append(h.cursors, c)
+		}
+	}
+	heap.Init(h)
+
+	for h.Len() > 0 {
+		c := h.cursors[0]
+		if !yield(c.record, nil) {
+			return nil
+		}
+		ok, err := advance(c)
+		if err != nil {
+			return err
+		}
+		if ok {
+			heap.Fix(h, 0)
+		} else {
+			heap.Pop(h)
+		}
+	}
+	return nil
+}
+
+// runCursor is the next unmerged record of a run file
+type runCursor[K any] struct {
+	run    int
+	reader *recordReader
+	record Record
+	key    K
+}
+
+// runHeap orders cursors by key, then by run so equal keys keep input order
+type runHeap[K any] struct {
+	cursors []*runCursor[K]
+	compare func(a, b K) int
+}
+
+func (h *runHeap[K]) Len() int { return len(h.cursors) }
+
+func (h *runHeap[K]) Less(i, j int) bool {
+	a, b := h.cursors[i], h.cursors[j]
+	if c := h.compare(a.key, b.key); c != 0 {
+		return c < 0
+	}
+	return a.run < b.run
+}
+
+func (h *runHeap[K]) Swap(i, j int) { h.cursors[i], h.cursors[j] = h.cursors[j], h.cursors[i] }
+
+func (h *runHeap[K]) Push(x any) { h.cursors = append(h.cursors, x.(*runCursor[K])) }
+
+func (h *runHeap[K]) Pop() any {
+	last := h.cursors[len(h.cursors)-1]
+	h.cursors = h.cursors[:len(h.cursors)-1]
+	return last
+}
@@ -0,0 +1,206 @@
+package ssql
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ============================================================================
+// EXTERNAL SORT TESTS
+// ============================================================================
+
+func TestRecordCodecRoundTrip(t *testing.T) {
+	when := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+	price, _ := ParseDecimal("19.99")
+	nested := MakeMutableRecord().String("city", "Oslo").Int("zip", 150).Freeze()
+	original := MakeMutableRecord().
+		String("name", "widget").
+		Int("qty", -42).
+		Float("ratio", 0.25).
+		Bool("active", true).
+		Time("created", when).
+		Decimal("price", price).
+		Null("note").
+		JSONString("tags", JSONString(`["a","b"]`)).
+		Nested("address", nested).
+		StringSeq("labels", slices.Values([]string{"x", "y"})).
+		RecordSeq("items", slices.Values([]Record{nested})).
+		Freeze()
+
+	var buf bytes.Buffer
+	w := newRecordWriter(&buf)
+	if err := w.Write(original); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := w.Write(MakeMutableRecord().Freeze()); err != nil {
+		t.Fatalf("Write empty: %v", err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	r := newRecordReader(&buf)
+	decoded, err := r.Read()
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if !slices.Equal(decoded.Keys(), original.Keys()) {
+		t.Errorf("Field order changed: %v", decoded.Keys())
+	}
+	if d := DiffRecords(original, decoded); !d.Empty() {
+		t.Errorf("Round trip changed fields: %+v", d)
+	}
+	if empty, err := r.Read(); err != nil || empty.Len() != 0 {
+		t.Errorf("Expected empty record, got %v, %v", empty, err)
+	}
+	if _, err := r.Read(); err != io.EOF {
+		t.Errorf("Expected io.EOF, got %v", err)
+	}
+
+	if _, err := appendRecord(nil, NewRecord(map[string]any{"bad": []int{1}})); err == nil {
+		t.Error("Expected error encoding an unsupported value type")
+	}
+}
+
+func TestRecordCodecContainerValues(t *testing.T) {
+	type celsius float64
+	original := NewRecord(map[string]any{
+		"object":  map[string]any{"city": "Oslo", "zip": float64(150), "tags": []any{"a", nil, true}},
+		"array":   []any{float64(1), map[string]any{"k": "v"}, nil},
+		"ints":    slices.Values([]int{3, -1, 4}),
+		"bytes":   slices.Values([]uint8{0, 255}),
+		"wide":    slices.Values([]uint64{1 << 63}),
+		"small":   slices.Values([]float32{0.5, -2}),
+		"celsius": celsius(21.5),
+	})
+
+	buf, err := appendRecord(nil, original)
+	if err != nil {
+		t.Fatalf("appendRecord: %v", err)
+	}
+	decoded, err := newRecordReader(bytes.NewReader(buf)).Read()
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	expected := original.ToMutable().Float("celsius", 21.5).Freeze()
+	if d := DiffRecords(expected, decoded); !d.Empty() {
+		t.Errorf("Round trip changed fields: %+v", d)
+	}
+	if _, ok := decoded.fields["ints"].(iter.Seq[int]); !ok {
+		t.Errorf("Expected iter.Seq[int], got %T", decoded.fields["ints"])
+	}
+}
+
+// sortSpillsLike checks that ExternalSortBy, forced to spill every
+// record, yields the same records as SortBy
+func sortSpillsLike(t *testing.T, records []Record, key func(Record) string) {
+	t.Helper()
+	dir := t.TempDir()
+	spilled := slices.Collect(ExternalSortBy(key, ExternalSortConfig{MemoryLimit: 1, TempDir: dir})(slices.Values(records)))
+	expected := slices.Collect(SortBy(key)(slices.Values(records)))
+	if len(spilled) != len(expected) {
+		t.Fatalf("Expected %d records, got %d", len(expected), len(spilled))
+	}
+	for i := range spilled {
+		if d := DiffRecords(expected[i], spilled[i]); !d.Empty() {
+			t.Errorf("Record %d changed through spill: %+v", i, d)
+		}
+	}
+}
+
+func TestExternalSortBySpillsJSONRecords(t *testing.T) {
+	input := `{"id": "b", "user": {"name": "Ann", "roles": ["admin", "dev"]}, "scores": [1.5, 2, null]}
+{"id": "a", "user": {"name": "Bob", "roles": []}, "scores": [], "price": 19.90}
+{"id": "c", "user": null, "scores": [{"k": 1}]}
+`
+	key := func(r Record) string { return GetOr(r, "id", "") }
+	for _, decimals := range []bool{false, true} {
+		records := slices.Collect(ReadJSONFromReader(strings.NewReader(input), JSONConfig{Decimals: decimals}))
+		sortSpillsLike(t, records, key)
+	}
+}
+
+func TestExternalSortBySpillsStructRecords(t *testing.T) {
+	type reading struct {
+		Sensor  string
+		Samples []int
+		Weights []float32
+		Raw     []byte
+		Labels  []string
+	}
+	readings := []reading{
+		{Sensor: "s2", Samples: []int{3, 1}, Weights: []float32{0.5}, Raw: []byte{1, 2}, Labels: []string{"x"}},
+		{Sensor: "s1", Samples: []int{7}, Weights: nil, Raw: []byte{}, Labels: nil},
+		{Sensor: "s3", Samples: []int{}, Weights: []float32{1, 2}, Raw: []byte{255}, Labels: []string{"y", "z"}},
+	}
+	records := slices.Collect(FromStructs(slices.Values(readings)))
+	sortSpillsLike(t, records, func(r Record) string { return GetOr(r, "Sensor", "") })
+}
+
+func TestExternalSortBy(t *testing.T) {
+	dir := t.TempDir()
+	var records []Record
+	for i := range 2000 {
+		records = append(records, MakeMutableRecord().
+			Int("key", int64((i*7919)%100)).
+			Int("seq", int64(i)).
+			String("payload", fmt.Sprintf("row-%04d", i)).
+			Freeze())
+	}
+	key := func(r Record) int64 { return GetOr(r, "key", int64(0)) }
+
+	// A budget of a few KB forces many runs
+	sorted := slices.Collect(ExternalSortBy(key, ExternalSortConfig{MemoryLimit: 8 << 10, TempDir: dir})(slices.Values(records)))
+	expected := slices.Collect(SortBy(key)(slices.Values(records)))
+	if len(sorted) != len(expected) {
+		t.Fatalf("Expected %d records, got %d", len(expected), len(sorted))
+	}
+	for i := range sorted {
+		if key(sorted[i]) != key(expected[i]) {
+			t.Fatalf("Record %d out of order: key %d, expected %d", i, key(sorted[i]), key(expected[i]))
+		}
+		// Stable: equal keys keep input order
+		if i > 0 && key(sorted[i]) == key(sorted[i-1]) && GetOr(sorted[i], "seq", int64(0)) < GetOr(sorted[i-1], "seq", int64(0)) {
+			t.Fatalf("Sort is not stable at record %d", i)
+		}
+	}
+	if GetOr(sorted[0], "payload", "") == "" {
+		t.Error("Fields lost through spill")
+	}
+
+	// Early stop removes the run files too
+	for range Limit[Record](3)(ExternalSortBy(key, ExternalSortConfig{MemoryLimit: 8 << 10, TempDir: dir})(slices.Values(records))) {
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("Expected run files to be removed, found %d", len(entries))
+	}
+}
+
+func TestExternalSortBySafeErrors(t *testing.T) {
+	records := []Record{
+		NewRecord(map[string]any{"n": int64(2), "bad": []int{1}}),
+		MakeMutableRecord().Int("n", 1).Freeze(),
+	}
+	var errs []error
+	for _, err := range ExternalSortBySafe(func(r Record) int64 { return GetOr(r, "n", int64(0)) }, ExternalSortConfig{MemoryLimit: 1, TempDir: t.TempDir()})(Safe(slices.Values(records))) {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) != 1 {
+		t.Errorf("Expected one spill error for an unencodable field, got %v", errs)
+	}
+
+	// Small inputs are sorted in memory, so any field type works
+	inMemory := slices.Collect(ExternalSortBy(func(r Record) int64 { return GetOr(r, "n", int64(0)) })(slices.Values(records)))
+	if len(inMemory) != 2 || GetOr(inMemory[0], "n", int64(0)) != 1 {
+		t.Errorf("Unexpected in-memory sort %v", inMemory)
+	}
+}
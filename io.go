@@ -3,6 +3,7 @@ package ssql
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
@@ -10,7 +11,6 @@ import (
 	"iter"
 	"os"
 	"os/exec"
-	"slices"
 	"strconv"
 	"strings"
 )
@@ -28,7 +28,9 @@ type CSVConfig struct {
 	HasHeaders bool
 	Delimiter  rune
 	Comment    rune
-	Fields     []string // Optional: fields to write (nil = auto-detect all fields in alphabetical order)
+	Fields     []string // Optional: fields to write (nil = auto-detect all fields in field order)
+	Schema     Schema   // Optional: column types for reading (empty = infer the type of each value)
+	Decimals   bool     // Optional: infer non-integer numbers as Decimal instead of float64
 }
 
 // DefaultCSVConfig provides sensible defaults for CSV processing
@@ -75,23 +77,11 @@ func ReadCSVFromReader(reader io.Reader, config ...CSVConfig) iter.Seq[Record] {
 				return // EOF or error
 			}
 
-			record := MakeMutableRecord()
-			if cfg.HasHeaders && len(headers) > 0 {
-				// Use headers as field names
-				for i, value := range row {
-					if i < len(headers) {
-						record.fields[headers[i]] = parseValue(value)
-					}
-				}
-			} else {
-				// Generate column names: col_0, col_1, etc.
-				for i, value := range row {
-					record.fields[fmt.Sprintf("col_%d", i)] = parseValue(value)
-				}
-			}
+			// For simple API, keep unparseable cells as strings
+			record, _ := csvRowToRecord(row, headers, cfg)
 
 			// Add row number
-			record.fields["_row_number"] = rowIndex
+			record.set("_row_number", rowIndex)
 			rowIndex++
 
 			if !yield(record.Freeze()) {
@@ -132,26 +122,27 @@ func ReadCSVSafeFromReader(reader io.Reader, config ...CSVConfig) iter.Seq2[Reco
 				return
 			}
 			if err != nil {
-				if !yield(Record{}, fmt.Errorf("failed to read CSV row %d: %w", rowIndex, err)) {
+				recErr := &RecordError{Stage: "read-csv", Position: rowIndex, Err: fmt.Errorf("failed to read CSV row %d: %w", rowIndex, err)}
+				if row != nil {
+					recErr.Input = row
+				}
+				if !yield(Record{}, recErr) {
 					return
 				}
 				continue
 			}
 
-			record := MakeMutableRecord()
-			if cfg.HasHeaders && len(headers) > 0 {
-				for i, value := range row {
-					if i < len(headers) {
-						record.fields[headers[i]] = parseValue(value)
-					}
-				}
-			} else {
-				for i, value := range row {
-					record.fields[fmt.Sprintf("col_%d", i)] = parseValue(value)
+			record, parseErr := csvRowToRecord(row, headers, cfg)
+			if parseErr != nil {
+				recErr := &RecordError{Stage: "read-csv", Position: rowIndex, Input: row, Err: fmt.Errorf("failed to parse CSV row %d: %w", rowIndex, parseErr)}
+				if !yield(Record{}, recErr) {
+					return
 				}
+				rowIndex++
+				continue
 			}
 
-			record.fields["_row_number"] = rowIndex
+			record.set("_row_number", rowIndex)
 			rowIndex++
 
 			if !yield(record.Freeze(), nil) {
@@ -161,6 +152,44 @@ func ReadCSVSafeFromReader(reader io.Reader, config ...CSVConfig) iter.Seq2[Reco
 	}
 }
 
+// csvRowToRecord converts a CSV row to a record.
+// Columns described by cfg.Schema are parsed as their declared type; all
+// other columns use type inference. Returns the first schema parse error,
+// in which case the offending cell is kept as a string.
+func csvRowToRecord(row, headers []string, cfg CSVConfig) (MutableRecord, error) {
+	record := MakeMutableRecordWithCapacity(len(row) + 1)
+	var firstErr error
+	for i, value := range row {
+		var name string
+		if cfg.HasHeaders && len(headers) > 0 {
+			// Use headers as field names
+			if i >= len(headers) {
+				continue
+			}
+			name = headers[i]
+		} else {
+			// Generate column names: col_0, col_1, etc.
+			name = fmt.Sprintf("col_%d", i)
+		}
+
+		field, typed := cfg.Schema.Field(name)
+		if !typed {
+			if cfg.Decimals {
+				record.set(name, parseValueDecimal(value))
+			} else {
+				record.set(name, parseValue(value))
+			}
+			continue
+		}
+		parsed, err := parseTypedValue(value, field)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		record.set(name, parsed)
+	}
+	return record, firstErr
+}
+
 // WriteCSVToWriter writes records as CSV to an io.Writer
 func WriteCSVToWriter(sb iter.Seq[Record], writer io.Writer, config ...CSVConfig) error {
 	cfg := DefaultCSVConfig()
@@ -180,29 +209,24 @@ func WriteCSVToWriter(sb iter.Seq[Record], writer io.Writer, config ...CSVConfig
 		fields = cfg.Fields
 	} else {
 		// Auto-detect: materialize all records to collect unique field names
+		// in the order they first appear (field order within a record)
 		fieldSet := make(map[string]bool)
 		for record := range sb {
 			recordsBuffer = append(recordsBuffer, record)
-			for field := range record.All() {
+			for field, val := range record.All() {
 				// Skip complex fields (iter.Seq, Record) and internal metadata fields
-				if val, exists := record.fields[field]; exists && !isIterSeq(val) {
-					if _, isRecord := val.(Record); !isRecord {
-						// Skip internal metadata fields starting with underscore
-						if !strings.HasPrefix(field, "_") {
-							fieldSet[field] = true
-						}
+				if fieldSet[field] || isIterSeq(val) {
+					continue
+				}
+				if _, isRecord := val.(Record); !isRecord {
+					// Skip internal metadata fields starting with underscore
+					if !strings.HasPrefix(field, "_") {
+						fieldSet[field] = true
+						fields = append(fields, field)
 					}
 				}
 			}
 		}
-
-		// Sort field names alphabetically for consistent ordering
-		for field := range fieldSet {
-			fields = append(fields, field)
-		}
-
-		// Sort using standard library
-		slices.Sort(fields)
 	}
 
 	// Write headers if enabled
@@ -319,8 +343,30 @@ func ReadCSVSafe(filename string, config ...CSVConfig) iter.Seq2[Record, error]
 	}
 }
 
+// ReadCSVCtx reads a CSV file like ReadCSV and stops when ctx is cancelled
+//
+// Example:
+//
+//	data, err := ssql.ReadCSVCtx(r.Context(), "large.csv")
+//	if err != nil {
+//	    return err
+//	}
+func ReadCSVCtx(ctx context.Context, filename string, config ...CSVConfig) (iter.Seq[Record], error) {
+	seq, err := ReadCSV(filename, config...)
+	if err != nil {
+		return nil, err
+	}
+	return WithContext[Record](ctx)(seq), nil
+}
+
+// ReadCSVSafeCtx reads a CSV file like ReadCSVSafe and stops when ctx is
+// cancelled, yielding ctx.Err() as the final error
+func ReadCSVSafeCtx(ctx context.Context, filename string, config ...CSVConfig) iter.Seq2[Record, error] {
+	return WithContextSafe[Record](ctx)(ReadCSVSafe(filename, config...))
+}
+
 // WriteCSV writes records to a CSV file.
-// Field names are auto-detected and sorted alphabetically unless specified in config.
+// Field names are auto-detected in field order unless specified in config.
 //
 // Example:
 //
@@ -363,8 +409,19 @@ func WriteCSV(sb iter.Seq[Record], filename string, config ...CSVConfig) error {
 // JSON OPERATIONS WITH IO.READER/IO.WRITER
 // ============================================================================
 
+// JSONConfig configures JSON reading
+type JSONConfig struct {
+	Schema   Schema // Optional: field types (empty = keep the types decoded from JSON)
+	Decimals bool   // Optional: decode non-integer numbers as Decimal instead of float64
+}
+
 // ReadJSONFromReader reads JSON records from an io.Reader (one JSON object per line)
-func ReadJSONFromReader(reader io.Reader) iter.Seq[Record] {
+func ReadJSONFromReader(reader io.Reader, config ...JSONConfig) iter.Seq[Record] {
+	var cfg JSONConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+
 	return func(yield func(Record) bool) {
 		scanner := bufio.NewScanner(reader)
 		lineNumber := int64(0)
@@ -376,15 +433,20 @@ func ReadJSONFromReader(reader io.Reader) iter.Seq[Record] {
 				continue
 			}
 
-			var record Record
-			if err := json.Unmarshal([]byte(line), &record); err != nil {
+			record, err := decodeJSONRecord(line, cfg)
+			if err != nil {
 				// For simple API, skip invalid JSON lines
 				lineNumber++
 				continue
 			}
 
+			// For simple API, keep values that don't fit the schema as decoded
+			if cfg.Schema.Len() > 0 {
+				record, _ = cfg.Schema.Coerce(record)
+			}
+
 			// Add line number metadata
-			record.fields["_line_number"] = lineNumber
+			record.set("_line_number", lineNumber)
 			lineNumber++
 
 			if !yield(record) {
@@ -395,7 +457,12 @@ func ReadJSONFromReader(reader io.Reader) iter.Seq[Record] {
 }
 
 // ReadJSONSafeFromReader reads JSON records from an io.Reader with error handling
-func ReadJSONSafeFromReader(reader io.Reader) iter.Seq2[Record, error] {
+func ReadJSONSafeFromReader(reader io.Reader, config ...JSONConfig) iter.Seq2[Record, error] {
+	var cfg JSONConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+
 	return func(yield func(Record, error) bool) {
 		scanner := bufio.NewScanner(reader)
 		lineNumber := int64(0)
@@ -407,16 +474,30 @@ func ReadJSONSafeFromReader(reader io.Reader) iter.Seq2[Record, error] {
 				continue
 			}
 
-			var record Record
-			if err := json.Unmarshal([]byte(line), &record); err != nil {
-				if !yield(Record{}, fmt.Errorf("failed to parse JSON on line %d: %w", lineNumber, err)) {
+			record, err := decodeJSONRecord(line, cfg)
+			if err != nil {
+				recErr := &RecordError{Stage: "read-json", Position: lineNumber, Input: line, Err: fmt.Errorf("failed to parse JSON on line %d: %w", lineNumber, err)}
+				if !yield(Record{}, recErr) {
 					return
 				}
 				lineNumber++
 				continue
 			}
 
-			record.fields["_line_number"] = lineNumber
+			if cfg.Schema.Len() > 0 {
+				coerced, schemaErr := cfg.Schema.Coerce(record)
+				if schemaErr != nil {
+					recErr := &RecordError{Stage: "read-json", Position: lineNumber, Input: line, Err: fmt.Errorf("line %d: %w", lineNumber, schemaErr)}
+					if !yield(Record{}, recErr) {
+						return
+					}
+					lineNumber++
+					continue
+				}
+				record = coerced
+			}
+
+			record.set("_line_number", lineNumber)
 			lineNumber++
 
 			if !yield(record, nil) {
@@ -442,17 +523,17 @@ func WriteJSONToWriter(sb iter.Seq[Record], writer io.Writer) error {
 			case JSONString:
 				// Parse JSONString back to structured data to avoid double-encoding
 				if parsed, err := v.Parse(); err == nil {
-					jsonRecord.fields[key] = parsed
+					jsonRecord.set(key, parsed)
 				} else {
 					// Fallback to string if parsing fails
-					jsonRecord.fields[key] = string(v)
+					jsonRecord.set(key, string(v))
 				}
 			default:
 				if isIterSeq(value) {
 					// Convert iter.Seq to array for JSON
-					jsonRecord.fields[key] = materializeSequence(value)
+					jsonRecord.set(key, materializeSequence(value))
 				} else {
-					jsonRecord.fields[key] = value
+					jsonRecord.set(key, value)
 				}
 			}
 		}
@@ -488,7 +569,7 @@ func WriteJSONToWriter(sb iter.Seq[Record], writer io.Writer) error {
 //	    timestamp := ssql.GetOr(record, "timestamp", "")
 //	    fmt.Printf("%s: %s\n", timestamp, eventType)
 //	}
-func ReadJSON(filename string) (iter.Seq[Record], error) {
+func ReadJSON(filename string, config ...JSONConfig) (iter.Seq[Record], error) {
 	file, err := os.Open(filename)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open file %s: %w", filename, err)
@@ -498,7 +579,7 @@ func ReadJSON(filename string) (iter.Seq[Record], error) {
 		defer file.Close()
 
 		// Use the io.Reader version
-		for record := range ReadJSONFromReader(file) {
+		for record := range ReadJSONFromReader(file, config...) {
 			if !yield(record) {
 				return
 			}
@@ -509,7 +590,7 @@ func ReadJSON(filename string) (iter.Seq[Record], error) {
 }
 
 // ReadJSONSafe reads JSON with error handling
-func ReadJSONSafe(filename string) iter.Seq2[Record, error] {
+func ReadJSONSafe(filename string, config ...JSONConfig) iter.Seq2[Record, error] {
 	return func(yield func(Record, error) bool) {
 		file, err := os.Open(filename)
 		if err != nil {
@@ -520,35 +601,28 @@ func ReadJSONSafe(filename string) iter.Seq2[Record, error] {
 		}
 		defer file.Close()
 
-		scanner := bufio.NewScanner(file)
-		lineNum := 0
-		for scanner.Scan() {
-			line := strings.TrimSpace(scanner.Text())
-			if line == "" {
-				continue
-			}
-
-			var record Record
-			if err := json.Unmarshal([]byte(line), &record); err != nil {
-				if !yield(Record{}, fmt.Errorf("invalid JSON on line %d: %w", lineNum, err)) {
-					return
-				}
-				continue
-			}
-
-			// Add line metadata
-			record.fields["_line_number"] = int64(lineNum)
-			lineNum++
-
-			if !yield(record, nil) {
+		// Use the io.Reader version, so positions count every line
+		for record, err := range ReadJSONSafeFromReader(file, config...) {
+			if !yield(record, err) {
 				return
 			}
 		}
+	}
+}
 
-		if err := scanner.Err(); err != nil {
-			yield(Record{}, fmt.Errorf("error reading file: %w", err))
-		}
+// ReadJSONCtx reads a JSON lines file like ReadJSON and stops when ctx is cancelled
+func ReadJSONCtx(ctx context.Context, filename string, config ...JSONConfig) (iter.Seq[Record], error) {
+	seq, err := ReadJSON(filename, config...)
+	if err != nil {
+		return nil, err
 	}
+	return WithContext[Record](ctx)(seq), nil
+}
+
+// ReadJSONSafeCtx reads a JSON lines file like ReadJSONSafe and stops when
+// ctx is cancelled, yielding ctx.Err() as the final error
+func ReadJSONSafeCtx(ctx context.Context, filename string, config ...JSONConfig) iter.Seq2[Record, error] {
+	return WithContextSafe[Record](ctx)(ReadJSONSafe(filename, config...))
 }
 
 // WriteJSON writes records as JSON (one object per line)
@@ -589,10 +663,10 @@ func ReadLines(filename string) (iter.Seq[Record], error) {
 		scanner := bufio.NewScanner(file)
 		lineNum := 0
 		for scanner.Scan() {
-			record := Record{fields: map[string]any{
-				"line":        scanner.Text(),
-				"line_number": int64(lineNum),
-			}}
+			record := MakeMutableRecordWithCapacity(2).
+				String("line", scanner.Text()).
+				Int("line_number", int64(lineNum)).
+				Freeze()
 			lineNum++
 
 			if !yield(record) {
@@ -619,10 +693,10 @@ func ReadLinesSafe(filename string) iter.Seq2[Record, error] {
 		scanner := bufio.NewScanner(file)
 		lineNum := 0
 		for scanner.Scan() {
-			record := Record{fields: map[string]any{
-				"line":        scanner.Text(),
-				"line_number": int64(lineNum),
-			}}
+			record := MakeMutableRecordWithCapacity(2).
+				String("line", scanner.Text()).
+				Int("line_number", int64(lineNum)).
+				Freeze()
 			lineNum++
 
 			if !yield(record, nil) {
@@ -636,6 +710,21 @@ func ReadLinesSafe(filename string) iter.Seq2[Record, error] {
 	}
 }
 
+// ReadLinesCtx reads text lines like ReadLines and stops when ctx is cancelled
+func ReadLinesCtx(ctx context.Context, filename string) (iter.Seq[Record], error) {
+	seq, err := ReadLines(filename)
+	if err != nil {
+		return nil, err
+	}
+	return WithContext[Record](ctx)(seq), nil
+}
+
+// ReadLinesSafeCtx reads text lines like ReadLinesSafe and stops when ctx is
+// cancelled, yielding ctx.Err() as the final error
+func ReadLinesSafeCtx(ctx context.Context, filename string) iter.Seq2[Record, error] {
+	return WithContextSafe[Record](ctx)(ReadLinesSafe(filename))
+}
+
 // WriteLines writes records as text lines (using "line" field)
 func WriteLines(sb iter.Seq[Record], filename string) error {
 	file, err := os.Create(filename)
@@ -689,11 +778,73 @@ func parseValue(s string) any {
 	return s
 }
 
+// parseValueDecimal is parseValue for the Decimals reader modes:
+// integers stay int64 and other numbers become Decimal instead of float64
+func parseValueDecimal(s string) any {
+	s = strings.TrimSpace(s)
+
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i
+	}
+	if d, err := ParseDecimal(s); err == nil {
+		return d
+	}
+	return parseValue(s)
+}
+
+// decodeJSONRecord decodes one JSON object. With cfg.Decimals, numbers are
+// decoded from their text so fractional values never pass through float64.
+func decodeJSONRecord(line string, cfg JSONConfig) (Record, error) {
+	var record Record
+	if !cfg.Decimals {
+		err := json.Unmarshal([]byte(line), &record)
+		return record, err
+	}
+
+	fields, order, err := decodeJSONObject([]byte(line), true)
+	if err != nil {
+		return Record{}, err
+	}
+	for k, v := range fields {
+		if v == nil {
+			fields[k] = Null{}
+		} else {
+			fields[k] = decimalJSONValue(v)
+		}
+	}
+	return Record{fields: fields, order: &order}, nil
+}
+
+// decimalJSONValue replaces json.Number values (including nested ones)
+// with int64 or Decimal
+func decimalJSONValue(v any) any {
+	switch val := v.(type) {
+	case json.Number:
+		return parseValueDecimal(string(val))
+	case map[string]any:
+		for k, sub := range val {
+			val[k] = decimalJSONValue(sub)
+		}
+		return val
+	case []any:
+		for i, sub := range val {
+			val[i] = decimalJSONValue(sub)
+		}
+		return val
+	default:
+		return v
+	}
+}
+
 // formatValue converts a value to string for output
 func formatValue(value any) string {
 	switch v := value.(type) {
 	case string:
 		return v
+	case Null:
+		return "" // NULL is an empty cell
+	case Decimal:
+		return v.String()
 	case JSONString:
 		return string(v) // For CSV, output the raw JSON string
 	case int:
@@ -792,8 +943,8 @@ func ReadCommandOutput(filename string, config ...CommandConfig) (iter.Seq[Recor
 
 			// Parse data line
 			record := parseDataLine(line, columnPositions, cfg.TrimSpaces)
-			record.fields["_line_number"] = int64(lineNum)
-			record.fields["_raw_line"] = line
+			record.set("_line_number", int64(lineNum))
+			record.set("_raw_line", line)
 
 			lineNum++
 
@@ -854,14 +1005,15 @@ func ReadCommandOutputSafe(filename string, config ...CommandConfig) iter.Seq2[R
 			// Parse data line
 			record, parseErr := parseDataLineSafe(line, columnPositions, cfg.TrimSpaces)
 			if parseErr != nil {
-				if !yield(Record{}, fmt.Errorf("error parsing line %d: %w", lineNum, parseErr)) {
+				recErr := &RecordError{Stage: "read-command-output", Position: int64(lineNum), Input: line, Err: fmt.Errorf("error parsing line %d: %w", lineNum, parseErr)}
+				if !yield(Record{}, recErr) {
 					return
 				}
 				continue
 			}
 
-			record.fields["_line_number"] = int64(lineNum)
-			record.fields["_raw_line"] = line
+			record.set("_line_number", int64(lineNum))
+			record.set("_raw_line", line)
 
 			lineNum++
 
@@ -876,12 +1028,28 @@ func ReadCommandOutputSafe(filename string, config ...CommandConfig) iter.Seq2[R
 	}
 }
 
+// ReadCommandOutputCtx reads command output like ReadCommandOutput and
+// stops when ctx is cancelled
+func ReadCommandOutputCtx(ctx context.Context, filename string, config ...CommandConfig) (iter.Seq[Record], error) {
+	seq, err := ReadCommandOutput(filename, config...)
+	if err != nil {
+		return nil, err
+	}
+	return WithContext[Record](ctx)(seq), nil
+}
+
+// ReadCommandOutputSafeCtx reads command output like ReadCommandOutputSafe
+// and stops when ctx is cancelled, yielding ctx.Err() as the final error
+func ReadCommandOutputSafeCtx(ctx context.Context, filename string, config ...CommandConfig) iter.Seq2[Record, error] {
+	return WithContextSafe[Record](ctx)(ReadCommandOutputSafe(filename, config...))
+}
+
 // ============================================================================
 // TABLE DISPLAY
 // ============================================================================
 
 // DisplayTable formats and prints records as a table to stdout.
-// Columns are sorted alphabetically and sized to fit content.
+// Columns follow field order (first appearance across records) and are sized to fit content.
 // Long values are truncated with "..." if they exceed maxWidth.
 //
 // Example:
@@ -891,12 +1059,16 @@ func ReadCommandOutputSafe(filename string, config ...CommandConfig) iter.Seq2[R
 func DisplayTable(records iter.Seq[Record], maxWidth int) {
 	// Collect records and determine columns
 	var allRecords []Record
+	var columns []string
 	columnSet := make(map[string]bool)
 
 	for record := range records {
 		allRecords = append(allRecords, record)
 		for field := range record.All() {
-			columnSet[field] = true
+			if !columnSet[field] {
+				columnSet[field] = true
+				columns = append(columns, field)
+			}
 		}
 	}
 
@@ -904,13 +1076,6 @@ func DisplayTable(records iter.Seq[Record], maxWidth int) {
 		return // No records to display
 	}
 
-	// Get sorted column names for consistent ordering
-	columns := make([]string, 0, len(columnSet))
-	for col := range columnSet {
-		columns = append(columns, col)
-	}
-	slices.Sort(columns)
-
 	// Calculate max width for each column
 	colWidths := make(map[string]int)
 	for _, col := range columns {
@@ -1003,23 +1168,42 @@ func DisplayTable(records iter.Seq[Record], maxWidth int) {
 //	    "count": ssql.Count(),
 //	})(ssql.GroupByFields("procs", "UID")(data))
 func ExecCommand(command string, args []string, config ...CommandConfig) (iter.Seq[Record], error) {
+	return ExecCommandCtx(context.Background(), command, args, config...)
+}
+
+// ExecCommandCtx executes a command like ExecCommand, killing it when ctx
+// is cancelled. The stream ends once the command is killed.
+// The command is also killed if the consumer stops iterating early.
+//
+// Example:
+//
+//	// Stream log lines until the client disconnects
+//	lines, err := ssql.ExecCommandCtx(r.Context(), "tail", []string{"-f", "app.log"},
+//	    ssql.CommandConfig{HasHeaders: false})
+func ExecCommandCtx(ctx context.Context, command string, args []string, config ...CommandConfig) (iter.Seq[Record], error) {
 	cfg := DefaultCommandConfig()
 	if len(config) > 0 {
 		cfg = config[0]
 	}
 
-	cmd := exec.Command(command, args...)
+	cmdCtx, cancel := context.WithCancel(ctx)
+	cmd := exec.CommandContext(cmdCtx, command, args...)
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
+		cancel()
 		return nil, fmt.Errorf("failed to create stdout pipe: %w", err)
 	}
 
 	if err := cmd.Start(); err != nil {
+		cancel()
 		return nil, fmt.Errorf("failed to start command: %w", err)
 	}
 
 	seq := func(yield func(Record) bool) {
-		defer func() { _ = cmd.Wait() }()
+		defer func() {
+			cancel()
+			_ = cmd.Wait()
+		}()
 
 		scanner := bufio.NewScanner(stdout)
 
@@ -1028,6 +1212,9 @@ func ExecCommand(command string, args []string, config ...CommandConfig) (iter.S
 		headerProcessed := false
 
 		for scanner.Scan() {
+			if ctx.Err() != nil {
+				return // Cancelled: ignore output buffered before the kill
+			}
 			line := scanner.Text()
 
 			// Skip empty lines if configured
@@ -1045,9 +1232,9 @@ func ExecCommand(command string, args []string, config ...CommandConfig) (iter.S
 
 			// Parse data line
 			record := parseDataLine(line, columnPositions, cfg.TrimSpaces)
-			record.fields["_line_number"] = int64(lineNum)
-			record.fields["_raw_line"] = line
-			record.fields["_command"] = command
+			record.set("_line_number", int64(lineNum))
+			record.set("_raw_line", line)
+			record.set("_command", command)
 
 			lineNum++
 
@@ -1062,13 +1249,23 @@ func ExecCommand(command string, args []string, config ...CommandConfig) (iter.S
 
 // ExecCommandSafe executes a command with error handling
 func ExecCommandSafe(command string, args []string, config ...CommandConfig) iter.Seq2[Record, error] {
+	return ExecCommandSafeCtx(context.Background(), command, args, config...)
+}
+
+// ExecCommandSafeCtx executes a command like ExecCommandSafe, killing it
+// when ctx is cancelled and yielding ctx.Err() as the final error.
+// The command is also killed if the consumer stops iterating early.
+func ExecCommandSafeCtx(ctx context.Context, command string, args []string, config ...CommandConfig) iter.Seq2[Record, error] {
 	cfg := DefaultCommandConfig()
 	if len(config) > 0 {
 		cfg = config[0]
 	}
 
 	return func(yield func(Record, error) bool) {
-		cmd := exec.Command(command, args...)
+		cmdCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		cmd := exec.CommandContext(cmdCtx, command, args...)
 		stdout, err := cmd.StdoutPipe()
 		if err != nil {
 			if !yield(Record{}, fmt.Errorf("failed to create stdout pipe: %w", err)) {
@@ -1083,8 +1280,19 @@ func ExecCommandSafe(command string, args []string, config ...CommandConfig) ite
 			}
 			return
 		}
+		stopped := false
 		defer func() {
-			if waitErr := cmd.Wait(); waitErr != nil {
+			if stopped {
+				// The consumer is done: kill the command and ignore how it ends
+				cancel()
+				_ = cmd.Wait()
+				return
+			}
+			waitErr := cmd.Wait()
+			switch {
+			case ctx.Err() != nil:
+				yield(Record{}, ctx.Err())
+			case waitErr != nil:
 				yield(Record{}, fmt.Errorf("command failed: %w", waitErr))
 			}
 		}()
@@ -1096,6 +1304,9 @@ func ExecCommandSafe(command string, args []string, config ...CommandConfig) ite
 		headerProcessed := false
 
 		for scanner.Scan() {
+			if ctx.Err() != nil {
+				return // Cancelled: ignore output buffered before the kill
+			}
 			line := scanner.Text()
 
 			// Skip empty lines if configured
@@ -1108,6 +1319,7 @@ func ExecCommandSafe(command string, args []string, config ...CommandConfig) ite
 				columnPositions = parseHeaderLine(line)
 				if len(columnPositions) == 0 {
 					if !yield(Record{}, fmt.Errorf("failed to parse header line: %s", line)) {
+						stopped = true
 						return
 					}
 					continue
@@ -1120,25 +1332,30 @@ func ExecCommandSafe(command string, args []string, config ...CommandConfig) ite
 			// Parse data line
 			record, parseErr := parseDataLineSafe(line, columnPositions, cfg.TrimSpaces)
 			if parseErr != nil {
-				if !yield(Record{}, fmt.Errorf("error parsing line %d: %w", lineNum, parseErr)) {
+				recErr := &RecordError{Stage: "exec", Position: int64(lineNum), Input: line, Err: fmt.Errorf("error parsing line %d: %w", lineNum, parseErr)}
+				if !yield(Record{}, recErr) {
+					stopped = true
 					return
 				}
 				continue
 			}
 
-			record.fields["_line_number"] = int64(lineNum)
-			record.fields["_raw_line"] = line
-			record.fields["_command"] = command
+			record.set("_line_number", int64(lineNum))
+			record.set("_raw_line", line)
+			record.set("_command", command)
 
 			lineNum++
 
 			if !yield(record, nil) {
+				stopped = true
 				return
 			}
 		}
 
-		if err := scanner.Err(); err != nil {
-			yield(Record{}, fmt.Errorf("error reading command output: %w", err))
+		if err := scanner.Err(); err != nil && ctx.Err() == nil {
+			if !yield(Record{}, fmt.Errorf("error reading command output: %w", err)) {
+				stopped = true
+			}
 		}
 	}
 }
@@ -1198,14 +1415,14 @@ func parseDataLine(line string, columns []ColumnInfo, _ bool) Record {
 				// Last field gets all remaining tokens joined with spaces
 				remainingTokens := tokens[i:]
 				value := strings.Join(remainingTokens, " ")
-				record.fields[col.Name] = parseCommandValue(value)
+				record.set(col.Name, parseCommandValue(value))
 			} else {
 				// Regular field gets single token
-				record.fields[col.Name] = parseCommandValue(tokens[i])
+				record.set(col.Name, parseCommandValue(tokens[i]))
 			}
 		} else {
 			// No more tokens, use empty string
-			record.fields[col.Name] = ""
+			record.set(col.Name, "")
 		}
 	}
 
@@ -1230,14 +1447,14 @@ func parseDataLineSafe(line string, columns []ColumnInfo, _ bool) (Record, error
 				// Last field gets all remaining tokens joined with spaces
 				remainingTokens := tokens[i:]
 				value := strings.Join(remainingTokens, " ")
-				record.fields[col.Name] = parseCommandValue(value)
+				record.set(col.Name, parseCommandValue(value))
 			} else {
 				// Regular field gets single token
-				record.fields[col.Name] = parseCommandValue(tokens[i])
+				record.set(col.Name, parseCommandValue(tokens[i]))
 			}
 		} else {
 			// No more tokens, use empty string
-			record.fields[col.Name] = ""
+			record.set(col.Name, "")
 		}
 	}
 
@@ -1366,7 +1583,7 @@ func ReadJSONAuto(filename string) (iter.Seq[Record], error) {
 	// Check if it starts with '[' (JSON array)
 	if data[0] == '[' {
 		// Parse as JSON array
-		var records []map[string]interface{}
+		var records []Record
 		if err := json.Unmarshal(data, &records); err != nil {
 			return nil, fmt.Errorf("failed to parse JSON array: %w", err)
 		}
@@ -1374,7 +1591,7 @@ func ReadJSONAuto(filename string) (iter.Seq[Record], error) {
 		return func(yield func(Record) bool) {
 			for _, rec := range records {
 				record := MakeMutableRecord()
-				for k, v := range rec {
+				for k, v := range rec.All() {
 					record = addJSONField(record, k, v)
 				}
 				if !yield(record.Freeze()) {
@@ -1391,17 +1608,13 @@ func ReadJSONAuto(filename string) (iter.Seq[Record], error) {
 // WriteJSONPretty writes records as a pretty-printed JSON array
 func WriteJSONPretty(sb iter.Seq[Record], filename string) error {
 	// Collect all records
-	var recordMaps []map[string]interface{}
+	var objects []Record
 	for record := range sb {
-		data := make(map[string]interface{})
-		for k, v := range record.All() {
-			data[k] = convertRecordValueForJSON(v)
-		}
-		recordMaps = append(recordMaps, data)
+		objects = append(objects, convertRecordValueForJSON(record).(Record))
 	}
 
 	// Marshal as pretty JSON
-	jsonBytes, err := json.MarshalIndent(recordMaps, "", "  ")
+	jsonBytes, err := json.MarshalIndent(objects, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal JSON: %w", err)
 	}
@@ -1415,7 +1628,7 @@ func WriteJSONPretty(sb iter.Seq[Record], filename string) error {
 }
 
 // addJSONField adds a JSON field to a MutableRecord using type-safe methods
-// Skips nil values. Converts arrays/objects to JSONString for type safety.
+// Stores JSON null as Null. Converts arrays/objects to JSONString for type safety.
 func addJSONField(record MutableRecord, key string, value interface{}) MutableRecord {
 	switch val := value.(type) {
 	case float64:
@@ -1429,9 +1642,9 @@ func addJSONField(record MutableRecord, key string, value interface{}) MutableRe
 		return record.Bool(key, val)
 	case string:
 		return record.String(key, val)
-	case nil:
-		// Skip nil values - don't add field
-		return record
+	case nil, Null:
+		// Keep JSON null as an explicit Null field
+		return record.Null(key)
 	case []interface{}, map[string]interface{}:
 		// Convert arrays/objects to JSONString for type safety
 		jsonBytes, err := json.Marshal(val)
@@ -1450,12 +1663,16 @@ func addJSONField(record MutableRecord, key string, value interface{}) MutableRe
 func convertRecordValueForJSON(v interface{}) interface{} {
 	switch val := v.(type) {
 	case Record:
-		// Convert nested Record to map
-		result := make(map[string]interface{})
+		// Convert nested Record values, keeping the field order
+		result := MakeMutableRecordWithCapacity(val.Len())
 		for k, subv := range val.All() {
-			result[k] = convertRecordValueForJSON(subv)
+			result.set(k, convertRecordValueForJSON(subv))
 		}
-		return result
+		return result.Freeze()
+	case Null:
+		return nil
+	case Decimal:
+		return json.Number(val.String())
 	case int64, float64, bool, string, nil:
 		return val
 	default:
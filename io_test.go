@@ -2,12 +2,15 @@ package ssql
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"iter"
 	"os"
 	"path/filepath"
 	"slices"
 	"strings"
 	"testing"
+	"time"
 )
 
 // ============================================================================
@@ -314,6 +317,26 @@ func TestReadJSONSafe(t *testing.T) {
 	if len(result) != 2 {
 		t.Fatalf("Expected 2 records, got %d", len(result))
 	}
+
+	// Bad lines still count towards the positions of the lines after them
+	badFile := filepath.Join(tmpDir, "bad.jsonl")
+	bad := "{\"n\":0}\n{oops\n{\"n\":\"x\"}\n\n{\"n\":4}\n"
+	if err := os.WriteFile(badFile, []byte(bad), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var positions []int64
+	var lines []int64
+	for record, err := range ReadJSONSafe(badFile, JSONConfig{Schema: NewSchema(SchemaField{Name: "n", Type: TypeInt})}) {
+		var recErr *RecordError
+		if errors.As(err, &recErr) {
+			positions = append(positions, recErr.Position)
+			continue
+		}
+		lines = append(lines, GetOr(record, "_line_number", int64(-1)))
+	}
+	if !slices.Equal(positions, []int64{1, 2}) || !slices.Equal(lines, []int64{0, 4}) {
+		t.Errorf("Expected errors at lines [1 2] and records at [0 4], got %v and %v", positions, lines)
+	}
 }
 
 // ============================================================================
@@ -487,6 +510,100 @@ func TestExecCommandWithConfig(t *testing.T) {
 	}
 }
 
+func TestExecCommandCtx(t *testing.T) {
+	config := DefaultCommandConfig()
+	config.HasHeaders = false
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// yes never exits on its own: cancelling must kill it
+	seq, err := ExecCommandCtx(ctx, "yes", nil, config)
+	if err != nil {
+		t.Skipf("yes not available: %v", err)
+	}
+
+	count := 0
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range seq {
+			count++
+			if count == 5 {
+				cancel()
+			}
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("ExecCommandCtx did not stop after cancel")
+	}
+	if count != 5 {
+		t.Errorf("Expected 5 records before cancel, got %d", count)
+	}
+}
+
+func TestExecCommandSafeCtx(t *testing.T) {
+	config := DefaultCommandConfig()
+	config.HasHeaders = false
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	var lastErr error
+	for _, err := range ExecCommandSafeCtx(ctx, "sleep", []string{"10"}, config) {
+		lastErr = err
+	}
+	if !errors.Is(lastErr, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded error, got %v", lastErr)
+	}
+
+	// Stopping early kills the command without reporting an error
+	// (the first "NAME VALUE" line is the header)
+	for _, err := range ExecCommandSafeCtx(context.Background(), "yes", []string{"NAME VALUE"}) {
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		break
+	}
+}
+
+func TestReadCSVCtx(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "data.csv")
+	if err := os.WriteFile(filename, []byte("n\n1\n2\n3\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	seq, err := ReadCSVCtx(context.Background(), filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(slices.Collect(seq)); got != 3 {
+		t.Errorf("Expected 3 records, got %d", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	seq, _ = ReadCSVCtx(ctx, filename)
+	if got := len(slices.Collect(seq)); got != 0 {
+		t.Errorf("Expected no records from cancelled context, got %d", got)
+	}
+
+	var lastErr error
+	for _, err := range ReadCSVSafeCtx(ctx, filename) {
+		lastErr = err
+	}
+	if !errors.Is(lastErr, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", lastErr)
+	}
+
+	if _, err := ReadCSVCtx(context.Background(), "/nonexistent/file.csv"); err == nil {
+		t.Error("Expected error for missing file")
+	}
+}
+
 // ============================================================================
 // CHANNEL CONVERSION TESTS
 // ============================================================================
@@ -1232,3 +1349,68 @@ func TestFunctionalPipelineComposition(t *testing.T) {
 		}
 	}
 }
+
+// ============================================================================
+// NULL TESTS
+// ============================================================================
+
+func TestNullCSVAndJSON(t *testing.T) {
+	records := []Record{
+		MakeMutableRecord().String("name", "Alice").Null("manager").Freeze(),
+	}
+
+	var csvBuf bytes.Buffer
+	if err := WriteCSVToWriter(slices.Values(records), &csvBuf); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(csvBuf.String(), "Alice,\n") && !strings.Contains(csvBuf.String(), ",Alice\n") {
+		t.Errorf("Expected null written as an empty CSV cell, got %q", csvBuf.String())
+	}
+
+	var jsonBuf bytes.Buffer
+	if err := WriteJSONToWriter(slices.Values(records), &jsonBuf); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(jsonBuf.String(), `"manager":null`) {
+		t.Errorf("Expected JSON null, got %q", jsonBuf.String())
+	}
+
+	back := slices.Collect(ReadJSONFromReader(&jsonBuf))
+	if len(back) != 1 || !back[0].IsNull("manager") {
+		t.Errorf("Expected JSON null to read back as Null, got %v", back)
+	}
+}
+
+func TestFieldOrderCSVAndJSON(t *testing.T) {
+	input := "zeta,alpha,mid\n1,2,3\n"
+	records := slices.Collect(ReadCSVFromReader(strings.NewReader(input)))
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(records))
+	}
+
+	// CSV output keeps the source header order
+	var csvBuf bytes.Buffer
+	if err := WriteCSVToWriter(slices.Values(records), &csvBuf); err != nil {
+		t.Fatalf("WriteCSVToWriter failed: %v", err)
+	}
+	if header, _, _ := strings.Cut(csvBuf.String(), "\n"); header != "zeta,alpha,mid" {
+		t.Errorf("CSV header = %q, want source order", header)
+	}
+
+	// Fields added later are appended as new columns
+	updated := records[0].ToMutable().String("added", "x").Freeze()
+	var jsonBuf bytes.Buffer
+	if err := WriteJSONToWriter(slices.Values([]Record{updated}), &jsonBuf); err != nil {
+		t.Fatalf("WriteJSONToWriter failed: %v", err)
+	}
+	want := `{"zeta":1,"alpha":2,"mid":3,"_row_number":0,"added":"x"}`
+	if got := strings.TrimSpace(jsonBuf.String()); got != want {
+		t.Errorf("JSON = %s, want %s", got, want)
+	}
+
+	// JSON input keeps the key order of each line
+	decoded := slices.Collect(ReadJSONFromReader(strings.NewReader(`{"b":1,"a":2}` + "\n")))
+	if keys := decoded[0].Keys(); !slices.Equal(keys, []string{"b", "a", "_line_number"}) {
+		t.Errorf("JSON keys = %v, want document order", keys)
+	}
+}
@@ -0,0 +1,196 @@
+package ssql
+
+import (
+	"cmp"
+	"container/heap"
+	"iter"
+)
+
+// ============================================================================
+// MERGING MULTIPLE STREAMS
+// ============================================================================
+
+// MergeSorted merges streams that are each sorted by keyFn into one sorted
+// stream, reading them lazily (a streaming k-way merge: one element per
+// input is held at a time). Elements with equal keys keep the order of
+// their streams in seqs, so the merge is stable. Unsorted inputs are merged
+// without error but the output is then not sorted.
+//
+// Example:
+//
+//	// Per-day logs, each in timestamp order
+//	all := ssql.MergeSorted(func(r ssql.Record) int64 {
+//	    return ssql.GetOr(r, "ts", int64(0))
+//	}, monday, tuesday, wednesday)
+func MergeSorted[T any, K cmp.Ordered](keyFn func(T) K, seqs ...iter.Seq[T]) iter.Seq[T] {
+	return MergeSortedFunc(func(a, b T) int {
+		return cmp.Compare(keyFn(a), keyFn(b))
+	}, seqs...)
+}
+
+// MergeSortedFunc merges streams that are each sorted by compare into one
+// sorted stream, like MergeSorted. Use it with CompareByKeys to merge
+// records sorted by several fields.
+//
+// Example:
+//
+//	merged := ssql.MergeSortedFunc(ssql.CompareByKeys(ssql.Asc("region"), ssql.Desc("revenue")), east, west)
+func MergeSortedFunc[T any](compare func(a, b T) int, seqs ...iter.Seq[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		h := &mergeHeap[T]{compare: compare}
+		for i, seq := range seqs {
+			next, stop := iter.Pull(seq)
+			defer stop()
+			if v, ok := next(); ok {
+				h.cursors = append(h.cursors, &mergeCursor[T]{value: v, next: next, source: i})
+			}
+		}
+		heap.Init(h)
+
+		for h.Len() > 0 {
+			c := h.cursors[0]
+			if !yield(c.value) {
+				return
+			}
+			if v, ok := c.next(); ok {
+				c.value = v
+				heap.Fix(h, 0)
+			} else {
+				heap.Pop(h)
+			}
+		}
+	}
+}
+
+// mergeCursor is the current element of one MergeSortedFunc input
+type mergeCursor[T any] struct {
+	value  T
+	next   func() (T, bool)
+	source int
+}
+
+// mergeHeap orders cursors by value, then by input position
+type mergeHeap[T any] struct {
+	cursors []*mergeCursor[T]
+	compare func(a, b T) int
+}
+
+func (h *mergeHeap[T]) Len() int { return len(h.cursors) }
+
+func (h *mergeHeap[T]) Less(i, j int) bool {
+	a, b := h.cursors[i], h.cursors[j]
+	if c := h.compare(a.value, b.value); c != 0 {
+		return c < 0
+	}
+	return a.source < b.source
+}
+
+func (h *mergeHeap[T]) Swap(i, j int) { h.cursors[i], h.cursors[j] = h.cursors[j], h.cursors[i] }
+
+func (h *mergeHeap[T]) Push(x any) { h.cursors = append(h.cursors, x.(*mergeCursor[T])) }
+
+func (h *mergeHeap[T]) Pop() any {
+	last := h.cursors[len(h.cursors)-1]
+	h.cursors = h.cursors[:len(h.cursors)-1]
+	return last
+}
+
+// Interleave takes one element from each stream in turn (round-robin),
+// continuing with the remaining streams as shorter ones run out
+//
+// Example:
+//
+//	// Alternate between sources so none dominates a sample
+//	mixed := ssql.Limit[ssql.Record](1000)(ssql.Interleave(web, mobile, api))
+func Interleave[T any](seqs ...iter.Seq[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		nexts := make([]func() (T, bool), 0, len(seqs))
+		for _, seq := range seqs {
+			next, stop := iter.Pull(seq)
+			defer stop()
+			nexts = append(nexts, next)
+		}
+
+		for len(nexts) > 0 {
+			live := nexts[:0]
+			for _, next := range nexts {
+				v, ok := next()
+				if !ok {
+					continue
+				}
+				if !yield(v) {
+					return
+				}
+				live = append(live, next)
+			}
+			nexts = live
+		}
+	}
+}
+
+// Zip pairs the elements of two streams by position, stopping when either
+// runs out
+//
+// Example:
+//
+//	for expected, actual := range ssql.Zip(want, got) {
+//	    if !expected.Equal(actual) {
+//	        fmt.Println("mismatch:", expected, actual)
+//	    }
+//	}
+func Zip[A, B any](a iter.Seq[A], b iter.Seq[B]) iter.Seq2[A, B] {
+	return func(yield func(A, B) bool) {
+		nextB, stop := iter.Pull(b)
+		defer stop()
+		for va := range a {
+			vb, ok := nextB()
+			if !ok || !yield(va, vb) {
+				return
+			}
+		}
+	}
+}
+
+// ZipRecords combines the records at the same position in each stream into
+// one record, stopping when any stream runs out. Fields from seqs[i] are
+// renamed with prefixes[i] prepended; streams without a prefix keep their
+// field names, and on a name clash the later stream's value wins.
+//
+// Example:
+//
+//	// Line up readings from two sensors sampled together
+//	paired := ssql.ZipRecords([]string{"left_", "right_"}, leftSensor, rightSensor)
+//	// {"left_temp": 21.5, "left_ts": ..., "right_temp": 22.1, "right_ts": ...}
+func ZipRecords(prefixes []string, seqs ...iter.Seq[Record]) iter.Seq[Record] {
+	return func(yield func(Record) bool) {
+		if len(seqs) == 0 {
+			return
+		}
+		nexts := make([]func() (Record, bool), len(seqs))
+		for i, seq := range seqs {
+			next, stop := iter.Pull(seq)
+			defer stop()
+			nexts[i] = next
+		}
+
+		for {
+			result := MakeMutableRecord()
+			for i, next := range nexts {
+				r, ok := next()
+				if !ok {
+					return
+				}
+				prefix := ""
+				if i < len(prefixes) {
+					prefix = prefixes[i]
+				}
+				for k, v := range r.All() {
+					result.set(prefix+k, v)
+				}
+			}
+			if !yield(result.Freeze()) {
+				return
+			}
+		}
+	}
+}
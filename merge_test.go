@@ -0,0 +1,113 @@
+package ssql
+
+import (
+	"slices"
+	"strconv"
+	"testing"
+)
+
+// ============================================================================
+// MERGE AND ZIP TESTS
+// ============================================================================
+
+func TestMergeSorted(t *testing.T) {
+	type event struct {
+		ts     int
+		source string
+	}
+	a := slices.Values([]event{{1, "a"}, {4, "a"}, {4, "a2"}, {9, "a"}})
+	b := slices.Values([]event{{2, "b"}, {4, "b"}, {10, "b"}})
+	c := slices.Values([]event{})
+	d := slices.Values([]event{{0, "d"}, {4, "d"}})
+
+	got := slices.Collect(MergeSorted(func(e event) int { return e.ts }, a, b, c, d))
+	want := []event{{0, "d"}, {1, "a"}, {2, "b"}, {4, "a"}, {4, "a2"}, {4, "b"}, {4, "d"}, {9, "a"}, {10, "b"}}
+	if !slices.Equal(got, want) {
+		t.Errorf("MergeSorted = %v, want %v", got, want)
+	}
+
+	if got := slices.Collect(MergeSorted(func(n int) int { return n })); len(got) != 0 {
+		t.Errorf("MergeSorted of no streams = %v", got)
+	}
+
+	// Early termination stops every input
+	var read int
+	counting := func(yield func(int) bool) {
+		for i := 0; ; i += 2 {
+			read++
+			if !yield(i) {
+				return
+			}
+		}
+	}
+	first := slices.Collect(Limit[int](5)(MergeSorted(func(n int) int { return n }, counting, slices.Values([]int{1, 3}))))
+	if !slices.Equal(first, []int{0, 1, 2, 3, 4}) || read > 5 {
+		t.Errorf("Limited merge = %v after reading %d", first, read)
+	}
+}
+
+func TestMergeSortedFuncRecords(t *testing.T) {
+	row := func(region string, revenue int64) Record {
+		return MakeMutableRecord().String("region", region).Int("revenue", revenue).Freeze()
+	}
+	east := slices.Values([]Record{row("east", 900), row("east", 100), row("west", 50)})
+	west := slices.Values([]Record{row("east", 500), row("north", 10)})
+
+	var got []string
+	for r := range MergeSortedFunc(CompareByKeys(Asc("region"), Desc("revenue")), east, west) {
+		got = append(got, GetOr(r, "region", "")+":"+strconv.FormatInt(GetOr(r, "revenue", int64(0)), 10))
+	}
+	want := []string{"east:900", "east:500", "east:100", "north:10", "west:50"}
+	if !slices.Equal(got, want) {
+		t.Errorf("MergeSortedFunc = %v, want %v", got, want)
+	}
+}
+
+func TestInterleave(t *testing.T) {
+	got := slices.Collect(Interleave(
+		slices.Values([]int{1, 4, 7, 9, 10}),
+		slices.Values([]int{2, 5}),
+		slices.Values([]int{3, 6, 8}),
+	))
+	want := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	if !slices.Equal(got, want) {
+		t.Errorf("Interleave = %v, want %v", got, want)
+	}
+	if got := slices.Collect(Limit[int](2)(Interleave(slices.Values(want), slices.Values(want)))); !slices.Equal(got, []int{1, 1}) {
+		t.Errorf("Limited Interleave = %v", got)
+	}
+}
+
+func TestZip(t *testing.T) {
+	var pairs []string
+	for n, s := range Zip(slices.Values([]int{1, 2, 3}), slices.Values([]string{"a", "b"})) {
+		pairs = append(pairs, strconv.Itoa(n)+s)
+	}
+	if !slices.Equal(pairs, []string{"1a", "2b"}) {
+		t.Errorf("Zip = %v", pairs)
+	}
+
+	left := slices.Values([]Record{
+		MakeMutableRecord().Float("temp", 21.5).Int("id", 1).Freeze(),
+		MakeMutableRecord().Float("temp", 21.7).Int("id", 2).Freeze(),
+	})
+	right := slices.Values([]Record{
+		MakeMutableRecord().Float("temp", 22.1).Int("id", 7).Freeze(),
+		MakeMutableRecord().Float("temp", 22.0).Int("id", 8).Freeze(),
+		MakeMutableRecord().Float("temp", 22.3).Int("id", 9).Freeze(),
+	})
+	zipped := slices.Collect(ZipRecords([]string{"left_", "right_"}, left, right))
+	if len(zipped) != 2 {
+		t.Fatalf("ZipRecords should stop at the shorter stream, got %d records", len(zipped))
+	}
+	if !slices.Equal(zipped[1].Keys(), []string{"left_temp", "left_id", "right_temp", "right_id"}) ||
+		GetOr(zipped[1], "left_temp", 0.0) != 21.7 || GetOr(zipped[1], "right_id", int64(0)) != 8 {
+		t.Errorf("Unexpected zipped record %v", zipped[1])
+	}
+
+	// Without prefixes the later stream wins clashes
+	merged := slices.Collect(ZipRecords(nil, left, right))
+	if GetOr(merged[0], "id", int64(0)) != 7 || len(merged[0].Keys()) != 2 {
+		t.Errorf("Unprefixed ZipRecords = %v", merged[0])
+	}
+}
@@ -436,74 +436,6 @@ func Tee[T any](input iter.Seq[T], n int) []iter.Seq[T] {
 	return streams
 }
 
-// LazyTee splits a stream into multiple identical streams using channels for infinite streams.
-// Unlike Tee, this doesn't buffer the entire stream in memory, making it suitable for infinite streams.
-// Uses channels with backpressure to handle slow consumers gracefully.
-func LazyTee[T any](input iter.Seq[T], n int) []iter.Seq[T] {
-	if n <= 0 {
-		return nil
-	}
-
-	// Create channels for each output stream
-	channels := make([]chan T, n)
-	done := make(chan struct{})
-
-	for i := range n {
-		channels[i] = make(chan T, 100) // Buffered to handle temporary speed differences
-	}
-
-	// Start broadcaster goroutine
-	go func() {
-		defer func() {
-			for _, ch := range channels {
-				close(ch)
-			}
-		}()
-
-		for v := range input {
-			// Send to all channels
-			for i, ch := range channels {
-				select {
-				case ch <- v:
-					// Successfully sent to this channel
-				case <-done:
-					// One of the consumers has terminated, stop broadcasting
-					return
-				default:
-					// Channel is full - consumer is too slow
-					// For now, we'll drop the value for this consumer
-					// In production, you might want different backpressure strategies
-					_ = i // Just to use the variable
-				}
-			}
-		}
-	}()
-
-	// Create output iterators
-	streams := make([]iter.Seq[T], n)
-	for i := range n {
-		ch := channels[i]
-		streams[i] = func(yield func(T) bool) {
-			defer func() {
-				// Signal termination to broadcaster
-				select {
-				case <-done:
-				default:
-					close(done)
-				}
-			}()
-
-			for v := range ch {
-				if !yield(v) {
-					return
-				}
-			}
-		}
-	}
-
-	return streams
-}
-
 // ============================================================================
 // STREAMING AGGREGATIONS FOR INFINITE STREAMS
 // ============================================================================
@@ -526,12 +458,12 @@ func RunningSum(fieldName string) Filter[Record, Record] {
 				outputRecord := MakeMutableRecord()
 				// Copy original record
 				for k, v := range record.All() {
-					outputRecord.fields[k] = v
+					outputRecord.set(k, v)
 				}
 				// Add running sum fields
-				outputRecord.fields["running_sum"] = runningTotal
-				outputRecord.fields["running_count"] = int64(count)
-				outputRecord.fields["running_avg"] = runningTotal / float64(count)
+				outputRecord.set("running_sum", runningTotal)
+				outputRecord.set("running_count", int64(count))
+				outputRecord.set("running_avg", runningTotal/float64(count))
 
 				if !yield(outputRecord.Freeze()) {
 					return
@@ -575,11 +507,11 @@ func RunningAverage(fieldName string, windowSize int) Filter[Record, Record] {
 				// Create output record
 				outputRecord := MakeMutableRecord()
 				for k, v := range record.All() {
-					outputRecord.fields[k] = v
+					outputRecord.set(k, v)
 				}
-				outputRecord.fields["moving_avg"] = avg
-				outputRecord.fields["window_size"] = int64(len(window))
-				outputRecord.fields["total_count"] = int64(count)
+				outputRecord.set("moving_avg", avg)
+				outputRecord.set("window_size", int64(len(window)))
+				outputRecord.set("total_count", int64(count))
 
 				if !yield(outputRecord.Freeze()) {
 					return
@@ -611,10 +543,10 @@ func ExponentialMovingAverage(fieldName string, alpha float64) Filter[Record, Re
 				// Create output record
 				outputRecord := MakeMutableRecord()
 				for k, v := range record.All() {
-					outputRecord.fields[k] = v
+					outputRecord.set(k, v)
 				}
-				outputRecord.fields["ema"] = ema
-				outputRecord.fields["alpha"] = alpha
+				outputRecord.set("ema", ema)
+				outputRecord.set("alpha", alpha)
 
 				if !yield(outputRecord.Freeze()) {
 					return
@@ -650,11 +582,11 @@ func RunningMinMax(fieldName string) Filter[Record, Record] {
 				// Create output record
 				outputRecord := MakeMutableRecord()
 				for k, v := range record.All() {
-					outputRecord.fields[k] = v
+					outputRecord.set(k, v)
 				}
-				outputRecord.fields["running_min"] = min
-				outputRecord.fields["running_max"] = max
-				outputRecord.fields["running_range"] = max - min
+				outputRecord.set("running_min", min)
+				outputRecord.set("running_max", max)
+				outputRecord.set("running_range", max-min)
 
 				if !yield(outputRecord.Freeze()) {
 					return
@@ -681,11 +613,11 @@ func RunningCount(fieldName string) Filter[Record, Record] {
 				// Create output record
 				outputRecord := MakeMutableRecord()
 				for k, v := range record.All() {
-					outputRecord.fields[k] = v
+					outputRecord.set(k, v)
 				}
-				outputRecord.fields["distinct_counts"] = counts
-				outputRecord.fields["total_count"] = totalCount
-				outputRecord.fields["distinct_values"] = int64(len(counts))
+				outputRecord.set("distinct_counts", counts)
+				outputRecord.set("total_count", totalCount)
+				outputRecord.set("distinct_values", int64(len(counts)))
 
 				if !yield(outputRecord.Freeze()) {
 					return
@@ -767,6 +699,7 @@ func SlidingCountWindow[T any](windowSize, stepSize int) Filter[T, []T] {
 
 // TimeWindow groups elements by time duration (requires timestamp field)
 // Critical for time-series analysis of infinite streams
+// Assumes timestamps arrive in order; use EventTimeWindow for out-of-order input
 func TimeWindow[T any](duration time.Duration, timeField string) Filter[T, []T] {
 	return func(input iter.Seq[T]) iter.Seq[[]T] {
 		return func(yield func([]T) bool) {
@@ -819,6 +752,7 @@ func TimeWindow[T any](duration time.Duration, timeField string) Filter[T, []T]
 
 // SlidingTimeWindow creates overlapping time-based windows
 // Perfect for real-time analytics with overlapping time periods
+// Assumes timestamps arrive in order; use EventTimeWindow with a Slide for out-of-order input
 func SlidingTimeWindow[T any](windowDuration, slideDuration time.Duration, timeField string) Filter[T, []T] {
 	return func(input iter.Seq[T]) iter.Seq[[]T] {
 		return func(yield func([]T) bool) {
@@ -969,37 +903,118 @@ func TakeUntil[T any](predicate func(T) bool) Filter[T, T] {
 	}
 }
 
-// Timeout limits stream processing to a maximum duration
-// Automatically terminates infinite streams after the specified time
-func Timeout[T any](duration time.Duration) Filter[T, T] {
+// WithContext ends a stream when ctx is cancelled or its deadline passes.
+// The input is read on a separate goroutine, so the stream ends promptly even
+// while the input is blocked waiting for its next element; that goroutine
+// stops at the input's next element. Use the ...Ctx sources (e.g. ReadCSVCtx,
+// ExecCommandCtx) to also stop reading files and kill child processes.
+//
+// Example:
+//
+//	func handler(w http.ResponseWriter, r *http.Request) {
+//	    events, _ := ssql.ReadJSONCtx(r.Context(), "events.jsonl")
+//	    errors := ssql.Where(func(e ssql.Record) bool {
+//	        return ssql.GetOr(e, "level", "") == "error"
+//	    })(events)
+//	    // Stop streaming when the client disconnects
+//	    ssql.WriteJSONToWriter(ssql.WithContext[ssql.Record](r.Context())(errors), w)
+//	}
+func WithContext[T any](ctx context.Context) Filter[T, T] {
 	return func(input iter.Seq[T]) iter.Seq[T] {
 		return func(yield func(T) bool) {
-			ctx, cancel := context.WithTimeout(context.Background(), duration)
-			defer cancel()
-
-			done := make(chan struct{})
+			forwardUntilDone(ctx, input, yield)
+		}
+	}
+}
 
-			go func() {
-				defer close(done)
-				for v := range input {
-					select {
-					case <-ctx.Done():
-						return // Timeout reached
-					default:
-						if !yield(v) {
-							return
-						}
+// WithContextSafe ends an error-aware stream when ctx is cancelled or its
+// deadline passes, yielding ctx.Err() as the final error.
+// See WithContext.
+func WithContextSafe[T any](ctx context.Context) FilterWithErrors[T, T] {
+	return func(input iter.Seq2[T, error]) iter.Seq2[T, error] {
+		return func(yield func(T, error) bool) {
+			type pair struct {
+				value T
+				err   error
+			}
+			pairs := func(yield func(pair) bool) {
+				for v, err := range input {
+					if !yield(pair{v, err}) {
+						return
 					}
 				}
-			}()
+			}
+			if forwardUntilDone(ctx, pairs, func(p pair) bool { return yield(p.value, p.err) }) {
+				var zero T
+				yield(zero, ctx.Err())
+			}
+		}
+	}
+}
+
+// forwardUntilDone passes the elements of input to yield until the input
+// ends, yield returns false or ctx is done. Returns true if it stopped
+// because ctx is done.
+func forwardUntilDone[T any](ctx context.Context, input iter.Seq[T], yield func(T) bool) bool {
+	if ctx.Err() != nil {
+		return true
+	}
+	if ctx.Done() == nil {
+		// ctx can never be cancelled: no need for a goroutine
+		for v := range input {
+			if !yield(v) {
+				return false
+			}
+		}
+		return false
+	}
 
+	values := make(chan T)
+	stop := make(chan struct{})
+	defer close(stop)
+
+	go func() {
+		defer close(values)
+		for v := range input {
 			select {
-			case <-done:
-				// Processing completed normally
+			case values <- v:
+			case <-stop:
+				return
 			case <-ctx.Done():
-				// Timeout reached
+				return
 			}
 		}
+	}()
+
+	for {
+		select {
+		case v, ok := <-values:
+			if !ok {
+				// The reader also closes values when it sees ctx done
+				return ctx.Err() != nil
+			}
+			if ctx.Err() != nil {
+				return true // Both were ready: cancellation wins
+			}
+			if !yield(v) {
+				return false
+			}
+		case <-ctx.Done():
+			return true
+		}
+	}
+}
+
+// Timeout limits stream processing to a maximum duration
+// Automatically terminates infinite streams after the specified time
+func Timeout[T any](duration time.Duration) Filter[T, T] {
+	return func(input iter.Seq[T]) iter.Seq[T] {
+		return func(yield func(T) bool) {
+			ctx, cancel := context.WithTimeout(context.Background(), duration)
+			defer cancel()
+
+			forwardUntilDone(ctx, input, yield)
+		}
 	}
 }
 
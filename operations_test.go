@@ -1,6 +1,8 @@
 package ssql
 
 import (
+	"context"
+	"errors"
 	"iter"
 	"slices"
 	"testing"
@@ -806,6 +808,105 @@ func TestTimeout(t *testing.T) {
 	}
 }
 
+func TestWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// A source that blocks forever after its first elements
+	blocked := make(chan struct{})
+	defer close(blocked)
+	source := func(yield func(int) bool) {
+		for i := range 3 {
+			if !yield(i) {
+				return
+			}
+		}
+		<-blocked
+	}
+
+	var result []int
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for v := range WithContext[int](ctx)(source) {
+			result = append(result, v)
+			if v == 2 {
+				cancel()
+			}
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("WithContext did not end a blocked stream after cancel")
+	}
+	if !slices.Equal(result, []int{0, 1, 2}) {
+		t.Errorf("Expected [0 1 2], got %v", result)
+	}
+
+	// Without cancellation the stream passes through unchanged
+	all := slices.Collect(WithContext[int](context.Background())(slices.Values([]int{1, 2, 3})))
+	if !slices.Equal(all, []int{1, 2, 3}) {
+		t.Errorf("Expected [1 2 3], got %v", all)
+	}
+}
+
+func TestWithContextSafe(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var values []int
+	var errs []error
+	for v, err := range WithContextSafe[int](ctx)(Safe(slices.Values([]int{1, 2, 3, 4}))) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		values = append(values, v)
+		if v == 2 {
+			cancel()
+		}
+	}
+
+	if !slices.Equal(values, []int{1, 2}) {
+		t.Errorf("Expected [1 2], got %v", values)
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], context.Canceled) {
+		t.Errorf("Expected a single context.Canceled error, got %v", errs)
+	}
+}
+
+func TestWithContextSafeAsyncCancel(t *testing.T) {
+	// Cancelled from another goroutine while the consumer is busy, the
+	// stream must always end with ctx.Err(), never look like a clean end
+	endless := func(yield func(int, error) bool) {
+		for i := 0; ; i++ {
+			if !yield(i, nil) {
+				return
+			}
+		}
+	}
+	for run := range 100 {
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(100 * time.Microsecond)
+			cancel()
+		}()
+		var last error
+		for _, err := range WithContextSafe[int](ctx)(endless) {
+			if err != nil {
+				last = err
+			}
+			time.Sleep(10 * time.Microsecond)
+		}
+		cancel()
+		if !errors.Is(last, context.Canceled) {
+			t.Fatalf("Run %d: expected context.Canceled at the end, got %v", run, last)
+		}
+	}
+}
+
 func TestTimeBasedTimeout(t *testing.T) {
 	now := time.Now()
 	input := slices.Values([]Record{
@@ -0,0 +1,238 @@
+package ssql
+
+import (
+	"iter"
+	"runtime"
+	"sync"
+)
+
+// ============================================================================
+// PARALLEL TRANSFORM AND FILTER OPERATIONS
+// ============================================================================
+
+// ParallelOrder controls the output order of ParallelSelect and ParallelWhere
+type ParallelOrder int
+
+const (
+	// ParallelOrdered yields results in input order. A slow element holds
+	// back the results after it until it completes.
+	ParallelOrdered ParallelOrder = iota
+	// ParallelUnordered yields results as soon as they are ready.
+	ParallelUnordered
+)
+
+// ParallelSelect transforms elements like Select, calling fn from a pool
+// of worker goroutines. Use it when fn is expensive (expression evaluation,
+// hashing, parsing); for cheap functions the coordination costs more than
+// it saves. fn must be safe to call concurrently.
+//
+// workers of zero or less uses runtime.GOMAXPROCS(0) workers. The input is
+// read on the caller's goroutine and at most 2×workers elements are in
+// flight, so a slow consumer slows down reading. When the consumer stops
+// iterating the workers stop too. A panic in fn is re-raised on the
+// consumer's goroutine.
+//
+// Example:
+//
+//	scored := ssql.ParallelSelect(8, ssql.ParallelOrdered, func(r ssql.Record) ssql.Record {
+//	    return r.ToMutable().Float("score", expensiveScore(r)).Freeze()
+//	})(records)
+func ParallelSelect[T, U any](workers int, order ParallelOrder, fn func(T) U) Filter[T, U] {
+	return func(input iter.Seq[T]) iter.Seq[U] {
+		return func(yield func(U) bool) {
+			parallelMap(input, workers, order, fn, yield)
+		}
+	}
+}
+
+// ParallelSelectSafe transforms elements like SelectSafe, calling fn from
+// a pool of worker goroutines. Errors from the input are passed through in
+// position (see ParallelSelect).
+func ParallelSelectSafe[T, U any](workers int, order ParallelOrder, fn func(T) (U, error)) FilterWithErrors[T, U] {
+	return func(input iter.Seq2[T, error]) iter.Seq2[U, error] {
+		return func(yield func(U, error) bool) {
+			type result struct {
+				value U
+				err   error
+			}
+			apply := func(p valueErr[T]) result {
+				if p.err != nil {
+					var zero U
+					return result{zero, p.err}
+				}
+				v, err := fn(p.value)
+				return result{v, err}
+			}
+			parallelMap(valueErrs(input), workers, order, apply, func(r result) bool {
+				return yield(r.value, r.err)
+			})
+		}
+	}
+}
+
+// ParallelWhere filters elements like Where, calling predicate from a pool
+// of worker goroutines. predicate must be safe to call concurrently.
+// See ParallelSelect for workers, ordering and backpressure.
+//
+// Example:
+//
+//	matched := ssql.ParallelWhere(0, ssql.ParallelUnordered, func(r ssql.Record) bool {
+//	    return re.MatchString(ssql.GetOr(r, "message", ""))
+//	})(logs)
+func ParallelWhere[T any](workers int, order ParallelOrder, predicate func(T) bool) Filter[T, T] {
+	return func(input iter.Seq[T]) iter.Seq[T] {
+		return func(yield func(T) bool) {
+			type result struct {
+				value T
+				keep  bool
+			}
+			apply := func(v T) result {
+				return result{v, predicate(v)}
+			}
+			parallelMap(input, workers, order, apply, func(r result) bool {
+				return !r.keep || yield(r.value)
+			})
+		}
+	}
+}
+
+// ParallelWhereSafe filters elements like WhereSafe, calling predicate from
+// a pool of worker goroutines. Errors from the input are passed through in
+// position (see ParallelSelect).
+func ParallelWhereSafe[T any](workers int, order ParallelOrder, predicate func(T) (bool, error)) FilterWithErrors[T, T] {
+	return func(input iter.Seq2[T, error]) iter.Seq2[T, error] {
+		return func(yield func(T, error) bool) {
+			type result struct {
+				value T
+				keep  bool
+				err   error
+			}
+			apply := func(p valueErr[T]) result {
+				if p.err != nil {
+					return result{p.value, true, p.err}
+				}
+				keep, err := predicate(p.value)
+				return result{p.value, keep || err != nil, err}
+			}
+			parallelMap(valueErrs(input), workers, order, apply, func(r result) bool {
+				return !r.keep || yield(r.value, r.err)
+			})
+		}
+	}
+}
+
+// valueErr is one element of an iter.Seq2[T, error]
+type valueErr[T any] struct {
+	value T
+	err   error
+}
+
+// valueErrs turns an iter.Seq2[T, error] into a sequence of pairs
+func valueErrs[T any](input iter.Seq2[T, error]) iter.Seq[valueErr[T]] {
+	return func(yield func(valueErr[T]) bool) {
+		for v, err := range input {
+			if !yield(valueErr[T]{v, err}) {
+				return
+			}
+		}
+	}
+}
+
+// parallelMap passes fn(v) for each element v of input to yield, calling fn
+// on worker goroutines. The input is read on the calling goroutine, with at
+// most 2×workers elements in flight.
+func parallelMap[T, R any](input iter.Seq[T], workers int, order ParallelOrder, fn func(T) R, yield func(R) bool) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	window := 2 * workers
+
+	type job struct {
+		index int
+		value T
+	}
+	type result struct {
+		index    int
+		value    R
+		panicked any
+	}
+
+	// Both channels hold a full window, so neither the workers nor the
+	// dispatch loop below ever block on a send
+	jobs := make(chan job, window)
+	results := make(chan result, window)
+	stop := make(chan struct{})
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				select {
+				case <-stop:
+					return // The consumer is done: skip the remaining jobs
+				default:
+				}
+				results <- func() (r result) {
+					r.index = j.index
+					defer func() {
+						if p := recover(); p != nil {
+							r.panicked = p
+						}
+					}()
+					r.value = fn(j.value)
+					return r
+				}()
+			}
+		}()
+	}
+	defer func() {
+		close(stop)
+		close(jobs)
+		wg.Wait()
+	}()
+
+	pending := make(map[int]R) // ParallelOrdered: results waiting for earlier ones
+	next := 0
+	emit := func(r result) bool {
+		if r.panicked != nil {
+			panic(r.panicked)
+		}
+		if order == ParallelUnordered {
+			return yield(r.value)
+		}
+		pending[r.index] = r.value
+		for {
+			v, ok := pending[next]
+			if !ok {
+				return true
+			}
+			delete(pending, next)
+			next++
+			if !yield(v) {
+				return false
+			}
+		}
+	}
+
+	// Results held in pending count towards the window, so a slow element
+	// cannot make pending grow without bound
+	inFlight, index := 0, 0
+	for v := range input {
+		for inFlight+len(pending) >= window {
+			inFlight--
+			if !emit(<-results) {
+				return
+			}
+		}
+		jobs <- job{index, v}
+		index++
+		inFlight++
+	}
+	for ; inFlight > 0; inFlight-- {
+		if !emit(<-results) {
+			return
+		}
+	}
+}
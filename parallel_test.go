@@ -0,0 +1,176 @@
+package ssql
+
+import (
+	"errors"
+	"iter"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// ============================================================================
+// PARALLEL OPERATIONS TESTS
+// ============================================================================
+
+// countTo yields 0..n-1, counting how many elements were read
+func countTo(n int, read *atomic.Int64) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for i := range n {
+			read.Add(1)
+			if !yield(i) {
+				return
+			}
+		}
+	}
+}
+
+func TestParallelSelectOrdered(t *testing.T) {
+	var read atomic.Int64
+	square := func(n int) int {
+		// Later elements finish first
+		time.Sleep(time.Duration(100-n%100) * time.Microsecond)
+		return n * n
+	}
+
+	result := slices.Collect(ParallelSelect(4, ParallelOrdered, square)(countTo(500, &read)))
+	expected := slices.Collect(Select(func(n int) int { return n * n })(countTo(500, &read)))
+	if !slices.Equal(result, expected) {
+		t.Errorf("ParallelSelect ordered: results out of order or missing")
+	}
+}
+
+func TestParallelSelectUnordered(t *testing.T) {
+	var read atomic.Int64
+	result := slices.Collect(ParallelSelect(0, ParallelUnordered, func(n int) int { return n * 2 })(countTo(1000, &read)))
+	if len(result) != 1000 {
+		t.Fatalf("Expected 1000 results, got %d", len(result))
+	}
+	slices.Sort(result)
+	for i, v := range result {
+		if v != i*2 {
+			t.Fatalf("Expected %d at %d, got %d", i*2, i, v)
+		}
+	}
+}
+
+func TestParallelWhere(t *testing.T) {
+	var read atomic.Int64
+	even := func(n int) bool { return n%2 == 0 }
+
+	result := slices.Collect(ParallelWhere(3, ParallelOrdered, even)(countTo(100, &read)))
+	expected := slices.Collect(Where(even)(countTo(100, &read)))
+	if !slices.Equal(result, expected) {
+		t.Errorf("ParallelWhere: expected %v, got %v", expected, result)
+	}
+}
+
+func TestParallelEarlyStop(t *testing.T) {
+	var read, calls atomic.Int64
+	double := func(n int) int {
+		calls.Add(1)
+		return n * 2
+	}
+
+	result := slices.Collect(Limit[int](5)(ParallelSelect(4, ParallelOrdered, double)(countTo(1_000_000, &read))))
+	if !slices.Equal(result, []int{0, 2, 4, 6, 8}) {
+		t.Errorf("Expected first 5 results, got %v", result)
+	}
+	// Backpressure: only a window of 2×workers elements (plus the one
+	// waiting for room in the window) is read ahead
+	if read.Load() > 5+8+1 {
+		t.Errorf("Expected at most 14 elements read, got %d", read.Load())
+	}
+	if calls.Load() > read.Load() {
+		t.Errorf("fn called %d times for %d elements", calls.Load(), read.Load())
+	}
+}
+
+func TestParallelSafe(t *testing.T) {
+	errBad := errors.New("bad input")
+	input := func(yield func(int, error) bool) {
+		for i := range 10 {
+			var err error
+			if i == 3 {
+				err = errBad
+			}
+			if !yield(i, err) {
+				return
+			}
+		}
+	}
+	check := func(n int) (bool, error) {
+		if n == 7 {
+			return false, errors.New("seven")
+		}
+		return n%2 == 1, nil
+	}
+
+	var values []int
+	var errs []error
+	for v, err := range ParallelWhereSafe(4, ParallelOrdered, check)(input) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		values = append(values, v)
+	}
+	if !slices.Equal(values, []int{1, 5, 9}) {
+		t.Errorf("Expected [1 5 9], got %v", values)
+	}
+	if len(errs) != 2 || !errors.Is(errs[0], errBad) || errs[1].Error() != "seven" {
+		t.Errorf("Expected input error then predicate error, got %v", errs)
+	}
+
+	var squares []int
+	for v, err := range ParallelSelectSafe(2, ParallelOrdered, func(n int) (int, error) { return n * n, nil })(input) {
+		if err == nil {
+			squares = append(squares, v)
+		}
+	}
+	if !slices.Equal(squares, []int{0, 1, 4, 16, 25, 36, 49, 64, 81}) {
+		t.Errorf("Unexpected squares %v", squares)
+	}
+}
+
+func TestParallelPanic(t *testing.T) {
+	defer func() {
+		if p := recover(); p != "boom" {
+			t.Errorf("Expected panic 'boom', got %v", p)
+		}
+	}()
+
+	var read atomic.Int64
+	for range ParallelSelect(2, ParallelOrdered, func(n int) int {
+		if n == 3 {
+			panic("boom")
+		}
+		return n
+	})(countTo(10, &read)) {
+	}
+	t.Error("Expected panic")
+}
+
+func BenchmarkParallelSelect(b *testing.B) {
+	work := func(n int) int {
+		sum := 0
+		for i := range 20000 {
+			sum += i ^ n
+		}
+		return sum
+	}
+	var read atomic.Int64
+
+	b.Run("Select", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for range Select(work)(countTo(1000, &read)) {
+			}
+		}
+	})
+	b.Run("Parallel", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for range ParallelSelect(0, ParallelOrdered, work)(countTo(1000, &read)) {
+			}
+		}
+	})
+}
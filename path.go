@@ -0,0 +1,479 @@
+package ssql
+
+import (
+	"fmt"
+	"iter"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ============================================================================
+// NESTED FIELD PATHS
+// ============================================================================
+
+// Field paths address values inside nested data without flattening the stream:
+//
+//	user.address.city    nested Record fields (or JSON objects)
+//	items[0].sku         element 0 of an array, then its "sku" field
+//	matrix[1][2]         arrays of arrays
+//
+// A segment can step into a nested Record, a JSONString object or array,
+// or an iter.Seq field. An exact top-level field name always takes
+// precedence, so flattened fields such as "user.name" (from DotFlatten)
+// keep working unchanged.
+
+// pathSegment is one step of a parsed field path
+type pathSegment struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+func (s pathSegment) String() string {
+	if s.isIndex {
+		return fmt.Sprintf("[%d]", s.index)
+	}
+	return s.key
+}
+
+// isPath reports whether field uses path syntax
+func isPath(field string) bool {
+	return strings.ContainsAny(field, ".[")
+}
+
+// parsePath splits a field path into segments
+func parsePath(path string) ([]pathSegment, error) {
+	var segs []pathSegment
+	rest := path
+	expectKey := true
+	for rest != "" {
+		switch {
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid path %q: unclosed '['", path)
+			}
+			index, err := strconv.Atoi(rest[1:end])
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("invalid path %q: bad index %q", path, rest[1:end])
+			}
+			segs = append(segs, pathSegment{index: index, isIndex: true})
+			rest = rest[end+1:]
+			expectKey = false
+		case rest[0] == '.':
+			if expectKey {
+				return nil, fmt.Errorf("invalid path %q: empty field name", path)
+			}
+			rest = rest[1:]
+			expectKey = true
+			if rest == "" {
+				return nil, fmt.Errorf("invalid path %q: empty field name", path)
+			}
+		default:
+			if !expectKey {
+				return nil, fmt.Errorf("invalid path %q: expected '.' or '['", path)
+			}
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			segs = append(segs, pathSegment{key: rest[:end]})
+			rest = rest[end:]
+			expectKey = false
+		}
+	}
+	if len(segs) == 0 {
+		return nil, fmt.Errorf("invalid path %q: empty field name", path)
+	}
+	return segs, nil
+}
+
+// lookup returns the value of a top-level field, or of a nested path
+// when there is no top-level field with that exact name
+func (r Record) lookup(field string) (any, bool) {
+	if val, exists := r.fields[field]; exists {
+		return val, true
+	}
+	if !isPath(field) {
+		return nil, false
+	}
+	segs, err := parsePath(field)
+	if err != nil {
+		return nil, false
+	}
+	return lookupPath(r, segs)
+}
+
+// lookupPath walks segs from val. Values found inside JSONString data are
+// converted back to record values (integral numbers to int64, objects to
+// Record, arrays to JSONString, null to Null).
+func lookupPath(val any, segs []pathSegment) (any, bool) {
+	inJSON := false
+	for _, seg := range segs {
+		if js, ok := val.(JSONString); ok {
+			parsed, err := js.Parse()
+			if err != nil {
+				return nil, false
+			}
+			val, inJSON = parsed, true
+		}
+
+		var ok bool
+		switch v := val.(type) {
+		case Record:
+			if seg.isIndex {
+				return nil, false
+			}
+			val, ok = v.fields[seg.key]
+		case map[string]any:
+			if seg.isIndex {
+				return nil, false
+			}
+			val, ok = v[seg.key]
+		case []any:
+			if !seg.isIndex || seg.index >= len(v) {
+				return nil, false
+			}
+			val, ok = v[seg.index], true
+		default:
+			if !seg.isIndex || !isIterSeq(val) {
+				return nil, false
+			}
+			items := materializeSequence(val)
+			if seg.index >= len(items) {
+				return nil, false
+			}
+			val, ok = items[seg.index], true
+		}
+		if !ok {
+			return nil, false
+		}
+	}
+	if inJSON {
+		if val == nil {
+			return Null{}, true
+		}
+		return normalizeJSONValue(val), true
+	}
+	// Elements of sequences such as iter.Seq[int] are returned as canonical scalars
+	return canonicalScalar(val), true
+}
+
+// SetPath stores value at a nested field path, creating intermediate
+// Records for missing object fields (mutates in place). An existing
+// top-level field with exactly this name is overwritten instead.
+// Nested Records, JSONString values and sequences on the path are copied,
+// never modified, so other Records sharing them are unaffected.
+// Indexing past the end of a JSON array pads it with nulls; sequences only
+// allow appending at their length. value must be a valid Record value.
+//
+// Example:
+//
+//	mut := record.ToMutable()
+//	if err := mut.SetPath("user.address.city", "Paris"); err != nil {
+//	    return err
+//	}
+//	mut.SetPath("items[0].qty", int64(2))
+func (m MutableRecord) SetPath(path string, value any) error {
+	if !isValueType(value) {
+		return fmt.Errorf("path %q: invalid value type %T", path, value)
+	}
+	if _, exists := m.fields[path]; exists {
+		m.set(path, value)
+		return nil
+	}
+	segs, err := parsePath(path)
+	if err != nil {
+		return err
+	}
+	if segs[0].isIndex {
+		return fmt.Errorf("path %q: must start with a field name", path)
+	}
+	key := segs[0].key
+	if len(segs) == 1 {
+		m.set(key, value)
+		return nil
+	}
+	updated, err := setInPath(m.fields[key], segs[1:], value, false)
+	if err != nil {
+		return fmt.Errorf("path %q: %w", path, err)
+	}
+	m.set(key, updated)
+	return nil
+}
+
+// DeletePath removes the value at a nested field path (mutates in place).
+// An exact top-level field name is deleted first; otherwise the path is
+// followed and the last segment is removed from its object or array.
+// Returns false if nothing was found to delete.
+//
+// Example:
+//
+//	mut.DeletePath("user.password")
+//	mut.DeletePath("items[2]")
+func (m MutableRecord) DeletePath(path string) bool {
+	if _, exists := m.fields[path]; exists {
+		m.remove(path)
+		return true
+	}
+	if !isPath(path) {
+		return false
+	}
+	segs, err := parsePath(path)
+	if err != nil || segs[0].isIndex || len(segs) < 2 {
+		return false
+	}
+	key := segs[0].key
+	child, exists := m.fields[key]
+	if !exists {
+		return false
+	}
+	updated, ok := deleteInPath(child, segs[1:], false)
+	if ok {
+		m.set(key, updated)
+	}
+	return ok
+}
+
+// Project returns a new Record containing only the given fields.
+// Nested paths are copied to the same nested position, so
+// Project("id", "user.address.city") keeps {"id", "user": {"address": {"city"}}}.
+// Fields that do not exist are skipped.
+//
+// Example:
+//
+//	slim := ssql.Select(func(r ssql.Record) ssql.Record {
+//	    return r.Project("id", "customer.name", "items[0].sku")
+//	})(orders)
+func (r Record) Project(fields ...string) Record {
+	result := MakeMutableRecordWithCapacity(len(fields))
+	for _, field := range fields {
+		if val, exists := r.fields[field]; exists {
+			result.set(field, val)
+			continue
+		}
+		if val, exists := r.lookup(field); exists {
+			// Cannot fail: the path resolved in r and val is a record value
+			_ = result.SetPath(field, val)
+		}
+	}
+	return result.Freeze()
+}
+
+// setInPath returns a copy of container with value stored at segs
+func setInPath(container any, segs []pathSegment, value any, inJSON bool) (any, error) {
+	seg, rest := segs[0], segs[1:]
+	next := func(child any) (any, error) {
+		if len(rest) == 0 {
+			return value, nil
+		}
+		return setInPath(child, rest, value, inJSON)
+	}
+
+	switch c := container.(type) {
+	case nil, Null:
+		// Missing (or null) containers are created: objects for field
+		// segments, JSON arrays for index segments
+		switch {
+		case seg.isIndex && inJSON:
+			return setInPath([]any{}, segs, value, inJSON)
+		case seg.isIndex:
+			return setInPath(JSONString("[]"), segs, value, inJSON)
+		case inJSON:
+			return setInPath(map[string]any{}, segs, value, inJSON)
+		default:
+			return setInPath(MakeMutableRecord().Freeze(), segs, value, inJSON)
+		}
+	case Record:
+		if seg.isIndex {
+			return nil, fmt.Errorf("cannot index Record with %s", seg)
+		}
+		child, err := next(c.fields[seg.key])
+		if err != nil {
+			return nil, err
+		}
+		updated := c.ToMutable()
+		updated.set(seg.key, child)
+		return updated.Freeze(), nil
+	case map[string]any:
+		if seg.isIndex {
+			return nil, fmt.Errorf("cannot index object with %s", seg)
+		}
+		obj := maps.Clone(c)
+		child, err := next(obj[seg.key])
+		if err != nil {
+			return nil, err
+		}
+		obj[seg.key] = child
+		return obj, nil
+	case []any:
+		if !seg.isIndex {
+			return nil, fmt.Errorf("cannot read field %q of an array", seg.key)
+		}
+		return setSliceElement(c, seg, next)
+	case JSONString:
+		parsed, err := c.Parse()
+		if err != nil {
+			return nil, err
+		}
+		updated, err := setInPath(parsed, segs, pathJSONValue(value), true)
+		if err != nil {
+			return nil, err
+		}
+		return NewJSONString(updated)
+	case iter.Seq[Record]:
+		if !seg.isIndex {
+			return nil, fmt.Errorf("cannot read field %q of a sequence", seg.key)
+		}
+		items := slices.Collect(c)
+		updated, err := setSliceElement(items, seg, func(child any) (any, error) {
+			v, err := next(child)
+			if err != nil {
+				return nil, err
+			}
+			r, ok := v.(Record)
+			if !ok {
+				return nil, fmt.Errorf("iter.Seq[Record] element must be a Record, got %T", v)
+			}
+			return r, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return slices.Values(updated), nil
+	default:
+		return nil, fmt.Errorf("cannot set %s inside %T", seg, container)
+	}
+}
+
+// setSliceElement returns a copy of items with element seg.index replaced by
+// next(old element). An index equal to len(items) appends; JSON arrays
+// ([]any) are padded with nulls for larger indexes.
+func setSliceElement[E any](items []E, seg pathSegment, next func(any) (any, error)) ([]E, error) {
+	if seg.index > len(items) {
+		padded, isJSON := any(items).([]any)
+		if !isJSON {
+			return nil, fmt.Errorf("index %s out of range (length %d)", seg, len(items))
+		}
+		items = any(append(slices.Clone(padded), make([]any, seg.index-len(padded))...)).([]E)
+	}
+	var old any
+	if seg.index < len(items) {
+		old = items[seg.index]
+	}
+	v, err := next(old)
+	if err != nil {
+		return nil, err
+	}
+	elem, ok := v.(E)
+	if !ok {
+		return nil, fmt.Errorf("cannot store %T in element %s", v, seg)
+	}
+	updated := slices.Clone(items)
+	if seg.index == len(items) {
+		return append(updated, elem), nil
+	}
+	updated[seg.index] = elem
+	return updated, nil
+}
+
+// deleteInPath returns a copy of container with the value at segs removed
+func deleteInPath(container any, segs []pathSegment, inJSON bool) (any, bool) {
+	seg, rest := segs[0], segs[1:]
+
+	switch c := container.(type) {
+	case Record:
+		child, exists := c.fields[seg.key]
+		if seg.isIndex || !exists {
+			return nil, false
+		}
+		fields := c.ToMutable()
+		if len(rest) == 0 {
+			fields.remove(seg.key)
+		} else if updated, ok := deleteInPath(child, rest, inJSON); ok {
+			fields.set(seg.key, updated)
+		} else {
+			return nil, false
+		}
+		return fields.Freeze(), true
+	case map[string]any:
+		child, exists := c[seg.key]
+		if seg.isIndex || !exists {
+			return nil, false
+		}
+		obj := maps.Clone(c)
+		if len(rest) == 0 {
+			delete(obj, seg.key)
+		} else if updated, ok := deleteInPath(child, rest, inJSON); ok {
+			obj[seg.key] = updated
+		} else {
+			return nil, false
+		}
+		return obj, true
+	case []any:
+		return deleteSliceElement(c, seg, rest, inJSON)
+	case JSONString:
+		parsed, err := c.Parse()
+		if err != nil {
+			return nil, false
+		}
+		updated, ok := deleteInPath(parsed, segs, true)
+		if !ok {
+			return nil, false
+		}
+		js, err := NewJSONString(updated)
+		return js, err == nil
+	case iter.Seq[Record]:
+		updated, ok := deleteSliceElement(slices.Collect(c), seg, rest, inJSON)
+		if !ok {
+			return nil, false
+		}
+		return slices.Values(updated), true
+	default:
+		return nil, false
+	}
+}
+
+// deleteSliceElement removes element seg.index, or deletes rest inside it
+func deleteSliceElement[E any](items []E, seg pathSegment, rest []pathSegment, inJSON bool) ([]E, bool) {
+	if !seg.isIndex || seg.index >= len(items) {
+		return nil, false
+	}
+	if len(rest) == 0 {
+		return slices.Delete(slices.Clone(items), seg.index, seg.index+1), true
+	}
+	updated, ok := deleteInPath(items[seg.index], rest, inJSON)
+	if !ok {
+		return nil, false
+	}
+	elem, ok := updated.(E)
+	if !ok {
+		return nil, false
+	}
+	result := slices.Clone(items)
+	result[seg.index] = elem
+	return result, true
+}
+
+// pathJSONValue converts a record value for storage inside parsed JSON data
+func pathJSONValue(value any) any {
+	switch v := value.(type) {
+	case JSONString:
+		if parsed, err := v.Parse(); err == nil {
+			return parsed
+		}
+		return string(v)
+	default:
+		if isIterSeq(value) {
+			items := materializeSequence(value)
+			result := make([]any, len(items))
+			for i, item := range items {
+				result[i] = pathJSONValue(item)
+			}
+			return result
+		}
+		return convertRecordValueForJSON(value)
+	}
+}
@@ -0,0 +1,195 @@
+package ssql
+
+import (
+	"iter"
+	"slices"
+	"testing"
+)
+
+func pathTestOrder() Record {
+	address := MakeMutableRecord().String("city", "Paris").String("zip", "75001").Freeze()
+	customer := MakeMutableRecord().Int("id", 7).Nested("address", address).Freeze()
+	return MakeMutableRecord().
+		Int("id", 1).
+		Nested("customer", customer).
+		JSONString("items", JSONString(`[{"sku": "A1", "qty": 2}, {"sku": "B2", "qty": 1, "note": null}]`)).
+		StringSeq("tags", slices.Values([]string{"new", "vip"})).
+		String("flat.name", "kept").
+		Freeze()
+}
+
+func TestParsePath(t *testing.T) {
+	segs, err := parsePath("items[1].sku")
+	if err != nil || len(segs) != 3 || segs[0].key != "items" || !segs[1].isIndex || segs[1].index != 1 || segs[2].key != "sku" {
+		t.Errorf("Unexpected segments %v, %v", segs, err)
+	}
+	for _, bad := range []string{"", ".a", "a.", "a..b", "a[", "a[x]", "a[-1]", "a[0]b"} {
+		if _, err := parsePath(bad); err == nil {
+			t.Errorf("parsePath(%q): expected error", bad)
+		}
+	}
+}
+
+func TestGetPath(t *testing.T) {
+	r := pathTestOrder()
+
+	if city := GetOr(r, "customer.address.city", ""); city != "Paris" {
+		t.Errorf("Expected Paris, got %q", city)
+	}
+	if id, ok := Get[int64](r, "customer.id"); !ok || id != 7 {
+		t.Errorf("Expected customer.id 7, got %v, %v", id, ok)
+	}
+	if sku := GetOr(r, "items[1].sku", ""); sku != "B2" {
+		t.Errorf("Expected B2, got %q", sku)
+	}
+	if qty, ok := r.lookup("items[0].qty"); !ok || qty != int64(2) {
+		t.Errorf("Expected JSON integer as int64, got %v (%T)", qty, qty)
+	}
+	if note, ok := r.lookup("items[1].note"); !ok || !IsNull(note) {
+		t.Errorf("Expected JSON null as Null, got %v (%T)", note, note)
+	}
+	if item, ok := Get[Record](r, "items[0]"); !ok || GetOr(item, "sku", "") != "A1" {
+		t.Errorf("Expected JSON object as Record, got %v", item)
+	}
+	if tag := GetOr(r, "tags[1]", ""); tag != "vip" {
+		t.Errorf("Expected vip, got %q", tag)
+	}
+	if GetOr(r, "flat.name", "") != "kept" {
+		t.Error("Exact top-level field names must take precedence over paths")
+	}
+	for _, missing := range []string{"customer.phone", "items[5].sku", "id.x", "customer[0]", "tags.x"} {
+		if _, ok := Get[any](r, missing); ok {
+			t.Errorf("Expected %q to be missing", missing)
+		}
+	}
+}
+
+func TestSetPath(t *testing.T) {
+	original := pathTestOrder()
+	mut := original.ToMutable()
+
+	if err := mut.SetPath("customer.address.city", "Lyon"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := mut.SetPath("shipping.method.name", "express"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := mut.SetPath("items[0].qty", int64(5)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := mut.SetPath("items[2].sku", "C3"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := mut.SetPath("flat.name", "changed"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	r := mut.Freeze()
+
+	if GetOr(r, "customer.address.city", "") != "Lyon" || GetOr(r, "customer.address.zip", "") != "75001" {
+		t.Errorf("Nested set lost sibling fields: %v", r)
+	}
+	if GetOr(r, "shipping.method.name", "") != "express" {
+		t.Error("Expected intermediate Records to be created")
+	}
+	if GetOr(r, "items[0].qty", int64(0)) != 5 || GetOr(r, "items[2].sku", "") != "C3" {
+		t.Errorf("Unexpected items after set: %v", r.fields["items"])
+	}
+	if GetOr(r, "flat.name", "") != "changed" || r.Has("flat") {
+		t.Error("Existing top-level field should be overwritten, not nested")
+	}
+
+	// The original record and its nested values are unchanged
+	if GetOr(original, "customer.address.city", "") != "Paris" || GetOr(original, "items[0].qty", int64(0)) != 2 {
+		t.Error("SetPath must not modify shared nested values")
+	}
+
+	if err := mut.SetPath("id.x", "nope"); err == nil {
+		t.Error("Expected error setting a field inside a scalar")
+	}
+	if err := mut.SetPath("customer.id", 3); err == nil {
+		t.Error("Expected error for non-canonical value type")
+	}
+}
+
+func TestSetPathRecordSeq(t *testing.T) {
+	lines := slices.Values([]Record{MakeMutableRecord().String("sku", "A").Freeze()})
+	mut := MakeMutableRecord().RecordSeq("lines", lines)
+
+	if err := mut.SetPath("lines[0].qty", int64(3)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	seq, ok := Get[iter.Seq[Record]](mut.Freeze(), "lines")
+	if !ok {
+		t.Fatalf("Expected lines to stay an iter.Seq[Record]")
+	}
+	got := slices.Collect(seq)
+	if len(got) != 1 || GetOr(got[0], "qty", int64(0)) != 3 || GetOr(got[0], "sku", "") != "A" {
+		t.Errorf("Unexpected lines: %v", got)
+	}
+}
+
+func TestDeletePath(t *testing.T) {
+	original := pathTestOrder()
+	mut := original.ToMutable()
+
+	if !mut.DeletePath("customer.address.zip") || !mut.DeletePath("items[0]") || !mut.DeletePath("flat.name") {
+		t.Fatal("Expected deletes to succeed")
+	}
+	if mut.DeletePath("customer.phone") || mut.DeletePath("items[9]") || mut.DeletePath("nope") {
+		t.Error("Deleting missing paths should report false")
+	}
+	r := mut.Freeze()
+
+	if r.Has("flat.name") || r.Has("flat") {
+		t.Error("Expected top-level dotted field to be deleted")
+	}
+	if _, ok := Get[any](r, "customer.address.zip"); ok || GetOr(r, "customer.address.city", "") != "Paris" {
+		t.Error("Expected only zip to be removed")
+	}
+	if GetOr(r, "items[0].sku", "") != "B2" {
+		t.Errorf("Expected remaining item B2 first, got %v", r.fields["items"])
+	}
+	if GetOr(original, "customer.address.zip", "") != "75001" {
+		t.Error("DeletePath must not modify shared nested values")
+	}
+}
+
+func TestProject(t *testing.T) {
+	r := pathTestOrder().Project("id", "customer.address.city", "items[1].sku", "flat.name", "missing")
+
+	if !slices.Equal(slices.Sorted(slices.Values(r.Keys())), []string{"customer", "flat.name", "id", "items"}) {
+		t.Errorf("Unexpected keys %v", r.Keys())
+	}
+	if GetOr(r, "customer.address.city", "") != "Paris" || r.Has("customer.id") {
+		t.Errorf("Expected only the projected nested field, got %v", r.fields["customer"])
+	}
+	if _, ok := Get[any](r, "customer.address.zip"); ok {
+		t.Error("Sibling nested fields should not be projected")
+	}
+	if GetOr(r, "items[1].sku", "") != "B2" || !IsNull(r.fields["items"].(JSONString).MustParse().([]any)[0]) {
+		t.Errorf("Expected items padded with null, got %v", r.fields["items"])
+	}
+}
+
+func TestPathJoinAndGroup(t *testing.T) {
+	orders := slices.Values([]Record{
+		MakeMutableRecord().Int("order", 1).Nested("customer", MakeMutableRecord().Int("id", 7).Freeze()).Freeze(),
+		MakeMutableRecord().Int("order", 2).Nested("customer", MakeMutableRecord().Int("id", 8).Freeze()).Freeze(),
+		MakeMutableRecord().Int("order", 3).Nested("customer", MakeMutableRecord().Int("id", 7).Freeze()).Freeze(),
+	})
+	customers := slices.Values([]Record{
+		MakeMutableRecord().Nested("customer", MakeMutableRecord().Int("id", 7).String("name", "Ann").Freeze()).Freeze(),
+	})
+
+	joined := slices.Collect(InnerJoin(customers, OnFields("customer.id"))(orders))
+	if len(joined) != 2 {
+		t.Errorf("Expected 2 joined rows, got %d", len(joined))
+	}
+
+	groups := slices.Collect(Aggregate("rows", map[string]AggregateFunc{
+		"n": Count(),
+	})(GroupByFields("rows", "customer.id")(orders)))
+	if len(groups) != 2 || GetOr(groups[0], "customer.id", int64(0)) != 7 || GetOr(groups[0], "n", int64(0)) != 2 {
+		t.Errorf("Unexpected groups %v", groups)
+	}
+}
@@ -0,0 +1,372 @@
+package ssql
+
+import (
+	"context"
+	"iter"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// CLOCKS
+// ============================================================================
+
+// Clock is the time source of RateLimit, Throttle and BatchByTimeOrCount.
+// The filters use SystemClock unless one is passed, so tests can drive
+// them with a ManualClock instead of sleeping.
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+	// NewTimer returns a timer that fires once d has passed
+	NewTimer(d time.Duration) ClockTimer
+}
+
+// ClockTimer is a single-shot timer created by a Clock
+type ClockTimer interface {
+	// C returns the channel that receives the time when the timer fires
+	C() <-chan time.Time
+	// Stop prevents the timer from firing, reporting whether it was pending
+	Stop() bool
+}
+
+// SystemClock returns the Clock backed by the time package
+func SystemClock() Clock {
+	return systemClock{}
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+func (systemClock) NewTimer(d time.Duration) ClockTimer { return systemTimer{time.NewTimer(d)} }
+
+type systemTimer struct{ t *time.Timer }
+
+func (t systemTimer) C() <-chan time.Time { return t.t.C }
+
+func (t systemTimer) Stop() bool { return t.t.Stop() }
+
+// clockOrSystem returns the first clock given, or SystemClock
+func clockOrSystem(clock []Clock) Clock {
+	if len(clock) > 0 && clock[0] != nil {
+		return clock[0]
+	}
+	return SystemClock()
+}
+
+// ManualClock is a Clock whose time only moves when Advance is called,
+// for testing time-dependent pipelines without sleeping. It is safe for
+// concurrent use.
+//
+// Example:
+//
+//	clock := ssql.NewManualClock(time.Now())
+//	batches := ssql.BatchByTimeOrCount[ssql.Record](100, time.Second, clock)(events)
+//	// ... once a batch is waiting, fire its timeout:
+//	clock.Advance(time.Second)
+type ManualClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*manualTimer
+}
+
+// NewManualClock returns a ManualClock set to start
+func NewManualClock(start time.Time) *ManualClock {
+	return &ManualClock{now: start}
+}
+
+// Now returns the clock's current time
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// NewTimer returns a timer that fires when the clock is advanced by d;
+// a timer with d <= 0 fires immediately
+func (c *ManualClock) NewTimer(d time.Duration) ClockTimer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &manualTimer{clock: c, at: c.now.Add(d), ch: make(chan time.Time, 1)}
+	if d <= 0 {
+		t.ch <- c.now
+		return t
+	}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// Advance moves the clock forward by d, firing the timers that come due
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	pending := c.timers[:0]
+	for _, t := range c.timers {
+		if t.at.After(c.now) {
+			pending = append(pending, t)
+			continue
+		}
+		t.ch <- c.now
+	}
+	clear(c.timers[len(pending):])
+	c.timers = pending
+}
+
+// PendingTimers returns the number of timers waiting to fire, so a test
+// can tell when a filter has started waiting
+func (c *ManualClock) PendingTimers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+// manualTimer is a timer of a ManualClock
+type manualTimer struct {
+	clock *ManualClock
+	at    time.Time
+	ch    chan time.Time
+}
+
+func (t *manualTimer) C() <-chan time.Time { return t.ch }
+
+func (t *manualTimer) Stop() bool {
+	c := t.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, pending := range c.timers {
+		if pending == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// ============================================================================
+// RATE LIMITING, THROTTLING AND TIME-BASED BATCHING
+// ============================================================================
+
+// RateLimit passes elements through at no more than perSecond on average,
+// allowing bursts of up to burst elements (at least 1) after a quiet
+// period. The consumer's goroutine waits before yielding an element that
+// would exceed the rate, so a slow source is never delayed further and
+// nothing runs once iteration stops. perSecond <= 0 disables the limit.
+//
+// Use RateLimitCtx to end the stream, including a wait in progress, on
+// cancellation.
+//
+// Example:
+//
+//	// At most 50 requests per second to the downstream API, bursts of 10
+//	for r := range ssql.RateLimit[ssql.Record](50, 10)(events) {
+//	    post(r)
+//	}
+func RateLimit[T any](perSecond float64, burst int, clock ...Clock) Filter[T, T] {
+	return RateLimitCtx[T](context.Background(), perSecond, burst, clock...)
+}
+
+// RateLimitCtx limits the rate of elements like RateLimit and ends the
+// stream when ctx is cancelled or its deadline passes, without waiting for
+// the element being held back.
+//
+// Example:
+//
+//	// Stop posting as soon as the request is cancelled
+//	for r := range ssql.RateLimitCtx[ssql.Record](req.Context(), 50, 10)(events) {
+//	    post(r)
+//	}
+func RateLimitCtx[T any](ctx context.Context, perSecond float64, burst int, clock ...Clock) Filter[T, T] {
+	clk := clockOrSystem(clock)
+	if burst < 1 {
+		burst = 1
+	}
+	return func(input iter.Seq[T]) iter.Seq[T] {
+		return func(yield func(T) bool) {
+			if perSecond <= 0 {
+				for v := range input {
+					if ctx.Err() != nil || !yield(v) {
+						return
+					}
+				}
+				return
+			}
+
+			// Each element is due one interval after the previous one;
+			// up to burst-1 intervals of credit may build up while idle
+			interval := time.Duration(float64(time.Second) / perSecond)
+			tolerance := time.Duration(burst-1) * interval
+			var due time.Time
+			for v := range input {
+				if ctx.Err() != nil {
+					return
+				}
+				now := clk.Now()
+				if due.Before(now) {
+					due = now
+				}
+				if wait := due.Sub(now) - tolerance; wait > 0 {
+					timer := clk.NewTimer(wait)
+					select {
+					case <-timer.C():
+					case <-ctx.Done():
+						timer.Stop()
+						return
+					}
+				}
+				due = due.Add(interval)
+				if !yield(v) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Throttle emits at most one element per interval: the first element
+// after a quiet period starts an interval, and when it ends the latest
+// element received during it is emitted; the elements it replaced are
+// dropped. A pending element is emitted at once when the input ends.
+// Use it to sample fast-changing state, such as the latest reading of a
+// sensor, at a bounded rate.
+//
+// The input is read on a separate goroutine so intervals end on time
+// while the input is waiting; when iteration stops that goroutine stops at
+// the input's next element.
+//
+// Example:
+//
+//	// Refresh the dashboard with the newest metrics at most once a second
+//	for m := range ssql.Throttle[ssql.Record](time.Second)(metrics) {
+//	    dashboard.Update(m)
+//	}
+func Throttle[T any](interval time.Duration, clock ...Clock) Filter[T, T] {
+	clk := clockOrSystem(clock)
+	return func(input iter.Seq[T]) iter.Seq[T] {
+		return func(yield func(T) bool) {
+			values, stop := readAsync(input)
+			defer stop()
+
+			var latest T
+			var pending bool
+			var timer ClockTimer
+			var tick <-chan time.Time
+			defer func() {
+				if timer != nil {
+					timer.Stop()
+				}
+			}()
+
+			for {
+				select {
+				case v, ok := <-values:
+					if !ok {
+						if pending {
+							yield(latest)
+						}
+						return
+					}
+					latest, pending = v, true
+					if tick == nil {
+						timer = clk.NewTimer(interval)
+						tick = timer.C()
+					}
+				case <-tick:
+					timer, tick = nil, nil
+					pending = false
+					if !yield(latest) {
+						return
+					}
+				}
+			}
+		}
+	}
+}
+
+// BatchByTimeOrCount groups elements into slices, emitting a batch as soon
+// as it holds maxItems elements or maxWait has passed since its first
+// element, whichever comes first, so downstream sees full batches under
+// load and prompt partial ones when the stream is quiet. The last partial
+// batch is emitted when the input ends. maxItems <= 0 batches by time only,
+// and maxWait <= 0 by count only.
+//
+// The input is read on a separate goroutine so batches are emitted on time
+// while the input is waiting; when iteration stops that goroutine stops at
+// the input's next element.
+//
+// Example:
+//
+//	// Bulk-insert up to 500 rows at a time, never holding a row over 2s
+//	for batch := range ssql.BatchByTimeOrCount[ssql.Record](500, 2*time.Second)(events) {
+//	    db.InsertMany(batch)
+//	}
+func BatchByTimeOrCount[T any](maxItems int, maxWait time.Duration, clock ...Clock) Filter[T, []T] {
+	clk := clockOrSystem(clock)
+	return func(input iter.Seq[T]) iter.Seq[[]T] {
+		return func(yield func([]T) bool) {
+			values, stop := readAsync(input)
+			defer stop()
+
+			var batch []T
+			var timer ClockTimer
+			var tick <-chan time.Time
+			stopTimer := func() {
+				if timer != nil {
+					timer.Stop()
+				}
+				timer, tick = nil, nil
+			}
+			defer stopTimer()
+			flush := func() bool {
+				stopTimer()
+				out := batch
+				batch = nil
+				return yield(out)
+			}
+
+			for {
+				select {
+				case v, ok := <-values:
+					if !ok {
+						if len(batch) > 0 {
+							flush()
+						}
+						return
+					}
+					batch = append(batch, v)
+					if len(batch) == 1 && maxWait > 0 {
+						timer = clk.NewTimer(maxWait)
+						tick = timer.C()
+					}
+					if maxItems > 0 && len(batch) >= maxItems && !flush() {
+						return
+					}
+				case <-tick:
+					timer, tick = nil, nil
+					if !flush() {
+						return
+					}
+				}
+			}
+		}
+	}
+}
+
+// readAsync reads input on a new goroutine, passing its elements through
+// the returned channel, which is closed when the input ends. Calling stop
+// makes the goroutine return at the input's next element.
+func readAsync[T any](input iter.Seq[T]) (values <-chan T, stop func()) {
+	ch := make(chan T)
+	done := make(chan struct{})
+	go func() {
+		defer close(ch)
+		for v := range input {
+			select {
+			case ch <- v:
+			case <-done:
+				return
+			}
+		}
+	}()
+	return ch, func() { close(done) }
+}
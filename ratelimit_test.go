@@ -0,0 +1,243 @@
+package ssql
+
+import (
+	"context"
+	"iter"
+	"slices"
+	"testing"
+	"time"
+)
+
+// ============================================================================
+// RATE LIMITING AND TIME-BASED BATCHING TESTS
+// ============================================================================
+
+// instantClock is a ManualClock that advances itself to each timer's
+// deadline, so waits take no real time
+type instantClock struct {
+	*ManualClock
+}
+
+func (c instantClock) NewTimer(d time.Duration) ClockTimer {
+	t := c.ManualClock.NewTimer(d)
+	c.Advance(d)
+	return t
+}
+
+// chanInput returns a stream fed by send, which returns once the filter
+// reading the stream has received the element, and a func ending it
+func chanInput[T any]() (input iter.Seq[T], send func(T), end func()) {
+	values := make(chan T)
+	received := make(chan struct{})
+	input = func(yield func(T) bool) {
+		for v := range values {
+			if !yield(v) {
+				return
+			}
+			received <- struct{}{}
+		}
+	}
+	send = func(v T) {
+		values <- v
+		<-received
+	}
+	return input, send, func() { close(values) }
+}
+
+// collectAsync consumes output on a new goroutine, passing it on
+func collectAsync[T any](output iter.Seq[T]) <-chan T {
+	ch := make(chan T)
+	go func() {
+		defer close(ch)
+		for v := range output {
+			ch <- v
+		}
+	}()
+	return ch
+}
+
+// waitForTimers waits until clock has n pending timers
+func waitForTimers(t *testing.T, clock *ManualClock, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for clock.PendingTimers() != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d pending timers, have %d", n, clock.PendingTimers())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// receive returns the next output or fails after a timeout
+func receive[T any](t *testing.T, ch <-chan T) T {
+	t.Helper()
+	select {
+	case v := <-ch:
+		return v
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for output")
+		panic("unreachable")
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := instantClock{NewManualClock(start)}
+
+	var offsets []time.Duration
+	for range RateLimit[int](10, 3, clock)(slices.Values(make([]int, 6))) {
+		offsets = append(offsets, clock.Now().Sub(start))
+	}
+	ms := time.Millisecond
+	if want := []time.Duration{0, 0, 0, 100 * ms, 200 * ms, 300 * ms}; !slices.Equal(offsets, want) {
+		t.Errorf("Burst of 3 at 10/s yielded at %v, want %v", offsets, want)
+	}
+
+	// Idle time builds up burst credit again, but no more than burst
+	clock.Advance(time.Second)
+	offsets = offsets[:0]
+	resumed := clock.Now()
+	for range RateLimit[int](10, 2, clock)(slices.Values(make([]int, 4))) {
+		offsets = append(offsets, clock.Now().Sub(resumed))
+	}
+	if want := []time.Duration{0, 0, 100 * ms, 200 * ms}; !slices.Equal(offsets, want) {
+		t.Errorf("Burst of 2 yielded at %v, want %v", offsets, want)
+	}
+
+	if got := slices.Collect(RateLimit[int](0, 0, clock)(slices.Values([]int{1, 2, 3}))); !slices.Equal(got, []int{1, 2, 3}) {
+		t.Errorf("Unlimited RateLimit = %v", got)
+	}
+}
+
+func TestRateLimitCtxCancelWhileWaiting(t *testing.T) {
+	clock := NewManualClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	out := collectAsync(RateLimitCtx[int](ctx, 1, 1, clock)(slices.Values([]int{1, 2, 3})))
+	if v := receive(t, out); v != 1 {
+		t.Fatalf("First element = %d", v)
+	}
+	waitForTimers(t, clock, 1) // holding back the second element
+	cancel()
+	select {
+	case v, ok := <-out:
+		if ok {
+			t.Errorf("Expected the stream to end on cancel, got %d", v)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Cancel did not end the wait")
+	}
+	if n := clock.PendingTimers(); n != 0 {
+		t.Errorf("Expected the wait timer to be stopped, %d pending", n)
+	}
+
+	// Already cancelled: nothing is yielded
+	if got := slices.Collect(RateLimitCtx[int](ctx, 0, 0, clock)(slices.Values([]int{1}))); len(got) != 0 {
+		t.Errorf("Cancelled RateLimitCtx yielded %v", got)
+	}
+}
+
+func TestThrottle(t *testing.T) {
+	clock := NewManualClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	input, send, end := chanInput[string]()
+	out := collectAsync(Throttle[string](time.Second, clock)(input))
+
+	send("a")
+	waitForTimers(t, clock, 1)
+	send("b")
+	send("c")
+	clock.Advance(time.Second)
+	if got := receive(t, out); got != "c" {
+		t.Errorf("First interval emitted %q, want the latest, c", got)
+	}
+
+	// A quiet period emits nothing; the next element starts a new interval
+	send("d")
+	waitForTimers(t, clock, 1)
+	clock.Advance(999 * time.Millisecond)
+	send("e")
+	clock.Advance(time.Millisecond)
+	if got := receive(t, out); got != "e" {
+		t.Errorf("Second interval emitted %q, want e", got)
+	}
+
+	// The pending element is flushed when the input ends
+	send("f")
+	end()
+	if got := receive(t, out); got != "f" {
+		t.Errorf("End of input emitted %q, want f", got)
+	}
+	if _, open := <-out; open {
+		t.Error("Expected the output to end")
+	}
+	if clock.PendingTimers() != 0 {
+		t.Errorf("Throttle left %d timers pending", clock.PendingTimers())
+	}
+}
+
+func TestBatchByTimeOrCount(t *testing.T) {
+	clock := NewManualClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	input, send, end := chanInput[int]()
+	out := collectAsync(BatchByTimeOrCount[int](3, time.Second, clock)(input))
+
+	// Count limit
+	send(1)
+	send(2)
+	send(3)
+	if got := receive(t, out); !slices.Equal(got, []int{1, 2, 3}) {
+		t.Errorf("Full batch = %v", got)
+	}
+	waitForTimers(t, clock, 0)
+
+	// Time limit, measured from the batch's first element
+	send(4)
+	waitForTimers(t, clock, 1)
+	clock.Advance(500 * time.Millisecond)
+	send(5)
+	clock.Advance(500 * time.Millisecond)
+	if got := receive(t, out); !slices.Equal(got, []int{4, 5}) {
+		t.Errorf("Timed-out batch = %v", got)
+	}
+
+	// Partial batch at the end of input
+	send(6)
+	end()
+	if got := receive(t, out); !slices.Equal(got, []int{6}) {
+		t.Errorf("Final batch = %v", got)
+	}
+	if _, open := <-out; open {
+		t.Error("Expected the output to end")
+	}
+}
+
+func TestBatchByTimeOrCountEarlyStop(t *testing.T) {
+	finished := make(chan struct{})
+	source := func(yield func(int) bool) {
+		defer close(finished)
+		for i := 0; ; i++ {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+	clock := NewManualClock(time.Now())
+	var batches [][]int
+	for batch := range BatchByTimeOrCount[int](4, time.Minute, clock)(iter.Seq[int](source)) {
+		batches = append(batches, batch)
+		if len(batches) == 2 {
+			break
+		}
+	}
+	if !slices.Equal(batches[1], []int{4, 5, 6, 7}) {
+		t.Errorf("Batches = %v", batches)
+	}
+	select {
+	case <-finished:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Input still being read after iteration stopped")
+	}
+	if clock.PendingTimers() != 0 {
+		t.Errorf("Stopped batcher left %d timers pending", clock.PendingTimers())
+	}
+}
@@ -0,0 +1,492 @@
+package ssql
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"maps"
+	"math"
+	"reflect"
+	"slices"
+	"time"
+)
+
+// ============================================================================
+// BINARY RECORD ENCODING (SPILL FILES)
+// ============================================================================
+
+// Records spilled to disk are written in a compact binary form: a uvarint
+// field count, then for each field a uvarint-prefixed name, a type tag and
+// the value. It is private to one process run and not a stable format.
+
+// Value type tags of the binary record encoding
+const (
+	tagNull byte = iota
+	tagInt
+	tagFloat
+	tagBool
+	tagString
+	tagTime
+	tagDecimal
+	tagJSONString
+	tagRecord
+	tagSeqRecord
+	tagSeqString
+	tagSeqInt
+	tagSeqFloat
+	tagSeqBool
+	tagSeqTime
+	tagSeqNumber // iter.Seq of another numeric type, followed by its reflect.Kind
+	tagMap       // map[string]any, as decoded from nested JSON objects
+	tagSlice     // []any, as decoded from JSON arrays
+	tagNil       // untyped nil inside a map or slice
+)
+
+// recordWriter writes records in the binary record encoding
+type recordWriter struct {
+	w   *bufio.Writer
+	buf []byte
+}
+
+func newRecordWriter(w io.Writer) *recordWriter {
+	return &recordWriter{w: bufio.NewWriterSize(w, 64*1024)}
+}
+
+// Write appends one record
+func (rw *recordWriter) Write(r Record) error {
+	buf, err := appendRecord(rw.buf[:0], r)
+	if err != nil {
+		return err
+	}
+	rw.buf = buf
+	_, err = rw.w.Write(buf)
+	return err
+}
+
+// Flush writes any buffered data to the underlying writer
+func (rw *recordWriter) Flush() error {
+	return rw.w.Flush()
+}
+
+// appendRecord appends the encoding of r to buf
+func appendRecord(buf []byte, r Record) ([]byte, error) {
+	keys := r.keyOrder()
+	buf = binary.AppendUvarint(buf, uint64(len(keys)))
+	for _, k := range keys {
+		buf = appendString(buf, k)
+		var err error
+		if buf, err = appendValue(buf, r.fields[k]); err != nil {
+			return nil, fmt.Errorf("field %q: %w", k, err)
+		}
+	}
+	return buf, nil
+}
+
+func appendString(buf []byte, s string) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+func appendTime(buf []byte, t time.Time) ([]byte, error) {
+	b, err := t.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return appendString(buf, string(b)), nil
+}
+
+// appendSeq appends the elements of seq, each encoded by appendElem
+func appendSeq[E any](buf []byte, tag byte, seq iter.Seq[E], appendElem func([]byte, E) ([]byte, error)) ([]byte, error) {
+	elems := slices.Collect(seq)
+	buf = append(buf, tag)
+	buf = binary.AppendUvarint(buf, uint64(len(elems)))
+	for _, e := range elems {
+		var err error
+		if buf, err = appendElem(buf, e); err != nil {
+			return nil, err
+		}
+	}
+	return buf, nil
+}
+
+// appendNumberSeq appends an iter.Seq of a numeric type other than int64
+// and float64. The kind is written where appendSeq writes its tag, so
+// readValue can restore the same element type.
+func appendNumberSeq[E int | int8 | int16 | int32 | uint | uint8 | uint16 | uint32 | uint64 | float32](buf []byte, kind reflect.Kind, seq iter.Seq[E]) ([]byte, error) {
+	return appendSeq(append(buf, tagSeqNumber), byte(kind), seq, func(b []byte, e E) ([]byte, error) {
+		switch kind {
+		case reflect.Float32:
+			return binary.LittleEndian.AppendUint32(b, math.Float32bits(float32(e))), nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return binary.AppendUvarint(b, uint64(e)), nil
+		default:
+			return binary.AppendVarint(b, int64(e)), nil
+		}
+	})
+}
+
+// appendValue appends a type tag and the encoding of v
+func appendValue(buf []byte, v any) ([]byte, error) {
+	switch val := v.(type) {
+	case nil:
+		return append(buf, tagNil), nil
+	case Null:
+		return append(buf, tagNull), nil
+	case int64:
+		return binary.AppendVarint(append(buf, tagInt), val), nil
+	case float64:
+		return binary.LittleEndian.AppendUint64(append(buf, tagFloat), math.Float64bits(val)), nil
+	case bool:
+		if val {
+			return append(buf, tagBool, 1), nil
+		}
+		return append(buf, tagBool, 0), nil
+	case string:
+		return appendString(append(buf, tagString), val), nil
+	case time.Time:
+		return appendTime(append(buf, tagTime), val)
+	case Decimal:
+		buf = binary.AppendVarint(append(buf, tagDecimal), val.coef)
+		return binary.AppendVarint(buf, int64(val.exp)), nil
+	case JSONString:
+		return appendString(append(buf, tagJSONString), string(val)), nil
+	case Record:
+		return appendRecord(append(buf, tagRecord), val)
+	case iter.Seq[Record]:
+		return appendSeq(buf, tagSeqRecord, val, appendRecord)
+	case iter.Seq[string]:
+		return appendSeq(buf, tagSeqString, val, func(b []byte, s string) ([]byte, error) { return appendString(b, s), nil })
+	case iter.Seq[int64]:
+		return appendSeq(buf, tagSeqInt, val, func(b []byte, n int64) ([]byte, error) { return binary.AppendVarint(b, n), nil })
+	case iter.Seq[float64]:
+		return appendSeq(buf, tagSeqFloat, val, func(b []byte, f float64) ([]byte, error) {
+			return binary.LittleEndian.AppendUint64(b, math.Float64bits(f)), nil
+		})
+	case iter.Seq[bool]:
+		return appendSeq(buf, tagSeqBool, val, func(b []byte, t bool) ([]byte, error) {
+			if t {
+				return append(b, 1), nil
+			}
+			return append(b, 0), nil
+		})
+	case iter.Seq[time.Time]:
+		return appendSeq(buf, tagSeqTime, val, appendTime)
+	case iter.Seq[int]:
+		return appendNumberSeq(buf, reflect.Int, val)
+	case iter.Seq[int8]:
+		return appendNumberSeq(buf, reflect.Int8, val)
+	case iter.Seq[int16]:
+		return appendNumberSeq(buf, reflect.Int16, val)
+	case iter.Seq[int32]:
+		return appendNumberSeq(buf, reflect.Int32, val)
+	case iter.Seq[uint]:
+		return appendNumberSeq(buf, reflect.Uint, val)
+	case iter.Seq[uint8]:
+		return appendNumberSeq(buf, reflect.Uint8, val)
+	case iter.Seq[uint16]:
+		return appendNumberSeq(buf, reflect.Uint16, val)
+	case iter.Seq[uint32]:
+		return appendNumberSeq(buf, reflect.Uint32, val)
+	case iter.Seq[uint64]:
+		return appendNumberSeq(buf, reflect.Uint64, val)
+	case iter.Seq[float32]:
+		return appendNumberSeq(buf, reflect.Float32, val)
+	case map[string]any:
+		keys := slices.Sorted(maps.Keys(val))
+		buf = binary.AppendUvarint(append(buf, tagMap), uint64(len(keys)))
+		for _, k := range keys {
+			buf = appendString(buf, k)
+			var err error
+			if buf, err = appendValue(buf, val[k]); err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	case []any:
+		buf = binary.AppendUvarint(append(buf, tagSlice), uint64(len(val)))
+		for _, e := range val {
+			var err error
+			if buf, err = appendValue(buf, e); err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	}
+
+	// Named types over a scalar kind (type Celsius float64) are stored as
+	// their underlying value
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return appendValue(buf, rv.Int())
+	case reflect.Float32, reflect.Float64:
+		return appendValue(buf, rv.Float())
+	case reflect.Bool:
+		return appendValue(buf, rv.Bool())
+	case reflect.String:
+		return appendValue(buf, rv.String())
+	default:
+		return nil, fmt.Errorf("cannot encode value of type %T", v)
+	}
+}
+
+// recordReader reads records written by recordWriter
+type recordReader struct {
+	r *bufio.Reader
+}
+
+func newRecordReader(r io.Reader) *recordReader {
+	return &recordReader{r: bufio.NewReaderSize(r, 64*1024)}
+}
+
+// Read returns the next record, or io.EOF after the last one
+func (rr *recordReader) Read() (Record, error) {
+	if _, err := rr.r.Peek(1); err != nil {
+		return Record{}, err
+	}
+	r, err := rr.readRecord()
+	if errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+	return r, err
+}
+
+func (rr *recordReader) readRecord() (Record, error) {
+	n, err := binary.ReadUvarint(rr.r)
+	if err != nil {
+		return Record{}, err
+	}
+	record := MakeMutableRecordWithCapacity(int(n))
+	for range n {
+		name, err := rr.readString()
+		if err != nil {
+			return Record{}, err
+		}
+		value, err := rr.readValue()
+		if err != nil {
+			return Record{}, err
+		}
+		record.set(name, value)
+	}
+	return record.Freeze(), nil
+}
+
+func (rr *recordReader) readString() (string, error) {
+	n, err := binary.ReadUvarint(rr.r)
+	if err != nil {
+		return "", err
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(rr.r, b); err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func (rr *recordReader) readFloat() (float64, error) {
+	var b [8]byte
+	if _, err := io.ReadFull(rr.r, b[:]); err != nil {
+		return 0, err
+	}
+	return math.Float64frombits(binary.LittleEndian.Uint64(b[:])), nil
+}
+
+func (rr *recordReader) readBool() (bool, error) {
+	b, err := rr.r.ReadByte()
+	return b == 1, err
+}
+
+func (rr *recordReader) readTime() (time.Time, error) {
+	s, err := rr.readString()
+	if err != nil {
+		return time.Time{}, err
+	}
+	var t time.Time
+	err = t.UnmarshalBinary([]byte(s))
+	return t, err
+}
+
+func (rr *recordReader) readInt() (int64, error) {
+	return binary.ReadVarint(rr.r)
+}
+
+// readSeq reads a sequence written by appendSeq
+func readSeq[E any](rr *recordReader, readElem func() (E, error)) (iter.Seq[E], error) {
+	n, err := binary.ReadUvarint(rr.r)
+	if err != nil {
+		return nil, err
+	}
+	elems := make([]E, n)
+	for i := range elems {
+		if elems[i], err = readElem(); err != nil {
+			return nil, err
+		}
+	}
+	return slices.Values(elems), nil
+}
+
+// readNumberSeq reads a sequence written by appendNumberSeq
+func (rr *recordReader) readNumberSeq() (any, error) {
+	kind, err := rr.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	signed := func() (int64, error) { return binary.ReadVarint(rr.r) }
+	unsigned := func() (uint64, error) { return binary.ReadUvarint(rr.r) }
+	switch reflect.Kind(kind) {
+	case reflect.Int:
+		return readConvertedSeq[int](rr, signed)
+	case reflect.Int8:
+		return readConvertedSeq[int8](rr, signed)
+	case reflect.Int16:
+		return readConvertedSeq[int16](rr, signed)
+	case reflect.Int32:
+		return readConvertedSeq[int32](rr, signed)
+	case reflect.Uint:
+		return readConvertedSeq[uint](rr, unsigned)
+	case reflect.Uint8:
+		return readConvertedSeq[uint8](rr, unsigned)
+	case reflect.Uint16:
+		return readConvertedSeq[uint16](rr, unsigned)
+	case reflect.Uint32:
+		return readConvertedSeq[uint32](rr, unsigned)
+	case reflect.Uint64:
+		return readConvertedSeq[uint64](rr, unsigned)
+	case reflect.Float32:
+		return readConvertedSeq[float32](rr, func() (float32, error) {
+			var b [4]byte
+			_, err := io.ReadFull(rr.r, b[:])
+			return math.Float32frombits(binary.LittleEndian.Uint32(b[:])), err
+		})
+	default:
+		return nil, fmt.Errorf("invalid sequence kind %d", kind)
+	}
+}
+
+// readConvertedSeq reads the elements of a numeric sequence as W and
+// converts them to E
+func readConvertedSeq[E, W int | int8 | int16 | int32 | int64 | uint | uint8 | uint16 | uint32 | uint64 | float32](rr *recordReader, readElem func() (W, error)) (iter.Seq[E], error) {
+	return readSeq(rr, func() (E, error) {
+		w, err := readElem()
+		return E(w), err
+	})
+}
+
+func (rr *recordReader) readValue() (any, error) {
+	tag, err := rr.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	switch tag {
+	case tagNil:
+		return nil, nil
+	case tagNull:
+		return Null{}, nil
+	case tagInt:
+		return rr.readInt()
+	case tagFloat:
+		return rr.readFloat()
+	case tagBool:
+		return rr.readBool()
+	case tagString:
+		return rr.readString()
+	case tagTime:
+		return rr.readTime()
+	case tagDecimal:
+		coef, err := rr.readInt()
+		if err != nil {
+			return nil, err
+		}
+		exp, err := rr.readInt()
+		return Decimal{coef: coef, exp: int32(exp)}, err
+	case tagJSONString:
+		s, err := rr.readString()
+		return JSONString(s), err
+	case tagRecord:
+		return rr.readRecord()
+	case tagSeqRecord:
+		return readSeq(rr, rr.readRecord)
+	case tagSeqString:
+		return readSeq(rr, rr.readString)
+	case tagSeqInt:
+		return readSeq(rr, rr.readInt)
+	case tagSeqFloat:
+		return readSeq(rr, rr.readFloat)
+	case tagSeqBool:
+		return readSeq(rr, rr.readBool)
+	case tagSeqTime:
+		return readSeq(rr, rr.readTime)
+	case tagSeqNumber:
+		return rr.readNumberSeq()
+	case tagMap:
+		n, err := binary.ReadUvarint(rr.r)
+		if err != nil {
+			return nil, err
+		}
+		m := make(map[string]any, n)
+		for range n {
+			k, err := rr.readString()
+			if err != nil {
+				return nil, err
+			}
+			if m[k], err = rr.readValue(); err != nil {
+				return nil, err
+			}
+		}
+		return m, nil
+	case tagSlice:
+		n, err := binary.ReadUvarint(rr.r)
+		if err != nil {
+			return nil, err
+		}
+		s := make([]any, n)
+		for i := range s {
+			if s[i], err = rr.readValue(); err != nil {
+				return nil, err
+			}
+		}
+		return s, nil
+	default:
+		return nil, fmt.Errorf("invalid value tag %d", tag)
+	}
+}
+
+// estimateRecordSize approximates the memory a record holds, for
+// memory-bounded operations
+func estimateRecordSize(r Record) int64 {
+	size := int64(64) // Record header and map overhead
+	for k, v := range r.fields {
+		size += 32 + int64(len(k)) + estimateValueSize(v)
+	}
+	return size
+}
+
+func estimateValueSize(v any) int64 {
+	switch val := v.(type) {
+	case string:
+		return 16 + int64(len(val))
+	case JSONString:
+		return 16 + int64(len(val))
+	case Record:
+		return estimateRecordSize(val)
+	case map[string]any:
+		size := int64(64)
+		for k, sub := range val {
+			size += 32 + int64(len(k)) + estimateValueSize(sub)
+		}
+		return size
+	case []any:
+		size := int64(24)
+		for _, sub := range val {
+			size += estimateValueSize(sub)
+		}
+		return size
+	case time.Time:
+		return 24
+	default:
+		return 16
+	}
+}
@@ -0,0 +1,203 @@
+package ssql
+
+import (
+	"cmp"
+	"hash/fnv"
+	"iter"
+	"math/rand/v2"
+	"slices"
+)
+
+// ============================================================================
+// SAMPLING
+// ============================================================================
+
+// ReservoirSample keeps a uniform random sample of k elements from a stream
+// of unknown length in one pass, holding only k elements in memory. The
+// sample is emitted in input order once the input ends. The same seed on
+// the same input gives the same sample; pass e.g. time.Now().UnixNano()
+// for a different sample each run.
+//
+// Example:
+//
+//	// 1000 representative rows from a huge file
+//	sample := ssql.ReservoirSample[ssql.Record](1000, 42)(records)
+func ReservoirSample[T any](k int, seed int64) Filter[T, T] {
+	return func(input iter.Seq[T]) iter.Seq[T] {
+		return func(yield func(T) bool) {
+			if k <= 0 {
+				return
+			}
+			r := newReservoir[T](k, newSampleRand(seed))
+			var i int64
+			for v := range input {
+				r.add(i, v)
+				i++
+			}
+			emitInOrder(r.items, yield)
+		}
+	}
+}
+
+// BernoulliSample keeps each element independently with probability p,
+// streaming, so the sample size varies around p times the input size.
+// The same seed on the same input keeps the same elements.
+//
+// Example:
+//
+//	// About 1% of events
+//	sample := ssql.BernoulliSample[ssql.Record](0.01, 42)(events)
+func BernoulliSample[T any](p float64, seed int64) Filter[T, T] {
+	return func(input iter.Seq[T]) iter.Seq[T] {
+		return func(yield func(T) bool) {
+			rng := newSampleRand(seed)
+			for v := range input {
+				if rng.Float64() < p && !yield(v) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// StratifiedSample keeps a uniform random sample of up to k records from
+// each group of records with the same keyFields values (a reservoir per
+// group, as ReservoirSample), so small groups are represented as well as
+// large ones. Samples are emitted in input order once the input ends.
+// Records whose key field holds a nested Record or sequence are skipped.
+// The sample differs from run to run; use StratifiedSampleSeeded for a
+// repeatable one.
+//
+// Example:
+//
+//	// 100 orders from every region
+//	sample := ssql.StratifiedSample(100, "region")(orders)
+func StratifiedSample(k int, keyFields ...string) Filter[Record, Record] {
+	return StratifiedSampleSeeded(k, rand.Int64(), keyFields...)
+}
+
+// StratifiedSampleSeeded samples like StratifiedSample with a fixed seed:
+// the same seed on the same input gives the same sample.
+//
+// Example:
+//
+//	sample := ssql.StratifiedSampleSeeded(100, 42, "region")(orders)
+func StratifiedSampleSeeded(k int, seed int64, keyFields ...string) Filter[Record, Record] {
+	return func(input iter.Seq[Record]) iter.Seq[Record] {
+		return func(yield func(Record) bool) {
+			if k <= 0 {
+				return
+			}
+			rng := newSampleRand(seed)
+			groups := make(map[string]*reservoir[Record])
+			var order []*reservoir[Record]
+			var i int64
+			for r := range input {
+				key, _, ok := groupKey(r, keyFields)
+				if !ok {
+					continue
+				}
+				g, exists := groups[key]
+				if !exists {
+					g = newReservoir[Record](k, rng)
+					groups[key] = g
+					order = append(order, g)
+				}
+				g.add(i, r)
+				i++
+			}
+
+			var kept []sampled[Record]
+			for _, g := range order {
+				kept = append(kept, g.items...)
+			}
+			emitInOrder(kept, yield)
+		}
+	}
+}
+
+// HashSample keeps the records whose keyFields values hash below fraction,
+// so about fraction of the distinct keys are kept, each with all of its
+// records. The decision depends only on the key values, so the same
+// entities are kept across runs, files and machines, and samples of
+// related datasets line up (for example users in both clicks and orders).
+// Records whose key field holds a nested Record or sequence are skipped.
+//
+// Example:
+//
+//	// Every event of 5% of users, consistently
+//	sample := ssql.HashSample(0.05, "user_id")(events)
+func HashSample(fraction float64, keyFields ...string) Filter[Record, Record] {
+	return func(input iter.Seq[Record]) iter.Seq[Record] {
+		return func(yield func(Record) bool) {
+			for r := range input {
+				key, _, ok := groupKey(r, keyFields)
+				if !ok || hashFraction(key) >= fraction {
+					continue
+				}
+				if !yield(r) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// hashFraction maps key to [0, 1) with a stable hash: FNV-1a, mixed with
+// the splitmix64 finalizer so similar keys spread evenly
+func hashFraction(key string) float64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	x := h.Sum64()
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return float64(x>>11) / (1 << 53)
+}
+
+// newSampleRand returns the random source for seed
+func newSampleRand(seed int64) *rand.Rand {
+	return rand.New(rand.NewPCG(uint64(seed), 0x5ca1ab1e))
+}
+
+// sampled is an element kept by a reservoir with its input position
+type sampled[T any] struct {
+	index int64
+	value T
+}
+
+// reservoir is a uniform sample of up to k elements (Algorithm R)
+type reservoir[T any] struct {
+	k     int
+	seen  int64
+	items []sampled[T]
+	rng   *rand.Rand
+}
+
+func newReservoir[T any](k int, rng *rand.Rand) *reservoir[T] {
+	return &reservoir[T]{k: k, rng: rng}
+}
+
+// add offers the element at input position index to the sample
+func (r *reservoir[T]) add(index int64, v T) {
+	r.seen++
+	if len(r.items) < r.k {
+		r.items = append(r.items, sampled[T]{index, v})
+		return
+	}
+	if j := r.rng.Int64N(r.seen); j < int64(r.k) {
+		r.items[j] = sampled[T]{index, v}
+	}
+}
+
+// emitInOrder yields sampled elements in input order
+func emitInOrder[T any](items []sampled[T], yield func(T) bool) {
+	slices.SortFunc(items, func(a, b sampled[T]) int { return cmp.Compare(a.index, b.index) })
+	for _, item := range items {
+		if !yield(item.value) {
+			return
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package ssql
+
+import (
+	"fmt"
+	"slices"
+	"sync/atomic"
+	"testing"
+)
+
+// ============================================================================
+// SAMPLING TESTS
+// ============================================================================
+
+func TestReservoirSample(t *testing.T) {
+	input := slices.Collect(countTo(10000, new(atomic.Int64)))
+
+	sample := slices.Collect(ReservoirSample[int](100, 7)(slices.Values(input)))
+	if len(sample) != 100 || !slices.IsSorted(sample) {
+		t.Fatalf("Expected 100 elements in input order, got %d: %v", len(sample), sample)
+	}
+	if again := slices.Collect(ReservoirSample[int](100, 7)(slices.Values(input))); !slices.Equal(sample, again) {
+		t.Error("The same seed should give the same sample")
+	}
+	if other := slices.Collect(ReservoirSample[int](100, 8)(slices.Values(input))); slices.Equal(sample, other) {
+		t.Error("Different seeds should give different samples")
+	}
+
+	// Uniform: each half of the input holds about half the sample
+	var firstHalf int
+	for _, v := range sample {
+		if v < 5000 {
+			firstHalf++
+		}
+	}
+	if firstHalf < 30 || firstHalf > 70 {
+		t.Errorf("Sample looks skewed: %d of 100 from the first half", firstHalf)
+	}
+
+	if got := slices.Collect(ReservoirSample[int](20, 1)(slices.Values(input[:5]))); !slices.Equal(got, input[:5]) {
+		t.Errorf("Short input should be kept whole, got %v", got)
+	}
+	if got := slices.Collect(ReservoirSample[int](0, 1)(slices.Values(input))); len(got) != 0 {
+		t.Errorf("k=0 should keep nothing, got %v", got)
+	}
+}
+
+func TestBernoulliSample(t *testing.T) {
+	input := slices.Collect(countTo(10000, new(atomic.Int64)))
+	sample := slices.Collect(BernoulliSample[int](0.1, 3)(slices.Values(input)))
+	if len(sample) < 850 || len(sample) > 1150 || !slices.IsSorted(sample) {
+		t.Errorf("Expected about 1000 elements in order, got %d", len(sample))
+	}
+	if again := slices.Collect(BernoulliSample[int](0.1, 3)(slices.Values(input))); !slices.Equal(sample, again) {
+		t.Error("The same seed should give the same sample")
+	}
+	if got := slices.Collect(BernoulliSample[int](1, 3)(slices.Values(input))); len(got) != len(input) {
+		t.Errorf("p=1 should keep everything, got %d", len(got))
+	}
+	if got := slices.Collect(BernoulliSample[int](0, 3)(slices.Values(input))); len(got) != 0 {
+		t.Errorf("p=0 should keep nothing, got %d", len(got))
+	}
+}
+
+func TestStratifiedSample(t *testing.T) {
+	var orders []Record
+	for i := range 1000 {
+		region := "north"
+		if i%100 == 0 {
+			region = "south" // a small group
+		}
+		orders = append(orders, MakeMutableRecord().Int("id", int64(i)).String("region", region).Freeze())
+	}
+
+	sample := slices.Collect(StratifiedSampleSeeded(5, 11, "region")(slices.Values(orders)))
+	counts := map[string]int{}
+	var ids []int64
+	for _, r := range sample {
+		counts[GetOr(r, "region", "")]++
+		ids = append(ids, GetOr(r, "id", int64(-1)))
+	}
+	if counts["north"] != 5 || counts["south"] != 5 || !slices.IsSorted(ids) {
+		t.Errorf("Expected 5 per region in input order, got %v %v", counts, ids)
+	}
+	if again := slices.Collect(StratifiedSampleSeeded(5, 11, "region")(slices.Values(orders))); !slices.EqualFunc(sample, again, Record.Equal) {
+		t.Error("The same seed should give the same sample")
+	}
+	if unseeded := slices.Collect(StratifiedSample(5, "region")(slices.Values(orders))); len(unseeded) != 10 {
+		t.Errorf("Expected 5 per region without a seed, got %d records", len(unseeded))
+	}
+}
+
+func TestHashSample(t *testing.T) {
+	var events []Record
+	for i := range 5000 {
+		events = append(events, MakeMutableRecord().String("user", fmt.Sprintf("u%d", i%500)).Int("n", int64(i)).Freeze())
+	}
+	sample := slices.Collect(HashSample(0.2, "user")(slices.Values(events)))
+
+	users := map[string]int{}
+	for _, r := range sample {
+		users[GetOr(r, "user", "")]++
+	}
+	if len(users) < 70 || len(users) > 130 {
+		t.Errorf("Expected about 100 of 500 users, got %d", len(users))
+	}
+	for user, n := range users {
+		if n != 10 {
+			t.Errorf("User %s kept %d of 10 events; a user's events should be kept together", user, n)
+		}
+	}
+
+	// Consistent across inputs: a user kept from one dataset is kept from another
+	reversed := slices.Clone(events)
+	slices.Reverse(reversed)
+	for r := range HashSample(0.2, "user")(slices.Values(reversed)) {
+		if _, ok := users[GetOr(r, "user", "")]; !ok {
+			t.Fatalf("User %v sampled from one input but not the other", GetOr(r, "user", ""))
+		}
+	}
+	// A larger fraction keeps a superset
+	wider := map[string]bool{}
+	for r := range HashSample(0.5, "user")(slices.Values(events)) {
+		wider[GetOr(r, "user", "")] = true
+	}
+	for user := range users {
+		if !wider[user] {
+			t.Errorf("User %s in the 20%% sample but not the 50%% one", user)
+		}
+	}
+}
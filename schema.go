@@ -0,0 +1,580 @@
+package ssql
+
+import (
+	"fmt"
+	"iter"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ============================================================================
+// SCHEMA - TYPED FIELD DEFINITIONS FOR RECORD STREAMS
+// ============================================================================
+
+// FieldType identifies the canonical Value type of a schema field.
+type FieldType int
+
+const (
+	// TypeUnknown is used for fields whose type could not be determined
+	// (for example, a field that only ever held null).
+	TypeUnknown FieldType = iota
+	// TypeInt is the canonical int64 type
+	TypeInt
+	// TypeFloat is the canonical float64 type
+	TypeFloat
+	// TypeBool is the bool type
+	TypeBool
+	// TypeString is the string type
+	TypeString
+	// TypeTime is the time.Time type
+	TypeTime
+	// TypeJSON is the JSONString type
+	TypeJSON
+	// TypeRecord is a nested Record
+	TypeRecord
+	// TypeSeq is any of the iter.Seq types accepted by Value
+	TypeSeq
+	// TypeDecimal is the exact Decimal type
+	TypeDecimal
+)
+
+// String returns the lower-case name of the field type
+func (t FieldType) String() string {
+	switch t {
+	case TypeInt:
+		return "int"
+	case TypeFloat:
+		return "float"
+	case TypeBool:
+		return "bool"
+	case TypeString:
+		return "string"
+	case TypeTime:
+		return "time"
+	case TypeJSON:
+		return "json"
+	case TypeRecord:
+		return "record"
+	case TypeSeq:
+		return "seq"
+	case TypeDecimal:
+		return "decimal"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseFieldType parses a type name as returned by FieldType.String.
+// "int64" and "float64" are accepted as aliases for "int" and "float".
+func ParseFieldType(name string) (FieldType, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "int", "int64":
+		return TypeInt, nil
+	case "float", "float64":
+		return TypeFloat, nil
+	case "bool":
+		return TypeBool, nil
+	case "string":
+		return TypeString, nil
+	case "time":
+		return TypeTime, nil
+	case "json":
+		return TypeJSON, nil
+	case "record":
+		return TypeRecord, nil
+	case "seq":
+		return TypeSeq, nil
+	case "decimal":
+		return TypeDecimal, nil
+	default:
+		return TypeUnknown, fmt.Errorf("unknown field type %q", name)
+	}
+}
+
+// fieldTypeOf returns the FieldType of a record value
+func fieldTypeOf(value any) FieldType {
+	switch value.(type) {
+	case int64:
+		return TypeInt
+	case float64:
+		return TypeFloat
+	case bool:
+		return TypeBool
+	case string:
+		return TypeString
+	case time.Time:
+		return TypeTime
+	case JSONString:
+		return TypeJSON
+	case Record:
+		return TypeRecord
+	case Decimal:
+		return TypeDecimal
+	default:
+		if isIterSeq(value) {
+			return TypeSeq
+		}
+		return TypeUnknown
+	}
+}
+
+// SchemaField describes a single field of a Schema
+type SchemaField struct {
+	Name     string
+	Type     FieldType
+	Nullable bool // Field may be missing or null
+}
+
+// Schema is an ordered set of typed field definitions for a Record stream.
+// A Schema lets readers and filters make type decisions once per column
+// instead of guessing the type of every cell.
+//
+// Fields not described by the schema are passed through untouched.
+//
+// Example:
+//
+//	schema := ssql.NewSchema(
+//	    ssql.SchemaField{Name: "id", Type: ssql.TypeInt},
+//	    ssql.SchemaField{Name: "amount", Type: ssql.TypeFloat},
+//	    ssql.SchemaField{Name: "note", Type: ssql.TypeString, Nullable: true},
+//	)
+//
+//	// Parse CSV columns using the schema types
+//	data, _ := ssql.ReadCSV("orders.csv", ssql.CSVConfig{
+//	    HasHeaders: true,
+//	    Delimiter:  ',',
+//	    Schema:     schema,
+//	})
+type Schema struct {
+	fields []SchemaField
+	index  map[string]int
+}
+
+// NewSchema creates a Schema from field definitions.
+// If a field name appears more than once, the last definition wins.
+func NewSchema(fields ...SchemaField) Schema {
+	s := Schema{index: make(map[string]int, len(fields))}
+	for _, f := range fields {
+		if i, exists := s.index[f.Name]; exists {
+			s.fields[i] = f
+			continue
+		}
+		s.index[f.Name] = len(s.fields)
+		s.fields = append(s.fields, f)
+	}
+	return s
+}
+
+// Len returns the number of fields in the schema
+func (s Schema) Len() int {
+	return len(s.fields)
+}
+
+// Fields returns a copy of the schema fields in order
+func (s Schema) Fields() []SchemaField {
+	return slices.Clone(s.fields)
+}
+
+// Names returns the field names in schema order
+func (s Schema) Names() []string {
+	names := make([]string, len(s.fields))
+	for i, f := range s.fields {
+		names[i] = f.Name
+	}
+	return names
+}
+
+// Field returns the definition of a field
+func (s Schema) Field(name string) (SchemaField, bool) {
+	if i, exists := s.index[name]; exists {
+		return s.fields[i], true
+	}
+	return SchemaField{}, false
+}
+
+// String returns a compact description such as "id:int, note:string?"
+func (s Schema) String() string {
+	parts := make([]string, len(s.fields))
+	for i, f := range s.fields {
+		parts[i] = f.Name + ":" + f.Type.String()
+		if f.Nullable {
+			parts[i] += "?"
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Validate checks that a record conforms to the schema without converting values.
+// Returns an error describing every non-conforming field.
+func (s Schema) Validate(r Record) error {
+	var problems []string
+	for _, f := range s.fields {
+		val, exists := r.fields[f.Name]
+		if !exists || IsNull(val) {
+			if !f.Nullable {
+				problems = append(problems, fmt.Sprintf("field '%s' is required", f.Name))
+			}
+			continue
+		}
+		if f.Type != TypeUnknown && fieldTypeOf(val) != f.Type {
+			problems = append(problems, fmt.Sprintf("field '%s' has type %s, want %s", f.Name, fieldTypeOf(val), f.Type))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("schema violation: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Coerce converts the fields of a record to the schema types.
+// Returns the converted record and an error describing every field that
+// could not be converted or a required field that is missing. Fields that
+// cannot be converted keep their original value in the returned record.
+func (s Schema) Coerce(r Record) (Record, error) {
+	result := r.ToMutable()
+	var problems []string
+	for _, f := range s.fields {
+		val, exists := r.fields[f.Name]
+		if !exists || IsNull(val) {
+			if !f.Nullable {
+				problems = append(problems, fmt.Sprintf("field '%s' is required", f.Name))
+			}
+			continue
+		}
+		converted, ok := coerceValue(val, f.Type)
+		if !ok {
+			problems = append(problems, fmt.Sprintf("field '%s': cannot convert %T to %s", f.Name, val, f.Type))
+			continue
+		}
+		result.set(f.Name, converted)
+	}
+	if len(problems) > 0 {
+		return result.Freeze(), fmt.Errorf("schema violation: %s", strings.Join(problems, "; "))
+	}
+	return result.Freeze(), nil
+}
+
+// ============================================================================
+// SCHEMA INFERENCE
+// ============================================================================
+
+// InferSchema infers a Schema by examining the first sampleN records of a stream.
+// A sampleN of 0 or less examines the whole stream (which must then be finite).
+//
+// Because the sampled records are consumed from the input, InferSchema returns
+// a replacement stream that yields the sampled records followed by the rest of
+// the input. Use the returned stream instead of the original. When the sample
+// reaches the end of the input, the input is released before InferSchema
+// returns; otherwise the rest of it stays open until the returned stream is
+// iterated, so a caller that only wants the schema must still range over the
+// returned stream (breaking out at once is enough) to release the input.
+//
+// Type widening rules:
+//   - int64 and float64 in the same field widen to float
+//   - Decimal mixed with int64 or float64 widens to decimal
+//   - any other mix of types widens to string
+//   - a field missing or null in any sampled record is nullable
+//
+// Example:
+//
+//	data, _ := ssql.ReadCSV("events.csv")
+//	schema, data := ssql.InferSchema(data, 1000)
+//	typed := ssql.EnforceSchema(schema, ssql.SchemaCoerce)(data)
+func InferSchema(input iter.Seq[Record], sampleN int) (Schema, iter.Seq[Record]) {
+	next, stop := iter.Pull(input)
+
+	var sample []Record
+	for sampleN <= 0 || len(sample) < sampleN {
+		r, ok := next()
+		if !ok {
+			break
+		}
+		sample = append(sample, r)
+	}
+	exhausted := sampleN <= 0 || len(sample) < sampleN
+	if exhausted {
+		stop()
+	}
+
+	schema := inferSchemaFromRecords(sample)
+
+	replay := func(yield func(Record) bool) {
+		defer stop()
+		for _, r := range sample {
+			if !yield(r) {
+				return
+			}
+		}
+		if exhausted {
+			return
+		}
+		for {
+			r, ok := next()
+			if !ok || !yield(r) {
+				return
+			}
+		}
+	}
+
+	return schema, replay
+}
+
+// inferSchemaFromRecords builds a Schema from materialized records
+func inferSchemaFromRecords(records []Record) Schema {
+	var order []string
+	types := make(map[string]FieldType)
+	seen := make(map[string]int)
+	nullable := make(map[string]bool)
+
+	for _, r := range records {
+		// Fields are inferred in record field order
+		for _, k := range r.keyOrder() {
+			// Metadata fields added by readers are not part of the data schema
+			if strings.HasPrefix(k, "_") {
+				continue
+			}
+			val := r.fields[k]
+			if _, known := types[k]; !known {
+				order = append(order, k)
+				types[k] = TypeUnknown
+			}
+			seen[k]++
+			if IsNull(val) {
+				nullable[k] = true
+				continue
+			}
+			types[k] = widenFieldType(types[k], fieldTypeOf(val))
+		}
+	}
+
+	fields := make([]SchemaField, 0, len(order))
+	for _, name := range order {
+		fields = append(fields, SchemaField{
+			Name:     name,
+			Type:     types[name],
+			Nullable: nullable[name] || seen[name] < len(records),
+		})
+	}
+	return NewSchema(fields...)
+}
+
+// widenFieldType returns the narrowest type able to hold values of both types
+func widenFieldType(current, observed FieldType) FieldType {
+	switch {
+	case current == TypeUnknown:
+		return observed
+	case observed == TypeUnknown || current == observed:
+		return current
+	case (current == TypeInt && observed == TypeFloat) || (current == TypeFloat && observed == TypeInt):
+		return TypeFloat
+	case current == TypeDecimal && (observed == TypeInt || observed == TypeFloat),
+		observed == TypeDecimal && (current == TypeInt || current == TypeFloat):
+		return TypeDecimal
+	default:
+		return TypeString
+	}
+}
+
+// ============================================================================
+// SCHEMA ENFORCEMENT
+// ============================================================================
+
+// SchemaPolicy controls what EnforceSchema does with non-conforming records
+type SchemaPolicy int
+
+const (
+	// SchemaCoerce converts values to the schema types and drops records
+	// that still do not conform (unconvertible values, missing required fields).
+	SchemaCoerce SchemaPolicy = iota
+	// SchemaReject drops records that do not already conform, without converting.
+	SchemaReject
+	// SchemaFlag converts what it can and keeps every record. Non-conforming
+	// records get a "_schema_errors" string field describing the problems.
+	SchemaFlag
+)
+
+// SchemaErrorsField is the field added to non-conforming records by SchemaFlag
+const SchemaErrorsField = "_schema_errors"
+
+// EnforceSchema applies a Schema to a Record stream using the given policy.
+//
+// Example:
+//
+//	schema := ssql.NewSchema(
+//	    ssql.SchemaField{Name: "age", Type: ssql.TypeInt},
+//	)
+//
+//	// "42" becomes int64(42); records with age "n/a" are dropped
+//	clean := ssql.EnforceSchema(schema, ssql.SchemaCoerce)(data)
+//
+//	// Keep everything, but mark bad records for later inspection
+//	flagged := ssql.EnforceSchema(schema, ssql.SchemaFlag)(data)
+func EnforceSchema(schema Schema, policy SchemaPolicy) Filter[Record, Record] {
+	return func(input iter.Seq[Record]) iter.Seq[Record] {
+		return func(yield func(Record) bool) {
+			for record := range input {
+				switch policy {
+				case SchemaReject:
+					if schema.Validate(record) != nil {
+						continue
+					}
+					if !yield(record) {
+						return
+					}
+				case SchemaFlag:
+					coerced, err := schema.Coerce(record)
+					if err != nil {
+						coerced = coerced.String(SchemaErrorsField, err.Error())
+					}
+					if !yield(coerced) {
+						return
+					}
+				default:
+					coerced, err := schema.Coerce(record)
+					if err != nil {
+						continue
+					}
+					if !yield(coerced) {
+						return
+					}
+				}
+			}
+		}
+	}
+}
+
+// EnforceSchemaSafe converts records to the schema types with error handling.
+// Non-conforming records are yielded with an error describing the violation.
+func EnforceSchemaSafe(schema Schema) FilterWithErrors[Record, Record] {
+	return func(input iter.Seq2[Record, error]) iter.Seq2[Record, error] {
+		return func(yield func(Record, error) bool) {
+			for record, err := range input {
+				if err != nil {
+					if !yield(record, err) {
+						return
+					}
+					continue
+				}
+				if !yield(schema.Coerce(record)) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// ============================================================================
+// SCHEMA VALUE CONVERSION
+// ============================================================================
+
+// coerceValue converts a value to the given field type.
+// Conversions are stricter than Get: lossy float→int and arbitrary
+// string→bool conversions are refused.
+func coerceValue(val any, t FieldType) (any, bool) {
+	if t == TypeUnknown || fieldTypeOf(val) == t {
+		return val, true
+	}
+
+	switch t {
+	case TypeInt:
+		switch v := val.(type) {
+		case float64:
+			if v == math.Trunc(v) && !math.IsInf(v, 0) {
+				return int64(v), true
+			}
+		case string:
+			if i, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64); err == nil {
+				return i, true
+			}
+		case bool:
+			if v {
+				return int64(1), true
+			}
+			return int64(0), true
+		case Decimal:
+			if v.Scale() == 0 {
+				return v.Int64()
+			}
+		}
+	case TypeFloat:
+		switch v := val.(type) {
+		case int64:
+			return float64(v), true
+		case Decimal:
+			return v.Float64(), true
+		case string:
+			if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+				return f, true
+			}
+		}
+	case TypeBool:
+		switch v := val.(type) {
+		case string:
+			if b, err := strconv.ParseBool(strings.TrimSpace(v)); err == nil {
+				return b, true
+			}
+		case int64:
+			if v == 0 || v == 1 {
+				return v == 1, true
+			}
+		}
+	case TypeString:
+		switch val.(type) {
+		case Record:
+			return nil, false
+		default:
+			if isIterSeq(val) {
+				return nil, false
+			}
+			return formatValue(val), true
+		}
+	case TypeTime:
+		if v, ok := val.(string); ok {
+			if ts := parseTimeValue(strings.TrimSpace(v)); !ts.IsZero() {
+				return ts, true
+			}
+			return nil, false
+		}
+		if v, ok := val.(int64); ok {
+			return time.Unix(v, 0).UTC(), true
+		}
+	case TypeDecimal:
+		if v, ok := val.(string); ok {
+			val = strings.TrimSpace(v)
+		}
+		if d, ok := convertToDecimal(val); ok {
+			return d, true
+		}
+	case TypeJSON:
+		if v, ok := val.(string); ok && JSONString(v).IsValid() {
+			return JSONString(v), true
+		}
+		if js, err := NewJSONString(val); err == nil {
+			return js, true
+		}
+	}
+
+	return nil, false
+}
+
+// parseTypedValue parses a raw text cell according to a schema field.
+// Empty cells in nullable fields become Null; other failures return an error.
+func parseTypedValue(s string, f SchemaField) (any, error) {
+	trimmed := strings.TrimSpace(s)
+	if trimmed == "" && f.Nullable && f.Type != TypeString {
+		return Null{}, nil
+	}
+	if f.Type == TypeString {
+		return s, nil
+	}
+	if f.Type == TypeUnknown {
+		return parseValue(s), nil
+	}
+	if converted, ok := coerceValue(trimmed, f.Type); ok {
+		return converted, nil
+	}
+	return s, fmt.Errorf("field '%s': cannot parse %q as %s", f.Name, s, f.Type)
+}
@@ -0,0 +1,250 @@
+package ssql
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNewSchema(t *testing.T) {
+	schema := NewSchema(
+		SchemaField{Name: "id", Type: TypeInt},
+		SchemaField{Name: "name", Type: TypeString},
+		SchemaField{Name: "id", Type: TypeFloat, Nullable: true},
+	)
+
+	if schema.Len() != 2 {
+		t.Fatalf("Expected 2 fields, got %d", schema.Len())
+	}
+	if !slices.Equal(schema.Names(), []string{"id", "name"}) {
+		t.Errorf("Unexpected field order: %v", schema.Names())
+	}
+	field, ok := schema.Field("id")
+	if !ok || field.Type != TypeFloat || !field.Nullable {
+		t.Errorf("Expected later definition of id to win, got %+v", field)
+	}
+	if got := schema.String(); got != "id:float?, name:string" {
+		t.Errorf("Unexpected schema string: %q", got)
+	}
+}
+
+func TestParseFieldType(t *testing.T) {
+	for _, ft := range []FieldType{TypeInt, TypeFloat, TypeBool, TypeString, TypeTime, TypeJSON, TypeRecord, TypeSeq, TypeDecimal} {
+		parsed, err := ParseFieldType(ft.String())
+		if err != nil || parsed != ft {
+			t.Errorf("ParseFieldType(%q) = %v, %v", ft.String(), parsed, err)
+		}
+	}
+	if _, err := ParseFieldType("decimal128"); err == nil {
+		t.Error("Expected error for unknown type name")
+	}
+}
+
+func TestInferSchema(t *testing.T) {
+	records := []Record{
+		MakeMutableRecord().Int("id", 1).Int("score", 10).String("name", "a").Int("_row_number", 0).Freeze(),
+		MakeMutableRecord().Int("id", 2).Float("score", 2.5).Int("name", 7).Freeze(),
+		MakeMutableRecord().Int("id", 3).Bool("extra", true).Freeze(),
+	}
+
+	schema, replay := InferSchema(slices.Values(records), 0)
+
+	want := map[string]SchemaField{
+		"id":    {Name: "id", Type: TypeInt},
+		"score": {Name: "score", Type: TypeFloat, Nullable: true},
+		"name":  {Name: "name", Type: TypeString, Nullable: true},
+		"extra": {Name: "extra", Type: TypeBool, Nullable: true},
+	}
+	if schema.Len() != len(want) {
+		t.Fatalf("Expected %d fields, got %s", len(want), schema)
+	}
+	for name, w := range want {
+		got, ok := schema.Field(name)
+		if !ok || got != w {
+			t.Errorf("Field %s: expected %+v, got %+v", name, w, got)
+		}
+	}
+	if _, ok := schema.Field("_row_number"); ok {
+		t.Error("Metadata fields should not be inferred")
+	}
+
+	if got := slices.Collect(replay); len(got) != 3 {
+		t.Errorf("Expected replay of 3 records, got %d", len(got))
+	}
+}
+
+func TestInferSchemaSample(t *testing.T) {
+	var records []Record
+	for i := range 10 {
+		records = append(records, MakeMutableRecord().Int("n", int64(i)).Freeze())
+	}
+	records = append(records, MakeMutableRecord().String("n", "late").Freeze())
+
+	schema, replay := InferSchema(slices.Values(records), 5)
+
+	field, _ := schema.Field("n")
+	if field.Type != TypeInt {
+		t.Errorf("Expected int from 5 sampled records, got %s", field.Type)
+	}
+
+	got := slices.Collect(replay)
+	if len(got) != len(records) {
+		t.Fatalf("Expected %d replayed records, got %d", len(records), len(got))
+	}
+	for i := range records {
+		if !got[i].Equal(records[i]) {
+			t.Errorf("Record %d changed during replay", i)
+		}
+	}
+}
+
+func TestInferSchemaReleasesInput(t *testing.T) {
+	released := false
+	input := func(yield func(Record) bool) {
+		defer func() { released = true }()
+		for i := range 10 {
+			if !yield(MakeMutableRecord().Int("n", int64(i)).Freeze()) {
+				return
+			}
+		}
+	}
+
+	// A sample that reaches the end releases the input straight away
+	InferSchema(input, 100)
+	if !released {
+		t.Error("Expected input released after sampling the whole stream")
+	}
+
+	// Otherwise breaking out of the returned stream releases it
+	released = false
+	_, replay := InferSchema(input, 3)
+	if released {
+		t.Fatal("Input released before the rest was read")
+	}
+	for range replay {
+		break
+	}
+	if !released {
+		t.Error("Expected input released after breaking out of the replay")
+	}
+}
+
+func TestEnforceSchemaPolicies(t *testing.T) {
+	schema := NewSchema(
+		SchemaField{Name: "age", Type: TypeInt},
+		SchemaField{Name: "note", Type: TypeString, Nullable: true},
+	)
+	records := []Record{
+		MakeMutableRecord().String("age", "42").Freeze(),
+		MakeMutableRecord().Int("age", 30).String("note", "ok").Freeze(),
+		MakeMutableRecord().String("age", "n/a").Freeze(),
+		MakeMutableRecord().String("note", "missing age").Freeze(),
+	}
+
+	coerced := slices.Collect(EnforceSchema(schema, SchemaCoerce)(slices.Values(records)))
+	if len(coerced) != 2 {
+		t.Fatalf("SchemaCoerce: expected 2 records, got %d", len(coerced))
+	}
+	if age, ok := coerced[0].fields["age"].(int64); !ok || age != 42 {
+		t.Errorf("SchemaCoerce: expected int64 42, got %v (%T)", coerced[0].fields["age"], coerced[0].fields["age"])
+	}
+
+	rejected := slices.Collect(EnforceSchema(schema, SchemaReject)(slices.Values(records)))
+	if len(rejected) != 1 || GetOr(rejected[0], "note", "") != "ok" {
+		t.Errorf("SchemaReject: expected only the already-typed record, got %v", rejected)
+	}
+
+	flagged := slices.Collect(EnforceSchema(schema, SchemaFlag)(slices.Values(records)))
+	if len(flagged) != 4 {
+		t.Fatalf("SchemaFlag: expected 4 records, got %d", len(flagged))
+	}
+	if flagged[0].Has(SchemaErrorsField) || flagged[1].Has(SchemaErrorsField) {
+		t.Error("SchemaFlag: conforming records should not be flagged")
+	}
+	if msg := GetOr(flagged[2], SchemaErrorsField, ""); !strings.Contains(msg, "age") {
+		t.Errorf("SchemaFlag: expected error about age, got %q", msg)
+	}
+	if msg := GetOr(flagged[3], SchemaErrorsField, ""); !strings.Contains(msg, "required") {
+		t.Errorf("SchemaFlag: expected required-field error, got %q", msg)
+	}
+}
+
+func TestEnforceSchemaSafe(t *testing.T) {
+	schema := NewSchema(SchemaField{Name: "ok", Type: TypeBool})
+	input := Safe(slices.Values([]Record{
+		MakeMutableRecord().String("ok", "true").Freeze(),
+		MakeMutableRecord().String("ok", "maybe").Freeze(),
+	}))
+
+	var errs int
+	var values []bool
+	for r, err := range EnforceSchemaSafe(schema)(input) {
+		if err != nil {
+			errs++
+			continue
+		}
+		values = append(values, GetOr(r, "ok", false))
+	}
+	if errs != 1 || !slices.Equal(values, []bool{true}) {
+		t.Errorf("Expected one converted value and one error, got %v and %d errors", values, errs)
+	}
+}
+
+func TestReadCSVWithSchema(t *testing.T) {
+	data := "zip,amount,comment\n01234,10,\n98765,2.5,hello\n"
+	schema := NewSchema(
+		SchemaField{Name: "zip", Type: TypeString},
+		SchemaField{Name: "amount", Type: TypeFloat},
+		SchemaField{Name: "comment", Type: TypeString, Nullable: true},
+	)
+	cfg := DefaultCSVConfig()
+	cfg.Schema = schema
+
+	records := slices.Collect(ReadCSVFromReader(strings.NewReader(data), cfg))
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(records))
+	}
+	if zip, ok := records[0].fields["zip"].(string); !ok || zip != "01234" {
+		t.Errorf("Expected zip kept as string \"01234\", got %v (%T)", records[0].fields["zip"], records[0].fields["zip"])
+	}
+	// Without a schema "10" would become int64; the column is float everywhere
+	if amount, ok := records[0].fields["amount"].(float64); !ok || amount != 10 {
+		t.Errorf("Expected float64 10, got %v (%T)", records[0].fields["amount"], records[0].fields["amount"])
+	}
+}
+
+func TestReadCSVSafeWithSchemaError(t *testing.T) {
+	data := "n\n1\nx\n3\n"
+	cfg := DefaultCSVConfig()
+	cfg.Schema = NewSchema(SchemaField{Name: "n", Type: TypeInt})
+
+	var good, bad int
+	for _, err := range ReadCSVSafeFromReader(strings.NewReader(data), cfg) {
+		if err != nil {
+			bad++
+		} else {
+			good++
+		}
+	}
+	if good != 2 || bad != 1 {
+		t.Errorf("Expected 2 good rows and 1 error, got %d and %d", good, bad)
+	}
+}
+
+func TestReadJSONWithSchema(t *testing.T) {
+	data := `{"price": 3, "qty": "7"}` + "\n" + `{"price": 2.5, "qty": 1}` + "\n"
+	schema := NewSchema(
+		SchemaField{Name: "price", Type: TypeFloat},
+		SchemaField{Name: "qty", Type: TypeInt},
+	)
+
+	records := slices.Collect(ReadJSONFromReader(strings.NewReader(data), JSONConfig{Schema: schema}))
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(records))
+	}
+	for i, r := range records {
+		if err := schema.Validate(r); err != nil {
+			t.Errorf("Record %d does not conform: %v", i, err)
+		}
+	}
+}
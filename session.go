@@ -0,0 +1,218 @@
+package ssql
+
+import (
+	"cmp"
+	"container/heap"
+	"iter"
+	"slices"
+	"time"
+)
+
+// ============================================================================
+// SESSION WINDOWS
+// ============================================================================
+
+// Fields of SessionWindow output records
+const (
+	// SessionStartField holds the time of the session's first event
+	SessionStartField = "session_start"
+	// SessionEndField holds the time of the session's last event
+	SessionEndField = "session_end"
+	// SessionDurationField holds SessionEnd - SessionStart in seconds (float64)
+	SessionDurationField = "session_duration"
+	// SessionCountField holds the number of events in the session (int64)
+	SessionCountField = "session_count"
+	// SessionRecordsField holds the session's records as an iter.Seq[Record];
+	// pass it to Aggregate as the sequence field
+	SessionRecordsField = "session_records"
+)
+
+// SessionWindow groups records into sessions: runs of events for the same
+// key (the values of keyFields) with less than gap between consecutive
+// events, by the event time in timeField. A session ends once the stream
+// reaches an event time gap or more after the session's last event, so
+// sessions are emitted while an unbounded stream is still running; sessions
+// still open when the input ends are emitted then, in order of start time.
+//
+// Each output record holds the key fields (like GroupByFields),
+// SessionStartField, SessionEndField, SessionDurationField,
+// SessionCountField and the session's records in SessionRecordsField, so it
+// can be passed to Aggregate.
+//
+// Input is expected in event-time order. Events slightly out of order still
+// join a session when they fall within gap of it; an event more than gap
+// before its key's open session forms a session of its own. Records without
+// a valid time, or whose key field holds a nested Record or sequence, are
+// skipped.
+//
+// Example:
+//
+//	// Clickstream sessions per user, closed after 30 minutes of inactivity
+//	summary := ssql.Chain(
+//	    ssql.SessionWindow(30*time.Minute, "ts", "user_id"),
+//	    ssql.Aggregate(ssql.SessionRecordsField, map[string]ssql.AggregateFunc{
+//	        "pages": ssql.Count(),
+//	        "bytes": ssql.Sum("bytes"),
+//	    }),
+//	)(clicks)
+func SessionWindow(gap time.Duration, timeField string, keyFields ...string) Filter[Record, Record] {
+	return func(input iter.Seq[Record]) iter.Seq[Record] {
+		return func(yield func(Record) bool) {
+			if gap <= 0 {
+				return
+			}
+			open := make(map[string]*session)
+			expiry := &sessionHeap{} // open sessions, earliest end first
+			var created int64
+			var maxTime time.Time
+
+			newSession := func(key string, groupFields Record, r Record, t time.Time) *session {
+				s := &session{groupKey: key, groupFields: groupFields, start: t, end: t, members: []Record{r}, created: created}
+				created++
+				return s
+			}
+			emit := func(s *session) bool {
+				return yield(s.record())
+			}
+
+			for r := range input {
+				val, _ := r.lookup(timeField)
+				t := parseTimeValue(val)
+				if t.IsZero() {
+					continue // Skip records without valid timestamps
+				}
+				key, groupFields, ok := groupKey(r, keyFields)
+				if !ok {
+					continue
+				}
+
+				// Close sessions the stream has moved gap past
+				if t.After(maxTime) {
+					maxTime = t
+					var closed []*session
+					for expiry.Len() > 0 && maxTime.Sub(expiry.sessions[0].end) >= gap {
+						s := heap.Pop(expiry).(*session)
+						delete(open, s.groupKey)
+						closed = append(closed, s)
+					}
+					sortSessions(closed)
+					for _, s := range closed {
+						if !emit(s) {
+							return
+						}
+					}
+				}
+
+				if s, exists := open[key]; exists {
+					switch {
+					case t.Sub(s.end) >= gap:
+						// Inactivity gap: close the session and start a new one
+						heap.Remove(expiry, s.index)
+						delete(open, key)
+						if !emit(s) {
+							return
+						}
+					case s.start.Sub(t) >= gap:
+						// Far older than the open session: a session of its own
+						if !emit(newSession(key, groupFields, r, t)) {
+							return
+						}
+						continue
+					default:
+						s.add(r, t)
+						heap.Fix(expiry, s.index)
+					}
+				}
+				if _, exists := open[key]; !exists {
+					s := newSession(key, groupFields, r, t)
+					open[key] = s
+					heap.Push(expiry, s)
+				}
+			}
+
+			// End of input: emit the sessions still open
+			remaining := expiry.sessions
+			sortSessions(remaining)
+			for _, s := range remaining {
+				if !emit(s) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// session is the state of one open session
+type session struct {
+	groupKey    string
+	groupFields Record
+	start, end  time.Time
+	members     []Record
+	created     int64
+	index       int // position in the sessionHeap
+}
+
+// add extends the session with an event at time t
+func (s *session) add(r Record, t time.Time) {
+	s.members = append(s.members, r)
+	if t.Before(s.start) {
+		s.start = t
+	}
+	if t.After(s.end) {
+		s.end = t
+	}
+}
+
+// record builds the session's output record
+func (s *session) record() Record {
+	result := MakeMutableRecord()
+	for k, v := range s.groupFields.All() {
+		result.set(k, v)
+	}
+	result.set(SessionStartField, s.start)
+	result.set(SessionEndField, s.end)
+	result.set(SessionDurationField, s.end.Sub(s.start).Seconds())
+	result.set(SessionCountField, int64(len(s.members)))
+	result.set(SessionRecordsField, slices.Values(s.members))
+	return result.Freeze()
+}
+
+// sortSessions orders sessions by start time, then creation
+func sortSessions(sessions []*session) {
+	slices.SortFunc(sessions, func(a, b *session) int {
+		if c := a.start.Compare(b.start); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.created, b.created)
+	})
+}
+
+// sessionHeap orders open sessions by the time of their last event, so
+// the sessions to close are found without scanning every open session
+type sessionHeap struct {
+	sessions []*session
+}
+
+func (h *sessionHeap) Len() int { return len(h.sessions) }
+
+func (h *sessionHeap) Less(i, j int) bool { return h.sessions[i].end.Before(h.sessions[j].end) }
+
+func (h *sessionHeap) Swap(i, j int) {
+	h.sessions[i], h.sessions[j] = h.sessions[j], h.sessions[i]
+	h.sessions[i].index = i
+	h.sessions[j].index = j
+}
+
+func (h *sessionHeap) Push(x any) {
+	s := x.(*session)
+	s.index = len(h.sessions)
+	h.sessions = append(h.sessions, s)
+}
+
+func (h *sessionHeap) Pop() any {
+	old := h.sessions
+	s := old[len(old)-1]
+	old[len(old)-1] = nil
+	h.sessions = old[:len(old)-1]
+	return s
+}
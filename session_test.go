@@ -0,0 +1,88 @@
+package ssql
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+// ============================================================================
+// SESSION WINDOW TESTS
+// ============================================================================
+
+func TestSessionWindow(t *testing.T) {
+	base := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	click := func(minute int, user string, bytes int64) Record {
+		return MakeMutableRecord().
+			Time("ts", base.Add(time.Duration(minute)*time.Minute)).
+			String("user", user).
+			Int("bytes", bytes).
+			Freeze()
+	}
+	clicks := []Record{
+		click(0, "ann", 10),
+		click(5, "bob", 1),
+		click(12, "ann", 20),
+		click(40, "ann", 5),  // 28 min after ann's last click: still closes at gap 20
+		click(41, "bob", 2),  // bob's first session closed at minute 25
+		click(39, "ann", 15), // slightly out of order: joins ann's second session
+		MakeMutableRecord().String("user", "ann").Freeze(), // no time: skipped
+	}
+
+	sessions := slices.Collect(SessionWindow(20*time.Minute, "ts", "user")(slices.Values(clicks)))
+	var got []string
+	for _, s := range sessions {
+		start := GetOr(s, SessionStartField, time.Time{}).Sub(base)
+		got = append(got, fmt.Sprintf("%s@%v+%vs/%d",
+			GetOr(s, "user", ""), start, GetOr(s, SessionDurationField, 0.0), GetOr(s, SessionCountField, int64(0))))
+	}
+	want := []string{"ann@0s+720s/2", "bob@5m0s+0s/1", "ann@39m0s+60s/2", "bob@41m0s+0s/1"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Sessions = %v, want %v", got, want)
+	}
+
+	// Output feeds Aggregate
+	totals := slices.Collect(Aggregate(SessionRecordsField, map[string]AggregateFunc{
+		"bytes": Sum("bytes"),
+	})(slices.Values(sessions)))
+	if GetOr(totals[0], "bytes", 0.0) != 30 || GetOr(totals[2], "bytes", 0.0) != 20 {
+		t.Errorf("Unexpected session totals %v", totals)
+	}
+
+	// Without key fields all events share one session stream
+	if all := slices.Collect(SessionWindow(20*time.Minute, "ts")(slices.Values(clicks))); len(all) != 2 {
+		t.Errorf("Expected 2 unkeyed sessions, got %d", len(all))
+	}
+
+	// Early stop
+	if first := slices.Collect(Limit[Record](1)(SessionWindow(time.Minute, "ts", "user")(slices.Values(clicks)))); len(first) != 1 {
+		t.Errorf("Expected 1 session after Limit, got %d", len(first))
+	}
+
+	// A late event that doesn't move its session's end leaves the session
+	// due to close at the same time
+	late := []Record{
+		click(0, "ann", 1),
+		click(10, "ann", 1),
+		click(12, "bob", 1),
+		click(8, "ann", 1), // late: ann's session still ends at minute 10
+		click(31, "cat", 1),
+		click(35, "dan", 1),
+	}
+	read := 0
+	counted := func(yield func(Record) bool) {
+		for _, r := range late {
+			read++
+			if !yield(r) {
+				return
+			}
+		}
+	}
+	for s := range SessionWindow(20*time.Minute, "ts", "user")(counted) {
+		if GetOr(s, "user", "") != "ann" || GetOr(s, SessionCountField, int64(0)) != 3 || read != 5 {
+			t.Errorf("Expected ann's session of 3 when minute 31 arrives, got %v after %d records", s, read)
+		}
+		break
+	}
+}
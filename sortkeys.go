@@ -0,0 +1,363 @@
+package ssql
+
+import (
+	"cmp"
+	"fmt"
+	"iter"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ============================================================================
+// MULTI-KEY SORTING
+// ============================================================================
+
+// SortDirection is the direction of one sort key
+type SortDirection int
+
+const (
+	// Ascending sorts smallest first (the default)
+	Ascending SortDirection = iota
+	// Descending sorts largest first
+	Descending
+)
+
+// NullOrder places null and missing values relative to all other values,
+// whatever the key's direction
+type NullOrder int
+
+const (
+	// NullsLast sorts null and missing values after all others (the default)
+	NullsLast NullOrder = iota
+	// NullsFirst sorts null and missing values before all others
+	NullsFirst
+)
+
+// SortMode selects how the values of a sort key are compared
+type SortMode int
+
+const (
+	// SortAuto compares by type: numbers numerically (int64, float64 and
+	// Decimal together), strings lexically, times chronologically and
+	// false before true. Values of different kinds sort numbers, strings,
+	// times, bools, then anything else by its printed form.
+	SortAuto SortMode = iota
+	// SortNumeric compares values as numbers, parsing numeric strings.
+	// Values that are not numbers sort after all numbers, lexically.
+	SortNumeric
+	// SortLexical compares the printed form of values byte by byte
+	SortLexical
+	// SortNatural compares the printed form of values with runs of digits
+	// compared as numbers, so "file9" sorts before "file10"
+	SortNatural
+	// SortCaseInsensitive compares the printed form of values ignoring case
+	SortCaseInsensitive
+	// SortNaturalCaseInsensitive compares like SortNatural, ignoring case
+	SortNaturalCaseInsensitive
+)
+
+// SortKey is one key of a multi-key sort. Field names the field to sort by
+// (nested paths like "customer.name" allowed); set KeyFn instead to sort by
+// a computed value. The zero values of Direction, Nulls and Mode sort
+// ascending, nulls last, by type.
+type SortKey struct {
+	Field     string
+	KeyFn     func(Record) any
+	Direction SortDirection
+	Nulls     NullOrder
+	Mode      SortMode
+}
+
+// Asc returns an ascending sort key on field
+func Asc(field string) SortKey {
+	return SortKey{Field: field}
+}
+
+// Desc returns a descending sort key on field
+func Desc(field string) SortKey {
+	return SortKey{Field: field, Direction: Descending}
+}
+
+// WithMode returns a copy of the key using the comparison mode m
+func (k SortKey) WithMode(m SortMode) SortKey {
+	k.Mode = m
+	return k
+}
+
+// WithNulls returns a copy of the key placing null values by n
+func (k SortKey) WithNulls(n NullOrder) SortKey {
+	k.Nulls = n
+	return k
+}
+
+// value extracts the key's value from r; missing fields read as nil
+func (k SortKey) value(r Record) any {
+	if k.KeyFn != nil {
+		return k.KeyFn(r)
+	}
+	v, _ := r.lookup(k.Field)
+	return v
+}
+
+// compare orders two values extracted by this key
+func (k SortKey) compare(a, b any) int {
+	aNull, bNull := IsNull(a), IsNull(b)
+	switch {
+	case aNull && bNull:
+		return 0
+	case aNull || bNull:
+		c := 1 // a is null: after b
+		if bNull {
+			c = -1
+		}
+		if k.Nulls == NullsFirst {
+			c = -c
+		}
+		return c
+	}
+	c := compareSortValues(a, b, k.Mode)
+	if k.Direction == Descending {
+		c = -c
+	}
+	return c
+}
+
+// SortByKeys sorts records by several keys, each with its own direction,
+// null placement and comparison mode. Later keys break ties in earlier
+// ones, and records equal on every key keep their input order.
+//
+// Example:
+//
+//	// Region A-Z, then highest revenue first
+//	sorted := ssql.SortByKeys(ssql.Asc("region"), ssql.Desc("revenue"))(sales)
+//
+//	// File names in natural order, case-insensitive owner as a tie-break
+//	sorted = ssql.SortByKeys(
+//	    ssql.Asc("file").WithMode(ssql.SortNatural),
+//	    ssql.SortKey{Field: "owner", Mode: ssql.SortCaseInsensitive, Nulls: ssql.NullsFirst},
+//	)(files)
+func SortByKeys(keys ...SortKey) Filter[Record, Record] {
+	compare := compareKeyValues(keys)
+	return func(input iter.Seq[Record]) iter.Seq[Record] {
+		return func(yield func(Record) bool) {
+			// Extract each record's key values once rather than per comparison
+			type keyed struct {
+				values []any
+				record Record
+			}
+			var items []keyed
+			for r := range input {
+				items = append(items, keyed{sortKeyValues(keys, r), r})
+			}
+			slices.SortStableFunc(items, func(a, b keyed) int { return compare(a.values, b.values) })
+			for _, item := range items {
+				if !yield(item.record) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// CompareByKeys returns a comparison function ordering records like
+// SortByKeys, for use with Stream.SortBy or slices.SortStableFunc.
+func CompareByKeys(keys ...SortKey) func(a, b Record) int {
+	compare := compareKeyValues(keys)
+	return func(a, b Record) int {
+		return compare(sortKeyValues(keys, a), sortKeyValues(keys, b))
+	}
+}
+
+// sortKeyValues extracts the value of every key from r
+func sortKeyValues(keys []SortKey, r Record) []any {
+	values := make([]any, len(keys))
+	for i, k := range keys {
+		values[i] = k.value(r)
+	}
+	return values
+}
+
+// compareKeyValues compares key values extracted by sortKeyValues
+func compareKeyValues(keys []SortKey) func(a, b []any) int {
+	return func(a, b []any) int {
+		for i, k := range keys {
+			if c := k.compare(a[i], b[i]); c != 0 {
+				return c
+			}
+		}
+		return 0
+	}
+}
+
+// compareSortValues compares two non-null values in the given mode
+func compareSortValues(a, b any, mode SortMode) int {
+	switch mode {
+	case SortNumeric:
+		af, aok := sortNumber(a)
+		bf, bok := sortNumber(b)
+		switch {
+		case aok && bok:
+			return compareNumbers(af, bf)
+		case aok:
+			return -1
+		case bok:
+			return 1
+		}
+		return strings.Compare(sortString(a), sortString(b))
+	case SortLexical:
+		return strings.Compare(sortString(a), sortString(b))
+	case SortNatural:
+		return compareNatural(sortString(a), sortString(b))
+	case SortCaseInsensitive:
+		return strings.Compare(strings.ToLower(sortString(a)), strings.ToLower(sortString(b)))
+	case SortNaturalCaseInsensitive:
+		return compareNatural(strings.ToLower(sortString(a)), strings.ToLower(sortString(b)))
+	}
+
+	// SortAuto: compare like kinds by type, unlike kinds by kind rank
+	if ra, rb := sortKindRank(a), sortKindRank(b); ra != rb {
+		return cmp.Compare(ra, rb)
+	}
+	switch av := a.(type) {
+	case string:
+		return strings.Compare(av, b.(string))
+	case time.Time:
+		return av.Compare(b.(time.Time))
+	case bool:
+		bv := b.(bool)
+		switch {
+		case av == bv:
+			return 0
+		case !av:
+			return -1
+		default:
+			return 1
+		}
+	case int64, float64, Decimal:
+		return compareNumbers(a, b)
+	}
+	return strings.Compare(sortString(a), sortString(b))
+}
+
+// sortKindRank orders the kinds of value SortAuto compares
+func sortKindRank(v any) int {
+	switch v.(type) {
+	case int64, float64, Decimal:
+		return 0
+	case string:
+		return 1
+	case time.Time:
+		return 2
+	case bool:
+		return 3
+	default:
+		return 4
+	}
+}
+
+// sortNumber returns v as a number (int64, float64 or Decimal), parsing
+// numeric strings
+func sortNumber(v any) (any, bool) {
+	switch val := v.(type) {
+	case int64, float64, Decimal:
+		return val, true
+	case string:
+		s := strings.TrimSpace(val)
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return i, true
+		}
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f, true
+		}
+	}
+	return nil, false
+}
+
+// compareNumbers compares int64, float64 and Decimal values, exactly when
+// neither is a float64
+func compareNumbers(a, b any) int {
+	switch av := a.(type) {
+	case int64:
+		switch bv := b.(type) {
+		case int64:
+			return cmp.Compare(av, bv)
+		case Decimal:
+			return DecimalFromInt(av).Cmp(bv)
+		}
+	case Decimal:
+		switch bv := b.(type) {
+		case Decimal:
+			return av.Cmp(bv)
+		case int64:
+			return av.Cmp(DecimalFromInt(bv))
+		}
+	}
+	return cmp.Compare(sortFloat(a), sortFloat(b))
+}
+
+func sortFloat(v any) float64 {
+	switch val := v.(type) {
+	case int64:
+		return float64(val)
+	case float64:
+		return val
+	case Decimal:
+		return val.Float64()
+	}
+	return 0
+}
+
+// sortString returns the printed form of v used by the text sort modes
+func sortString(v any) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case time.Time:
+		return val.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(v)
+}
+
+// compareNatural compares strings with runs of digits compared by numeric
+// value, so "file9" < "file10". Strings equal that way (like "a01" and
+// "a1") fall back to a byte comparison.
+func compareNatural(a, b string) int {
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if isDigit(a[i]) && isDigit(b[j]) {
+			si, sj := i, j
+			for i < len(a) && isDigit(a[i]) {
+				i++
+			}
+			for j < len(b) && isDigit(b[j]) {
+				j++
+			}
+			// Compare digit runs by value: ignore leading zeros, then the
+			// longer run is larger, then compare digit by digit
+			da := strings.TrimLeft(a[si:i], "0")
+			db := strings.TrimLeft(b[sj:j], "0")
+			if c := cmp.Compare(len(da), len(db)); c != 0 {
+				return c
+			}
+			if c := strings.Compare(da, db); c != 0 {
+				return c
+			}
+			continue
+		}
+		if c := cmp.Compare(a[i], b[j]); c != 0 {
+			return c
+		}
+		i++
+		j++
+	}
+	if c := cmp.Compare(len(a)-i, len(b)-j); c != 0 {
+		return c
+	}
+	return strings.Compare(a, b)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,136 @@
+package ssql
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// ============================================================================
+// MULTI-KEY SORT TESTS
+// ============================================================================
+
+func TestSortByKeys(t *testing.T) {
+	sale := func(id int64, region string, revenue any) Record {
+		fields := map[string]any{"id": id, "region": region}
+		if revenue != nil {
+			fields["revenue"] = revenue
+		}
+		return NewRecord(fields)
+	}
+	price, _ := ParseDecimal("250.5")
+	sales := []Record{
+		sale(1, "west", int64(100)),
+		sale(2, "east", 300.0),
+		sale(3, "west", nil),
+		sale(4, "east", price),
+		sale(5, "west", int64(100)),
+		sale(6, "east", int64(300)),
+	}
+	ids := func(records []Record) []int64 {
+		var out []int64
+		for _, r := range records {
+			out = append(out, GetOr(r, "id", int64(0)))
+		}
+		return out
+	}
+
+	// Region ascending, revenue descending; int64, float64 and Decimal
+	// compare as numbers, ties keep input order, missing revenue sorts last
+	sorted := slices.Collect(SortByKeys(Asc("region"), Desc("revenue"))(slices.Values(sales)))
+	if got := ids(sorted); !slices.Equal(got, []int64{2, 6, 4, 1, 5, 3}) {
+		t.Errorf("Asc region, Desc revenue: got %v", got)
+	}
+
+	sorted = slices.Collect(SortByKeys(Desc("revenue").WithNulls(NullsFirst))(slices.Values(sales)))
+	if got := ids(sorted); got[0] != 3 {
+		t.Errorf("Expected missing revenue first, got %v", got)
+	}
+
+	// Key function
+	byLength := SortKey{KeyFn: func(r Record) any { return int64(len(GetOr(r, "region", ""))) }, Direction: Descending}
+	if got := ids(slices.Collect(SortByKeys(byLength, Asc("id"))(slices.Values(sales[:2])))); !slices.Equal(got, []int64{1, 2}) {
+		t.Errorf("KeyFn sort: got %v", got)
+	}
+
+	// CompareByKeys agrees with SortByKeys
+	records := slices.Clone(sales)
+	slices.SortStableFunc(records, CompareByKeys(Asc("region"), Desc("revenue")))
+	if !slices.Equal(ids(records), []int64{2, 6, 4, 1, 5, 3}) {
+		t.Errorf("CompareByKeys: got %v", ids(records))
+	}
+}
+
+func TestSortByKeysModes(t *testing.T) {
+	names := func(values ...string) []Record {
+		var out []Record
+		for _, v := range values {
+			out = append(out, MakeMutableRecord().String("name", v).Freeze())
+		}
+		return out
+	}
+	sortNames := func(mode SortMode, values ...string) string {
+		var out []string
+		for r := range SortByKeys(Asc("name").WithMode(mode))(slices.Values(names(values...))) {
+			out = append(out, GetOr(r, "name", ""))
+		}
+		return strings.Join(out, " ")
+	}
+
+	tests := []struct {
+		mode   SortMode
+		values []string
+		want   string
+	}{
+		{SortAuto, []string{"file10", "file9", "File1"}, "File1 file10 file9"},
+		{SortLexical, []string{"10", "9", "100"}, "10 100 9"},
+		{SortNumeric, []string{"10", "abc", "9", "1.5"}, "1.5 9 10 abc"},
+		{SortNatural, []string{"file10", "file9", "file1", "file09b"}, "file1 file9 file09b file10"},
+		{SortCaseInsensitive, []string{"beta", "Alpha", "alpha", "Beta"}, "Alpha alpha beta Beta"},
+		{SortNaturalCaseInsensitive, []string{"Img12", "img2", "IMG1"}, "IMG1 img2 Img12"},
+	}
+	for _, tt := range tests {
+		if got := sortNames(tt.mode, tt.values...); got != tt.want {
+			t.Errorf("Mode %d: got %q, want %q", tt.mode, got, tt.want)
+		}
+	}
+
+	// SortAuto orders unlike kinds: numbers, then strings, then bools
+	mixed := []Record{
+		MakeMutableRecord().Bool("v", true).Freeze(),
+		MakeMutableRecord().String("v", "x").Freeze(),
+		MakeMutableRecord().Float("v", 2.5).Freeze(),
+		MakeMutableRecord().Int("v", 2).Freeze(),
+	}
+	var kinds []string
+	for r := range SortByKeys(Asc("v"))(slices.Values(mixed)) {
+		v, _ := Get[any](r, "v")
+		kinds = append(kinds, fmt.Sprintf("%T", v))
+	}
+	if !slices.Equal(kinds, []string{"int64", "float64", "string", "bool"}) {
+		t.Errorf("Unexpected mixed-kind order %v", kinds)
+	}
+}
+
+func TestExternalSortByKeys(t *testing.T) {
+	var records []Record
+	for i := range 500 {
+		records = append(records, MakeMutableRecord().
+			String("group", []string{"b", "a", "c"}[i%3]).
+			Int("n", int64((i*37)%50)).
+			Int("seq", int64(i)).
+			Freeze())
+	}
+	keys := []SortKey{Asc("group"), Desc("n")}
+	expected := slices.Collect(SortByKeys(keys...)(slices.Values(records)))
+	sorted := slices.Collect(ExternalSortByKeys(ExternalSortConfig{MemoryLimit: 4 << 10, TempDir: t.TempDir()}, keys...)(slices.Values(records)))
+	if len(sorted) != len(expected) {
+		t.Fatalf("Expected %d records, got %d", len(expected), len(sorted))
+	}
+	for i := range sorted {
+		if GetOr(sorted[i], "seq", int64(-1)) != GetOr(expected[i], "seq", int64(-2)) {
+			t.Fatalf("Record %d differs from the in-memory sort: %v vs %v", i, sorted[i], expected[i])
+		}
+	}
+}
@@ -3,6 +3,9 @@ package ssql
 import (
 	"fmt"
 	"iter"
+	"maps"
+	"reflect"
+	"slices"
 	"strings"
 )
 
@@ -67,11 +70,11 @@ func innerJoinNested(
 				joined := MakeMutableRecord()
 				// Copy left record
 				for k, v := range left.All() {
-					joined.fields[k] = v
+					joined.set(k, v)
 				}
 				// Copy right record
 				for k, v := range right.All() {
-					joined.fields[k] = v
+					joined.set(k, v)
 				}
 				if !yield(joined.Freeze()) {
 					return
@@ -113,11 +116,11 @@ func innerJoinHash(
 					joined := MakeMutableRecord()
 					// Copy left record
 					for k, v := range left.All() {
-						joined.fields[k] = v
+						joined.set(k, v)
 					}
 					// Copy right record
 					for k, v := range right.All() {
-						joined.fields[k] = v
+						joined.set(k, v)
 					}
 					if !yield(joined.Freeze()) {
 						return
@@ -192,11 +195,11 @@ func leftJoinNested(
 				joined := MakeMutableRecord()
 				// Copy left record
 				for k, v := range left.All() {
-					joined.fields[k] = v
+					joined.set(k, v)
 				}
 				// Copy right record
 				for k, v := range right.All() {
-					joined.fields[k] = v
+					joined.set(k, v)
 				}
 				if !yield(joined.Freeze()) {
 					return
@@ -244,11 +247,11 @@ func leftJoinHash(
 						joined := MakeMutableRecord()
 						// Copy left record
 						for k, v := range left.All() {
-							joined.fields[k] = v
+							joined.set(k, v)
 						}
 						// Copy right record
 						for k, v := range right.All() {
-							joined.fields[k] = v
+							joined.set(k, v)
 						}
 						if !yield(joined.Freeze()) {
 							return
@@ -312,11 +315,11 @@ func rightJoinNested(
 				joined := MakeMutableRecord()
 				// Copy left record
 				for k, v := range left.All() {
-					joined.fields[k] = v
+					joined.set(k, v)
 				}
 				// Copy right record
 				for k, v := range right.All() {
-					joined.fields[k] = v
+					joined.set(k, v)
 				}
 				if !yield(joined.Freeze()) {
 					return
@@ -372,11 +375,11 @@ func rightJoinHash(
 						joined := MakeMutableRecord()
 						// Copy left record
 						for k, v := range left.All() {
-							joined.fields[k] = v
+							joined.set(k, v)
 						}
 						// Copy right record
 						for k, v := range right.All() {
-							joined.fields[k] = v
+							joined.set(k, v)
 						}
 						if !yield(joined.Freeze()) {
 							return
@@ -443,11 +446,11 @@ func fullJoinNested(
 				joined := MakeMutableRecord()
 				// Copy left record
 				for k, v := range left.All() {
-					joined.fields[k] = v
+					joined.set(k, v)
 				}
 				// Copy right record
 				for k, v := range right.All() {
-					joined.fields[k] = v
+					joined.set(k, v)
 				}
 				if !yield(joined.Freeze()) {
 					return
@@ -519,11 +522,11 @@ func fullJoinHash(
 						joined := MakeMutableRecord()
 						// Copy left record
 						for k, v := range left.All() {
-							joined.fields[k] = v
+							joined.set(k, v)
 						}
 						// Copy right record
 						for k, v := range right.All() {
-							joined.fields[k] = v
+							joined.set(k, v)
 						}
 						if !yield(joined.Freeze()) {
 							return
@@ -578,6 +581,8 @@ func FullJoin(rightSeq iter.Seq[Record], predicate JoinPredicate) Filter[Record,
 
 // OnFields creates a join predicate that matches records on specified fields.
 // This is the most common way to join records (equivalent to SQL ON field1 = field2).
+// Records with a missing or null key field never match (null does not equal null).
+// Fields may be nested paths such as "customer.id".
 //
 // Example:
 //
@@ -597,23 +602,51 @@ func OnFields(fields ...string) JoinPredicate {
 }
 
 // Match implements JoinPredicate for fieldsJoinPredicate
+// Null never matches anything, including another null (SQL semantics).
 func (p *fieldsJoinPredicate) Match(left, right Record) bool {
 	for _, field := range p.fields {
-		leftVal, leftExists := left.fields[field]
-		rightVal, rightExists := right.fields[field]
-		if !leftExists || !rightExists || leftVal != rightVal {
+		leftVal, leftExists := left.lookup(field)
+		rightVal, rightExists := right.lookup(field)
+		if !leftExists || !rightExists || !JoinValuesEqual(leftVal, rightVal) {
 			return false
 		}
 	}
 	return true
 }
 
+// JoinValuesEqual reports whether two field values match as join keys, as
+// OnFields compares them: both must have the same type and be equal, and
+// null never matches anything, including another null. Values that cannot
+// be compared with == (nested Records, sequences, JSON objects and arrays)
+// match when their contents are equal.
+//
+// Example:
+//
+//	byEmail := ssql.OnCondition(func(user, login ssql.Record) bool {
+//	    email, _ := ssql.Get[any](user, "email")
+//	    account, _ := ssql.Get[any](login, "account")
+//	    return ssql.JoinValuesEqual(email, account)
+//	})
+func JoinValuesEqual(a, b any) bool {
+	if IsNull(a) || IsNull(b) {
+		return false
+	}
+	ta := reflect.TypeOf(a)
+	if ta != reflect.TypeOf(b) {
+		return false
+	}
+	if ta.Comparable() {
+		return a == b
+	}
+	return valuesEqual(a, b)
+}
+
 // ExtractKey implements KeyExtractor for hash join optimization
 func (p *fieldsJoinPredicate) ExtractKey(r Record) (string, bool) {
 	var parts []string
 	for _, field := range p.fields {
-		val, exists := r.fields[field]
-		if !exists {
+		val, exists := r.lookup(field)
+		if !exists || IsNull(val) {
 			return "", false
 		}
 		// Convert value to string for hash key
@@ -687,11 +720,11 @@ func GroupBy[K comparable](sequenceField string, keyField string, keyFn func(Rec
 				result := MakeMutableRecord()
 
 				// Set the key field
-				result.fields[keyField] = key
+				result.set(keyField, key)
 
 				// Add the sequence of group members as an iter.Seq[Record]
 				groupRecords := groups[key]
-				result.fields[sequenceField] = func() iter.Seq[Record] {
+				result.set(sequenceField, func() iter.Seq[Record] {
 					return func(yield func(Record) bool) {
 						for _, record := range groupRecords {
 							if !yield(record) {
@@ -699,7 +732,7 @@ func GroupBy[K comparable](sequenceField string, keyField string, keyFn func(Rec
 							}
 						}
 					}
-				}()
+				}())
 
 				if !yield(result.Freeze()) {
 					return
@@ -709,10 +742,48 @@ func GroupBy[K comparable](sequenceField string, keyField string, keyFn func(Rec
 	}
 }
 
+// Group key markers for absent and null grouping fields.
+// They contain a NUL byte so they cannot collide with formatted values.
+const (
+	groupKeyMissing = "\x00missing"
+	groupKeyNull    = "\x00null"
+)
+
+// groupKey returns the GroupByFields key of a record and its grouping field
+// values. Missing fields form their own group and stay absent; all nulls
+// group together (SQL GROUP BY semantics). ok is false if a grouping field
+// holds a complex value (iter.Seq or Record), which cannot be grouped on.
+func groupKey(record Record, fields []string) (key string, groupingFields Record, ok bool) {
+	keyParts := make([]string, 0, len(fields))
+	grouping := MakeMutableRecord()
+	for _, field := range fields {
+		val, exists := record.lookup(field)
+		switch {
+		case !exists:
+			keyParts = append(keyParts, groupKeyMissing)
+		case IsNull(val):
+			keyParts = append(keyParts, groupKeyNull)
+			grouping.set(field, Null{})
+		case !isSimpleValue(val):
+			return "", Record{}, false
+		default:
+			keyParts = append(keyParts, fmt.Sprintf("%v", val))
+			grouping.set(field, val)
+		}
+	}
+	return strings.Join(keyParts, "\x00"), grouping.Freeze(), true
+}
+
 // GroupByFields groups records by specified field values (SQL GROUP BY field1, field2...).
 // Returns Records with grouping fields + a sequence field containing group members.
 // Use with Aggregate to compute aggregations over each group.
 //
+// Fields may be nested paths such as "user.address.city"; the output field
+// is named by the path.
+//
+// Null grouping values form a single group whose output field is Null.
+// Records missing a grouping field form a separate group in which that field is absent.
+//
 // This is the most common grouping operation in StreamV3.
 //
 // Example:
@@ -739,35 +810,14 @@ func GroupByFields(sequenceField string, fields ...string) Filter[Record, Record
 
 			// Collect all records into groups
 			for record := range input {
-				var keyParts []string
-				groupingFields := MakeMutableRecord()
-				hasComplexField := false
-
-				for _, field := range fields {
-					if val, exists := record.fields[field]; exists {
-						// Validate that the field value is simple (no iter.Seq or Record)
-						if !isSimpleValue(val) {
-							// Skip this entire record if any grouping field is complex
-							hasComplexField = true
-							break
-						}
-						keyParts = append(keyParts, fmt.Sprintf("%v", val))
-						groupingFields.fields[field] = val
-					} else {
-						keyParts = append(keyParts, "<nil>")
-						groupingFields.fields[field] = nil
-					}
-				}
-
-				// Skip records with complex grouping field values
-				if hasComplexField {
+				key, groupingFields, ok := groupKey(record, fields)
+				if !ok {
+					// Skip records with complex grouping field values
 					continue
 				}
-
-				key := fmt.Sprintf("[%s]", strings.Join(keyParts, ","))
 				if _, exists := groups[key]; !exists {
 					keys = append(keys, key)
-					groupFields[key] = groupingFields.Freeze()
+					groupFields[key] = groupingFields
 				}
 				groups[key] = append(groups[key], record)
 			}
@@ -778,12 +828,12 @@ func GroupByFields(sequenceField string, fields ...string) Filter[Record, Record
 
 				// Copy the grouping field values
 				for k, v := range groupFields[key].All() {
-					result.fields[k] = v
+					result.set(k, v)
 				}
 
 				// Add the sequence of group members as an iter.Seq[Record]
 				groupRecords := groups[key]
-				result.fields[sequenceField] = func() iter.Seq[Record] {
+				result.set(sequenceField, func() iter.Seq[Record] {
 					return func(yield func(Record) bool) {
 						for _, record := range groupRecords {
 							if !yield(record) {
@@ -791,7 +841,7 @@ func GroupByFields(sequenceField string, fields ...string) Filter[Record, Record
 							}
 						}
 					}
-				}()
+				}())
 
 				if !yield(result.Freeze()) {
 					return
@@ -857,7 +907,7 @@ func Aggregate(sequenceField string, aggregations map[string]AggregateFunc) Filt
 				// Copy all fields except the sequence field
 				for field, value := range record.All() {
 					if field != sequenceField {
-						result.fields[field] = value
+						result.set(field, value)
 					}
 				}
 
@@ -870,10 +920,11 @@ func Aggregate(sequenceField string, aggregations map[string]AggregateFunc) Filt
 							records = append(records, r)
 						}
 
-						// Apply all aggregation functions (type-safe at compile time)
-						for name, aggFn := range aggregations {
-							aggResult := aggFn(records)
-							result.fields[name] = aggResult.getValue()
+						// Apply all aggregation functions (type-safe at compile time),
+						// adding result fields in name order so output is deterministic
+						for _, name := range slices.Sorted(maps.Keys(aggregations)) {
+							aggResult := aggregations[name](records)
+							result.set(name, aggResult.getValue())
 						}
 					}
 				}
@@ -891,20 +942,34 @@ func Aggregate(sequenceField string, aggregations map[string]AggregateFunc) Filt
 // ============================================================================
 
 // Count returns the number of records in a group (SQL COUNT(*)).
+// With a field argument it counts only records where that field is present
+// and not null (SQL COUNT(field)).
 //
 // Example:
 //
 //	aggregations := map[string]ssql.AggregateFunc{
-//	    "total": ssql.Count(),
+//	    "total":       ssql.Count(),
+//	    "with_email":  ssql.Count("email"),
 //	}
-func Count() AggregateFunc {
+func Count(field ...string) AggregateFunc {
 	return func(records []Record) AggregateResult {
-		return AggResult[int64]{val: int64(len(records))}
+		if len(field) == 0 {
+			return AggResult[int64]{val: int64(len(records))}
+		}
+		var count int64
+		for _, record := range records {
+			if val, exists := record.lookup(field[0]); exists && !IsNull(val) {
+				count++
+			}
+		}
+		return AggResult[int64]{val: count}
 	}
 }
 
 // Sum sums numeric values from a field across all records (SQL SUM(field)).
-// Automatically converts values to float64.
+// Automatically converts values to float64. Missing and null values are skipped.
+// If any value in the group is a Decimal the sum is computed exactly and the
+// result is a Decimal.
 //
 // Example:
 //
@@ -913,6 +978,10 @@ func Count() AggregateFunc {
 //	}
 func Sum(field string) AggregateFunc {
 	return func(records []Record) AggregateResult {
+		if values, ok := decimalValues(records, field); ok {
+			return AggResult[Decimal]{val: sumDecimals(values)}
+		}
+
 		var sum float64
 		for _, record := range records {
 			// Use type-safe Get with automatic conversion to float64
@@ -925,7 +994,10 @@ func Sum(field string) AggregateFunc {
 }
 
 // Avg calculates the average of numeric values from a field (SQL AVG(field)).
-// Automatically converts values to float64. Returns 0.0 for empty groups.
+// Automatically converts values to float64. Missing and null values are skipped
+// and do not count towards the divisor. Returns 0.0 for empty groups.
+// If any value in the group is a Decimal the result is a Decimal rounded
+// half away from zero to the larger of 6 and the input scale.
 //
 // Example:
 //
@@ -934,6 +1006,18 @@ func Sum(field string) AggregateFunc {
 //	}
 func Avg(field string) AggregateFunc {
 	return func(records []Record) AggregateResult {
+		if values, ok := decimalValues(records, field); ok {
+			if len(values) == 0 {
+				return AggResult[Decimal]{}
+			}
+			places := int32(6)
+			for _, v := range values {
+				places = max(places, v.Scale())
+			}
+			count := DecimalFromInt(int64(len(values)))
+			return AggResult[Decimal]{val: sumDecimals(values).Div(count, places)}
+		}
+
 		var sum float64
 		var count int64
 		for _, record := range records {
@@ -952,6 +1036,8 @@ func Avg(field string) AggregateFunc {
 
 // Min finds the minimum value from a field across all records (SQL MIN(field)).
 // Requires specifying the type parameter for type safety.
+// For numeric T, a group containing Decimal values is compared exactly and
+// the result is a Decimal.
 //
 // Example:
 //
@@ -965,6 +1051,11 @@ func Min[T OrderedValue](field string) AggregateFunc {
 			var zero T
 			return AggResult[T]{val: zero}
 		}
+		if isNumericOrdered[T]() {
+			if values, ok := decimalValues(records, field); ok {
+				return AggResult[Decimal]{val: extremeDecimal(values, -1)}
+			}
+		}
 
 		var min T
 		found := false
@@ -983,6 +1074,8 @@ func Min[T OrderedValue](field string) AggregateFunc {
 
 // Max finds the maximum value from a field across all records (SQL MAX(field)).
 // Requires specifying the type parameter for type safety.
+// For numeric T, a group containing Decimal values is compared exactly and
+// the result is a Decimal.
 //
 // Example:
 //
@@ -996,6 +1089,11 @@ func Max[T OrderedValue](field string) AggregateFunc {
 			var zero T
 			return AggResult[T]{val: zero}
 		}
+		if isNumericOrdered[T]() {
+			if values, ok := decimalValues(records, field); ok {
+				return AggResult[Decimal]{val: extremeDecimal(values, 1)}
+			}
+		}
 
 		var max T
 		found := false
@@ -1012,6 +1110,37 @@ func Max[T OrderedValue](field string) AggregateFunc {
 	}
 }
 
+// sumDecimals adds values exactly
+func sumDecimals(values []Decimal) Decimal {
+	var sum Decimal
+	for _, v := range values {
+		sum = sum.Add(v)
+	}
+	return sum
+}
+
+// extremeDecimal returns the smallest (sign -1) or largest (sign +1) value
+func extremeDecimal(values []Decimal, sign int) Decimal {
+	var result Decimal
+	for i, v := range values {
+		if i == 0 || v.Cmp(result) == sign {
+			result = v
+		}
+	}
+	return result
+}
+
+// isNumericOrdered reports whether T is one of the numeric OrderedValue types
+func isNumericOrdered[T OrderedValue]() bool {
+	var zero T
+	switch any(zero).(type) {
+	case string:
+		return false
+	default:
+		return true
+	}
+}
+
 // First returns the first non-nil value from a field
 // Requires specifying the type parameter for compile-time type safety
 func First[T Value](field string) AggregateFunc {
@@ -3,6 +3,7 @@ package ssql
 import (
 	"iter"
 	"slices"
+	"strings"
 	"testing"
 )
 
@@ -685,3 +686,100 @@ func TestAggregateNoSequenceField(t *testing.T) {
 		t.Error("Should not have aggregation when sequence field is missing")
 	}
 }
+
+// ============================================================================
+// NULL SEMANTICS TESTS
+// ============================================================================
+
+func TestJoinNullKeysNeverMatch(t *testing.T) {
+	left := []Record{
+		MakeMutableRecord().Null("id").String("l", "a").Freeze(),
+		MakeMutableRecord().Int("id", 1).String("l", "b").Freeze(),
+	}
+	right := []Record{
+		MakeMutableRecord().Null("id").String("r", "x").Freeze(),
+		MakeMutableRecord().Int("id", 1).String("r", "y").Freeze(),
+	}
+
+	inner := slices.Collect(InnerJoin(slices.Values(right), OnFields("id"))(slices.Values(left)))
+	if len(inner) != 1 || GetOr(inner[0], "r", "") != "y" {
+		t.Errorf("Expected only the non-null key to join, got %v", inner)
+	}
+
+	cond := OnCondition(func(l, r Record) bool { return OnFields("id").Match(l, r) })
+	nested := slices.Collect(InnerJoin(slices.Values(right), cond)(slices.Values(left)))
+	if len(nested) != 1 {
+		t.Errorf("Expected nested loop join to agree with hash join, got %d rows", len(nested))
+	}
+
+	outer := slices.Collect(LeftJoin(slices.Values(right), OnFields("id"))(slices.Values(left)))
+	if len(outer) != 2 || outer[0].Has("r") {
+		t.Errorf("Expected null-keyed left row to be unmatched, got %v", outer)
+	}
+}
+
+func TestJoinOnNestedJSONValues(t *testing.T) {
+	left := slices.Collect(ReadJSONFromReader(strings.NewReader(`{"key": {"a": 1}, "l": "x"}` + "\n" + `{"key": [1, 2], "l": "y"}` + "\n")))
+	right := slices.Collect(ReadJSONFromReader(strings.NewReader(`{"key": {"a": 1}, "r": "p"}` + "\n" + `{"key": [1, 3], "r": "q"}` + "\n")))
+
+	joined := slices.Collect(InnerJoin(slices.Values(right), OnFields("key"))(slices.Values(left)))
+	if len(joined) != 1 || GetOr(joined[0], "r", "") != "p" {
+		t.Errorf("Expected only the equal objects to join, got %v", joined)
+	}
+	if JoinValuesEqual(Null{}, Null{}) || JoinValuesEqual(int64(1), 1.0) || !JoinValuesEqual(int64(1), int64(1)) {
+		t.Error("Unexpected JoinValuesEqual result for scalars")
+	}
+}
+
+func TestGroupByFieldsNullAndMissing(t *testing.T) {
+	records := slices.Values([]Record{
+		MakeMutableRecord().String("dept", "eng").Freeze(),
+		MakeMutableRecord().Null("dept").Freeze(),
+		MakeMutableRecord().Null("dept").Freeze(),
+		MakeMutableRecord().Freeze(),
+		MakeMutableRecord().String("dept", "<nil>").Freeze(),
+	})
+
+	groups := slices.Collect(GroupByFields("rows", "dept")(records))
+	if len(groups) != 4 {
+		t.Fatalf("Expected 4 groups (eng, null, missing, \"<nil>\"), got %d", len(groups))
+	}
+	counts := map[string]int{}
+	for _, g := range groups {
+		rows, _ := Get[iter.Seq[Record]](g, "rows")
+		key := "missing"
+		if g.IsNull("dept") {
+			key = "null"
+		} else if g.Has("dept") {
+			key = GetOr(g, "dept", "")
+		}
+		counts[key] = len(slices.Collect(rows))
+	}
+	if counts["null"] != 2 || counts["missing"] != 1 || counts["eng"] != 1 || counts["<nil>"] != 1 {
+		t.Errorf("Unexpected group sizes: %v", counts)
+	}
+}
+
+func TestAggregatesSkipNull(t *testing.T) {
+	records := []Record{
+		MakeMutableRecord().Float("amount", 10).String("email", "a@x").Freeze(),
+		MakeMutableRecord().Null("amount").Null("email").Freeze(),
+		MakeMutableRecord().Float("amount", 20).Freeze(),
+	}
+
+	if got := Count()(records).getValue(); got != int64(3) {
+		t.Errorf("Count(): expected 3, got %v", got)
+	}
+	if got := Count("email")(records).getValue(); got != int64(1) {
+		t.Errorf("Count(email): expected 1, got %v", got)
+	}
+	if got := Count("amount")(records).getValue(); got != int64(2) {
+		t.Errorf("Count(amount): expected 2, got %v", got)
+	}
+	if got := Avg("amount")(records).getValue(); got != 15.0 {
+		t.Errorf("Avg should ignore nulls: expected 15, got %v", got)
+	}
+	if got := Sum("amount")(records).getValue(); got != 30.0 {
+		t.Errorf("Sum should ignore nulls: expected 30, got %v", got)
+	}
+}
@@ -0,0 +1,326 @@
+package ssql
+
+import (
+	"io"
+	"iter"
+	"slices"
+	"time"
+)
+
+// ============================================================================
+// FLUENT METHOD-CHAINING API
+// ============================================================================
+
+// Stream wraps an iter.Seq so operations can be chained as methods:
+//
+//	adults := ssql.NewStream(people).
+//	    Where(func(p Person) bool { return p.Age >= 18 }).
+//	    Limit(10).
+//	    Collect()
+//
+// A Stream is an iter.Seq underneath, so it can be ranged over directly,
+// and any existing Filter plugs in through Apply. Go methods cannot have
+// their own type parameters, so operations that change the element type
+// are applied with Then:
+//
+//	names := ssql.Then(ssql.NewStream(people), ssql.Select(func(p Person) string { return p.Name }))
+type Stream[T any] iter.Seq[T]
+
+// NewStream wraps seq in a Stream
+func NewStream[T any](seq iter.Seq[T]) Stream[T] {
+	return Stream[T](seq)
+}
+
+// StreamOf returns a Stream of the given values
+func StreamOf[T any](values ...T) Stream[T] {
+	return Stream[T](slices.Values(values))
+}
+
+// Then applies a filter that may change the element type, continuing the chain
+func Then[T, U any](s Stream[T], f Filter[T, U]) Stream[U] {
+	return Stream[U](f(s.Seq()))
+}
+
+// Seq returns the underlying iterator
+func (s Stream[T]) Seq() iter.Seq[T] {
+	return iter.Seq[T](s)
+}
+
+// Apply applies any same-type Filter, such as one built with Chain or a
+// custom operation
+func (s Stream[T]) Apply(f Filter[T, T]) Stream[T] {
+	return Stream[T](f(s.Seq()))
+}
+
+// Select transforms each element without changing its type.
+// Use Then with ssql.Select to change the type.
+func (s Stream[T]) Select(fn func(T) T) Stream[T] {
+	return s.Apply(Select(fn))
+}
+
+// Where keeps the elements matching predicate (see Where)
+func (s Stream[T]) Where(predicate func(T) bool) Stream[T] {
+	return s.Apply(Where(predicate))
+}
+
+// Limit keeps the first n elements (see Limit)
+func (s Stream[T]) Limit(n int) Stream[T] {
+	return s.Apply(Limit[T](n))
+}
+
+// Offset skips the first n elements (see Offset)
+func (s Stream[T]) Offset(n int) Stream[T] {
+	return s.Apply(Offset[T](n))
+}
+
+// SortBy sorts the elements with a comparison function, as slices.SortFunc
+// does. Methods cannot be generic over a key type; for the key-function
+// form use Apply(ssql.SortBy(keyFn)).
+func (s Stream[T]) SortBy(cmp func(a, b T) int) Stream[T] {
+	return Stream[T](func(yield func(T) bool) {
+		values := slices.Collect(s.Seq())
+		slices.SortStableFunc(values, cmp)
+		for _, v := range values {
+			if !yield(v) {
+				return
+			}
+		}
+	})
+}
+
+// DistinctBy keeps the first element for each key (see DistinctBy).
+// Keys must be comparable at run time.
+func (s Stream[T]) DistinctBy(keyFn func(T) any) Stream[T] {
+	return s.Apply(DistinctBy(keyFn))
+}
+
+// Reverse reverses the order of the elements (see Reverse)
+func (s Stream[T]) Reverse() Stream[T] {
+	return s.Apply(Reverse[T]())
+}
+
+// Tee splits the stream into n buffered copies (see Tee)
+func (s Stream[T]) Tee(n int) []Stream[T] {
+	return toStreams(Tee(s.Seq(), n))
+}
+
+// LazyTee splits the stream into n unbuffered copies that must be
+// consumed concurrently (see LazyTee)
+func (s Stream[T]) LazyTee(n int, config ...LazyTeeConfig) []Stream[T] {
+	return toStreams(LazyTee(s.Seq(), n, config...))
+}
+
+// toStreams wraps each iterator in a Stream
+func toStreams[T any](seqs []iter.Seq[T]) []Stream[T] {
+	streams := make([]Stream[T], len(seqs))
+	for i, seq := range seqs {
+		streams[i] = Stream[T](seq)
+	}
+	return streams
+}
+
+// CountWindow groups elements into windows of size elements (see CountWindow).
+// The window methods return an iter.Seq rather than a Stream because a
+// generic type's methods cannot return the type instantiated with []T;
+// wrap the result in NewStream to keep chaining.
+func (s Stream[T]) CountWindow(size int) iter.Seq[[]T] {
+	return CountWindow[T](size)(s.Seq())
+}
+
+// SlidingCountWindow groups elements into overlapping windows (see SlidingCountWindow)
+func (s Stream[T]) SlidingCountWindow(windowSize, stepSize int) iter.Seq[[]T] {
+	return SlidingCountWindow[T](windowSize, stepSize)(s.Seq())
+}
+
+// TimeWindow groups elements by timeField into windows of duration (see TimeWindow)
+func (s Stream[T]) TimeWindow(duration time.Duration, timeField string) iter.Seq[[]T] {
+	return TimeWindow[T](duration, timeField)(s.Seq())
+}
+
+// SlidingTimeWindow groups elements by timeField into overlapping windows (see SlidingTimeWindow)
+func (s Stream[T]) SlidingTimeWindow(windowDuration, slideDuration time.Duration, timeField string) iter.Seq[[]T] {
+	return SlidingTimeWindow[T](windowDuration, slideDuration, timeField)(s.Seq())
+}
+
+// Collect consumes the stream and returns its elements
+func (s Stream[T]) Collect() []T {
+	return slices.Collect(s.Seq())
+}
+
+// Count consumes the stream and returns the number of elements
+func (s Stream[T]) Count() int {
+	n := 0
+	for range s {
+		n++
+	}
+	return n
+}
+
+// First returns the first element, reading nothing further.
+// ok is false if the stream is empty.
+func (s Stream[T]) First() (first T, ok bool) {
+	for v := range s {
+		return v, true
+	}
+	return first, false
+}
+
+// ForEach consumes the stream, calling fn for each element
+func (s Stream[T]) ForEach(fn func(T)) {
+	for v := range s {
+		fn(v)
+	}
+}
+
+// ============================================================================
+// RECORD STREAMS
+// ============================================================================
+
+// Records is a Stream of Records with the record-specific operations:
+// grouping, aggregation, joins and the writers.
+//
+// Example:
+//
+//	err := ssql.NewRecords(sales).
+//	    Where(func(r ssql.Record) bool { return ssql.GetOr(r, "amount", 0.0) > 100 }).
+//	    GroupByFields("sales", "region").
+//	    Aggregate("sales", map[string]ssql.AggregateFunc{"total": ssql.Sum("amount")}).
+//	    WriteCSV("totals.csv")
+//
+// The same-type Stream methods are redefined to return Records so the
+// chain keeps its record operations.
+type Records struct {
+	Stream[Record]
+}
+
+// NewRecords wraps seq in a Records stream
+func NewRecords(seq iter.Seq[Record]) Records {
+	return Records{Stream[Record](seq)}
+}
+
+// Apply applies any Filter[Record, Record], such as Update or DotFlatten
+func (r Records) Apply(f Filter[Record, Record]) Records {
+	return Records{r.Stream.Apply(f)}
+}
+
+// Select transforms each record (see Select)
+func (r Records) Select(fn func(Record) Record) Records {
+	return Records{r.Stream.Select(fn)}
+}
+
+// Update modifies each record through a MutableRecord (see Update)
+func (r Records) Update(fn func(MutableRecord) MutableRecord) Records {
+	return r.Apply(Update(fn))
+}
+
+// Where keeps the records matching predicate (see Where)
+func (r Records) Where(predicate func(Record) bool) Records {
+	return Records{r.Stream.Where(predicate)}
+}
+
+// Limit keeps the first n records (see Limit)
+func (r Records) Limit(n int) Records {
+	return Records{r.Stream.Limit(n)}
+}
+
+// Offset skips the first n records (see Offset)
+func (r Records) Offset(n int) Records {
+	return Records{r.Stream.Offset(n)}
+}
+
+// SortBy sorts the records with a comparison function (see Stream.SortBy)
+func (r Records) SortBy(cmp func(a, b Record) int) Records {
+	return Records{r.Stream.SortBy(cmp)}
+}
+
+// DistinctBy keeps the first record for each key (see DistinctBy)
+func (r Records) DistinctBy(keyFn func(Record) any) Records {
+	return Records{r.Stream.DistinctBy(keyFn)}
+}
+
+// Reverse reverses the order of the records (see Reverse)
+func (r Records) Reverse() Records {
+	return Records{r.Stream.Reverse()}
+}
+
+// Tee splits the stream into n buffered copies (see Tee)
+func (r Records) Tee(n int) []Records {
+	return toRecords(r.Stream.Tee(n))
+}
+
+// LazyTee splits the stream into n unbuffered copies that must be
+// consumed concurrently (see LazyTee)
+func (r Records) LazyTee(n int, config ...LazyTeeConfig) []Records {
+	return toRecords(r.Stream.LazyTee(n, config...))
+}
+
+// SpillingTee splits the stream into n copies that can be read at their
+// own pace, spilling to disk beyond memLimit bytes (see SpillingTee)
+func (r Records) SpillingTee(n int, memLimit int64, config ...SpillingTeeConfig) []Records {
+	return toRecords(toStreams(SpillingTee(r.Seq(), n, memLimit, config...)))
+}
+
+// toRecords wraps each Stream[Record] in Records
+func toRecords(streams []Stream[Record]) []Records {
+	records := make([]Records, len(streams))
+	for i, s := range streams {
+		records[i] = Records{s}
+	}
+	return records
+}
+
+// GroupByFields groups records by the given fields, collecting each group
+// into sequenceField (see GroupByFields)
+func (r Records) GroupByFields(sequenceField string, fields ...string) Records {
+	return r.Apply(GroupByFields(sequenceField, fields...))
+}
+
+// Aggregate computes aggregations over the groups in sequenceField (see Aggregate)
+func (r Records) Aggregate(sequenceField string, aggregations map[string]AggregateFunc) Records {
+	return r.Apply(Aggregate(sequenceField, aggregations))
+}
+
+// InnerJoin joins with right, keeping matching pairs (see InnerJoin)
+func (r Records) InnerJoin(right iter.Seq[Record], predicate JoinPredicate) Records {
+	return r.Apply(InnerJoin(right, predicate))
+}
+
+// LeftJoin joins with right, keeping every record of this stream (see LeftJoin)
+func (r Records) LeftJoin(right iter.Seq[Record], predicate JoinPredicate) Records {
+	return r.Apply(LeftJoin(right, predicate))
+}
+
+// RightJoin joins with right, keeping every record of right (see RightJoin)
+func (r Records) RightJoin(right iter.Seq[Record], predicate JoinPredicate) Records {
+	return r.Apply(RightJoin(right, predicate))
+}
+
+// FullJoin joins with right, keeping every record of both (see FullJoin)
+func (r Records) FullJoin(right iter.Seq[Record], predicate JoinPredicate) Records {
+	return r.Apply(FullJoin(right, predicate))
+}
+
+// WriteCSV consumes the stream, writing it to a CSV file (see WriteCSV)
+func (r Records) WriteCSV(filename string, config ...CSVConfig) error {
+	return WriteCSV(r.Seq(), filename, config...)
+}
+
+// WriteCSVTo consumes the stream, writing CSV to writer (see WriteCSVToWriter)
+func (r Records) WriteCSVTo(writer io.Writer, config ...CSVConfig) error {
+	return WriteCSVToWriter(r.Seq(), writer, config...)
+}
+
+// WriteJSON consumes the stream, writing it to a JSONL file (see WriteJSON)
+func (r Records) WriteJSON(filename string) error {
+	return WriteJSON(r.Seq(), filename)
+}
+
+// WriteJSONTo consumes the stream, writing JSONL to writer (see WriteJSONToWriter)
+func (r Records) WriteJSONTo(writer io.Writer) error {
+	return WriteJSONToWriter(r.Seq(), writer)
+}
+
+// DisplayTable consumes the stream, printing it as a table (see DisplayTable)
+func (r Records) DisplayTable(maxWidth int) {
+	DisplayTable(r.Seq(), maxWidth)
+}
@@ -0,0 +1,119 @@
+package ssql
+
+import (
+	"bytes"
+	"cmp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// ============================================================================
+// FLUENT STREAM TESTS
+// ============================================================================
+
+func TestStreamChaining(t *testing.T) {
+	result := StreamOf(5, 3, 8, 1, 9, 2, 7).
+		Where(func(n int) bool { return n > 1 }).
+		SortBy(cmp.Compare[int]).
+		Offset(1).
+		Limit(3).
+		Collect()
+	if !slices.Equal(result, []int{3, 5, 7}) {
+		t.Errorf("Expected [3 5 7], got %v", result)
+	}
+
+	// Existing filters plug in through Apply and Then
+	labels := Then(StreamOf(1, 2, 3).Apply(Chain(
+		Select(func(n int) int { return n * 10 }),
+		Where(func(n int) bool { return n != 20 }),
+	)), Select(strconv.Itoa)).Collect()
+	if !slices.Equal(labels, []string{"10", "30"}) {
+		t.Errorf("Expected [10 30], got %v", labels)
+	}
+
+	// A Stream ranges like the iterator it wraps
+	sum := 0
+	for n := range StreamOf(1, 2, 3) {
+		sum += n
+	}
+	if sum != 6 {
+		t.Errorf("Expected sum 6, got %d", sum)
+	}
+}
+
+func TestStreamTerminals(t *testing.T) {
+	s := StreamOf("a", "b", "a", "c")
+	if n := s.DistinctBy(func(v string) any { return v }).Count(); n != 3 {
+		t.Errorf("Expected 3 distinct values, got %d", n)
+	}
+	if first, ok := s.Reverse().First(); !ok || first != "c" {
+		t.Errorf("Expected first of reversed stream to be c, got %q, %v", first, ok)
+	}
+	if _, ok := StreamOf[int]().First(); ok {
+		t.Error("First of empty stream should report ok=false")
+	}
+
+	var joined strings.Builder
+	s.ForEach(func(v string) { joined.WriteString(v) })
+	if joined.String() != "abac" {
+		t.Errorf("Expected abac, got %q", joined.String())
+	}
+
+	windows := NewStream(StreamOf(1, 2, 3, 4, 5).CountWindow(2)).Collect()
+	if len(windows) != 3 || !slices.Equal(windows[2], []int{5}) {
+		t.Errorf("Unexpected windows %v", windows)
+	}
+
+	copies := StreamOf(1, 2).Tee(2)
+	if len(copies) != 2 || copies[0].Count() != 2 || copies[1].Count() != 2 {
+		t.Errorf("Tee should give two full copies")
+	}
+}
+
+func TestRecordsChaining(t *testing.T) {
+	sales := []Record{
+		MakeMutableRecord().String("region", "north").Int("amount", 100).Freeze(),
+		MakeMutableRecord().String("region", "south").Int("amount", 50).Freeze(),
+		MakeMutableRecord().String("region", "north").Int("amount", 25).Freeze(),
+		MakeMutableRecord().String("region", "east").Int("amount", 10).Freeze(),
+	}
+	regions := []Record{
+		MakeMutableRecord().String("region", "north").String("manager", "Ana").Freeze(),
+		MakeMutableRecord().String("region", "south").String("manager", "Bo").Freeze(),
+	}
+
+	var out bytes.Buffer
+	err := NewRecords(slices.Values(sales)).
+		Where(func(r Record) bool { return GetOr(r, "amount", int64(0)) >= 25 }).
+		GroupByFields("sales", "region").
+		Aggregate("sales", map[string]AggregateFunc{"total": Sum("amount")}).
+		InnerJoin(slices.Values(regions), OnFields("region")).
+		SortBy(func(a, b Record) int {
+			return cmp.Compare(GetOr(a, "region", ""), GetOr(b, "region", ""))
+		}).
+		WriteCSVTo(&out)
+	if err != nil {
+		t.Fatalf("WriteCSVTo: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected header and 2 rows, got %q", out.String())
+	}
+	if !strings.Contains(lines[1], "north") || !strings.Contains(lines[1], "125") || !strings.Contains(lines[1], "Ana") {
+		t.Errorf("Unexpected north row %q", lines[1])
+	}
+	if !strings.Contains(lines[2], "south") || !strings.Contains(lines[2], "Bo") {
+		t.Errorf("Unexpected south row %q", lines[2])
+	}
+
+	updated, ok := NewRecords(slices.Values(sales)).
+		Update(func(m MutableRecord) MutableRecord { return m.Bool("seen", true) }).
+		Offset(3).
+		First()
+	if !ok || GetOr(updated, "region", "") != "east" || !GetOr(updated, "seen", false) {
+		t.Errorf("Unexpected record %v", updated)
+	}
+}
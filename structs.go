@@ -0,0 +1,573 @@
+package ssql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"maps"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ============================================================================
+// TYPED STRUCT MAPPING - GO STRUCTS <-> RECORD STREAMS
+// ============================================================================
+
+// structField describes how one Go struct field maps to a Record field
+type structField struct {
+	name  string // Record field name
+	index []int  // Field index path (supports embedded structs)
+	typ   reflect.Type
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	recordType     = reflect.TypeOf(Record{})
+	jsonStringType = reflect.TypeOf(JSONString(""))
+)
+
+// structFields returns the Record mapping for a struct type.
+// Fields are named by their `ssql:"name"` tag, or the Go field name when untagged.
+// A tag of "-" skips the field. Untagged embedded structs are flattened.
+func structFields(t reflect.Type) []structField {
+	var fields []structField
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("ssql")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		// Flatten untagged embedded structs like encoding/json does
+		if sf.Anonymous && name == "" && sf.Type.Kind() == reflect.Struct && sf.Type != timeType {
+			for _, inner := range structFields(sf.Type) {
+				inner.index = append([]int{i}, inner.index...)
+				fields = append(fields, inner)
+			}
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		fields = append(fields, structField{name: name, index: []int{i}, typ: sf.Type})
+	}
+	return fields
+}
+
+// ============================================================================
+// STRUCT -> RECORD
+// ============================================================================
+
+// FromStructs converts a stream of Go structs into a stream of Records.
+// Field names come from `ssql:"name"` struct tags (or the Go field name).
+//
+// Type mapping:
+//   - signed and unsigned integers → int64, floats → float64; unsigned
+//     values above math.MaxInt64 → string holding the exact digits, which
+//     ToStructs reads back into unsigned fields
+//   - bool, string, time.Time, JSONString and Record are stored as-is
+//   - nested structs → nested Record
+//   - slices → the matching iter.Seq (slices of structs → iter.Seq[Record])
+//   - nil pointers are omitted; non-nil pointers store the pointed-to value
+//   - anything else (maps, arrays of unsupported types) → JSONString
+//
+// T may also be a pointer to a struct. Nil pointers in the stream are skipped.
+//
+// Example:
+//
+//	type Order struct {
+//	    ID       int64     `ssql:"id"`
+//	    Customer string    `ssql:"customer"`
+//	    Placed   time.Time `ssql:"placed_at"`
+//	    Note     *string   `ssql:"note"`  // Omitted when nil
+//	    Internal string    `ssql:"-"`     // Never exported
+//	}
+//
+//	records := ssql.FromStructs(slices.Values(orders))
+//	ssql.WriteCSV(records, "orders.csv")
+func FromStructs[T any](input iter.Seq[T]) iter.Seq[Record] {
+	return func(yield func(Record) bool) {
+		var zero T
+		t := reflect.TypeOf(zero)
+		if t == nil {
+			return
+		}
+		isPtr := t.Kind() == reflect.Pointer
+		if isPtr {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			panic(fmt.Sprintf("FromStructs: %v is not a struct type", t))
+		}
+		fields := structFields(t)
+
+		for item := range input {
+			v := reflect.ValueOf(item)
+			if isPtr {
+				if v.IsNil() {
+					continue
+				}
+				v = v.Elem()
+			}
+			if !yield(structToRecord(v, fields)) {
+				return
+			}
+		}
+	}
+}
+
+// structToRecord converts a struct value using a precomputed field mapping
+func structToRecord(v reflect.Value, fields []structField) Record {
+	result := MakeMutableRecordWithCapacity(len(fields))
+	for _, f := range fields {
+		fv, ok := fieldByIndex(v, f.index)
+		if !ok {
+			continue
+		}
+		if value, ok := goToRecordValue(fv); ok {
+			result.set(f.name, value)
+		}
+	}
+	return result.Freeze()
+}
+
+// fieldByIndex is like reflect.Value.FieldByIndex but reports nil embedded pointers
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// goToRecordValue converts a Go value to a canonical Record value.
+// Returns false for nil pointers and interfaces (the field is omitted).
+func goToRecordValue(v reflect.Value) (any, bool) {
+	switch v.Type() {
+	case timeType:
+		return v.Interface().(time.Time), true
+	case recordType:
+		return v.Interface().(Record), true
+	case jsonStringType:
+		return v.Interface().(JSONString), true
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil, false
+		}
+		return goToRecordValue(v.Elem())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return uintToRecordValue(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	case reflect.Bool:
+		return v.Bool(), true
+	case reflect.String:
+		return v.String(), true
+	case reflect.Struct:
+		return structToRecord(v, structFields(v.Type())), true
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil, false
+		}
+		return sliceToSeq(v), true
+	}
+
+	// Fallback: keep the data as JSON
+	js, err := NewJSONString(v.Interface())
+	if err != nil {
+		return nil, false
+	}
+	return js, true
+}
+
+// sliceToSeq converts a Go slice to the iter.Seq variant accepted by Value.
+// The slice is copied so later mutation of the source does not leak into the Record.
+func sliceToSeq(v reflect.Value) any {
+	elem := v.Type().Elem()
+	switch {
+	case elem == timeType:
+		return seqOfSlice(reflectSliceCopy[time.Time](v, func(e reflect.Value) time.Time { return e.Interface().(time.Time) }))
+	case elem == recordType:
+		return seqOfSlice(reflectSliceCopy[Record](v, func(e reflect.Value) Record { return e.Interface().(Record) }))
+	}
+
+	switch elem.Kind() {
+	case reflect.Int:
+		return seqOfSlice(reflectSliceCopy[int](v, func(e reflect.Value) int { return int(e.Int()) }))
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return seqOfSlice(reflectSliceCopy[int64](v, func(e reflect.Value) int64 { return e.Int() }))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		for i := range v.Len() {
+			if v.Index(i).Uint() > math.MaxInt64 {
+				// Too large for iter.Seq[int64]: keep the exact digits as JSON
+				js, _ := NewJSONString(v.Interface())
+				return js
+			}
+		}
+		return seqOfSlice(reflectSliceCopy[int64](v, func(e reflect.Value) int64 { return int64(e.Uint()) }))
+	case reflect.Float32, reflect.Float64:
+		return seqOfSlice(reflectSliceCopy[float64](v, func(e reflect.Value) float64 { return e.Float() }))
+	case reflect.Bool:
+		return seqOfSlice(reflectSliceCopy[bool](v, func(e reflect.Value) bool { return e.Bool() }))
+	case reflect.String:
+		return seqOfSlice(reflectSliceCopy[string](v, func(e reflect.Value) string { return e.String() }))
+	case reflect.Struct, reflect.Pointer:
+		var records []Record
+		for i := range v.Len() {
+			if r, ok := goToRecordValue(v.Index(i)); ok {
+				if rec, isRecord := r.(Record); isRecord {
+					records = append(records, rec)
+				}
+			}
+		}
+		return seqOfSlice(records)
+	}
+
+	js, _ := NewJSONString(v.Interface())
+	return js
+}
+
+// uintToRecordValue stores an unsigned integer as int64, or as its exact
+// digits if it is too large for an int64 (a Decimal would round it)
+func uintToRecordValue(u uint64) any {
+	if u <= math.MaxInt64 {
+		return int64(u)
+	}
+	return strconv.FormatUint(u, 10)
+}
+
+// reflectSliceCopy copies a reflected slice into a typed Go slice
+func reflectSliceCopy[E any](v reflect.Value, conv func(reflect.Value) E) []E {
+	out := make([]E, v.Len())
+	for i := range out {
+		out[i] = conv(v.Index(i))
+	}
+	return out
+}
+
+// seqOfSlice returns an iter.Seq over a slice
+func seqOfSlice[E any](s []E) iter.Seq[E] {
+	return func(yield func(E) bool) {
+		for _, e := range s {
+			if !yield(e) {
+				return
+			}
+		}
+	}
+}
+
+// ============================================================================
+// RECORD -> STRUCT
+// ============================================================================
+
+// ToStructs converts a stream of Records into Go structs of type T.
+// Uses the same `ssql:"name"` tags as FromStructs. Missing fields, and empty
+// strings for non-string fields, leave the struct field at its zero value
+// (nil for pointers). Values are converted with the same rules as Get, plus:
+//   - nested Records → nested structs
+//   - iter.Seq fields and JSONString arrays → slices
+//   - integer overflow and lossy float → int conversions are errors
+//
+// A record that cannot be converted yields the partially filled struct and an error.
+//
+// Example:
+//
+//	data, _ := ssql.ReadCSV("orders.csv")
+//	for order, err := range ssql.ToStructs[Order](data) {
+//	    if err != nil {
+//	        log.Printf("skipping row: %v", err)
+//	        continue
+//	    }
+//	    process(order)
+//	}
+func ToStructs[T any](input iter.Seq[Record]) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		t := reflect.TypeOf(zero)
+		if t == nil {
+			return
+		}
+		isPtr := t.Kind() == reflect.Pointer
+		if isPtr {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			panic(fmt.Sprintf("ToStructs: %v is not a struct type", t))
+		}
+		fields := structFields(t)
+
+		for record := range input {
+			target := reflect.New(t)
+			err := recordToStruct(record, target.Elem(), fields)
+
+			var item T
+			if isPtr {
+				item = target.Interface().(T)
+			} else {
+				item = target.Elem().Interface().(T)
+			}
+			if !yield(item, err) {
+				return
+			}
+		}
+	}
+}
+
+// recordToStruct fills a struct value from a record
+func recordToStruct(r Record, v reflect.Value, fields []structField) error {
+	var problems []string
+	for _, f := range fields {
+		val, exists := r.fields[f.name]
+		if !exists || IsNull(val) {
+			continue // a null (e.g. from JSON) leaves the field at its zero value, like a missing one
+		}
+		// Empty text (typically a blank CSV cell) means "no value" for non-string fields
+		if str, isStr := val.(string); isStr && str == "" && !isStringField(f.typ) {
+			continue
+		}
+		fv := allocFieldByIndex(v, f.index)
+		if err := assignValue(fv, val); err != nil {
+			problems = append(problems, fmt.Sprintf("field '%s': %v", f.name, err))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("cannot convert record: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// isStringField reports whether a field type (or pointer to it) holds text
+func isStringField(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.String && t != jsonStringType
+}
+
+// allocFieldByIndex walks an index path, allocating nil embedded pointers
+func allocFieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+// assignValue stores a Record value into a settable Go value with conversion
+func assignValue(dst reflect.Value, val any) error {
+	// Exact or assignable type match (Record, JSONString, time.Time, etc.)
+	src := reflect.ValueOf(val)
+	if src.Type().AssignableTo(dst.Type()) {
+		dst.Set(src)
+		return nil
+	}
+
+	switch dst.Type() {
+	case timeType:
+		if t, ok := convertToTime(val); ok {
+			dst.Set(reflect.ValueOf(t))
+			return nil
+		}
+		return fmt.Errorf("cannot convert %T to time.Time", val)
+	case jsonStringType:
+		js, err := NewJSONString(val)
+		if err != nil {
+			return err
+		}
+		dst.Set(reflect.ValueOf(js))
+		return nil
+	}
+
+	switch dst.Kind() {
+	case reflect.Pointer:
+		elem := reflect.New(dst.Type().Elem())
+		if err := assignValue(elem.Elem(), val); err != nil {
+			return err
+		}
+		dst.Set(elem)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, ok := convertToInt64(val)
+		if !ok {
+			return fmt.Errorf("cannot convert %T to %v", val, dst.Type())
+		}
+		if f, isFloat := val.(float64); isFloat && f != math.Trunc(f) {
+			return fmt.Errorf("cannot convert %v to %v without losing precision", f, dst.Type())
+		}
+		if dst.OverflowInt(i) {
+			return fmt.Errorf("value %d overflows %v", i, dst.Type())
+		}
+		dst.SetInt(i)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if str, isStr := val.(string); isStr {
+			// May exceed math.MaxInt64 (see uintToRecordValue)
+			if u, err := strconv.ParseUint(strings.TrimSpace(str), 10, 64); err == nil {
+				if dst.OverflowUint(u) {
+					return fmt.Errorf("value %d overflows %v", u, dst.Type())
+				}
+				dst.SetUint(u)
+				return nil
+			}
+		}
+		if d, isDecimal := val.(Decimal); isDecimal && d.Sign() > 0 {
+			u, err := strconv.ParseUint(d.String(), 10, 64)
+			if err != nil || dst.OverflowUint(u) {
+				return fmt.Errorf("cannot convert %v to %v", d, dst.Type())
+			}
+			dst.SetUint(u)
+			return nil
+		}
+		i, ok := convertToInt64(val)
+		if !ok {
+			return fmt.Errorf("cannot convert %T to %v", val, dst.Type())
+		}
+		if i < 0 || dst.OverflowUint(uint64(i)) {
+			return fmt.Errorf("value %d overflows %v", i, dst.Type())
+		}
+		dst.SetUint(uint64(i))
+		return nil
+	case reflect.Float32, reflect.Float64:
+		f, ok := convertToFloat64(val)
+		if !ok {
+			return fmt.Errorf("cannot convert %T to %v", val, dst.Type())
+		}
+		dst.SetFloat(f)
+		return nil
+	case reflect.Bool:
+		b, ok := convertToBool(val)
+		if !ok {
+			return fmt.Errorf("cannot convert %T to bool", val)
+		}
+		dst.SetBool(b)
+		return nil
+	case reflect.String:
+		s, _ := convertToString(val)
+		dst.SetString(s)
+		return nil
+	case reflect.Struct:
+		nested, ok := val.(Record)
+		if !ok {
+			return fmt.Errorf("cannot convert %T to %v", val, dst.Type())
+		}
+		return recordToStruct(nested, dst, structFields(dst.Type()))
+	case reflect.Slice:
+		return assignSlice(dst, val)
+	}
+
+	return fmt.Errorf("unsupported field type %v", dst.Type())
+}
+
+// assignSlice fills a slice from an iter.Seq field or a JSONString array
+func assignSlice(dst reflect.Value, val any) error {
+	var items []any
+	switch {
+	case isIterSeq(val):
+		items = materializeSequence(val)
+	default:
+		js, ok := val.(JSONString)
+		if !ok {
+			return fmt.Errorf("cannot convert %T to %v", val, dst.Type())
+		}
+		// Numbers are decoded from their text, as float64 would round
+		// integers beyond 2^53 (such as the digits of large unsigned values)
+		dec := json.NewDecoder(bytes.NewReader([]byte(js)))
+		dec.UseNumber()
+		var parsed any
+		if err := dec.Decode(&parsed); err != nil {
+			return err
+		}
+		arr, ok := parsed.([]any)
+		if !ok {
+			return fmt.Errorf("JSON value is not an array")
+		}
+		for _, e := range arr {
+			items = append(items, normalizeJSONValue(e))
+		}
+	}
+
+	out := reflect.MakeSlice(dst.Type(), len(items), len(items))
+	for i, item := range items {
+		if IsNull(item) {
+			continue
+		}
+		if err := assignValue(out.Index(i), canonicalScalar(item)); err != nil {
+			return fmt.Errorf("element %d: %w", i, err)
+		}
+	}
+	dst.Set(out)
+	return nil
+}
+
+// canonicalScalar widens non-canonical numeric sequence elements (int, int32,
+// float32, ...) to int64/float64 (the exact digits for unsigned values
+// above math.MaxInt64) so the standard conversions apply.
+func canonicalScalar(v any) any {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		return rv.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return uintToRecordValue(rv.Uint())
+	case reflect.Float32:
+		return rv.Float()
+	}
+	return v
+}
+
+// normalizeJSONValue converts generic decoded JSON into Record values
+func normalizeJSONValue(v any) any {
+	switch val := v.(type) {
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			return i
+		}
+		if _, err := strconv.ParseUint(string(val), 10, 64); err == nil {
+			return string(val) // exact digits, as uintToRecordValue stores them
+		}
+		f, _ := val.Float64()
+		return normalizeJSONValue(f)
+	case float64:
+		if val == math.Trunc(val) && math.Abs(val) < 1<<53 {
+			return int64(val)
+		}
+		return val
+	case map[string]any:
+		result := MakeMutableRecordWithCapacity(len(val))
+		for _, k := range slices.Sorted(maps.Keys(val)) { // JSON objects carry no key order here
+			if sub := val[k]; sub != nil {
+				result.set(k, normalizeJSONValue(sub))
+			}
+		}
+		return result.Freeze()
+	case []any:
+		js, _ := NewJSONString(val)
+		return js
+	default:
+		return v
+	}
+}
@@ -0,0 +1,249 @@
+package ssql
+
+import (
+	"iter"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testAddress struct {
+	City string `ssql:"city"`
+	Zip  string `ssql:"zip"`
+}
+
+type testAudit struct {
+	CreatedBy string `ssql:"created_by"`
+}
+
+type testCustomer struct {
+	testAudit
+	ID      int64         `ssql:"id"`
+	Name    string        `ssql:"name"`
+	Age     int           `ssql:"age"`
+	Score   float32       `ssql:"score"`
+	Active  bool          `ssql:"active"`
+	Joined  time.Time     `ssql:"joined"`
+	Address testAddress   `ssql:"address"`
+	Tags    []string      `ssql:"tags"`
+	Orders  []testAddress `ssql:"orders"`
+	Nick    *string       `ssql:"nick"`
+	Secret  string        `ssql:"-"`
+	Plain   string
+	hidden  string
+}
+
+func TestFromStructs(t *testing.T) {
+	joined := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	nick := "al"
+	customers := []testCustomer{
+		{
+			testAudit: testAudit{CreatedBy: "import"},
+			ID:        1, Name: "Alice", Age: 30, Score: 1.5, Active: true, Joined: joined,
+			Address: testAddress{City: "Paris", Zip: "75001"},
+			Tags:    []string{"vip", "new"},
+			Orders:  []testAddress{{City: "Lyon"}},
+			Nick:    &nick, Secret: "s", Plain: "p", hidden: "h",
+		},
+		{ID: 2, Name: "Bob"},
+	}
+
+	records := slices.Collect(FromStructs(slices.Values(customers)))
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(records))
+	}
+	r := records[0]
+
+	if v, ok := r.fields["age"].(int64); !ok || v != 30 {
+		t.Errorf("Expected age int64 30, got %v (%T)", r.fields["age"], r.fields["age"])
+	}
+	if v, ok := r.fields["score"].(float64); !ok || v != 1.5 {
+		t.Errorf("Expected score float64 1.5, got %v (%T)", r.fields["score"], r.fields["score"])
+	}
+	if GetOr(r, "joined", time.Time{}) != joined {
+		t.Errorf("Expected joined time to round-trip")
+	}
+	if GetOr(r, "created_by", "") != "import" {
+		t.Errorf("Expected embedded struct field to be flattened")
+	}
+	address, ok := Get[Record](r, "address")
+	if !ok || GetOr(address, "city", "") != "Paris" {
+		t.Errorf("Expected nested address Record, got %v", r.fields["address"])
+	}
+	tags, ok := Get[iter.Seq[string]](r, "tags")
+	if !ok || !slices.Equal(slices.Collect(tags), []string{"vip", "new"}) {
+		t.Errorf("Expected tags sequence, got %v", r.fields["tags"])
+	}
+	if GetOr(r, "nick", "") != "al" {
+		t.Errorf("Expected pointer field to be dereferenced")
+	}
+	if r.Has("Secret") || r.Has("secret") || r.Has("hidden") {
+		t.Errorf("Skipped and unexported fields must not be exported: %v", r.Keys())
+	}
+	if GetOr(r, "Plain", "") != "p" {
+		t.Errorf("Untagged fields should use the Go field name")
+	}
+
+	if records[1].Has("nick") {
+		t.Errorf("Nil pointer fields should be omitted")
+	}
+	if err := ValidateRecord(r); err != nil {
+		t.Errorf("FromStructs produced invalid record: %v", err)
+	}
+}
+
+func TestToStructsRoundTrip(t *testing.T) {
+	nick := "bo"
+	original := []testCustomer{
+		{
+			testAudit: testAudit{CreatedBy: "api"},
+			ID:        7, Name: "Bob", Age: 41, Score: 2.25, Active: true,
+			Joined:  time.Date(2023, 5, 6, 0, 0, 0, 0, time.UTC),
+			Address: testAddress{City: "Oslo", Zip: "0150"},
+			Tags:    []string{"a", "b"},
+			Orders:  []testAddress{{City: "Bergen", Zip: "5003"}, {City: "Tromsø"}},
+			Nick:    &nick,
+			Plain:   "x",
+		},
+	}
+
+	var got []testCustomer
+	for c, err := range ToStructs[testCustomer](FromStructs(slices.Values(original))) {
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		got = append(got, c)
+	}
+
+	if len(got) != 1 {
+		t.Fatalf("Expected 1 struct, got %d", len(got))
+	}
+	c := got[0]
+	want := original[0]
+	if c.ID != want.ID || c.Name != want.Name || c.Age != want.Age || c.Score != want.Score ||
+		c.Active != want.Active || !c.Joined.Equal(want.Joined) || c.Address != want.Address ||
+		c.CreatedBy != want.CreatedBy || c.Plain != want.Plain {
+		t.Errorf("Scalar round trip mismatch:\n got  %+v\n want %+v", c, want)
+	}
+	if !slices.Equal(c.Tags, want.Tags) || !slices.Equal(c.Orders, want.Orders) {
+		t.Errorf("Slice round trip mismatch: %v %v", c.Tags, c.Orders)
+	}
+	if c.Nick == nil || *c.Nick != "bo" {
+		t.Errorf("Pointer round trip mismatch: %v", c.Nick)
+	}
+}
+
+func TestToStructsConversions(t *testing.T) {
+	type row struct {
+		Count  int8      `ssql:"count"`
+		Price  float64   `ssql:"price"`
+		When   time.Time `ssql:"when"`
+		Items  []int     `ssql:"items"`
+		Maybe  *int64    `ssql:"maybe"`
+		Ignore string    `ssql:"ignore"`
+	}
+
+	csv := "count,price,when,items\n5,3,2024-03-01T10:00:00Z,\n"
+	records := ReadCSVFromReader(strings.NewReader(csv))
+	jsonItems := MakeMutableRecord().
+		Int("count", 1).
+		JSONString("items", JSONString("[1, 2, 3]")).
+		Freeze()
+	nulls := MakeMutableRecord().Null("count").Null("maybe").Null("ignore").Null("items").Freeze()
+
+	input := func(yield func(Record) bool) {
+		for r := range records {
+			if !yield(r) {
+				return
+			}
+		}
+		_ = yield(jsonItems) && yield(nulls)
+	}
+
+	var rows []row
+	for r, err := range ToStructs[row](input) {
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		rows = append(rows, r)
+	}
+
+	if rows[0].Count != 5 || rows[0].Price != 3 || rows[0].When.Year() != 2024 || rows[0].Maybe != nil {
+		t.Errorf("Unexpected CSV conversion: %+v", rows[0])
+	}
+	if !slices.Equal(rows[1].Items, []int{1, 2, 3}) {
+		t.Errorf("Expected JSONString array to fill slice, got %v", rows[1].Items)
+	}
+	if rows[2].Count != 0 || rows[2].Maybe != nil || rows[2].Ignore != "" || rows[2].Items != nil {
+		t.Errorf("Expected null fields to stay zero, got %+v", rows[2])
+	}
+}
+
+func TestToStructsErrors(t *testing.T) {
+	type small struct {
+		N int8 `ssql:"n"`
+	}
+	input := slices.Values([]Record{
+		MakeMutableRecord().Int("n", 1000).Freeze(),
+		MakeMutableRecord().Float("n", 1.5).Freeze(),
+		MakeMutableRecord().Int("n", 3).Freeze(),
+	})
+
+	var errs int
+	var values []int8
+	for s, err := range ToStructs[small](input) {
+		if err != nil {
+			errs++
+			continue
+		}
+		values = append(values, s.N)
+	}
+	if errs != 2 || !slices.Equal(values, []int8{3}) {
+		t.Errorf("Expected overflow and precision errors, got %d errors and %v", errs, values)
+	}
+}
+
+func TestStructsLargeUnsigned(t *testing.T) {
+	type counter struct {
+		Total uint64   `ssql:"total"`
+		Small uint64   `ssql:"small"`
+		Hist  []uint64 `ssql:"hist"`
+	}
+	input := []counter{{Total: math.MaxUint64, Small: 42, Hist: []uint64{1, math.MaxUint64}}}
+
+	records := slices.Collect(FromStructs(slices.Values(input)))
+	if total := records[0].fields["total"]; total != "18446744073709551615" {
+		t.Fatalf("Expected the exact digits of MaxUint64, got %T %v", total, total)
+	}
+	if small := records[0].fields["small"]; small != int64(42) {
+		t.Errorf("Expected int64 42, got %T %v", small, small)
+	}
+	if hist, ok := records[0].fields["hist"].(JSONString); !ok || !strings.Contains(string(hist), "18446744073709551615") {
+		t.Errorf("Expected the exact digits in a JSONString, got %T %v", records[0].fields["hist"], records[0].fields["hist"])
+	}
+
+	// Back into a uint64 field
+	for back, err := range ToStructs[counter](slices.Values(records)) {
+		if err != nil || back.Total != math.MaxUint64 || back.Small != 42 || !slices.Equal(back.Hist, input[0].Hist) {
+			t.Errorf("Expected an exact round trip of %+v, got %+v (%v)", input[0], back, err)
+		}
+	}
+	for _, err := range ToStructs[struct {
+		Total uint32 `ssql:"total"`
+	}](slices.Values(records)) {
+		if err == nil {
+			t.Error("Expected an overflow error for uint32")
+		}
+	}
+}
+
+func TestToStructsPointerType(t *testing.T) {
+	input := slices.Values([]Record{MakeMutableRecord().String("city", "Rome").Freeze()})
+	for a, err := range ToStructs[*testAddress](input) {
+		if err != nil || a == nil || a.City != "Rome" {
+			t.Errorf("Expected *testAddress with city Rome, got %v, %v", a, err)
+		}
+	}
+}
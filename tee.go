@@ -0,0 +1,555 @@
+package ssql
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// ============================================================================
+// LAZY TEE WITH BACKPRESSURE
+// ============================================================================
+
+// DefaultTeeBuffer is the per-branch buffer LazyTee uses when none is set
+const DefaultTeeBuffer = 100
+
+// ErrTeeOverflow is the error a LazyTeeSafe branch ends with when a branch
+// buffer fills under BackpressureFail
+var ErrTeeOverflow = errors.New("tee branch buffer full")
+
+// Backpressure decides what LazyTee does when a branch's buffer is full
+// because its consumer is slower than the others
+type Backpressure int
+
+const (
+	// BackpressureBlock makes the producer wait for the slow branch, so
+	// every branch sees every element and the tee runs at the speed of its
+	// slowest consumer (the default)
+	BackpressureBlock Backpressure = iota
+	// BackpressureDropNewest discards the element that does not fit,
+	// counting it in TeeStats
+	BackpressureDropNewest
+	// BackpressureDropOldest discards the oldest buffered element to make
+	// room, counting it in TeeStats, so a slow branch sees the latest data
+	BackpressureDropOldest
+	// BackpressureFail stops the tee: every branch ends, after its buffered
+	// elements, with an error wrapping ErrTeeOverflow (a panic in LazyTee)
+	BackpressureFail
+)
+
+// LazyTeeConfig configures LazyTee
+type LazyTeeConfig struct {
+	// Backpressure is the strategy for full branch buffers
+	Backpressure Backpressure
+	// BufferSize is the number of elements buffered for each branch. Zero
+	// or less uses DefaultTeeBuffer.
+	BufferSize int
+	// BufferSizes, if set, gives branch i a buffer of BufferSizes[i];
+	// missing or non-positive entries use BufferSize
+	BufferSizes []int
+	// Stats, if set, counts the elements dropped for each branch
+	Stats *TeeStats
+}
+
+// TeeStats counts the elements LazyTee dropped for each branch. It is
+// reset when passed to LazyTee and safe to read while the tee runs.
+type TeeStats struct {
+	dropped []atomic.Int64
+}
+
+// Dropped returns the number of elements dropped for branch
+func (s *TeeStats) Dropped(branch int) int64 {
+	if branch < 0 || branch >= len(s.dropped) {
+		return 0
+	}
+	return s.dropped[branch].Load()
+}
+
+// TotalDropped returns the number of elements dropped across all branches
+func (s *TeeStats) TotalDropped() int64 {
+	var total int64
+	for i := range s.dropped {
+		total += s.dropped[i].Load()
+	}
+	return total
+}
+
+// LazyTee splits a stream into n identical streams without buffering the
+// whole stream, so it works on infinite streams. A goroutine reads the
+// input and sends each element to every branch through a bounded buffer;
+// the branches must be consumed concurrently. What happens when a branch's
+// buffer is full is set by config (BackpressureBlock by default, so no
+// branch misses an element; with it, a branch that is never iterated
+// stalls the others once its buffer fills).
+//
+// A branch that stops early (its consumer breaks out of the loop) is
+// detached: the others keep receiving the complete stream, and the input
+// stops being read only when every branch has stopped or it is exhausted.
+//
+// Example:
+//
+//	// Dashboard may lag behind the archive; keep it on the latest data
+//	stats := &ssql.TeeStats{}
+//	branches := ssql.LazyTee(events, 2, ssql.LazyTeeConfig{
+//	    Backpressure: ssql.BackpressureDropOldest,
+//	    BufferSizes:  []int{10000, 100},
+//	    Stats:        stats,
+//	})
+//	go archive(branches[0])
+//	dashboard(branches[1])
+//	fmt.Println("dashboard skipped", stats.Dropped(1), "events")
+func LazyTee[T any](input iter.Seq[T], n int, config ...LazyTeeConfig) []iter.Seq[T] {
+	safe := LazyTeeSafe(Safe(input), n, config...)
+	streams := make([]iter.Seq[T], len(safe))
+	for i, branch := range safe {
+		streams[i] = Unsafe(branch)
+	}
+	return streams
+}
+
+// LazyTeeSafe splits an error-aware stream like LazyTee. Input errors are
+// passed to every branch in position, and under BackpressureFail each
+// branch ends with an error wrapping ErrTeeOverflow.
+func LazyTeeSafe[T any](input iter.Seq2[T, error], n int, config ...LazyTeeConfig) []iter.Seq2[T, error] {
+	if n <= 0 {
+		return nil
+	}
+	cfg := LazyTeeConfig{}
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = DefaultTeeBuffer
+	}
+	if cfg.Stats != nil {
+		cfg.Stats.dropped = make([]atomic.Int64, n)
+	}
+
+	t := &lazyTee[T]{cfg: cfg, branches: make([]teeBranch[T], n)}
+	for i := range t.branches {
+		size := cfg.BufferSize
+		if i < len(cfg.BufferSizes) && cfg.BufferSizes[i] > 0 {
+			size = cfg.BufferSizes[i]
+		}
+		t.branches[i] = teeBranch[T]{ch: make(chan valueErr[T], size), stopped: make(chan struct{})}
+	}
+	go t.broadcast(input)
+
+	streams := make([]iter.Seq2[T, error], n)
+	for i := range t.branches {
+		b := &t.branches[i]
+		streams[i] = func(yield func(T, error) bool) {
+			defer b.stop.Do(func() { close(b.stopped) })
+			for p := range b.ch {
+				if !yield(p.value, p.err) {
+					return
+				}
+			}
+			if t.failure != nil {
+				var zero T
+				yield(zero, t.failure)
+			}
+		}
+	}
+	return streams
+}
+
+// lazyTee holds the state shared by the broadcaster and the branches
+type lazyTee[T any] struct {
+	cfg      LazyTeeConfig
+	branches []teeBranch[T]
+	failure  error // set before the branch channels close
+}
+
+// teeBranch is one output of a lazyTee
+type teeBranch[T any] struct {
+	ch       chan valueErr[T]
+	stopped  chan struct{} // closed when the consumer stops iterating
+	stop     sync.Once
+	detached bool // only touched by the broadcaster
+}
+
+// broadcast sends every input element to the branches until the input is
+// exhausted, every branch has stopped, or a branch overflows under
+// BackpressureFail
+func (t *lazyTee[T]) broadcast(input iter.Seq2[T, error]) {
+	defer func() {
+		for i := range t.branches {
+			close(t.branches[i].ch)
+		}
+	}()
+
+	active := len(t.branches)
+	for v, err := range input {
+		p := valueErr[T]{v, err}
+		for i := range t.branches {
+			b := &t.branches[i]
+			if b.detached {
+				continue
+			}
+			select {
+			case <-b.stopped:
+				b.detached = true
+				active--
+				continue
+			default:
+			}
+			if !t.send(i, b, p) {
+				return
+			}
+			if b.detached {
+				active--
+			}
+		}
+		if active == 0 {
+			return
+		}
+	}
+}
+
+// send delivers p to branch i by the backpressure strategy. It returns
+// false if the tee must stop.
+func (t *lazyTee[T]) send(i int, b *teeBranch[T], p valueErr[T]) bool {
+	switch t.cfg.Backpressure {
+	case BackpressureDropNewest:
+		select {
+		case b.ch <- p:
+		default:
+			t.dropped(i)
+		}
+	case BackpressureDropOldest:
+		for {
+			select {
+			case b.ch <- p:
+				return true
+			default:
+			}
+			select {
+			case <-b.ch:
+				t.dropped(i)
+			default:
+			}
+		}
+	case BackpressureFail:
+		select {
+		case b.ch <- p:
+		default:
+			t.failure = fmt.Errorf("lazy tee branch %d: %w", i, ErrTeeOverflow)
+			return false
+		}
+	default:
+		select {
+		case b.ch <- p:
+		case <-b.stopped:
+			b.detached = true
+		}
+	}
+	return true
+}
+
+// dropped counts an element dropped for branch i
+func (t *lazyTee[T]) dropped(i int) {
+	if t.cfg.Stats != nil {
+		t.cfg.Stats.dropped[i].Add(1)
+	}
+}
+
+// ============================================================================
+// SPILLING TEE
+// ============================================================================
+
+// DefaultTeeMemory is the memory budget SpillingTee uses when none is set
+const DefaultTeeMemory = 64 << 20 // 64 MiB
+
+// SpillingTeeConfig configures SpillingTee
+type SpillingTeeConfig struct {
+	// TempDir is the directory for the spill file. Empty uses os.TempDir().
+	TempDir string
+}
+
+// SpillingTee splits a finite record stream into n identical streams that
+// can each be read at their own pace, one after another or concurrently,
+// without losing records. Records are read from the input as the leading
+// branch needs them and kept in memory until every branch has passed them;
+// once the records held exceed about memLimit bytes (zero or less uses
+// DefaultTeeMemory), the oldest are moved to a temporary file that lagging
+// branches read back. Branches that keep up with each other never touch
+// disk.
+//
+// A branch that stops early no longer holds records back, and the spill
+// file (created in config's TempDir) is removed once every branch has
+// finished or stopped, so iterate every branch. Fields are stored as
+// ExternalSortBy stores them. SpillingTee panics if the spill file cannot be written
+// or read; use SpillingTeeSafe to handle those errors.
+//
+// Example:
+//
+//	// Write a large file to CSV and chart it without holding it in memory
+//	branches := ssql.SpillingTee(records, 2, 256<<20)
+//	if err := ssql.WriteCSV(branches[0], "out.csv"); err != nil {
+//	    return err
+//	}
+//	return ssql.QuickChart(branches[1], "date", "revenue", "revenue.html")
+func SpillingTee(input iter.Seq[Record], n int, memLimit int64, config ...SpillingTeeConfig) []iter.Seq[Record] {
+	safe := SpillingTeeSafe(Safe(input), n, memLimit, config...)
+	streams := make([]iter.Seq[Record], len(safe))
+	for i, branch := range safe {
+		streams[i] = Unsafe(branch)
+	}
+	return streams
+}
+
+// SpillingTeeSafe splits an error-aware record stream like SpillingTee.
+// An input error ends the input: every branch yields it after the records
+// before it. A spill file error is yielded by every branch that reaches it.
+func SpillingTeeSafe(input iter.Seq2[Record, error], n int, memLimit int64, config ...SpillingTeeConfig) []iter.Seq2[Record, error] {
+	if n <= 0 {
+		return nil
+	}
+	if memLimit <= 0 {
+		memLimit = DefaultTeeMemory
+	}
+	cfg := SpillingTeeConfig{}
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	t := &spillingTee{memLimit: memLimit, tempDir: cfg.TempDir, branches: make([]spillBranch, n), active: n}
+	t.pull, t.stop = iter.Pull2(input)
+
+	streams := make([]iter.Seq2[Record, error], n)
+	for i := range n {
+		streams[i] = func(yield func(Record, error) bool) {
+			if t.started(i) {
+				return
+			}
+			defer t.finish(i)
+			for {
+				r, err, ok := t.read(i)
+				if !ok || !yield(r, err) || err != nil {
+					return
+				}
+			}
+		}
+	}
+	return streams
+}
+
+// spillingTee holds the state shared by the branches of a SpillingTee.
+// Records [memStart, pulled) are in memory and records [fileBase, fileEnd)
+// in the spill file; while the file holds records, fileEnd == memStart.
+type spillingTee struct {
+	mu       sync.Mutex
+	pull     func() (Record, error, bool)
+	stop     func()
+	memLimit int64
+	tempDir  string
+
+	memory   []Record
+	memSize  int64
+	memStart int64
+	pulled   int64
+	ended    bool
+	inputErr error
+
+	file       *os.File
+	writer     *recordWriter
+	unflushed  bool
+	fileBase   int64
+	fileEnd    int64
+	generation int // incremented when the file is emptied for reuse
+
+	branches []spillBranch
+	active   int
+	err      error // sticky spill file error
+}
+
+// spillBranch is the read position of one SpillingTee branch
+type spillBranch struct {
+	pos         int64 // index of the next record to yield
+	started     bool
+	done        bool
+	file        *os.File // the branch's own handle on the spill file
+	reader      *recordReader
+	readerIndex int64 // index of the next record reader returns
+	generation  int
+}
+
+// started marks branch i as iterated, reporting whether it already was
+func (t *spillingTee) started(i int) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	b := &t.branches[i]
+	was := b.started
+	b.started = true
+	return was
+}
+
+// read returns branch i's next record; ok is false at the end of input
+func (t *spillingTee) read(i int) (r Record, err error, ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.err != nil {
+		return Record{}, t.err, true
+	}
+
+	b := &t.branches[i]
+	switch {
+	case b.pos < t.memStart:
+		if r, err = t.readSpilled(b); err != nil {
+			t.err = err
+			return Record{}, err, true
+		}
+	case b.pos < t.pulled:
+		r = t.memory[b.pos-t.memStart]
+	case t.ended:
+		return Record{}, t.inputErr, t.inputErr != nil
+	default:
+		var more bool
+		r, err, more = t.pull()
+		if !more || err != nil {
+			t.ended, t.inputErr = true, err
+			return Record{}, err, err != nil
+		}
+		t.memory = append(t.memory, r)
+		t.memSize += estimateRecordSize(r)
+		t.pulled++
+	}
+	b.pos++
+
+	if err := t.compact(); err != nil {
+		t.err = err // reported on the next read
+	}
+	return r, nil, true
+}
+
+// compact drops records every branch has passed and spills the oldest
+// records while memory is over the limit
+func (t *spillingTee) compact() error {
+	minPos := t.pulled
+	for _, b := range t.branches {
+		if !b.done && b.pos < minPos {
+			minPos = b.pos
+		}
+	}
+
+	if t.fileEnd > t.fileBase && minPos >= t.fileEnd {
+		// No branch needs the spilled records: empty the file for reuse
+		if err := t.file.Truncate(0); err != nil {
+			return fmt.Errorf("spilling tee: truncating spill file: %w", err)
+		}
+		if _, err := t.file.Seek(0, io.SeekStart); err != nil {
+			return fmt.Errorf("spilling tee: rewinding spill file: %w", err)
+		}
+		t.writer = newRecordWriter(t.file)
+		t.unflushed = false
+		t.generation++
+	}
+	for t.memStart < minPos && len(t.memory) > 0 {
+		t.dropOldest()
+	}
+	if t.fileEnd == t.fileBase || minPos >= t.fileEnd {
+		t.fileBase, t.fileEnd = t.memStart, t.memStart
+	}
+
+	for t.memSize > t.memLimit && len(t.memory) > 0 {
+		if err := t.spill(t.memory[0]); err != nil {
+			return err
+		}
+		t.dropOldest()
+		t.fileEnd++
+	}
+	return nil
+}
+
+// dropOldest removes the oldest record from memory
+func (t *spillingTee) dropOldest() {
+	t.memSize -= estimateRecordSize(t.memory[0])
+	t.memory[0] = Record{}
+	t.memory = t.memory[1:]
+	t.memStart++
+}
+
+// spill appends r to the spill file, creating it if needed
+func (t *spillingTee) spill(r Record) error {
+	if t.file == nil {
+		file, err := os.CreateTemp(t.tempDir, "ssql-tee-*.spill")
+		if err != nil {
+			return fmt.Errorf("spilling tee: creating spill file: %w", err)
+		}
+		t.file, t.writer = file, newRecordWriter(file)
+	}
+	if err := t.writer.Write(r); err != nil {
+		return fmt.Errorf("spilling tee: writing spill file: %w", err)
+	}
+	t.unflushed = true
+	return nil
+}
+
+// readSpilled reads branch b's next record back from the spill file
+func (t *spillingTee) readSpilled(b *spillBranch) (Record, error) {
+	if t.unflushed {
+		if err := t.writer.Flush(); err != nil {
+			return Record{}, fmt.Errorf("spilling tee: writing spill file: %w", err)
+		}
+		t.unflushed = false
+	}
+	if b.reader == nil || b.generation != t.generation {
+		if b.file != nil {
+			b.file.Close()
+		}
+		file, err := os.Open(t.file.Name())
+		if err != nil {
+			return Record{}, fmt.Errorf("spilling tee: opening spill file: %w", err)
+		}
+		b.file, b.reader = file, newRecordReader(file)
+		b.readerIndex, b.generation = t.fileBase, t.generation
+	}
+
+	// Skip records the branch read from memory before it fell behind
+	for ; b.readerIndex <= b.pos; b.readerIndex++ {
+		r, err := b.reader.Read()
+		if err != nil {
+			return Record{}, fmt.Errorf("spilling tee: reading spill file: %w", err)
+		}
+		if b.readerIndex == b.pos {
+			b.readerIndex++
+			return r, nil
+		}
+	}
+	return Record{}, fmt.Errorf("spilling tee: spill file reader passed record %d", b.pos)
+}
+
+// finish marks branch i as done, releasing the records only it held back
+// and cleaning up once every branch is done
+func (t *spillingTee) finish(i int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	b := &t.branches[i]
+	if b.done {
+		return
+	}
+	b.done = true
+	t.active--
+	if b.file != nil {
+		b.file.Close()
+		b.file, b.reader = nil, nil
+	}
+
+	if t.active > 0 {
+		if err := t.compact(); err != nil && t.err == nil {
+			t.err = err
+		}
+		return
+	}
+	t.stop()
+	t.memory = nil
+	if t.file != nil {
+		t.file.Close()
+		os.Remove(t.file.Name())
+		t.file = nil
+	}
+}
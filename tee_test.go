@@ -0,0 +1,328 @@
+package ssql
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"math/rand/v2"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// ============================================================================
+// LAZY TEE TESTS
+// ============================================================================
+
+// collectAll consumes every branch concurrently
+func collectAll[T any](branches []iter.Seq[T]) [][]T {
+	results := make([][]T, len(branches))
+	var wg sync.WaitGroup
+	for i, branch := range branches {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = slices.Collect(branch)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+func TestLazyTeeBlockDeliversEverything(t *testing.T) {
+	var read atomic.Int64
+	branches := LazyTee(countTo(1000, &read), 3, LazyTeeConfig{BufferSize: 1})
+	want := slices.Collect(countTo(1000, new(atomic.Int64)))
+	for i, got := range collectAll(branches) {
+		if !slices.Equal(got, want) {
+			t.Errorf("Branch %d got %d elements, want all 1000 in order", i, len(got))
+		}
+	}
+}
+
+func TestLazyTeeDropStrategies(t *testing.T) {
+	input := slices.Values([]int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10})
+
+	// Branch 1 is not read until the input is exhausted, so its two-slot
+	// buffer overflows
+	for _, tc := range []struct {
+		name     string
+		strategy Backpressure
+		want     []int
+	}{
+		{"drop newest", BackpressureDropNewest, []int{1, 2}},
+		{"drop oldest", BackpressureDropOldest, []int{9, 10}},
+	} {
+		stats := &TeeStats{}
+		branches := LazyTee(input, 2, LazyTeeConfig{Backpressure: tc.strategy, BufferSizes: []int{0, 2}, Stats: stats})
+		if got := slices.Collect(branches[0]); len(got) != 10 {
+			t.Errorf("%s: fast branch got %v", tc.name, got)
+		}
+		if got := slices.Collect(branches[1]); !slices.Equal(got, tc.want) {
+			t.Errorf("%s: slow branch got %v, want %v", tc.name, got, tc.want)
+		}
+		if stats.Dropped(0) != 0 || stats.Dropped(1) != 8 || stats.TotalDropped() != 8 {
+			t.Errorf("%s: dropped %d and %d", tc.name, stats.Dropped(0), stats.Dropped(1))
+		}
+	}
+}
+
+func TestLazyTeeSafeFail(t *testing.T) {
+	input := Safe(slices.Values([]int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}))
+	branches := LazyTeeSafe(input, 2, LazyTeeConfig{Backpressure: BackpressureFail, BufferSizes: []int{100, 2}})
+
+	for i, want := range [][]int{{1, 2, 3}, {1, 2}} {
+		var got []int
+		var err error
+		for v, e := range branches[i] {
+			if e != nil {
+				err = e
+				break
+			}
+			got = append(got, v)
+		}
+		if !slices.Equal(got, want) || !errors.Is(err, ErrTeeOverflow) {
+			t.Errorf("Branch %d got %v, %v; want %v and ErrTeeOverflow", i, got, err, want)
+		}
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("LazyTee should panic on overflow under BackpressureFail")
+		}
+	}()
+	unsafe := LazyTee(slices.Values([]int{1, 2, 3}), 2, LazyTeeConfig{Backpressure: BackpressureFail, BufferSize: 1})
+	for range unsafe[0] {
+	}
+}
+
+func TestLazyTeeEarlyStop(t *testing.T) {
+	// One branch stopping early leaves the others the complete stream
+	var read atomic.Int64
+	branches := LazyTee(countTo(500, &read), 2, LazyTeeConfig{BufferSize: 1})
+	results := collectAll([]iter.Seq[int]{Limit[int](3)(branches[0]), branches[1]})
+	if len(results[0]) != 3 || len(results[1]) != 500 {
+		t.Errorf("Got %d and %d elements, want 3 and 500", len(results[0]), len(results[1]))
+	}
+
+	// Once every branch has stopped the input stops being read
+	finished := make(chan struct{})
+	source := func(yield func(int) bool) {
+		defer close(finished)
+		for i := 0; ; i++ {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+	branches = LazyTee(iter.Seq[int](source), 2, LazyTeeConfig{BufferSize: 1})
+	collectAll([]iter.Seq[int]{Limit[int](3)(branches[0]), Limit[int](5)(branches[1])})
+	select {
+	case <-finished:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Input still being read after every branch stopped")
+	}
+}
+
+// ============================================================================
+// SPILLING TEE TESTS
+// ============================================================================
+
+// teeRecords returns n records with an "id" field and a few value kinds
+func teeRecords(n int) []Record {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	records := make([]Record, n)
+	for i := range records {
+		records[i] = MakeMutableRecord().
+			Int("id", int64(i)).
+			String("name", fmt.Sprintf("item-%d", i)).
+			Float("score", float64(i)/4).
+			Time("at", base.Add(time.Duration(i)*time.Minute)).
+			Freeze()
+	}
+	return records
+}
+
+// recordIDs returns the "id" field of each record
+func recordIDs(records []Record) []int64 {
+	ids := make([]int64, len(records))
+	for i, r := range records {
+		ids[i] = GetOr(r, "id", int64(-1))
+	}
+	return ids
+}
+
+// spillFiles returns the names of the files in dir
+func spillFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	return names
+}
+
+func TestSpillingTeeSequential(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("TMPDIR", dir)
+	input := teeRecords(200)
+	want := recordIDs(input)
+
+	branches := SpillingTee(slices.Values(input), 3, 1024)
+	first := slices.Collect(branches[0])
+	if !slices.Equal(recordIDs(first), want) || !slices.EqualFunc(first, input, Record.Equal) {
+		t.Fatalf("First branch got %v", recordIDs(first))
+	}
+	if files := spillFiles(t, dir); len(files) != 1 {
+		t.Errorf("Expected one spill file while branches lag, got %v", files)
+	}
+	for i, branch := range branches[1:] {
+		if got := slices.Collect(branch); !slices.EqualFunc(got, input, Record.Equal) {
+			t.Errorf("Branch %d got %v", i+1, recordIDs(got))
+		}
+	}
+	if files := spillFiles(t, dir); len(files) != 0 {
+		t.Errorf("Spill file not removed: %v", files)
+	}
+}
+
+func TestSpillingTeeInterleaved(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("TMPDIR", dir)
+	input := teeRecords(500)
+	want := recordIDs(input)
+
+	// Branches advance by random steps, repeatedly falling behind and
+	// catching up, so the spill file is written, emptied and reused
+	rng := rand.New(rand.NewPCG(1, 2))
+	branches := SpillingTee(slices.Values(input), 3, 2048)
+	nexts := make([]func() (Record, bool), len(branches))
+	got := make([][]Record, len(branches))
+	for i, branch := range branches {
+		next, stop := iter.Pull(branch)
+		defer stop()
+		nexts[i] = next
+	}
+	finished := make([]bool, len(branches))
+	for remaining := len(branches); remaining > 0; {
+		i := rng.IntN(len(branches))
+		for step := rng.IntN(40); step > 0 && !finished[i]; step-- {
+			r, ok := nexts[i]()
+			if !ok {
+				finished[i] = true
+				remaining--
+				break
+			}
+			got[i] = append(got[i], r)
+		}
+	}
+	for i := range got {
+		if !slices.Equal(recordIDs(got[i]), want) {
+			t.Errorf("Branch %d got %v", i, recordIDs(got[i]))
+		}
+	}
+
+	// Concurrent readers
+	results := collectAll(SpillingTee(slices.Values(input), 4, 2048))
+	for i, r := range results {
+		if !slices.Equal(recordIDs(r), want) {
+			t.Errorf("Concurrent branch %d got %d records", i, len(r))
+		}
+	}
+	if files := spillFiles(t, dir); len(files) != 0 {
+		t.Errorf("Spill files not removed: %v", files)
+	}
+}
+
+func TestSpillingTeeLockstepStaysInMemory(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("TMPDIR", dir)
+	branches := SpillingTee(slices.Values(teeRecords(100)), 2, 2048)
+	next0, stop0 := iter.Pull(branches[0])
+	next1, stop1 := iter.Pull(branches[1])
+	defer stop0()
+	defer stop1()
+	for range 100 {
+		a, _ := next0()
+		b, _ := next1()
+		if !a.Equal(b) {
+			t.Fatalf("Branches diverged: %v and %v", a, b)
+		}
+	}
+	if files := spillFiles(t, dir); len(files) != 0 {
+		t.Errorf("Branches in lockstep should not spill, got %v", files)
+	}
+}
+
+func TestSpillingTeeJSONRecordsInTempDir(t *testing.T) {
+	dir := t.TempDir()
+	var lines strings.Builder
+	for i := range 100 {
+		fmt.Fprintf(&lines, `{"id": %d, "user": {"name": "u%d", "roles": ["admin", null]}, "scores": [%d.5, {"k": true}]}`+"\n", i, i, i)
+	}
+	input := slices.Collect(ReadJSONFromReader(strings.NewReader(lines.String())))
+
+	branches := SpillingTee(slices.Values(input), 2, 1024, SpillingTeeConfig{TempDir: dir})
+	first := slices.Collect(branches[0])
+	if len(first) != len(input) {
+		t.Fatalf("First branch got %d records", len(first))
+	}
+	if files := spillFiles(t, dir); len(files) != 1 {
+		t.Errorf("Expected the spill file in TempDir, got %v", files)
+	}
+	second := slices.Collect(branches[1])
+	if len(second) != len(input) {
+		t.Fatalf("Spilled branch got %d records", len(second))
+	}
+	for i := range input {
+		if d := DiffRecords(input[i], second[i]); !d.Empty() {
+			t.Fatalf("Record %d changed through spill: %+v", i, d)
+		}
+	}
+	if files := spillFiles(t, dir); len(files) != 0 {
+		t.Errorf("Spill file not removed: %v", files)
+	}
+}
+
+func TestSpillingTeeEarlyStopAndErrors(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	input := teeRecords(100)
+	branches := SpillingTee(slices.Values(input), 2, 1024)
+	if got := slices.Collect(Limit[Record](5)(branches[0])); len(got) != 5 {
+		t.Errorf("Stopped branch got %d records", len(got))
+	}
+	if got := slices.Collect(branches[1]); !slices.Equal(recordIDs(got), recordIDs(input)) {
+		t.Errorf("Remaining branch got %v", recordIDs(got))
+	}
+
+	failing := func(yield func(Record, error) bool) {
+		for _, r := range input[:3] {
+			if !yield(r, nil) {
+				return
+			}
+		}
+		yield(Record{}, errors.New("read failed"))
+	}
+	for i, branch := range SpillingTeeSafe(failing, 2, 0) {
+		var ids []int64
+		var err error
+		for r, e := range branch {
+			if e != nil {
+				err = e
+				continue
+			}
+			ids = append(ids, GetOr(r, "id", int64(-1)))
+		}
+		if !slices.Equal(ids, []int64{0, 1, 2}) || err == nil || err.Error() != "read failed" {
+			t.Errorf("Branch %d got %v, %v", i, ids, err)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package ssql
+
+import (
+	"cmp"
+	"container/heap"
+	"iter"
+	"slices"
+)
+
+// ============================================================================
+// TOP-K OPERATIONS
+// ============================================================================
+
+// TopKTracker keeps the k items with the largest (or, from
+// NewBottomKTracker, smallest) keys seen so far in a bounded heap, using
+// O(k) memory however many items are added. Among equal keys the earliest
+// added items are kept. Use it directly to read the current top k of an
+// unbounded stream at any point; the TopK filters wrap it and emit at the
+// end of their input.
+type TopKTracker[T any, K cmp.Ordered] struct {
+	k     int
+	keyFn func(T) K
+	heap  topKHeap[T, K]
+	seq   int64
+}
+
+// NewTopKTracker creates a tracker of the k items with the largest keys
+func NewTopKTracker[T any, K cmp.Ordered](k int, keyFn func(T) K) *TopKTracker[T, K] {
+	return &TopKTracker[T, K]{k: k, keyFn: keyFn}
+}
+
+// NewBottomKTracker creates a tracker of the k items with the smallest keys
+func NewBottomKTracker[T any, K cmp.Ordered](k int, keyFn func(T) K) *TopKTracker[T, K] {
+	return &TopKTracker[T, K]{k: k, keyFn: keyFn, heap: topKHeap[T, K]{bottom: true}}
+}
+
+// Add offers an item, keeping it if it ranks among the best k so far
+func (t *TopKTracker[T, K]) Add(item T) {
+	if t.k <= 0 {
+		return
+	}
+	e := topKEntry[T, K]{item: item, key: t.keyFn(item), seq: t.seq}
+	t.seq++
+	if len(t.heap.entries) < t.k {
+		heap.Push(&t.heap, e)
+		return
+	}
+	// The root is the entry that would be evicted first
+	if t.heap.worse(t.heap.entries[0], e) {
+		t.heap.entries[0] = e
+		heap.Fix(&t.heap, 0)
+	}
+}
+
+// Items returns the kept items, best first
+func (t *TopKTracker[T, K]) Items() []T {
+	entries := slices.Clone(t.heap.entries)
+	slices.SortFunc(entries, func(a, b topKEntry[T, K]) int {
+		if t.heap.worse(b, a) {
+			return -1
+		}
+		return 1
+	})
+	items := make([]T, len(entries))
+	for i, e := range entries {
+		items[i] = e.item
+	}
+	return items
+}
+
+// Len returns the number of items kept, at most k
+func (t *TopKTracker[T, K]) Len() int {
+	return len(t.heap.entries)
+}
+
+// Reset discards the kept items
+func (t *TopKTracker[T, K]) Reset() {
+	clear(t.heap.entries)
+	t.heap.entries = t.heap.entries[:0]
+	t.seq = 0
+}
+
+// topKEntry is a kept item with its key and arrival order
+type topKEntry[T any, K cmp.Ordered] struct {
+	item T
+	key  K
+	seq  int64
+}
+
+// topKHeap is a heap with the worst kept entry at the root
+type topKHeap[T any, K cmp.Ordered] struct {
+	entries []topKEntry[T, K]
+	bottom  bool // keep the smallest keys instead of the largest
+}
+
+// worse reports whether a ranks below b: a smaller key (larger for
+// bottom), or an equal key that arrived later
+func (h *topKHeap[T, K]) worse(a, b topKEntry[T, K]) bool {
+	c := cmp.Compare(a.key, b.key)
+	if h.bottom {
+		c = -c
+	}
+	if c != 0 {
+		return c < 0
+	}
+	return a.seq > b.seq
+}
+
+func (h *topKHeap[T, K]) Len() int { return len(h.entries) }
+
+func (h *topKHeap[T, K]) Less(i, j int) bool { return h.worse(h.entries[i], h.entries[j]) }
+
+func (h *topKHeap[T, K]) Swap(i, j int) { h.entries[i], h.entries[j] = h.entries[j], h.entries[i] }
+
+func (h *topKHeap[T, K]) Push(x any) { h.entries = append(h.entries, x.(topKEntry[T, K])) }
+
+func (h *topKHeap[T, K]) Pop() any {
+	last := h.entries[len(h.entries)-1]
+	h.entries = h.entries[:len(h.entries)-1]
+	return last
+}
+
+// TopK keeps the k elements with the largest keys and emits them, largest
+// first, when the input ends. It holds only k elements, unlike SortBy
+// followed by Limit, which holds and sorts the whole input. Elements with
+// equal keys keep their input order. For an unbounded stream, apply TopK
+// to each window, or use a TopKTracker to read the current top k on demand.
+//
+// Example:
+//
+//	// Five largest orders
+//	biggest := ssql.TopK(5, func(r ssql.Record) float64 {
+//	    return ssql.GetOr(r, "amount", 0.0)
+//	})(orders)
+func TopK[T any, K cmp.Ordered](k int, keyFn func(T) K) Filter[T, T] {
+	return topK(func() *TopKTracker[T, K] { return NewTopKTracker(k, keyFn) })
+}
+
+// BottomK keeps the k elements with the smallest keys and emits them,
+// smallest first, when the input ends. See TopK.
+func BottomK[T any, K cmp.Ordered](k int, keyFn func(T) K) Filter[T, T] {
+	return topK(func() *TopKTracker[T, K] { return NewBottomKTracker(k, keyFn) })
+}
+
+func topK[T any, K cmp.Ordered](newTracker func() *TopKTracker[T, K]) Filter[T, T] {
+	return func(input iter.Seq[T]) iter.Seq[T] {
+		return func(yield func(T) bool) {
+			t := newTracker()
+			for v := range input {
+				t.Add(v)
+			}
+			for _, v := range t.Items() {
+				if !yield(v) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// TopKBy keeps the k records with the largest keys in each group of
+// groupFields and emits them when the input ends: groups in order of first
+// appearance, each group's records largest first. Memory is O(k) per
+// group. Records are grouped like GroupByFields, so records whose grouping
+// field holds a nested Record or sequence are skipped. With no groupFields
+// it is TopK over the whole input.
+//
+// Example:
+//
+//	// Top 3 products by revenue in every region
+//	best := ssql.TopKBy(3, []string{"region"}, func(r ssql.Record) float64 {
+//	    return ssql.GetOr(r, "revenue", 0.0)
+//	})(sales)
+func TopKBy[K cmp.Ordered](k int, groupFields []string, keyFn func(Record) K) Filter[Record, Record] {
+	return topKBy(groupFields, func() *TopKTracker[Record, K] { return NewTopKTracker(k, keyFn) })
+}
+
+// BottomKBy keeps the k records with the smallest keys in each group,
+// smallest first. See TopKBy.
+func BottomKBy[K cmp.Ordered](k int, groupFields []string, keyFn func(Record) K) Filter[Record, Record] {
+	return topKBy(groupFields, func() *TopKTracker[Record, K] { return NewBottomKTracker(k, keyFn) })
+}
+
+func topKBy[K cmp.Ordered](groupFields []string, newTracker func() *TopKTracker[Record, K]) Filter[Record, Record] {
+	return func(input iter.Seq[Record]) iter.Seq[Record] {
+		return func(yield func(Record) bool) {
+			trackers := make(map[string]*TopKTracker[Record, K])
+			var order []*TopKTracker[Record, K]
+			for r := range input {
+				key, _, ok := groupKey(r, groupFields)
+				if !ok {
+					continue
+				}
+				t, exists := trackers[key]
+				if !exists {
+					t = newTracker()
+					trackers[key] = t
+					order = append(order, t)
+				}
+				t.Add(r)
+			}
+			for _, t := range order {
+				for _, r := range t.Items() {
+					if !yield(r) {
+						return
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package ssql
+
+import (
+	"iter"
+	"slices"
+	"testing"
+)
+
+// ============================================================================
+// TOP-K TESTS
+// ============================================================================
+
+func TestTopK(t *testing.T) {
+	values := []int{5, 1, 9, 3, 9, 7, 2}
+	identity := func(v int) int { return v }
+
+	if got := slices.Collect(TopK(3, identity)(slices.Values(values))); !slices.Equal(got, []int{9, 9, 7}) {
+		t.Errorf("TopK(3) = %v", got)
+	}
+	if got := slices.Collect(BottomK(2, identity)(slices.Values(values))); !slices.Equal(got, []int{1, 2}) {
+		t.Errorf("BottomK(2) = %v", got)
+	}
+	if got := slices.Collect(TopK(10, identity)(slices.Values(values))); len(got) != len(values) || got[0] != 9 || got[6] != 1 {
+		t.Errorf("TopK larger than input = %v", got)
+	}
+	if got := slices.Collect(TopK(0, identity)(slices.Values(values))); len(got) != 0 {
+		t.Errorf("TopK(0) = %v", got)
+	}
+
+	// Equal keys keep the earliest items, in input order
+	type item struct {
+		name  string
+		score int
+	}
+	items := []item{{"a", 1}, {"b", 2}, {"c", 2}, {"d", 2}}
+	var names []string
+	for it := range TopK(2, func(it item) int { return it.score })(slices.Values(items)) {
+		names = append(names, it.name)
+	}
+	if !slices.Equal(names, []string{"b", "c"}) {
+		t.Errorf("Expected ties to keep b, c; got %v", names)
+	}
+
+	// The tracker holds at most k items and can be read at any time
+	tracker := NewTopKTracker(2, identity)
+	for _, v := range values {
+		tracker.Add(v)
+		if tracker.Len() > 2 {
+			t.Fatalf("Tracker grew to %d items", tracker.Len())
+		}
+	}
+	if !slices.Equal(tracker.Items(), []int{9, 9}) {
+		t.Errorf("Tracker items = %v", tracker.Items())
+	}
+	tracker.Reset()
+	tracker.Add(4)
+	if !slices.Equal(tracker.Items(), []int{4}) {
+		t.Errorf("Items after Reset = %v", tracker.Items())
+	}
+}
+
+func TestTopKBy(t *testing.T) {
+	sale := func(region string, revenue float64) Record {
+		return MakeMutableRecord().String("region", region).Float("revenue", revenue).Freeze()
+	}
+	sales := []Record{
+		sale("west", 10), sale("east", 50), sale("west", 30),
+		sale("east", 20), sale("west", 20), sale("east", 40),
+		MakeMutableRecord().Nested("region", MakeMutableRecord().Freeze()).Float("revenue", 99).Freeze(),
+	}
+	revenue := func(r Record) float64 { return GetOr(r, "revenue", 0.0) }
+
+	type pair struct {
+		region  string
+		revenue float64
+	}
+	collect := func(records iter.Seq[Record]) []pair {
+		var out []pair
+		for r := range records {
+			out = append(out, pair{GetOr(r, "region", ""), revenue(r)})
+		}
+		return out
+	}
+
+	got := collect(TopKBy(2, []string{"region"}, revenue)(slices.Values(sales)))
+	want := []pair{{"west", 30}, {"west", 20}, {"east", 50}, {"east", 40}}
+	if !slices.Equal(got, want) {
+		t.Errorf("TopKBy = %v, want %v", got, want)
+	}
+
+	got = collect(BottomKBy(1, []string{"region"}, revenue)(slices.Values(sales)))
+	want = []pair{{"west", 10}, {"east", 20}}
+	if !slices.Equal(got, want) {
+		t.Errorf("BottomKBy = %v, want %v", got, want)
+	}
+
+	// No group fields ranks the whole input
+	if got := collect(TopKBy(1, nil, revenue)(slices.Values(sales))); len(got) != 1 || got[0].revenue != 99 {
+		t.Errorf("Ungrouped TopKBy = %v", got)
+	}
+}
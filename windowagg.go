@@ -0,0 +1,79 @@
+package ssql
+
+import (
+	"time"
+)
+
+// ============================================================================
+// WINDOW AGGREGATION
+// ============================================================================
+
+// windowSequenceField holds window members between the window and Aggregate
+const windowSequenceField = "_window"
+
+// WindowSpec describes how WindowAggregate groups records into windows.
+// Build one with Tumbling, Sliding or Sessions, or use an
+// EventTimeWindowConfig or SessionWindowConfig directly.
+type WindowSpec interface {
+	// window returns the windowing filter and the field of its output
+	// records holding each window's members
+	window() (Filter[Record, Record], string)
+}
+
+// Tumbling returns a spec for back-to-back event-time windows of length
+// size, per key if keyFields are given. Set the returned config's
+// Watermark, AllowedLateness and Late fields to handle out-of-order input.
+func Tumbling(size time.Duration, timeField string, keyFields ...string) EventTimeWindowConfig {
+	return EventTimeWindowConfig{TimeField: timeField, Size: size, KeyFields: keyFields}
+}
+
+// Sliding returns a spec for overlapping event-time windows of length
+// size starting every slide, per key if keyFields are given
+func Sliding(size, slide time.Duration, timeField string, keyFields ...string) EventTimeWindowConfig {
+	return EventTimeWindowConfig{TimeField: timeField, Size: size, Slide: slide, KeyFields: keyFields}
+}
+
+func (c EventTimeWindowConfig) window() (Filter[Record, Record], string) {
+	return EventTimeWindow(windowSequenceField, c), windowSequenceField
+}
+
+// SessionWindowConfig is a WindowSpec for SessionWindow
+type SessionWindowConfig struct {
+	Gap       time.Duration
+	TimeField string
+	KeyFields []string
+}
+
+// Sessions returns a spec for per-key sessions split by inactivity gaps
+func Sessions(gap time.Duration, timeField string, keyFields ...string) SessionWindowConfig {
+	return SessionWindowConfig{Gap: gap, TimeField: timeField, KeyFields: keyFields}
+}
+
+func (c SessionWindowConfig) window() (Filter[Record, Record], string) {
+	return SessionWindow(c.Gap, c.TimeField, c.KeyFields...), SessionRecordsField
+}
+
+// WindowAggregate groups records into the windows of spec and emits one
+// summary record per window: the window's key fields and bounds
+// (WindowStartField and WindowEndField, or the session fields for
+// Sessions) followed by the aggregations, computed with the same
+// AggregateFunc set as Aggregate. Summaries are emitted as windows close,
+// so it works on unbounded streams.
+//
+// Example:
+//
+//	// Bytes and requests per host in five-minute windows
+//	traffic := ssql.WindowAggregate(ssql.Tumbling(5*time.Minute, "ts", "host"), map[string]ssql.AggregateFunc{
+//	    "requests": ssql.Count(),
+//	    "bytes":    ssql.Sum("bytes"),
+//	    "slowest":  ssql.Max[float64]("ms"),
+//	})(logs)
+//
+//	// Tolerate a minute of disorder
+//	spec := ssql.Tumbling(5*time.Minute, "ts")
+//	spec.Watermark = ssql.BoundedOutOfOrderness(time.Minute)
+//	traffic = ssql.WindowAggregate(spec, aggregations)(logs)
+func WindowAggregate(spec WindowSpec, aggregations map[string]AggregateFunc) Filter[Record, Record] {
+	window, sequenceField := spec.window()
+	return Chain(window, Aggregate(sequenceField, aggregations))
+}
@@ -0,0 +1,76 @@
+package ssql
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// ============================================================================
+// WINDOW AGGREGATION TESTS
+// ============================================================================
+
+func TestWindowAggregate(t *testing.T) {
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	request := func(minute int, host string, bytes int64) Record {
+		return MakeMutableRecord().
+			Time("ts", base.Add(time.Duration(minute)*time.Minute)).
+			String("host", host).
+			Int("bytes", bytes).
+			Freeze()
+	}
+	logs := []Record{
+		request(0, "a", 100), request(2, "b", 50), request(4, "a", 300),
+		request(6, "a", 10), request(11, "b", 70), request(7, "b", 5),
+	}
+	aggregations := map[string]AggregateFunc{
+		"requests": Count(),
+		"bytes":    Sum("bytes"),
+		"first":    First[int64]("bytes"),
+		"last":     Last[int64]("bytes"),
+	}
+
+	spec := Tumbling(5*time.Minute, "ts", "host")
+	spec.Watermark = BoundedOutOfOrderness(5 * time.Minute)
+	summaries := slices.Collect(WindowAggregate(spec, aggregations)(slices.Values(logs)))
+	if len(summaries) != 5 {
+		t.Fatalf("Expected 5 windows, got %d: %v", len(summaries), summaries)
+	}
+
+	first := summaries[0]
+	if !slices.Equal(first.Keys(), []string{"host", WindowStartField, WindowEndField, "bytes", "first", "last", "requests"}) {
+		t.Errorf("Unexpected summary fields %v", first.Keys())
+	}
+	if GetOr(first, "host", "") != "a" || GetOr(first, "requests", int64(0)) != 2 || GetOr(first, "bytes", 0.0) != 400 ||
+		GetOr(first, "first", int64(0)) != 100 || GetOr(first, "last", int64(0)) != 300 {
+		t.Errorf("Unexpected first window %v", first)
+	}
+	if !GetOr(first, WindowStartField, time.Time{}).Equal(base) {
+		t.Errorf("Unexpected window start %v", GetOr(first, WindowStartField, time.Time{}))
+	}
+	// b's 5-10 window still holds the out-of-order request at minute 7
+	if late := summaries[3]; GetOr(late, "host", "") != "b" || GetOr(late, "bytes", 0.0) != 5 {
+		t.Errorf("Unexpected window for the out-of-order request %v", late)
+	}
+	if last := summaries[4]; GetOr(last, "host", "") != "b" || GetOr(last, "bytes", 0.0) != 70 {
+		t.Errorf("Unexpected last window %v", last)
+	}
+
+	sliding := slices.Collect(WindowAggregate(Sliding(10*time.Minute, 5*time.Minute, "ts"), map[string]AggregateFunc{
+		"requests": Count(),
+	})(slices.Values(logs[:4])))
+	var counts []int64
+	for _, s := range sliding {
+		counts = append(counts, GetOr(s, "requests", int64(0)))
+	}
+	if !slices.Equal(counts, []int64{3, 4, 1}) {
+		t.Errorf("Sliding window counts = %v", counts)
+	}
+
+	sessions := slices.Collect(WindowAggregate(Sessions(3*time.Minute, "ts", "host"), map[string]AggregateFunc{
+		"bytes": Sum("bytes"),
+	})(slices.Values(logs[:4])))
+	if len(sessions) != 3 || GetOr(sessions[0], SessionCountField, int64(0)) != 1 || GetOr(sessions[2], "bytes", 0.0) != 310 {
+		t.Errorf("Unexpected session summaries %v", sessions)
+	}
+}